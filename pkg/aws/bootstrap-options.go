@@ -0,0 +1,142 @@
+package pkgaws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+
+	"github.com/devpablocristo/tech-house/pkg/aws/defs"
+)
+
+const defaultRoleSessionName = "tech-house"
+
+// Options configura explícitamente el stack de AWS real, sin leer el entorno
+type Options struct {
+	Region string // Región AWS (obligatoria)
+
+	// Credenciales base: un perfil compartido (~/.aws) o access key/secret estáticos.
+	// Son excluyentes; si no se indica ninguno se usa la cadena por defecto del SDK.
+	Profile         string
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// RoleARN asume ese rol (cross-account) a partir de las credenciales base
+	RoleARN         string
+	RoleSessionName string
+
+	Services []string
+
+	// Tracing instrumenta los clientes con X-Ray
+	Tracing bool
+
+	// ClientInit elige entre crear los clientes al conectar (eager, por defecto) o en su primer uso (lazy)
+	ClientInit string
+
+	// AssumeRoleClient reemplaza el cliente STS usado para asumir el rol (tests, endpoints propios)
+	AssumeRoleClient stscreds.AssumeRoleAPIClient
+}
+
+// Validate rechaza combinaciones de opciones inválidas o contradictorias
+func (o Options) Validate() error {
+	if o.Region == "" {
+		return NewConfigError("region", ErrConfigMissing, "region is required", nil)
+	}
+	if o.Profile != "" && (o.AccessKeyID != "" || o.SecretAccessKey != "") {
+		return NewConfigError("profile", ErrConfigInvalid, "profile and static credentials are mutually exclusive", nil)
+	}
+	if (o.AccessKeyID == "") != (o.SecretAccessKey == "") {
+		return NewConfigError("credentials", ErrConfigInvalid, "access key id and secret access key must be set together", nil)
+	}
+	if o.RoleARN != "" && !strings.HasPrefix(o.RoleARN, "arn:") {
+		return NewConfigError("role_arn", ErrConfigInvalid, fmt.Sprintf("invalid role ARN: %s", o.RoleARN), nil)
+	}
+	if o.RoleARN == "" && (o.RoleSessionName != "" || o.AssumeRoleClient != nil) {
+		return NewConfigError("role_arn", ErrConfigMissing, "role session name and assume role client require a role ARN", nil)
+	}
+	for _, service := range o.Services {
+		if !defs.ValidServices[service] {
+			return NewConfigError("services", ErrConfigInvalid, fmt.Sprintf("invalid service: %s", service), nil)
+		}
+	}
+	return nil
+}
+
+// BootstrapWithOptions crea un Stack de AWS real con región, perfil y/o assume-role explícitos.
+// A diferencia de Bootstrap no se memoiza: cada llamada crea un stack nuevo.
+func BootstrapWithOptions(opts Options) (defs.Stack, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	creds, err := resolveCredentials(context.Background(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	configOpts := []ConfigOption{
+		WithRegion(opts.Region),
+		WithCredentialsProvider(creds),
+		WithTracing(opts.Tracing),
+		WithClientInit(opts.ClientInit),
+	}
+	if len(opts.Services) > 0 {
+		configOpts = append(configOpts, WithServices(opts.Services))
+	}
+
+	cfg := NewConfig(defs.ProviderAWS, configOpts...)
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	factory, err := NewStackFactory(defs.ProviderAWS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stack factory: %w", err)
+	}
+
+	return factory.CreateStack(cfg)
+}
+
+// resolveCredentials arma el proveedor de credenciales: base (perfil, estáticas o cadena por defecto)
+// y, si hay RoleARN, el assume-role encima de la base con cache de credenciales
+func resolveCredentials(ctx context.Context, opts Options) (aws.CredentialsProvider, error) {
+	var base aws.CredentialsProvider
+	switch {
+	case opts.AccessKeyID != "":
+		base = credentials.NewStaticCredentialsProvider(opts.AccessKeyID, opts.SecretAccessKey, "")
+	default:
+		loadOpts := []func(*config.LoadOptions) error{config.WithRegion(opts.Region)}
+		if opts.Profile != "" {
+			loadOpts = append(loadOpts, config.WithSharedConfigProfile(opts.Profile))
+		}
+		cfg, err := config.LoadDefaultConfig(ctx, loadOpts...)
+		if err != nil {
+			return nil, NewConfigError("profile", ErrConfigInvalid, "failed to load AWS credentials", err).
+				WithDetail("profile", opts.Profile)
+		}
+		base = cfg.Credentials
+	}
+
+	if opts.RoleARN == "" {
+		return base, nil
+	}
+
+	client := opts.AssumeRoleClient
+	if client == nil {
+		client = sts.NewFromConfig(aws.Config{Region: opts.Region, Credentials: base})
+	}
+
+	sessionName := opts.RoleSessionName
+	if sessionName == "" {
+		sessionName = defaultRoleSessionName
+	}
+
+	return aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(client, opts.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = sessionName
+	})), nil
+}
@@ -0,0 +1,102 @@
+package pkgaws_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgaws "github.com/devpablocristo/tech-house/pkg/aws"
+)
+
+// stubAssumeRoleClient devuelve credenciales fijas sin llamar a STS
+type stubAssumeRoleClient struct {
+	input *sts.AssumeRoleInput
+}
+
+func (s *stubAssumeRoleClient) AssumeRole(ctx context.Context, params *sts.AssumeRoleInput, optFns ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	s.input = params
+	return &sts.AssumeRoleOutput{
+		Credentials: &ststypes.Credentials{
+			AccessKeyId:     aws.String("ASSUMED-KEY"),
+			SecretAccessKey: aws.String("assumed-secret"),
+			SessionToken:    aws.String("assumed-token"),
+			Expiration:      aws.Time(time.Now().Add(time.Hour)),
+		},
+	}, nil
+}
+
+func Test_BootstrapWithOptions(t *testing.T) {
+	t.Run("should assume role on top of static credentials", func(t *testing.T) {
+		client := &stubAssumeRoleClient{}
+
+		stack, err := pkgaws.BootstrapWithOptions(pkgaws.Options{
+			Region:           "us-west-2",
+			AccessKeyID:      "base-key",
+			SecretAccessKey:  "base-secret",
+			RoleARN:          "arn:aws:iam::123456789012:role/customers",
+			RoleSessionName:  "customers-test",
+			AssumeRoleClient: client,
+		})
+		require.NoError(t, err)
+
+		cfg := stack.GetConfig()
+		assert.Equal(t, "us-west-2", cfg.Region)
+
+		creds, err := cfg.Credentials.Retrieve(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "ASSUMED-KEY", creds.AccessKeyID)
+		assert.Equal(t, "assumed-token", creds.SessionToken)
+
+		require.NotNil(t, client.input)
+		assert.Equal(t, "arn:aws:iam::123456789012:role/customers", aws.ToString(client.input.RoleArn))
+		assert.Equal(t, "customers-test", aws.ToString(client.input.RoleSessionName))
+	})
+
+	t.Run("should load credentials from named profile", func(t *testing.T) {
+		dir := t.TempDir()
+		credsFile := filepath.Join(dir, "credentials")
+		require.NoError(t, os.WriteFile(credsFile, []byte("[dev]\naws_access_key_id = PROFILE-KEY\naws_secret_access_key = profile-secret\n"), 0o600))
+		t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credsFile)
+		t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+
+		stack, err := pkgaws.BootstrapWithOptions(pkgaws.Options{
+			Region:  "eu-west-1",
+			Profile: "dev",
+		})
+		require.NoError(t, err)
+
+		creds, err := stack.GetConfig().Credentials.Retrieve(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "PROFILE-KEY", creds.AccessKeyID)
+	})
+
+	t.Run("should reject invalid option combinations", func(t *testing.T) {
+		tests := []struct {
+			name string
+			opts pkgaws.Options
+		}{
+			{name: "missing region", opts: pkgaws.Options{Profile: "dev"}},
+			{name: "profile with static credentials", opts: pkgaws.Options{Region: "us-east-1", Profile: "dev", AccessKeyID: "key", SecretAccessKey: "secret"}},
+			{name: "access key without secret", opts: pkgaws.Options{Region: "us-east-1", AccessKeyID: "key"}},
+			{name: "malformed role ARN", opts: pkgaws.Options{Region: "us-east-1", RoleARN: "customers-role"}},
+			{name: "session name without role", opts: pkgaws.Options{Region: "us-east-1", RoleSessionName: "session"}},
+			{name: "invalid service", opts: pkgaws.Options{Region: "us-east-1", Services: []string{"dynamo"}}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := pkgaws.BootstrapWithOptions(tt.opts)
+				require.Error(t, err)
+				assert.True(t, pkgaws.IsConfigError(err))
+			})
+		}
+	})
+}
@@ -3,14 +3,55 @@ package pkgaws
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/spf13/viper"
 
 	"github.com/devpablocristo/tech-house/pkg/aws/defs"
 )
 
-// Bootstrap inicializa y retorna un Stack AWS basado en la configuración del entorno
+var (
+	instance defs.Stack
+	mu       sync.Mutex
+)
+
+// Bootstrap retorna el Stack AWS basado en la configuración del entorno.
+// El stack se inicializa una sola vez y se reutiliza en las llamadas siguientes (misma aws.Config
+// y mismos clientes), lo que evita pagar la inicialización en cada handler construido.
+// Es seguro para uso concurrente. Si la inicialización falla el error no se cachea y el próximo
+// llamado vuelve a intentar.
 func Bootstrap() (defs.Stack, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if instance != nil {
+		return instance, nil
+	}
+
+	stack, err := newStackFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	instance = stack
+	return instance, nil
+}
+
+// Rebootstrap descarta el stack cacheado y lo vuelve a inicializar con la configuración actual del entorno
+func Rebootstrap() (defs.Stack, error) {
+	ResetBootstrap()
+	return Bootstrap()
+}
+
+// ResetBootstrap descarta el stack cacheado; el próximo Bootstrap lo vuelve a crear. Pensado para tests.
+func ResetBootstrap() {
+	mu.Lock()
+	defer mu.Unlock()
+	instance = nil
+}
+
+// newStackFromEnv crea un Stack AWS nuevo a partir de la configuración del entorno
+func newStackFromEnv() (defs.Stack, error) {
 	// Validar y obtener el provider
 	provider := viper.GetString("AWS_PROVIDER")
 	if provider == "" {
@@ -29,6 +70,8 @@ func Bootstrap() (defs.Stack, error) {
 	opts := []ConfigOption{
 		WithCredentials(accessKey, secretKey),
 		WithRegion(region),
+		WithTracing(viper.GetBool("AWS_XRAY_ENABLED")),
+		WithClientInit(viper.GetString("AWS_CLIENT_INIT")),
 	}
 
 	// Validar y configurar servicios si están especificados
@@ -0,0 +1,87 @@
+package pkgaws_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgaws "github.com/devpablocristo/tech-house/pkg/aws"
+	defs "github.com/devpablocristo/tech-house/pkg/aws/defs"
+)
+
+func setAWSEnv(t *testing.T) {
+	t.Helper()
+	viper.Set("AWS_PROVIDER", defs.ProviderAWS)
+	viper.Set("AWS_ACCESS_KEY_ID", "test-access-key")
+	viper.Set("AWS_SECRET_ACCESS_KEY", "test-secret-key")
+	viper.Set("AWS_REGION", "us-east-1")
+	t.Cleanup(func() {
+		viper.Reset()
+		pkgaws.ResetBootstrap()
+	})
+}
+
+func Test_Bootstrap_Memoized(t *testing.T) {
+	t.Run("should reuse the same stack on repeated calls", func(t *testing.T) {
+		setAWSEnv(t)
+
+		first, err := pkgaws.Bootstrap()
+		require.NoError(t, err)
+		second, err := pkgaws.Bootstrap()
+		require.NoError(t, err)
+
+		assert.Same(t, first, second)
+	})
+
+	t.Run("should return a single stack under concurrent calls", func(t *testing.T) {
+		setAWSEnv(t)
+
+		stacks := make([]defs.Stack, 10)
+		var wg sync.WaitGroup
+		for i := range stacks {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				stack, err := pkgaws.Bootstrap()
+				assert.NoError(t, err)
+				stacks[i] = stack
+			}(i)
+		}
+		wg.Wait()
+
+		for _, stack := range stacks {
+			assert.Same(t, stacks[0], stack)
+		}
+	})
+
+	t.Run("should create a new stack after reset", func(t *testing.T) {
+		setAWSEnv(t)
+
+		first, err := pkgaws.Bootstrap()
+		require.NoError(t, err)
+
+		second, err := pkgaws.Rebootstrap()
+		require.NoError(t, err)
+
+		assert.NotSame(t, first, second)
+		third, err := pkgaws.Bootstrap()
+		require.NoError(t, err)
+		assert.Same(t, second, third)
+	})
+
+	t.Run("should not cache failed initialization", func(t *testing.T) {
+		setAWSEnv(t)
+		viper.Set("AWS_PROVIDER", "")
+
+		_, err := pkgaws.Bootstrap()
+		require.Error(t, err)
+
+		viper.Set("AWS_PROVIDER", defs.ProviderAWS)
+		stack, err := pkgaws.Bootstrap()
+		require.NoError(t, err)
+		assert.NotNil(t, stack)
+	})
+}
@@ -3,6 +3,8 @@ package pkgaws
 import (
 	"fmt"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+
 	"github.com/devpablocristo/tech-house/pkg/aws/defs"
 )
 
@@ -17,6 +19,9 @@ type Config struct {
 	webUIPort       int
 	services        []string
 	dataDir         string
+	credentials     aws.CredentialsProvider
+	tracing         bool
+	clientInit      string
 }
 
 // ConfigOption define un modificador de configuración
@@ -25,10 +30,11 @@ type ConfigOption func(*Config)
 // NewConfig crea una nueva configuración con opciones
 func NewConfig(provider string, opts ...ConfigOption) defs.Config {
 	cfg := &Config{
-		provider:  provider,
-		services:  make([]string, 0),
-		edgePort:  4566, // Puerto por defecto de Localstack
-		webUIPort: 4571, // Puerto por defecto del UI de Localstack
+		provider:   provider,
+		services:   make([]string, 0),
+		edgePort:   4566, // Puerto por defecto de Localstack
+		webUIPort:  4571, // Puerto por defecto del UI de Localstack
+		clientInit: defs.ClientInitEager,
 	}
 
 	for _, opt := range opts {
@@ -46,6 +52,29 @@ func WithCredentials(accessKey, secretKey string) ConfigOption {
 	}
 }
 
+// WithCredentialsProvider usa un proveedor de credenciales en lugar de access key/secret estáticos
+func WithCredentialsProvider(provider aws.CredentialsProvider) ConfigOption {
+	return func(c *Config) {
+		c.credentials = provider
+	}
+}
+
+// WithTracing instrumenta con X-Ray los clientes creados por el stack
+func WithTracing(enabled bool) ConfigOption {
+	return func(c *Config) {
+		c.tracing = enabled
+	}
+}
+
+// WithClientInit define si los clientes se crean al conectar (eager) o en su primer uso (lazy)
+func WithClientInit(mode string) ConfigOption {
+	return func(c *Config) {
+		if mode != "" {
+			c.clientInit = mode
+		}
+	}
+}
+
 func WithRegion(region string) ConfigOption {
 	return func(c *Config) {
 		c.awsRegion = region
@@ -121,19 +150,37 @@ func (c *Config) GetDataDir() string {
 	return c.dataDir
 }
 
+func (c *Config) GetCredentialsProvider() aws.CredentialsProvider {
+	return c.credentials
+}
+
+func (c *Config) IsTracingEnabled() bool {
+	return c.tracing
+}
+
+func (c *Config) GetClientInit() string {
+	return c.clientInit
+}
+
 // Validate verifica que la configuración sea válida
 func (c *Config) Validate() error {
-	// Validaciones básicas
-	if c.awsAccessKeyID == "" {
-		return fmt.Errorf("AWS_ACCESS_KEY_ID is required")
-	}
-	if c.awsSecretAccess == "" {
-		return fmt.Errorf("AWS_SECRET_ACCESS_KEY is required")
+	// Validaciones básicas; las credenciales estáticas no hacen falta si hay un proveedor explícito
+	if c.credentials == nil {
+		if c.awsAccessKeyID == "" {
+			return fmt.Errorf("AWS_ACCESS_KEY_ID is required")
+		}
+		if c.awsSecretAccess == "" {
+			return fmt.Errorf("AWS_SECRET_ACCESS_KEY is required")
+		}
 	}
 	if c.awsRegion == "" {
 		return fmt.Errorf("AWS_REGION is required")
 	}
 
+	if c.clientInit != defs.ClientInitEager && c.clientInit != defs.ClientInitLazy {
+		return fmt.Errorf("invalid client init mode: %s", c.clientInit)
+	}
+
 	// Validaciones específicas de Localstack
 	if c.provider == defs.ProviderLocalstack {
 		if c.endpoint == "" {
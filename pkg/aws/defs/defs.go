@@ -13,6 +13,14 @@ const (
 	ProviderLocalstack = "localstack"
 )
 
+// Modos de inicialización de los clientes del stack
+const (
+	// ClientInitEager crea los clientes de los servicios configurados al conectar el stack
+	ClientInitEager = "eager"
+	// ClientInitLazy crea cada cliente recién en su primer uso
+	ClientInitLazy = "lazy"
+)
+
 // Available AWS Services
 const (
 	ServiceS3             = "s3"
@@ -37,6 +45,7 @@ var ValidServices = map[string]bool{
 type Stack interface {
 	Connect() error
 	GetConfig() aws.Config
+	// NewSQSClient y NewLambdaClient retornan el cliente del stack; se crea una sola vez y se reutiliza
 	NewSQSClient() SQSClient
 	NewLambdaClient() LambdaClient
 }
@@ -51,6 +60,13 @@ type Config interface {
 	SetEndpoint(string)
 	GetServices() []string
 	SetServices([]string)
+	// GetCredentialsProvider retorna un proveedor de credenciales explícito (perfil, assume-role);
+	// nil indica usar las credenciales estáticas de la configuración
+	GetCredentialsProvider() aws.CredentialsProvider
+	// IsTracingEnabled indica si los clientes se instrumentan con X-Ray
+	IsTracingEnabled() bool
+	// GetClientInit retorna el modo de inicialización de los clientes (ClientInitEager o ClientInitLazy)
+	GetClientInit() string
 	Validate() error
 }
 
@@ -0,0 +1,89 @@
+package pkgaws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+)
+
+// LambdaInvoker es la parte del cliente Lambda del SDK que usa InvokeJSON (permite stubs en tests)
+type LambdaInvoker interface {
+	Invoke(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error)
+}
+
+// functionErrorPayload es el payload que retorna Lambda cuando la función falla (FunctionError != nil)
+type functionErrorPayload struct {
+	ErrorMessage string `json:"errorMessage"`
+	ErrorType    string `json:"errorType"`
+}
+
+// InvokeJSON invoca una Lambda de forma síncrona enviando req como JSON y decodificando la respuesta en Resp.
+// Los fallos se traducen a errores de dominio para que el retry y el mapeo HTTP del caller funcionen:
+//   - throttling de Lambda: types.ErrRateLimited (reintentable, ver types.IsRetryable)
+//   - error de la función en el payload: types.ErrInternal con el mensaje de la función
+//   - respuesta que no se puede decodificar: types.ErrInternal
+func InvokeJSON[Req, Resp any](ctx context.Context, client LambdaInvoker, functionName string, req Req) (Resp, error) {
+	var resp Resp
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return resp, types.NewError(types.ErrInvalidInput, "failed to marshal lambda payload", err)
+	}
+
+	out, err := client.Invoke(ctx, &lambda.InvokeInput{
+		FunctionName:   aws.String(functionName),
+		InvocationType: lambdatypes.InvocationTypeRequestResponse,
+		Payload:        payload,
+	})
+	if err != nil {
+		return resp, translateInvokeError(functionName, err)
+	}
+
+	if out.FunctionError != nil {
+		var fnErr functionErrorPayload
+		_ = json.Unmarshal(out.Payload, &fnErr)
+		if fnErr.ErrorMessage == "" {
+			fnErr.ErrorMessage = aws.ToString(out.FunctionError)
+		}
+		return resp, types.NewErrorWithContext(
+			types.ErrInternal,
+			fnErr.ErrorMessage,
+			nil,
+			map[string]any{"function": functionName, "error_type": fnErr.ErrorType},
+		)
+	}
+
+	if err := json.Unmarshal(out.Payload, &resp); err != nil {
+		return resp, types.NewErrorWithContext(
+			types.ErrInternal,
+			"malformed response from lambda function",
+			err,
+			map[string]any{"function": functionName},
+		)
+	}
+
+	return resp, nil
+}
+
+// translateInvokeError distingue el throttling (reintentable) del resto de fallos de la llamada
+func translateInvokeError(functionName string, err error) error {
+	errContext := map[string]any{"function": functionName}
+
+	var tooManyRequests *lambdatypes.TooManyRequestsException
+	var ec2Throttled *lambdatypes.EC2ThrottledException
+	if errors.As(err, &tooManyRequests) || errors.As(err, &ec2Throttled) {
+		return types.NewErrorWithContext(types.ErrRateLimited, "lambda invocation throttled", err, errContext)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return types.NewErrorWithContext(types.ErrTimeout, "lambda invocation timed out", err, errContext)
+	}
+
+	return types.NewErrorWithContext(types.ErrOperationFailed, "lambda invocation failed", err, errContext)
+}
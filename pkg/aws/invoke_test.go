@@ -0,0 +1,128 @@
+package pkgaws_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgaws "github.com/devpablocristo/tech-house/pkg/aws"
+	types "github.com/devpablocristo/tech-house/pkg/types"
+)
+
+type invokerStub struct {
+	out   *lambda.InvokeOutput
+	err   error
+	input *lambda.InvokeInput
+}
+
+func (s *invokerStub) Invoke(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	s.input = params
+	return s.out, s.err
+}
+
+type greetRequest struct {
+	Name string `json:"name"`
+}
+
+type greetResponse struct {
+	Message string `json:"message"`
+}
+
+func Test_InvokeJSON(t *testing.T) {
+	tests := []struct {
+		name          string
+		stub          *invokerStub
+		wantResp      greetResponse
+		wantErrType   types.ErrorType
+		wantRetryable bool
+		wantStatus    int
+		wantMessage   string
+	}{
+		{
+			name: "should decode the response",
+			stub: &invokerStub{out: &lambda.InvokeOutput{
+				StatusCode: 200,
+				Payload:    []byte(`{"message":"hola"}`),
+			}},
+			wantResp: greetResponse{Message: "hola"},
+		},
+		{
+			name: "should translate throttling to a retryable rate limit error",
+			stub: &invokerStub{err: fmt.Errorf("operation error Lambda: Invoke: %w",
+				&lambdatypes.TooManyRequestsException{Message: aws.String("Rate exceeded")})},
+			wantErrType:   types.ErrRateLimited,
+			wantRetryable: true,
+			wantStatus:    http.StatusTooManyRequests,
+		},
+		{
+			name:          "should translate EC2 throttling to a retryable rate limit error",
+			stub:          &invokerStub{err: &lambdatypes.EC2ThrottledException{Message: aws.String("throttled")}},
+			wantErrType:   types.ErrRateLimited,
+			wantRetryable: true,
+			wantStatus:    http.StatusTooManyRequests,
+		},
+		{
+			name: "should translate a function error to an internal error with the downstream message",
+			stub: &invokerStub{out: &lambda.InvokeOutput{
+				StatusCode:    200,
+				FunctionError: aws.String("Unhandled"),
+				Payload:       []byte(`{"errorMessage":"customer store unavailable","errorType":"errorString"}`),
+			}},
+			wantErrType: types.ErrInternal,
+			wantStatus:  http.StatusInternalServerError,
+			wantMessage: "customer store unavailable",
+		},
+		{
+			name: "should translate a malformed response to an internal error",
+			stub: &invokerStub{out: &lambda.InvokeOutput{
+				StatusCode: 200,
+				Payload:    []byte(`not json`),
+			}},
+			wantErrType: types.ErrInternal,
+			wantStatus:  http.StatusInternalServerError,
+			wantMessage: "malformed response from lambda function",
+		},
+		{
+			name:        "should translate other invoke failures to a non retryable error",
+			stub:        &invokerStub{err: errors.New("access denied")},
+			wantErrType: types.ErrOperationFailed,
+			wantStatus:  http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := pkgaws.InvokeJSON[greetRequest, greetResponse](context.Background(), tt.stub, "greeter", greetRequest{Name: "ana"})
+
+			require.NotNil(t, tt.stub.input)
+			assert.Equal(t, "greeter", aws.ToString(tt.stub.input.FunctionName))
+			assert.JSONEq(t, `{"name":"ana"}`, string(tt.stub.input.Payload))
+
+			if tt.wantErrType == "" {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantResp, resp)
+				return
+			}
+
+			require.Error(t, err)
+			errType, ok := types.GetErrorType(err)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantErrType, errType)
+			assert.Equal(t, tt.wantRetryable, types.IsRetryable(err))
+
+			apiErr, status := types.NewAPIError(err)
+			assert.Equal(t, tt.wantStatus, status)
+			if tt.wantMessage != "" {
+				assert.Equal(t, tt.wantMessage, apiErr.Message)
+			}
+		})
+	}
+}
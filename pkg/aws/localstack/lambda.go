@@ -154,12 +154,11 @@ func (c *lambdaClient) createErrorResponse(err error, statusCode int) (events.AP
 
 func (c *lambdaClient) getDefaultHeaders() map[string]string {
 	return map[string]string{
-		"Content-Type":                     "application/json",
-		"Access-Control-Allow-Origin":      "*",
-		"Access-Control-Allow-Headers":     "Content-Type,X-Amz-Date,Authorization,X-Api-Key",
-		"Access-Control-Allow-Methods":     "GET,POST,PUT,DELETE,PATCH,OPTIONS",
-		"Access-Control-Allow-Credentials": "true",
-		"X-Localstack-Implementation":      "true",
+		"Content-Type":                 "application/json",
+		"Access-Control-Allow-Origin":  "*",
+		"Access-Control-Allow-Headers": "Content-Type,X-Amz-Date,Authorization,X-Api-Key",
+		"Access-Control-Allow-Methods": "GET,POST,PUT,DELETE,PATCH,OPTIONS",
+		"X-Localstack-Implementation":  "true",
 	}
 }
 
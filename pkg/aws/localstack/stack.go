@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/credentials"
 
 	defs "github.com/devpablocristo/tech-house/pkg/aws/defs"
+	pkgtracing "github.com/devpablocristo/tech-house/pkg/aws/tracing"
 )
 
 // stack implementa la interfaz defs.Stack para Localstack
@@ -21,6 +23,12 @@ type stack struct {
 	mu          sync.RWMutex
 	connected   bool
 	initialized time.Time
+
+	// Clientes creados una sola vez (al conectar en modo eager, en el primer uso en modo lazy)
+	sqsOnce      sync.Once
+	sqsClient    defs.SQSClient
+	lambdaOnce   sync.Once
+	lambdaClient defs.LambdaClient
 }
 
 // NewStack crea una nueva instancia del stack de Localstack
@@ -79,8 +87,19 @@ func (s *stack) Connect() error {
 		return fmt.Errorf("failed to load Localstack config: %w", err)
 	}
 
+	// Instrumentar los clientes con X-Ray si está habilitado
+	if s.config.IsTracingEnabled() {
+		pkgtracing.Instrument(&awsCfg)
+	}
+
 	s.awsConfig = awsCfg
 	s.connected = true
+
+	// En modo eager los clientes se pagan en el arranque; en modo lazy, en el primer uso
+	if s.config.GetClientInit() != defs.ClientInitLazy {
+		s.initClients(awsCfg)
+	}
+
 	return nil
 }
 
@@ -91,32 +110,60 @@ func (s *stack) GetConfig() aws.Config {
 	return s.awsConfig
 }
 
-// NewSQSClient crea un nuevo cliente SQS para Localstack
+// NewSQSClient retorna el cliente SQS del stack, creándolo en el primer uso si hace falta
 func (s *stack) NewSQSClient() defs.SQSClient {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	if !s.connected {
-		if err := s.Connect(); err != nil {
-			return nil
-		}
+	if err := s.Connect(); err != nil {
+		return nil
 	}
-
-	return NewSQSClient(s.awsConfig, s.config.GetEndpoint())
+	return s.getSQSClient(s.GetConfig())
 }
 
-// NewLambdaClient crea un nuevo cliente Lambda para Localstack
+// NewLambdaClient retorna el cliente Lambda del stack, creándolo en el primer uso si hace falta
 func (s *stack) NewLambdaClient() defs.LambdaClient {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	if err := s.Connect(); err != nil {
+		return nil
+	}
+	return s.getLambdaClient(s.GetConfig())
+}
 
-	if !s.connected {
-		if err := s.Connect(); err != nil {
-			return nil
+// initClients crea por adelantado los clientes de los servicios configurados (todos si no hay ninguno)
+func (s *stack) initClients(cfg aws.Config) {
+	if s.usesService(defs.ServiceSQS) {
+		s.getSQSClient(cfg)
+	}
+	if s.usesService(defs.ServiceLambda) {
+		s.getLambdaClient(cfg)
+	}
+}
+
+// usesService indica si el servicio está entre los configurados; sin servicios configurados se asumen todos
+func (s *stack) usesService(service string) bool {
+	services := s.config.GetServices()
+	if len(services) == 0 {
+		return true
+	}
+	for _, configured := range services {
+		if strings.TrimSpace(configured) == service {
+			return true
 		}
 	}
+	return false
+}
+
+// getSQSClient crea el cliente SQS una sola vez; sync.Once lo hace seguro ante llamadas concurrentes
+func (s *stack) getSQSClient(cfg aws.Config) defs.SQSClient {
+	s.sqsOnce.Do(func() {
+		s.sqsClient = NewSQSClient(cfg, s.config.GetEndpoint())
+	})
+	return s.sqsClient
+}
 
-	return NewLambdaClient(s.awsConfig, s.config.GetEndpoint())
+// getLambdaClient crea el cliente Lambda una sola vez; sync.Once lo hace seguro ante llamadas concurrentes
+func (s *stack) getLambdaClient(cfg aws.Config) defs.LambdaClient {
+	s.lambdaOnce.Do(func() {
+		s.lambdaClient = NewLambdaClient(cfg, s.config.GetEndpoint())
+	})
+	return s.lambdaClient
 }
 
 // validateLocalstackEndpoint valida el endpoint de Localstack
@@ -3,6 +3,7 @@ package pkgrealstack
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -10,6 +11,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/credentials"
 
 	defs "github.com/devpablocristo/tech-house/pkg/aws/defs"
+	pkgtracing "github.com/devpablocristo/tech-house/pkg/aws/tracing"
 )
 
 // stack implementa la interfaz defs.Stack para AWS
@@ -18,6 +20,12 @@ type stack struct {
 	awsConfig aws.Config
 	mu        sync.RWMutex
 	connected bool
+
+	// Clientes creados una sola vez (al conectar en modo eager, en el primer uso en modo lazy)
+	sqsOnce      sync.Once
+	sqsClient    defs.SQSClient
+	lambdaOnce   sync.Once
+	lambdaClient defs.LambdaClient
 }
 
 // NewStack crea una nueva instancia del stack AWS
@@ -54,15 +62,21 @@ func (s *stack) Connect() error {
 	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
 	defer cancel()
 
+	// Credenciales: proveedor explícito (perfil, assume-role) o estáticas
+	var credsProvider aws.CredentialsProvider = credentials.NewStaticCredentialsProvider(
+		s.config.GetAwsAccessKeyID(),
+		s.config.GetAwsSecretAccessKey(),
+		"",
+	)
+	if provider := s.config.GetCredentialsProvider(); provider != nil {
+		credsProvider = provider
+	}
+
 	// Opciones base de configuración
 	var opts []func(*config.LoadOptions) error
 	opts = append(opts, []func(*config.LoadOptions) error{
 		config.WithRegion(s.config.GetAwsRegion()),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
-			s.config.GetAwsAccessKeyID(),
-			s.config.GetAwsSecretAccessKey(),
-			"",
-		)),
+		config.WithCredentialsProvider(credsProvider),
 	}...)
 
 	// Agregar opciones adicionales basadas en la configuración
@@ -76,8 +90,19 @@ func (s *stack) Connect() error {
 		return fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
+	// Instrumentar los clientes con X-Ray si está habilitado
+	if s.config.IsTracingEnabled() {
+		pkgtracing.Instrument(&awsCfg)
+	}
+
 	s.awsConfig = awsCfg
 	s.connected = true
+
+	// En modo eager los clientes se pagan en el arranque; en modo lazy, en el primer uso
+	if s.config.GetClientInit() != defs.ClientInitLazy {
+		s.initClients(awsCfg)
+	}
+
 	return nil
 }
 
@@ -88,32 +113,60 @@ func (s *stack) GetConfig() aws.Config {
 	return s.awsConfig
 }
 
-// NewSQSClient crea un nuevo cliente SQS
+// NewSQSClient retorna el cliente SQS del stack, creándolo en el primer uso si hace falta
 func (s *stack) NewSQSClient() defs.SQSClient {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	if !s.connected {
-		if err := s.Connect(); err != nil {
-			return nil
-		}
+	if err := s.Connect(); err != nil {
+		return nil
 	}
-
-	return NewSQSClient(s.awsConfig)
+	return s.getSQSClient(s.GetConfig())
 }
 
-// NewLambdaClient crea un nuevo cliente Lambda
+// NewLambdaClient retorna el cliente Lambda del stack, creándolo en el primer uso si hace falta
 func (s *stack) NewLambdaClient() defs.LambdaClient {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	if err := s.Connect(); err != nil {
+		return nil
+	}
+	return s.getLambdaClient(s.GetConfig())
+}
 
-	if !s.connected {
-		if err := s.Connect(); err != nil {
-			return nil
+// initClients crea por adelantado los clientes de los servicios configurados (todos si no hay ninguno)
+func (s *stack) initClients(cfg aws.Config) {
+	if s.usesService(defs.ServiceSQS) {
+		s.getSQSClient(cfg)
+	}
+	if s.usesService(defs.ServiceLambda) {
+		s.getLambdaClient(cfg)
+	}
+}
+
+// usesService indica si el servicio está entre los configurados; sin servicios configurados se asumen todos
+func (s *stack) usesService(service string) bool {
+	services := s.config.GetServices()
+	if len(services) == 0 {
+		return true
+	}
+	for _, configured := range services {
+		if strings.TrimSpace(configured) == service {
+			return true
 		}
 	}
+	return false
+}
+
+// getSQSClient crea el cliente SQS una sola vez; sync.Once lo hace seguro ante llamadas concurrentes
+func (s *stack) getSQSClient(cfg aws.Config) defs.SQSClient {
+	s.sqsOnce.Do(func() {
+		s.sqsClient = NewSQSClient(cfg)
+	})
+	return s.sqsClient
+}
 
-	return NewLambdaClient(s.awsConfig)
+// getLambdaClient crea el cliente Lambda una sola vez; sync.Once lo hace seguro ante llamadas concurrentes
+func (s *stack) getLambdaClient(cfg aws.Config) defs.LambdaClient {
+	s.lambdaOnce.Do(func() {
+		s.lambdaClient = NewLambdaClient(cfg)
+	})
+	return s.lambdaClient
 }
 
 // getServiceOptions retorna las opciones de configuración específicas para los servicios
@@ -0,0 +1,96 @@
+package pkgaws_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgaws "github.com/devpablocristo/tech-house/pkg/aws"
+	defs "github.com/devpablocristo/tech-house/pkg/aws/defs"
+)
+
+func newTestStack(tb testing.TB, clientInit string) defs.Stack {
+	tb.Helper()
+
+	cfg := pkgaws.NewConfig(defs.ProviderAWS,
+		pkgaws.WithCredentials("test-access-key", "test-secret-key"),
+		pkgaws.WithRegion("us-east-1"),
+		pkgaws.WithClientInit(clientInit),
+	)
+	require.NoError(tb, cfg.Validate())
+
+	factory, err := pkgaws.NewStackFactory(defs.ProviderAWS)
+	require.NoError(tb, err)
+
+	stack, err := factory.CreateStack(cfg)
+	require.NoError(tb, err)
+	return stack
+}
+
+func Test_Stack_ClientInit(t *testing.T) {
+	modes := []string{defs.ClientInitEager, defs.ClientInitLazy}
+
+	for _, mode := range modes {
+		t.Run(mode+" should reuse the same clients", func(t *testing.T) {
+			stack := newTestStack(t, mode)
+
+			assert.Same(t, stack.NewSQSClient(), stack.NewSQSClient())
+			assert.Same(t, stack.NewLambdaClient(), stack.NewLambdaClient())
+		})
+	}
+
+	t.Run("lazy should create a single client under concurrent first use", func(t *testing.T) {
+		stack := newTestStack(t, defs.ClientInitLazy)
+
+		const goroutines = 32
+		clients := make([]defs.LambdaClient, goroutines)
+
+		var wg sync.WaitGroup
+		for i := 0; i < goroutines; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				clients[i] = stack.NewLambdaClient()
+			}(i)
+		}
+		wg.Wait()
+
+		require.NotNil(t, clients[0])
+		for _, client := range clients[1:] {
+			assert.Same(t, clients[0], client)
+		}
+	})
+
+	t.Run("should reject an unknown mode", func(t *testing.T) {
+		cfg := pkgaws.NewConfig(defs.ProviderAWS,
+			pkgaws.WithCredentials("test-access-key", "test-secret-key"),
+			pkgaws.WithRegion("us-east-1"),
+			pkgaws.WithClientInit("sometimes"),
+		)
+		assert.Error(t, cfg.Validate())
+	})
+}
+
+// BenchmarkStackColdStart compara el costo de inicializar el stack creando todos los clientes (eager)
+// contra crearlos en el primer uso (lazy), y el de una ruta que solo usa el cliente Lambda
+func BenchmarkStackColdStart(b *testing.B) {
+	b.Run("eager", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			newTestStack(b, defs.ClientInitEager)
+		}
+	})
+
+	b.Run("lazy", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			newTestStack(b, defs.ClientInitLazy)
+		}
+	})
+
+	b.Run("lazy with lambda client", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			newTestStack(b, defs.ClientInitLazy).NewLambdaClient()
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package pkgtracing
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-xray-sdk-go/instrumentation/awsv2"
+	"github.com/aws/aws-xray-sdk-go/xray"
+)
+
+// Instrument agrega el middleware de X-Ray a la configuración de AWS, de modo que cada llamada
+// de los clientes creados a partir de ella (Lambda, SQS, DynamoDB, etc.) genere un subsegmento
+// dentro del segmento presente en el contexto
+func Instrument(cfg *aws.Config) {
+	if cfg == nil {
+		return
+	}
+	awsv2.AWSV2Instrumentor(&cfg.APIOptions)
+}
+
+// Capture ejecuta fn dentro de un subsegmento de X-Ray con el nombre indicado.
+// Si el contexto no trae un segmento (X-Ray deshabilitado, ejecución local, tests) solo ejecuta fn.
+func Capture(ctx context.Context, name string, fn func(context.Context) error) error {
+	if xray.GetSegment(ctx) == nil {
+		return fn(ctx)
+	}
+	return xray.Capture(ctx, name, fn)
+}
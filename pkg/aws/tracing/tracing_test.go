@@ -0,0 +1,105 @@
+package pkgtracing_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-xray-sdk-go/strategy/sampling"
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgtracing "github.com/devpablocristo/tech-house/pkg/aws/tracing"
+)
+
+// alwaysSample muestrea todos los segmentos, para que el test no dependa del reservoir por defecto
+type alwaysSample struct{}
+
+func (alwaysSample) ShouldTrace(*sampling.Request) *sampling.Decision {
+	return &sampling.Decision{Sample: true}
+}
+
+// beginSampledSegment abre un segmento raíz que siempre se muestrea
+func beginSampledSegment(t *testing.T) (context.Context, *xray.Segment) {
+	t.Helper()
+	ctx, err := xray.ContextWithConfig(context.Background(), xray.Config{SamplingStrategy: alwaysSample{}})
+	require.NoError(t, err)
+	return xray.BeginSegment(ctx, "test")
+}
+
+// subsegmentNames cierra el segmento raíz y retorna los nombres de sus subsegmentos
+func subsegmentNames(t *testing.T, root *xray.Segment) []string {
+	t.Helper()
+	root.Close(nil)
+
+	names := make([]string, 0, len(root.Subsegments))
+	for _, raw := range root.Subsegments {
+		var sub xray.Segment
+		require.NoError(t, json.Unmarshal(raw, &sub))
+		names = append(names, sub.Name)
+	}
+	return names
+}
+
+func TestCapture(t *testing.T) {
+	t.Run("should create a subsegment when the context has a segment", func(t *testing.T) {
+		ctx, root := beginSampledSegment(t)
+
+		called := false
+		err := pkgtracing.Capture(ctx, "downstream", func(ctx context.Context) error {
+			called = true
+			assert.NotNil(t, xray.GetSegment(ctx))
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.True(t, called)
+		assert.Equal(t, []string{"downstream"}, subsegmentNames(t, root))
+	})
+
+	t.Run("should only run the function when there is no segment", func(t *testing.T) {
+		expectedErr := errors.New("boom")
+
+		err := pkgtracing.Capture(context.Background(), "downstream", func(ctx context.Context) error {
+			assert.Nil(t, xray.GetSegment(ctx))
+			return expectedErr
+		})
+
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestInstrument(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		_, _ = w.Write([]byte(`{"MessageId":"1","MD5OfMessageBody":"5d41402abc4b2a76b9719d911017c592"}`))
+	}))
+	defer server.Close()
+
+	cfg := aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("test", "test", ""),
+		Retryer:     func() aws.Retryer { return aws.NopRetryer{} },
+	}
+	pkgtracing.Instrument(&cfg)
+
+	client := sqs.NewFromConfig(cfg, func(o *sqs.Options) {
+		o.BaseEndpoint = aws.String(server.URL)
+	})
+
+	ctx, root := beginSampledSegment(t)
+	_, err := client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:    aws.String(server.URL + "/queue"),
+		MessageBody: aws.String("hello"),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SQS"}, subsegmentNames(t, root))
+}
@@ -0,0 +1,99 @@
+package pkglogger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type loggerKey struct{}
+
+// Logger agrega a cada línea los campos del request (request_id, actor, ...) que cargaron los handlers,
+// para que los casos de uso y el repositorio logueen con contexto sin pasar los campos a mano
+type Logger struct {
+	fields []field
+	noop   bool
+}
+
+type field struct {
+	key   string
+	value any
+}
+
+// nopLogger es el logger que retorna FromContext cuando el contexto no tiene uno
+var nopLogger = &Logger{noop: true}
+
+// NewContext guarda el logger en el contexto
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext retorna el logger del contexto, o uno que descarta todo si no hay ninguno
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*Logger); ok {
+		return l
+	}
+	return nopLogger
+}
+
+// WithFields agrega pares clave/valor al logger del contexto (creándolo si no existe) y retorna el nuevo contexto
+func WithFields(ctx context.Context, keyvals ...any) context.Context {
+	l, ok := ctx.Value(loggerKey{}).(*Logger)
+	if !ok {
+		l = &Logger{}
+	}
+	return NewContext(ctx, l.With(keyvals...))
+}
+
+// With retorna un logger con los campos agregados; el receptor no se modifica
+func (l *Logger) With(keyvals ...any) *Logger {
+	if l.noop {
+		return l
+	}
+	fields := make([]field, len(l.fields), len(l.fields)+len(keyvals)/2)
+	copy(fields, l.fields)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		fields = append(fields, field{key: fmt.Sprint(keyvals[i]), value: keyvals[i+1]})
+	}
+	return &Logger{fields: fields}
+}
+
+// Enabled indica si el logger escribe (false para el logger por defecto sin contexto)
+func (l *Logger) Enabled() bool {
+	return !l.noop
+}
+
+func (l *Logger) Info(format string, v ...any) {
+	if l.noop {
+		return
+	}
+	Info(l.withFields(format), v...)
+}
+
+func (l *Logger) Warn(format string, v ...any) {
+	if l.noop {
+		return
+	}
+	Warn(l.withFields(format), v...)
+}
+
+func (l *Logger) Error(format string, v ...any) {
+	if l.noop {
+		return
+	}
+	Error(l.withFields(format), v...)
+}
+
+// withFields agrega los campos al final del formato como key=value (escapando los % de los valores)
+func (l *Logger) withFields(format string) string {
+	if len(l.fields) == 0 {
+		return format
+	}
+	var b strings.Builder
+	b.WriteString(format)
+	for _, f := range l.fields {
+		b.WriteString(" ")
+		b.WriteString(strings.ReplaceAll(fmt.Sprintf("%s=%v", f.key, f.value), "%", "%%"))
+	}
+	return b.String()
+}
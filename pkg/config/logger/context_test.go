@@ -0,0 +1,71 @@
+package pkglogger_test
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pkglogger "github.com/devpablocristo/tech-house/pkg/config/logger"
+)
+
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	flags := log.Flags()
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(flags)
+	})
+	return &buf
+}
+
+func Test_FromContext(t *testing.T) {
+	t.Run("should tag every line with the request fields", func(t *testing.T) {
+		buf := captureLog(t)
+
+		ctx := pkglogger.WithFields(context.Background(), "request_id", "abc-123")
+		ctx = pkglogger.WithFields(ctx, "actor", "billing-service")
+
+		pkglogger.FromContext(ctx).Warn("slow query: operation=%s", "GetByID")
+
+		assert.Contains(t, buf.String(), "slow query: operation=GetByID request_id=abc-123 actor=billing-service")
+	})
+
+	t.Run("should not leak fields into the parent context", func(t *testing.T) {
+		buf := captureLog(t)
+
+		parent := pkglogger.WithFields(context.Background(), "request_id", "abc-123")
+		_ = pkglogger.WithFields(parent, "actor", "billing-service")
+
+		pkglogger.FromContext(parent).Info("done")
+
+		assert.Contains(t, buf.String(), "done request_id=abc-123")
+		assert.NotContains(t, buf.String(), "actor")
+	})
+
+	t.Run("should escape percent signs in field values", func(t *testing.T) {
+		buf := captureLog(t)
+
+		ctx := pkglogger.WithFields(context.Background(), "actor", "100%")
+		pkglogger.FromContext(ctx).Error("failed")
+
+		assert.Contains(t, buf.String(), "failed actor=100%")
+	})
+
+	t.Run("should discard logs without a logger in context", func(t *testing.T) {
+		buf := captureLog(t)
+
+		l := pkglogger.FromContext(context.Background())
+		l.Error("nobody should see this")
+
+		assert.False(t, l.Enabled())
+		assert.Empty(t, buf.String())
+	})
+}
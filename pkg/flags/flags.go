@@ -0,0 +1,109 @@
+package pkgflags
+
+import (
+	"strconv"
+	"sync"
+)
+
+// Listener recibe el nuevo valor de un flag que cambió; value vacío indica que el flag se quitó
+type Listener func(name, value string)
+
+// Store expone flags de configuración que pueden cambiar sin reiniciar el proceso. Los componentes leen el
+// valor al arrancar y se suscriben para actualizarlo cuando cambia.
+type Store interface {
+	// Bool retorna el flag como booleano, o def si no está definido o no es un booleano válido
+	Bool(name string, def bool) bool
+	// Subscribe registra fn para que se invoque, en orden de registro, cada vez que el flag cambia
+	Subscribe(name string, fn Listener)
+}
+
+// registry guarda los valores y los listeners; lo comparten MemoryStore y PollingStore
+type registry struct {
+	mu        sync.RWMutex
+	values    map[string]string
+	listeners map[string][]Listener
+}
+
+func newRegistry(values map[string]string) *registry {
+	copied := make(map[string]string, len(values))
+	for name, value := range values {
+		copied[name] = value
+	}
+	return &registry{values: copied, listeners: make(map[string][]Listener)}
+}
+
+// Bool implementa Store
+func (r *registry) Bool(name string, def bool) bool {
+	r.mu.RLock()
+	value, ok := r.values[name]
+	r.mu.RUnlock()
+	if !ok {
+		return def
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+// Subscribe implementa Store
+func (r *registry) Subscribe(name string, fn Listener) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners[name] = append(r.listeners[name], fn)
+}
+
+// change es un flag que cambió junto con los listeners a notificar
+type change struct {
+	name      string
+	value     string
+	listeners []Listener
+}
+
+// replace reemplaza todos los valores y notifica a los listeners de los flags que cambiaron
+func (r *registry) replace(values map[string]string) {
+	r.mu.Lock()
+	changes := r.swap(values)
+	r.mu.Unlock()
+	notify(changes)
+}
+
+// set cambia un único flag y notifica a sus listeners si el valor es distinto
+func (r *registry) set(name, value string) {
+	r.mu.Lock()
+	values := make(map[string]string, len(r.values)+1)
+	for k, v := range r.values {
+		values[k] = v
+	}
+	values[name] = value
+	changes := r.swap(values)
+	r.mu.Unlock()
+	notify(changes)
+}
+
+// swap reemplaza los valores y retorna los flags con listeners que cambiaron; se llama con el lock tomado
+func (r *registry) swap(values map[string]string) []change {
+	var changes []change
+	for name, listeners := range r.listeners {
+		old, hadOld := r.values[name]
+		value, hasNew := values[name]
+		if hadOld != hasNew || old != value {
+			changes = append(changes, change{name: name, value: value, listeners: listeners})
+		}
+	}
+	r.values = make(map[string]string, len(values))
+	for name, value := range values {
+		r.values[name] = value
+	}
+	return changes
+}
+
+// notify invoca a los listeners fuera del lock, así pueden leer el Store
+func notify(changes []change) {
+	for _, c := range changes {
+		for _, fn := range c.listeners {
+			fn(c.name, c.value)
+		}
+	}
+}
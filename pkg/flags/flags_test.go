@@ -0,0 +1,69 @@
+package pkgflags_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgflags "github.com/devpablocristo/tech-house/pkg/flags"
+)
+
+func Test_MemoryStore(t *testing.T) {
+	t.Run("should read booleans with a default", func(t *testing.T) {
+		store := pkgflags.NewMemoryStore(map[string]string{"ON": "true", "BROKEN": "maybe"})
+
+		assert.True(t, store.Bool("ON", false))
+		assert.True(t, store.Bool("BROKEN", true))
+		assert.False(t, store.Bool("MISSING", false))
+	})
+
+	t.Run("should notify listeners only when the value changes", func(t *testing.T) {
+		store := pkgflags.NewMemoryStore(map[string]string{"STRICT": "false"})
+
+		var received []string
+		store.Subscribe("STRICT", func(name, value string) {
+			received = append(received, name+"="+value)
+		})
+
+		store.Set("STRICT", "false")
+		store.Set("OTHER", "true")
+		store.Set("STRICT", "true")
+
+		assert.Equal(t, []string{"STRICT=true"}, received)
+		assert.True(t, store.Bool("STRICT", false))
+	})
+}
+
+func Test_PollingStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.env")
+	require.NoError(t, os.WriteFile(path, []byte("STRICT=false\n"), 0o600))
+
+	store, err := pkgflags.NewPollingStore(context.Background(), pkgflags.EnvFileSource(path), 0)
+	require.NoError(t, err)
+	assert.False(t, store.Bool("STRICT", true))
+
+	var received []string
+	store.Subscribe("STRICT", func(name, value string) {
+		received = append(received, value)
+	})
+
+	t.Run("should notify a change read from the source", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("STRICT=true\n"), 0o600))
+		require.NoError(t, store.Refresh(context.Background()))
+
+		assert.Equal(t, []string{"true"}, received)
+		assert.True(t, store.Bool("STRICT", false))
+	})
+
+	t.Run("should keep the values when the source fails", func(t *testing.T) {
+		require.NoError(t, os.Remove(path))
+		require.Error(t, store.Refresh(context.Background()))
+
+		assert.True(t, store.Bool("STRICT", false))
+		assert.Equal(t, []string{"true"}, received)
+	})
+}
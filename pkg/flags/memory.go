@@ -0,0 +1,16 @@
+package pkgflags
+
+// MemoryStore es un Store en memoria cuyos flags se cambian con Set; sirve para tests y valores fijos
+type MemoryStore struct {
+	*registry
+}
+
+// NewMemoryStore crea el Store con los valores iniciales
+func NewMemoryStore(values map[string]string) *MemoryStore {
+	return &MemoryStore{registry: newRegistry(values)}
+}
+
+// Set cambia el valor del flag y notifica a sus listeners si es distinto del anterior
+func (s *MemoryStore) Set(name, value string) {
+	s.set(name, value)
+}
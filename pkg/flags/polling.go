@@ -0,0 +1,72 @@
+package pkgflags
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Source lee el valor actual de todos los flags
+type Source func(ctx context.Context) (map[string]string, error)
+
+// EnvFileSource lee los flags de un archivo .env en cada consulta, así los cambios al archivo se ven sin reiniciar
+func EnvFileSource(path string) Source {
+	return func(ctx context.Context) (map[string]string, error) {
+		values, err := godotenv.Read(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read flags from %s: %w", path, err)
+		}
+		return values, nil
+	}
+}
+
+// PollingStore es un Store que consulta su Source periódicamente y notifica los flags que cambiaron
+type PollingStore struct {
+	*registry
+	source   Source
+	interval time.Duration
+}
+
+// NewPollingStore crea el Store con una primera lectura de source; Start inicia la consulta periódica
+func NewPollingStore(ctx context.Context, source Source, interval time.Duration) (*PollingStore, error) {
+	values, err := source(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &PollingStore{registry: newRegistry(values), source: source, interval: interval}, nil
+}
+
+// Refresh vuelve a leer el Source y notifica los flags que cambiaron; si la lectura falla conserva los valores
+func (s *PollingStore) Refresh(ctx context.Context) error {
+	values, err := s.source(ctx)
+	if err != nil {
+		return err
+	}
+	s.replace(values)
+	return nil
+}
+
+// Start consulta el Source cada interval hasta que se cancela ctx. Una lectura que falla se loguea y se
+// reintenta en el próximo intervalo; un interval menor a 1 no consulta nunca.
+func (s *PollingStore) Start(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Refresh(ctx); err != nil {
+					log.Printf("Flags refresh failed: %v", err)
+				}
+			}
+		}
+	}()
+}
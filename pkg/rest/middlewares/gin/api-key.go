@@ -0,0 +1,176 @@
+package pkgmwr
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	pkglogger "github.com/devpablocristo/tech-house/pkg/config/logger"
+	pkgutils "github.com/devpablocristo/tech-house/pkg/utils"
+)
+
+// Constantes de autenticación por API key
+const (
+	// PrincipalContextKey es la clave de contexto donde se guarda el Principal autenticado
+	PrincipalContextKey = "principal"
+
+	apiKeyHeaderName = "X-API-Key"
+
+	errNoCredentials = "bearer token or api key required"
+	errInvalidAPIKey = "invalid api key"
+	errMissingScope  = "insufficient scope"
+)
+
+// Principal representa la identidad autenticada, venga de un JWT o de una API key
+type Principal struct {
+	ID     string
+	Scopes []string
+}
+
+// HasScope indica si el principal tiene el scope indicado
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyStore es el puerto para resolver API keys (DynamoDB, SSM, etc.).
+// Recibe el hash de la key (ver HashAPIKey), nunca la key en texto plano,
+// y retorna nil si la key no existe. Las implementaciones que comparen hashes
+// en memoria deben usar pkgutils.SecureCompare en lugar de ==.
+type APIKeyStore interface {
+	LookupAPIKey(ctx context.Context, keyHash string) (*Principal, error)
+}
+
+// HashAPIKey retorna el hash SHA-256 en hexadecimal con el que se guardan las API keys
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// staticAPIKeyStore implementa APIKeyStore sobre un mapa en memoria de hashes
+type staticAPIKeyStore struct {
+	keys map[string]Principal
+}
+
+// NewStaticAPIKeyStore crea un APIKeyStore a partir de un mapa hash -> Principal
+func NewStaticAPIKeyStore(keys map[string]Principal) APIKeyStore {
+	return &staticAPIKeyStore{keys: keys}
+}
+
+// LookupAPIKey recorre todas las keys comparando en tiempo constante, sin cortar en la primera
+// coincidencia, para que el tiempo de respuesta no revele cuánto se parece la key recibida a una válida
+func (s *staticAPIKeyStore) LookupAPIKey(ctx context.Context, keyHash string) (*Principal, error) {
+	var found *Principal
+	for hash, principal := range s.keys {
+		if pkgutils.SecureCompare(hash, keyHash) && found == nil {
+			p := principal
+			found = &p
+		}
+	}
+	return found, nil
+}
+
+// Authenticate middleware que acepta JWT (Authorization: Bearer) o API key (X-API-Key).
+// Primero intenta el JWT y, si no hay Bearer o no valida, la API key; si ninguno valida responde 401.
+func Authenticate(config Config, store APIKeyStore) gin.HandlerFunc {
+	v := newTokenValidator(config)
+
+	return func(c *gin.Context) {
+		var jwtErr error
+		if strings.HasPrefix(c.GetHeader(authHeaderName), bearerPrefix) {
+			token, err := v.validate(c)
+			if err == nil {
+				v.store(c, token)
+				setPrincipal(c, principalFromToken(token))
+				c.Next()
+				return
+			}
+			jwtErr = err
+		}
+
+		apiKey := c.GetHeader(apiKeyHeaderName)
+		if apiKey == "" || store == nil {
+			if jwtErr != nil {
+				abortWithError(c, http.StatusUnauthorized, jwtErr.Error())
+				return
+			}
+			abortWithError(c, http.StatusUnauthorized, errNoCredentials)
+			return
+		}
+
+		principal, err := store.LookupAPIKey(c.Request.Context(), HashAPIKey(apiKey))
+		if err != nil || principal == nil {
+			abortWithError(c, http.StatusUnauthorized, errInvalidAPIKey)
+			return
+		}
+
+		setPrincipal(c, principal)
+		c.Next()
+	}
+}
+
+// setPrincipal guarda el principal en el contexto de gin y lo agrega como "actor" al logger de contexto
+func setPrincipal(c *gin.Context, principal *Principal) {
+	c.Set(PrincipalContextKey, principal)
+	c.Request = c.Request.WithContext(pkglogger.WithFields(c.Request.Context(), "actor", principal.ID))
+}
+
+// RequireScope middleware que exige un scope al principal autenticado; responde 403 si no lo tiene
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			abortWithError(c, http.StatusUnauthorized, errNoCredentials)
+			return
+		}
+		if !principal.HasScope(scope) {
+			abortWithError(c, http.StatusForbidden, errMissingScope)
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetPrincipal obtiene el principal autenticado del contexto
+func GetPrincipal(c *gin.Context) (*Principal, bool) {
+	value, exists := c.Get(PrincipalContextKey)
+	if !exists {
+		return nil, false
+	}
+	principal, ok := value.(*Principal)
+	return principal, ok
+}
+
+// principalFromToken arma el principal a partir del "sub" y de los scopes del token
+// ("scope" separado por espacios, como en OAuth2, o "scopes" como arreglo)
+func principalFromToken(token *jwt.Token) *Principal {
+	principal := &Principal{}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return principal
+	}
+
+	principal.ID, _ = claims.GetSubject()
+
+	if scope, ok := claims["scope"].(string); ok {
+		principal.Scopes = strings.Fields(scope)
+	}
+	if scopes, ok := claims["scopes"].([]any); ok {
+		for _, s := range scopes {
+			if str, ok := s.(string); ok {
+				principal.Scopes = append(principal.Scopes, str)
+			}
+		}
+	}
+
+	return principal
+}
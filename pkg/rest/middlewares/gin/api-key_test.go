@@ -0,0 +1,200 @@
+package pkgmwr_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mwr "github.com/devpablocristo/tech-house/pkg/rest/middlewares/gin"
+)
+
+const testSecretKey = "test-secret"
+
+type failingAPIKeyStore struct{}
+
+func (failingAPIKeyStore) LookupAPIKey(ctx context.Context, keyHash string) (*mwr.Principal, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func signHMACToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	claims["exp"] = time.Now().Add(time.Hour).Unix()
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecretKey))
+	require.NoError(t, err)
+	return signed
+}
+
+func newAuthenticatedRouter(store mwr.APIKeyStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(mwr.Authenticate(mwr.Config{
+		SecretKey:   testSecretKey,
+		TokenLookup: "header:Authorization",
+		TokenPrefix: "Bearer ",
+	}, store))
+	r.GET("/protected", func(c *gin.Context) {
+		principal, _ := mwr.GetPrincipal(c)
+		c.String(http.StatusOK, principal.ID)
+	})
+	r.GET("/admin", mwr.RequireScope("admin"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func Test_StaticAPIKeyStore_LookupAPIKey(t *testing.T) {
+	keys := map[string]mwr.Principal{}
+	for i := 0; i < 20; i++ {
+		id := fmt.Sprintf("service-%d", i)
+		keys[mwr.HashAPIKey(id+"-key")] = mwr.Principal{ID: id, Scopes: []string{"customers:read"}}
+	}
+	store := mwr.NewStaticAPIKeyStore(keys)
+
+	t.Run("should find every key whatever its position in the scan", func(t *testing.T) {
+		// El orden de recorrido del mapa cambia en cada llamada: la key buscada queda al principio,
+		// al medio o al final, y el resultado no debe depender de eso
+		for round := 0; round < 5; round++ {
+			for i := 0; i < 20; i++ {
+				id := fmt.Sprintf("service-%d", i)
+				principal, err := store.LookupAPIKey(context.Background(), mwr.HashAPIKey(id+"-key"))
+				require.NoError(t, err)
+				require.NotNil(t, principal)
+				assert.Equal(t, id, principal.ID)
+			}
+		}
+	})
+
+	t.Run("should return nil for an unknown key", func(t *testing.T) {
+		principal, err := store.LookupAPIKey(context.Background(), mwr.HashAPIKey("unknown-key"))
+		require.NoError(t, err)
+		assert.Nil(t, principal)
+	})
+
+	t.Run("should return a copy of the principal", func(t *testing.T) {
+		hash := mwr.HashAPIKey("service-0-key")
+		principal, err := store.LookupAPIKey(context.Background(), hash)
+		require.NoError(t, err)
+		principal.ID = "changed"
+
+		again, err := store.LookupAPIKey(context.Background(), hash)
+		require.NoError(t, err)
+		assert.Equal(t, "service-0", again.ID)
+	})
+}
+
+func Test_Authenticate(t *testing.T) {
+	store := mwr.NewStaticAPIKeyStore(map[string]mwr.Principal{
+		mwr.HashAPIKey("billing-key"): {ID: "billing-service", Scopes: []string{"customers:read"}},
+		mwr.HashAPIKey("reports-key"): {ID: "reports-service", Scopes: []string{"customers:read"}},
+	})
+	router := newAuthenticatedRouter(store)
+
+	tests := []struct {
+		name          string
+		path          string
+		bearer        string
+		apiKey        string
+		store         mwr.APIKeyStore
+		wantCode      int
+		wantPrincipal string
+	}{
+		{
+			name:          "should accept valid jwt",
+			path:          "/protected",
+			bearer:        signHMACToken(t, jwt.MapClaims{"sub": "homero"}),
+			wantCode:      http.StatusOK,
+			wantPrincipal: "homero",
+		},
+		{
+			name:          "should accept valid api key",
+			path:          "/protected",
+			apiKey:        "billing-key",
+			wantCode:      http.StatusOK,
+			wantPrincipal: "billing-service",
+		},
+		{
+			name:          "should fall back to api key when jwt is invalid",
+			path:          "/protected",
+			bearer:        "not-a-jwt",
+			apiKey:        "billing-key",
+			wantCode:      http.StatusOK,
+			wantPrincipal: "billing-service",
+		},
+		{
+			name:          "should resolve the matching key among several",
+			path:          "/protected",
+			apiKey:        "reports-key",
+			wantCode:      http.StatusOK,
+			wantPrincipal: "reports-service",
+		},
+		{
+			name:     "should reject unknown api key",
+			path:     "/protected",
+			apiKey:   "wrong-key",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "should reject invalid jwt without api key",
+			path:     "/protected",
+			bearer:   "not-a-jwt",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "should reject request without credentials",
+			path:     "/protected",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "should reject api key when store fails",
+			path:     "/protected",
+			apiKey:   "billing-key",
+			store:    failingAPIKeyStore{},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "should allow jwt with required scope",
+			path:     "/admin",
+			bearer:   signHMACToken(t, jwt.MapClaims{"sub": "homero", "scope": "customers:read admin"}),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "should forbid api key without required scope",
+			path:     "/admin",
+			apiKey:   "billing-key",
+			wantCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := router
+			if tt.store != nil {
+				r = newAuthenticatedRouter(tt.store)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.bearer != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantPrincipal != "" {
+				assert.Equal(t, tt.wantPrincipal, w.Body.String())
+			}
+		})
+	}
+}
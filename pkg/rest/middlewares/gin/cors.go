@@ -0,0 +1,109 @@
+package pkgmwr
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSConfig configura el manejo de CORS
+type CORSConfig struct {
+	AllowedOrigins   []string      // Orígenes permitidos; "*" acepta cualquiera (ignorado si AllowCredentials)
+	AllowedMethods   []string      // Métodos permitidos en el preflight
+	AllowedHeaders   []string      // Headers permitidos en el preflight
+	ExposeHeaders    []string      // Headers que el navegador deja leer al cliente (ETag, X-RateLimit-*)
+	AllowCredentials bool          // Habilita cookies/credenciales; obliga a devolver un origen concreto
+	MaxAge           time.Duration // Tiempo que el navegador puede cachear el preflight; 0 no envía el header
+}
+
+// DefaultCORSConfig retorna una configuración por defecto sin credenciales
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions},
+		AllowedHeaders: []string{"Content-Type", "Authorization", "X-API-Key"},
+		ExposeHeaders:  []string{"ETag", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"},
+		MaxAge:         10 * time.Minute,
+	}
+}
+
+// CORSHeaders calcula los headers CORS para el origen recibido.
+// Retorna nil si no hay origen o si no está permitido. Con credenciales nunca se
+// responde "*": se devuelve el origen concreto y se agrega "Vary: Origin".
+func CORSHeaders(config CORSConfig, origin string, preflight bool) map[string]string {
+	if origin == "" {
+		return nil
+	}
+
+	allowOrigin := ""
+	for _, allowed := range config.AllowedOrigins {
+		if allowed == "*" && !config.AllowCredentials {
+			allowOrigin = "*"
+			continue
+		}
+		if strings.EqualFold(allowed, origin) {
+			allowOrigin = origin
+			break
+		}
+	}
+	if allowOrigin == "" {
+		return nil
+	}
+
+	headers := map[string]string{
+		"Access-Control-Allow-Origin": allowOrigin,
+	}
+	if allowOrigin != "*" {
+		headers["Vary"] = "Origin"
+	}
+	if config.AllowCredentials {
+		headers["Access-Control-Allow-Credentials"] = "true"
+	}
+
+	if !preflight {
+		if len(config.ExposeHeaders) > 0 {
+			headers["Access-Control-Expose-Headers"] = strings.Join(config.ExposeHeaders, ", ")
+		}
+		return headers
+	}
+
+	if len(config.AllowedMethods) > 0 {
+		headers["Access-Control-Allow-Methods"] = strings.Join(config.AllowedMethods, ", ")
+	}
+	if len(config.AllowedHeaders) > 0 {
+		headers["Access-Control-Allow-Headers"] = strings.Join(config.AllowedHeaders, ", ")
+	}
+	if config.MaxAge > 0 {
+		headers["Access-Control-Max-Age"] = strconv.Itoa(int(config.MaxAge.Seconds()))
+	}
+
+	return headers
+}
+
+// CORS middleware que responde los preflight y agrega los headers CORS a las respuestas.
+// Los preflight de orígenes no permitidos se rechazan con 403.
+func CORS(config CORSConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		headers := CORSHeaders(config, origin, preflight)
+		for name, value := range headers {
+			c.Header(name, value)
+		}
+
+		if preflight {
+			if headers == nil {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
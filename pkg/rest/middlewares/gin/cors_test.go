@@ -0,0 +1,141 @@
+package pkgmwr_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	mwr "github.com/devpablocristo/tech-house/pkg/rest/middlewares/gin"
+)
+
+func Test_CORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	credentialed := mwr.CORSConfig{
+		AllowedOrigins:   []string{"*", "https://app.example.com"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		ExposeHeaders:    []string{"ETag", "X-RateLimit-Remaining"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}
+
+	tests := []struct {
+		name        string
+		config      mwr.CORSConfig
+		method      string
+		origin      string
+		preflight   bool
+		wantCode    int
+		wantHeaders map[string]string
+		absent      []string
+	}{
+		{
+			name:     "should use wildcard origin without credentials",
+			config:   mwr.DefaultCORSConfig(),
+			method:   http.MethodGet,
+			origin:   "https://any.example.com",
+			wantCode: http.StatusOK,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":   "*",
+				"Access-Control-Expose-Headers": "ETag, X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset",
+			},
+			absent: []string{"Access-Control-Allow-Credentials", "Vary"},
+		},
+		{
+			name:      "should answer preflight with max age without credentials",
+			config:    mwr.DefaultCORSConfig(),
+			method:    http.MethodOptions,
+			origin:    "https://any.example.com",
+			preflight: true,
+			wantCode:  http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "*",
+				"Access-Control-Max-Age":       "600",
+				"Access-Control-Allow-Methods": "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+			},
+			absent: []string{"Access-Control-Allow-Credentials"},
+		},
+		{
+			name:     "should echo allowed origin with credentials",
+			config:   credentialed,
+			method:   http.MethodGet,
+			origin:   "https://app.example.com",
+			wantCode: http.StatusOK,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Expose-Headers":    "ETag, X-RateLimit-Remaining",
+				"Vary":                             "Origin",
+			},
+		},
+		{
+			name:      "should answer credentialed preflight with specific origin",
+			config:    credentialed,
+			method:    http.MethodOptions,
+			origin:    "https://app.example.com",
+			preflight: true,
+			wantCode:  http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Max-Age":           "3600",
+				"Access-Control-Allow-Headers":     "Content-Type, Authorization",
+			},
+		},
+		{
+			name:     "should never fall back to wildcard with credentials",
+			config:   credentialed,
+			method:   http.MethodGet,
+			origin:   "https://evil.example.com",
+			wantCode: http.StatusOK,
+			absent:   []string{"Access-Control-Allow-Origin", "Access-Control-Allow-Credentials"},
+		},
+		{
+			name:      "should reject preflight from disallowed origin",
+			config:    credentialed,
+			method:    http.MethodOptions,
+			origin:    "https://evil.example.com",
+			preflight: true,
+			wantCode:  http.StatusForbidden,
+			absent:    []string{"Access-Control-Allow-Origin"},
+		},
+		{
+			name:     "should skip requests without origin",
+			config:   credentialed,
+			method:   http.MethodGet,
+			wantCode: http.StatusOK,
+			absent:   []string{"Access-Control-Allow-Origin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(mwr.CORS(tt.config))
+			r.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(tt.method, "/test", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			for name, value := range tt.wantHeaders {
+				assert.Equal(t, value, w.Header().Get(name), name)
+			}
+			for _, name := range tt.absent {
+				assert.Empty(t, w.Header().Get(name), name)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package pkgmwr
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Valores por defecto del cache de JWKS
+const (
+	defaultJWKSCacheTTL              = 10 * time.Minute
+	defaultJWKSRefreshInterval       = 5 * time.Minute
+	defaultJWKSFetchTimeout          = 5 * time.Second
+	defaultJWKSForcedRefreshCooldown = 30 * time.Second
+
+	errUnknownKeyID = "unknown key id"
+)
+
+// jwk representa una clave individual dentro de un JWKS
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// jwks representa el documento JSON Web Key Set publicado por el IdP
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// jwksCache mantiene en memoria las claves públicas del IdP.
+// Se refresca en segundo plano cada refreshInterval y, si el IdP no responde,
+// sigue sirviendo las últimas claves conocidas.
+type jwksCache struct {
+	url             string
+	ttl             time.Duration
+	refreshInterval time.Duration
+	forcedCooldown  time.Duration
+	httpClient      *http.Client
+
+	mu        sync.RWMutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+
+	// refreshMu serializa los refrescos forzados para que un kid desconocido
+	// dispare un único fetch aunque lleguen muchas peticiones a la vez
+	refreshMu sync.Mutex
+	// lastForced es el último refresco forzado, exitoso o no (protegido por refreshMu). El kid sale del header
+	// sin verificar del token, así que sin este límite cualquiera podría disparar un fetch al IdP por request.
+	lastForced time.Time
+}
+
+// newJWKSCache crea el cache, intenta una primera carga y arranca el refresco en segundo plano, que corre hasta
+// que se cierra done. Si la primera carga falla el cache queda vacío y se reintenta en el próximo refresco o ante
+// un kid desconocido. forcedCooldown es el tiempo mínimo entre dos refrescos forzados por kids desconocidos.
+func newJWKSCache(url string, ttl, refreshInterval, forcedCooldown time.Duration, done <-chan struct{}) *jwksCache {
+	if ttl <= 0 {
+		ttl = defaultJWKSCacheTTL
+	}
+	if refreshInterval <= 0 {
+		refreshInterval = defaultJWKSRefreshInterval
+	}
+	if forcedCooldown <= 0 {
+		forcedCooldown = defaultJWKSForcedRefreshCooldown
+	}
+
+	c := &jwksCache{
+		url:             url,
+		ttl:             ttl,
+		refreshInterval: refreshInterval,
+		forcedCooldown:  forcedCooldown,
+		httpClient:      &http.Client{Timeout: defaultJWKSFetchTimeout},
+		keys:            make(map[string]*rsa.PublicKey),
+	}
+
+	if err := c.refresh(context.Background()); err != nil {
+		log.Printf("jwks: initial fetch from %s failed: %v", url, err)
+	}
+
+	go c.refreshLoop(done)
+
+	return c
+}
+
+// refreshLoop refresca las claves periódicamente hasta que se cierra done; con done en nil no termina nunca
+func (c *jwksCache) refreshLoop(done <-chan struct{}) {
+	ticker := time.NewTicker(c.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := c.refresh(context.Background()); err != nil {
+				log.Printf("jwks: background refresh failed, serving cached keys: %v", err)
+			}
+		}
+	}
+}
+
+// getKey retorna la clave asociada al kid.
+// Si el cache expiró intenta refrescarlo; si el kid es desconocido fuerza un único refresco antes de rechazar,
+// salvo que ya se haya forzado uno dentro de forcedCooldown.
+func (c *jwksCache) getKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	key, fresh := c.lookup(kid)
+	if key != nil && fresh {
+		return key, nil
+	}
+
+	if key != nil {
+		// Cache expirado: se intenta refrescar pero, si el IdP está caído, se sirve la clave cacheada
+		if err := c.refreshIfStale(ctx); err != nil {
+			log.Printf("jwks: refresh of stale cache failed, serving cached keys: %v", err)
+			return key, nil
+		}
+		if key, _ = c.lookup(kid); key != nil {
+			return key, nil
+		}
+		return nil, fmt.Errorf("%s: %s", errUnknownKeyID, kid)
+	}
+
+	if err := c.forceRefresh(ctx, kid); err != nil {
+		return nil, fmt.Errorf("%s: %s (%v)", errUnknownKeyID, kid, err)
+	}
+
+	if key, _ = c.lookup(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%s: %s", errUnknownKeyID, kid)
+}
+
+func (c *jwksCache) lookup(kid string) (*rsa.PublicKey, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.keys[kid], time.Since(c.fetchedAt) < c.ttl
+}
+
+// refreshIfStale refresca solo si nadie lo hizo mientras se esperaba el lock
+func (c *jwksCache) refreshIfStale(ctx context.Context) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	c.mu.RLock()
+	stale := time.Since(c.fetchedAt) >= c.ttl
+	c.mu.RUnlock()
+	if !stale {
+		return nil
+	}
+	return c.refresh(ctx)
+}
+
+// forceRefresh refresca ante un kid desconocido, salvo que otra petición ya lo haya traído o que el último
+// refresco forzado sea más reciente que forcedCooldown
+func (c *jwksCache) forceRefresh(ctx context.Context, kid string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	if key, _ := c.lookup(kid); key != nil {
+		return nil
+	}
+	if since := time.Since(c.lastForced); since < c.forcedCooldown {
+		return fmt.Errorf("jwks forced refresh skipped, last one %s ago", since.Round(time.Millisecond))
+	}
+	c.lastForced = time.Now()
+	return c.refresh(ctx)
+}
+
+// refresh descarga el JWKS y reemplaza las claves cacheadas
+func (c *jwksCache) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return fmt.Errorf("error creating jwks request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error fetching jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error fetching jwks: status code %d", resp.StatusCode)
+	}
+
+	var set jwks
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("error decoding jwks: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		pub, err := parseRSAJWK(k)
+		if err != nil {
+			log.Printf("jwks: skipping key %s: %v", k.Kid, err)
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+
+	c.mu.Lock()
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	c.mu.Unlock()
+
+	return nil
+}
+
+func parseRSAJWK(k jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
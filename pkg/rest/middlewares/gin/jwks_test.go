@@ -0,0 +1,236 @@
+package pkgmwr_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mwr "github.com/devpablocristo/tech-house/pkg/rest/middlewares/gin"
+)
+
+type signingKey struct {
+	kid string
+	key *rsa.PrivateKey
+}
+
+// fakeIdP publica un JWKS que se puede rotar o dejar caído durante el test
+type fakeIdP struct {
+	mu      sync.Mutex
+	keys    []signingKey
+	down    bool
+	fetches atomic.Int32
+}
+
+func newSigningKey(t *testing.T, kid string) signingKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return signingKey{kid: kid, key: key}
+}
+
+func (f *fakeIdP) rotate(keys ...signingKey) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keys = keys
+}
+
+func (f *fakeIdP) setDown(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+}
+
+func (f *fakeIdP) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.fetches.Add(1)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.down {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	keys := make([]map[string]string, 0, len(f.keys))
+	for _, k := range f.keys {
+		keys = append(keys, map[string]string{
+			"kty": "RSA",
+			"kid": k.kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(k.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.key.E)).Bytes()),
+		})
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+}
+
+func signToken(t *testing.T, k signingKey) string {
+	t.Helper()
+	return signTokenWithClaims(t, k, jwt.MapClaims{})
+}
+
+func signTokenWithClaims(t *testing.T, k signingKey, claims jwt.MapClaims) string {
+	t.Helper()
+	claims["sub"] = "homero"
+	claims["exp"] = time.Now().Add(time.Hour).Unix()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = k.kid
+	signed, err := token.SignedString(k.key)
+	require.NoError(t, err)
+	return signed
+}
+
+func newProtectedRouter(config mwr.Config) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/protected", mwr.Validate(config), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func doRequest(r http.Handler, token string) int {
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func Test_Validate_JWKSKeyRotation(t *testing.T) {
+	oldKey := newSigningKey(t, "key-1")
+	newKey := newSigningKey(t, "key-2")
+
+	idp := &fakeIdP{}
+	idp.rotate(oldKey)
+	server := httptest.NewServer(idp)
+	defer server.Close()
+
+	router := newProtectedRouter(mwr.Config{
+		TokenLookup:         "header:Authorization",
+		TokenPrefix:         "Bearer ",
+		JWKSURL:             server.URL,
+		JWKSCacheTTL:        time.Hour,
+		JWKSRefreshInterval: time.Hour,
+		// Sin cooldown efectivo: cada subtest que necesita un refresco forzado lo obtiene
+		JWKSForcedRefreshCooldown: time.Nanosecond,
+	})
+
+	t.Run("should accept token signed with cached key", func(t *testing.T) {
+		fetches := idp.fetches.Load()
+		assert.Equal(t, http.StatusOK, doRequest(router, signToken(t, oldKey)))
+		assert.Equal(t, fetches, idp.fetches.Load(), "cached key must not trigger a fetch")
+	})
+
+	t.Run("should serve cached keys while the IdP is down", func(t *testing.T) {
+		idp.setDown(true)
+		defer idp.setDown(false)
+		assert.Equal(t, http.StatusOK, doRequest(router, signToken(t, oldKey)))
+	})
+
+	t.Run("should force a single refresh on unknown kid after rotation", func(t *testing.T) {
+		idp.rotate(newKey)
+		fetches := idp.fetches.Load()
+		assert.Equal(t, http.StatusOK, doRequest(router, signToken(t, newKey)))
+		assert.Equal(t, fetches+1, idp.fetches.Load())
+	})
+
+	t.Run("should reject token signed with a retired key", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, doRequest(router, signToken(t, oldKey)))
+	})
+
+	t.Run("should reject unknown kid when refresh fails", func(t *testing.T) {
+		idp.setDown(true)
+		defer idp.setDown(false)
+		assert.Equal(t, http.StatusUnauthorized, doRequest(router, signToken(t, newSigningKey(t, "key-3"))))
+	})
+}
+
+func Test_Validate_JWKSForcedRefreshCooldown(t *testing.T) {
+	key := newSigningKey(t, "key-1")
+	idp := &fakeIdP{}
+	idp.rotate(key)
+	server := httptest.NewServer(idp)
+	defer server.Close()
+
+	router := newProtectedRouter(mwr.Config{
+		TokenLookup:               "header:Authorization",
+		TokenPrefix:               "Bearer ",
+		JWKSURL:                   server.URL,
+		JWKSCacheTTL:              time.Hour,
+		JWKSRefreshInterval:       time.Hour,
+		JWKSForcedRefreshCooldown: 500 * time.Millisecond,
+	})
+	initialFetches := idp.fetches.Load()
+
+	t.Run("should fetch once for a burst of unknown kids", func(t *testing.T) {
+		// Tokens firmados de antemano con kids inventados, para que la ráfaga entre en el cooldown
+		tokens := make([]string, 20)
+		for i := range tokens {
+			tokens[i] = signToken(t, signingKey{kid: fmt.Sprintf("forged-%d", i), key: key.key})
+		}
+
+		var wg sync.WaitGroup
+		for _, token := range tokens {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.Equal(t, http.StatusUnauthorized, doRequest(router, token))
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, initialFetches+1, idp.fetches.Load())
+	})
+
+	t.Run("should keep accepting known kids during the cooldown", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, doRequest(router, signToken(t, key)))
+	})
+
+	t.Run("should force a refresh again once the cooldown elapsed", func(t *testing.T) {
+		rotated := newSigningKey(t, "key-2")
+		idp.rotate(key, rotated)
+		time.Sleep(600 * time.Millisecond)
+
+		assert.Equal(t, http.StatusOK, doRequest(router, signToken(t, rotated)))
+		assert.Equal(t, initialFetches+2, idp.fetches.Load())
+	})
+}
+
+func Test_Validate_JWKSRefreshStopsOnDone(t *testing.T) {
+	idp := &fakeIdP{}
+	idp.rotate(newSigningKey(t, "key-1"))
+	server := httptest.NewServer(idp)
+	defer server.Close()
+
+	done := make(chan struct{})
+	newProtectedRouter(mwr.Config{
+		TokenLookup:         "header:Authorization",
+		TokenPrefix:         "Bearer ",
+		JWKSURL:             server.URL,
+		JWKSCacheTTL:        time.Hour,
+		JWKSRefreshInterval: 5 * time.Millisecond,
+		Done:                done,
+	})
+
+	require.Eventually(t, func() bool { return idp.fetches.Load() > 2 }, time.Second, 5*time.Millisecond, "should refresh in background")
+
+	close(done)
+	// un refresco en curso al cerrar done puede terminar, pero no debe arrancar ninguno más
+	time.Sleep(20 * time.Millisecond)
+	fetches := idp.fetches.Load()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, fetches, idp.fetches.Load())
+}
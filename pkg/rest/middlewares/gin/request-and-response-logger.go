@@ -1,17 +1,32 @@
 package pkgmwr
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"go-micro.dev/v4/logger"
 )
 
+// Valores por defecto del truncado de campos en los logs
+const (
+	defaultMaxFieldLength = 256
+	truncatedMarker       = "…[truncated]"
+)
+
 type HttpLoggingOptions struct {
 	LogLevel       string
 	IncludeHeaders bool
 	IncludeBody    bool
 	ExcludedPaths  []string
+	// MaxFieldLength trunca los strings del body logueado que superen este largo (en caracteres).
+	// 0 usa el valor por defecto (256) y un valor negativo deshabilita el truncado.
+	// Solo afecta al log: el body que recibe el handler no se modifica.
+	MaxFieldLength int
 }
 
 // INFO: registra y loggea las solicitudes HTTP entrantes y las respuestas salientes
@@ -33,9 +48,16 @@ func RequestAndResponseLogger(options HttpLoggingOptions) gin.HandlerFunc {
 			logger.Infof("Request headers: %v", c.Request.Header)
 		}
 
-		if options.IncludeBody {
-			// Log the request body if needed (make sure to handle large bodies appropriately)
-			// NOTE: You might need to handle the body as a buffer and reset it afterward
+		if options.IncludeBody && c.Request.Body != nil {
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				logger.Warnf("Error reading request body: %v", err)
+			}
+			// Restaurar el body para que el handler lo pueda leer completo
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			if len(body) > 0 {
+				logger.Infof("Request body: %s", truncateFields(body, options.MaxFieldLength))
+			}
 		}
 
 		// Procesar la solicitud
@@ -48,3 +70,53 @@ func RequestAndResponseLogger(options HttpLoggingOptions) gin.HandlerFunc {
 		logger.Infof("Response: %d, Latency: %v", statusCode, latency)
 	}
 }
+
+// truncateFields retorna la representación del body para el log con los strings largos truncados.
+// Si el body es JSON se trunca cada valor string; si no, se trunca el body completo.
+func truncateFields(body []byte, maxLength int) string {
+	if maxLength == 0 {
+		maxLength = defaultMaxFieldLength
+	}
+	if maxLength < 0 {
+		return string(body)
+	}
+
+	var data any
+	if err := json.Unmarshal(body, &data); err != nil {
+		return truncateString(string(body), maxLength)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(truncateValue(data, maxLength)); err != nil {
+		return truncateString(string(body), maxLength)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func truncateValue(value any, maxLength int) any {
+	switch v := value.(type) {
+	case string:
+		return truncateString(v, maxLength)
+	case map[string]any:
+		for key, item := range v {
+			v[key] = truncateValue(item, maxLength)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = truncateValue(item, maxLength)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func truncateString(s string, maxLength int) string {
+	if utf8.RuneCountInString(s) <= maxLength {
+		return s
+	}
+	return string([]rune(s)[:maxLength]) + truncatedMarker
+}
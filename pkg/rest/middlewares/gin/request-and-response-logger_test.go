@@ -0,0 +1,105 @@
+package pkgmwr_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-micro.dev/v4/logger"
+
+	mwr "github.com/devpablocristo/tech-house/pkg/rest/middlewares/gin"
+)
+
+// bufferLogger implementa logger.Logger guardando los mensajes en memoria
+type bufferLogger struct {
+	buf *bytes.Buffer
+}
+
+func (l *bufferLogger) Init(...logger.Option) error                 { return nil }
+func (l *bufferLogger) Options() logger.Options                     { return logger.Options{} }
+func (l *bufferLogger) Fields(map[string]interface{}) logger.Logger { return l }
+func (l *bufferLogger) String() string                              { return "buffer" }
+
+func (l *bufferLogger) Log(level logger.Level, v ...interface{}) {
+	fmt.Fprintln(l.buf, v...)
+}
+
+func (l *bufferLogger) Logf(level logger.Level, format string, v ...interface{}) {
+	fmt.Fprintf(l.buf, format+"\n", v...)
+}
+
+func Test_RequestAndResponseLogger_TruncatesLongFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var logs bytes.Buffer
+	previous := logger.DefaultLogger
+	logger.DefaultLogger = &bufferLogger{buf: &logs}
+	defer func() { logger.DefaultLogger = previous }()
+
+	longName := strings.Repeat("a", 300)
+
+	tests := []struct {
+		name          string
+		options       mwr.HttpLoggingOptions
+		wantLogged    string
+		notWantLogged string
+	}{
+		{
+			name:          "should truncate long fields with default max length",
+			options:       mwr.HttpLoggingOptions{IncludeBody: true},
+			wantLogged:    `"name":"` + strings.Repeat("a", 256) + `…[truncated]"`,
+			notWantLogged: longName,
+		},
+		{
+			name:          "should truncate long fields with configured max length",
+			options:       mwr.HttpLoggingOptions{IncludeBody: true, MaxFieldLength: 10},
+			wantLogged:    `"name":"aaaaaaaaaa…[truncated]"`,
+			notWantLogged: strings.Repeat("a", 11),
+		},
+		{
+			name:       "should keep short fields untouched",
+			options:    mwr.HttpLoggingOptions{IncludeBody: true, MaxFieldLength: 10},
+			wantLogged: `"last_name":"Simpson"`,
+		},
+		{
+			name:       "should not truncate when disabled",
+			options:    mwr.HttpLoggingOptions{IncludeBody: true, MaxFieldLength: -1},
+			wantLogged: longName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+
+			r := gin.New()
+			r.Use(mwr.RequestAndResponseLogger(tt.options))
+			r.POST("/customers", func(c *gin.Context) {
+				var body struct {
+					Name     string `json:"name"`
+					LastName string `json:"last_name"`
+				}
+				require.NoError(t, c.ShouldBindJSON(&body))
+				c.JSON(http.StatusCreated, body)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/customers", strings.NewReader(`{"name":"`+longName+`","last_name":"Simpson"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusCreated, w.Code)
+			assert.Contains(t, w.Body.String(), longName, "handler must receive the full value")
+			assert.Contains(t, logs.String(), tt.wantLogged)
+			if tt.notWantLogged != "" {
+				assert.NotContains(t, logs.String(), tt.notWantLogged)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package pkgmwr
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+
+	pkglogger "github.com/devpablocristo/tech-house/pkg/config/logger"
+)
+
+// Constantes del request ID
+const (
+	// DefaultRequestIDHeader es el header del request ID si no se configura otro
+	DefaultRequestIDHeader = "X-Request-ID"
+	// RequestIDContextKey es la clave del gin.Context donde se guarda el request ID
+	RequestIDContextKey = "request_id"
+
+	maxRequestIDLength = 128
+)
+
+type requestIDKey struct{}
+
+// RequestID middleware que toma el request ID del header indicado (X-Request-ID si está vacío)
+// o genera uno, lo deja en el contexto (también como campo del logger de contexto) y lo devuelve en la
+// respuesta bajo el mismo header
+func RequestID(headerName string) gin.HandlerFunc {
+	if headerName == "" {
+		headerName = DefaultRequestIDHeader
+	}
+
+	return func(c *gin.Context) {
+		id := ResolveRequestID(c.GetHeader(headerName))
+
+		c.Set(RequestIDContextKey, id)
+		ctx := ContextWithRequestID(c.Request.Context(), id)
+		c.Request = c.Request.WithContext(pkglogger.WithFields(ctx, "request_id", id))
+		c.Header(headerName, id)
+		c.Next()
+	}
+}
+
+// ResolveRequestID retorna el request ID recibido si es válido o uno nuevo si no vino o no es confiable
+// (demasiado largo o con caracteres fuera de [A-Za-z0-9._-]), para no propagar basura a logs y headers
+func ResolveRequestID(incoming string) string {
+	if isValidRequestID(incoming) {
+		return incoming
+	}
+	return NewRequestID()
+}
+
+// NewRequestID genera un request ID aleatorio de 32 caracteres hexadecimales
+func NewRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ContextWithRequestID guarda el request ID en un context.Context
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext obtiene el request ID de un context.Context
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package pkgmwr_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mwr "github.com/devpablocristo/tech-house/pkg/rest/middlewares/gin"
+)
+
+func Test_RequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		headerName string
+		incoming   map[string]string
+		wantHeader string
+		wantID     string
+	}{
+		{
+			name:       "should echo the id under a custom header name",
+			headerName: "X-Correlation-ID",
+			incoming:   map[string]string{"X-Correlation-ID": "abc-123"},
+			wantHeader: "X-Correlation-ID",
+			wantID:     "abc-123",
+		},
+		{
+			name:       "should ignore the default header when a custom one is configured",
+			headerName: "X-Correlation-ID",
+			incoming:   map[string]string{"X-Request-ID": "abc-123"},
+			wantHeader: "X-Correlation-ID",
+		},
+		{
+			name:       "should use X-Request-ID when no header name is configured",
+			incoming:   map[string]string{"X-Request-ID": "abc-123"},
+			wantHeader: mwr.DefaultRequestIDHeader,
+			wantID:     "abc-123",
+		},
+		{
+			name:       "should replace an invalid id",
+			headerName: "X-Correlation-ID",
+			incoming:   map[string]string{"X-Correlation-ID": "bad id\r\n" + strings.Repeat("x", 200)},
+			wantHeader: "X-Correlation-ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fromGin, fromCtx string
+			router := gin.New()
+			router.Use(mwr.RequestID(tt.headerName))
+			router.GET("/ping", func(c *gin.Context) {
+				fromGin = c.GetString(mwr.RequestIDContextKey)
+				fromCtx, _ = mwr.RequestIDFromContext(c.Request.Context())
+				c.Status(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+			for name, value := range tt.incoming {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			got := w.Header().Get(tt.wantHeader)
+			require.NotEmpty(t, got)
+			if tt.wantID != "" {
+				assert.Equal(t, tt.wantID, got)
+			} else {
+				assert.Len(t, got, 32)
+			}
+			assert.Equal(t, got, fromGin)
+			assert.Equal(t, got, fromCtx)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package pkgmwr
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultSecurityHeaders retorna la línea base de headers de seguridad que exigen los scanners
+func DefaultSecurityHeaders() map[string]string {
+	return map[string]string{
+		"X-Content-Type-Options":    "nosniff",
+		"X-Frame-Options":           "DENY",
+		"Strict-Transport-Security": "max-age=63072000; includeSubDomains",
+		"Referrer-Policy":           "no-referrer",
+		"Cache-Control":             "no-store",
+	}
+}
+
+// SecurityHeaders middleware que agrega headers estáticos a todas las respuestas.
+// Los headers se escriben antes de ejecutar el handler, por lo que también
+// quedan en las respuestas de error y en las abortadas por otros middlewares.
+func SecurityHeaders(headers map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for name, value := range headers {
+			c.Header(name, value)
+		}
+		c.Next()
+	}
+}
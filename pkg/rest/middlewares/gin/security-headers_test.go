@@ -0,0 +1,69 @@
+package pkgmwr_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	mwr "github.com/devpablocristo/tech-house/pkg/rest/middlewares/gin"
+)
+
+func Test_SecurityHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		handler  gin.HandlerFunc
+		wantCode int
+	}{
+		{
+			name:     "should add default headers to successful responses",
+			headers:  mwr.DefaultSecurityHeaders(),
+			handler:  func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"message": "pong"}) },
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "should add default headers to error responses",
+			headers:  mwr.DefaultSecurityHeaders(),
+			handler:  func(c *gin.Context) { c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "boom"}) },
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "should add custom headers",
+			headers:  map[string]string{"X-Frame-Options": "SAMEORIGIN", "Permissions-Policy": "geolocation=()"},
+			handler:  func(c *gin.Context) { c.Status(http.StatusNoContent) },
+			wantCode: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(mwr.SecurityHeaders(tt.headers))
+			r.GET("/test", tt.handler)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			for name, value := range tt.headers {
+				assert.Equal(t, value, w.Header().Get(name), name)
+			}
+		})
+	}
+
+	t.Run("should add headers to unknown routes", func(t *testing.T) {
+		r := gin.New()
+		r.Use(mwr.SecurityHeaders(mwr.DefaultSecurityHeaders()))
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -33,6 +34,8 @@ const (
 	errInvalidClaimType        = "invalid claim type"
 	errInvalidTokenLookup      = "invalid token lookup config"
 	errUnsupportedLookupMethod = "unsupported token lookup method"
+	errIssuerNotAllowed        = "token issuer not allowed"
+	errAudienceNotAllowed      = "token audience not allowed"
 )
 
 // Config permite configurar el comportamiento del middleware JWT
@@ -42,6 +45,33 @@ type Config struct {
 	TokenLookup  string // Formato: "header:Authorization" o "query:token"
 	TokenPrefix  string // Ejemplo: "Bearer "
 	ContextKey   string // Clave para almacenar el token en el contexto
+
+	JWKSURL             string        // Endpoint JWKS del IdP para claves RSA rotativas
+	JWKSCacheTTL        time.Duration // Tiempo tras el cual el cache se considera expirado
+	JWKSRefreshInterval time.Duration // Intervalo de refresco en segundo plano
+	// JWKSForcedRefreshCooldown es el tiempo mínimo entre refrescos forzados por un kid desconocido (30s por
+	// defecto); dentro de ese tiempo los tokens con kids desconocidos se rechazan sin consultar al IdP
+	JWKSForcedRefreshCooldown time.Duration
+	// Done detiene el refresco en segundo plano de los JWKS cuando se cierra (por ejemplo el ctx.Done() del
+	// shutdown del servidor); en nil el refresco dura toda la vida del proceso
+	Done <-chan struct{}
+
+	// Issuers habilita la federación: si no está vacío, solo se aceptan tokens cuyo
+	// claim "iss" coincida con alguno de la lista y se verifican con su propio JWKS
+	Issuers []IssuerConfig
+}
+
+// IssuerConfig describe un IdP de confianza
+type IssuerConfig struct {
+	Issuer    string   `json:"issuer"`    // Valor esperado del claim "iss"
+	JWKSURL   string   `json:"jwks_url"`  // Endpoint JWKS del IdP
+	Audiences []string `json:"audiences"` // Audiencias permitidas; vacío acepta cualquiera
+}
+
+// trustedIssuer asocia la configuración de un issuer con su cache de claves
+type trustedIssuer struct {
+	config IssuerConfig
+	keys   *jwksCache
 }
 
 // DefaultConfig retorna una configuración por defecto
@@ -53,59 +83,117 @@ func DefaultConfig() Config {
 	}
 }
 
-// Validate middleware para validación de JWT
-func Validate(config Config) gin.HandlerFunc {
+// tokenValidator concentra la verificación de JWT para que la usen tanto Validate como Authenticate
+type tokenValidator struct {
+	config       Config
+	rsaPublicKey *rsa.PublicKey
+	keySet       *jwksCache
+	issuers      map[string]*trustedIssuer
+}
+
+func newTokenValidator(config Config) *tokenValidator {
 	if config.TokenLookup == "" {
 		config = DefaultConfig()
 	}
 
-	var rsaPublicKey *rsa.PublicKey
+	v := &tokenValidator{
+		config:  config,
+		issuers: make(map[string]*trustedIssuer, len(config.Issuers)),
+	}
+
 	if config.PublicKeyPEM != "" {
 		key, err := parseRSAPublicKey(config.PublicKeyPEM)
 		if err != nil {
 			panic(fmt.Sprintf("failed to parse RSA public key: %v", err))
 		}
-		rsaPublicKey = key
+		v.rsaPublicKey = key
 	}
 
+	if config.JWKSURL != "" {
+		v.keySet = newJWKSCache(config.JWKSURL, config.JWKSCacheTTL, config.JWKSRefreshInterval, config.JWKSForcedRefreshCooldown, config.Done)
+	}
+
+	for _, issuer := range config.Issuers {
+		v.issuers[issuer.Issuer] = &trustedIssuer{
+			config: issuer,
+			keys:   newJWKSCache(issuer.JWKSURL, config.JWKSCacheTTL, config.JWKSRefreshInterval, config.JWKSForcedRefreshCooldown, config.Done),
+		}
+	}
+
+	return v
+}
+
+// Validate middleware para validación de JWT
+func Validate(config Config) gin.HandlerFunc {
+	v := newTokenValidator(config)
+
 	return func(c *gin.Context) {
-		token, err := extractToken(c, config)
+		token, err := v.validate(c)
 		if err != nil {
 			abortWithError(c, http.StatusUnauthorized, err.Error())
 			return
 		}
 
-		// Parsear sin validar para determinar el algoritmo
-		unverifiedToken, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
-		if err != nil {
-			abortWithError(c, http.StatusUnauthorized, fmt.Sprintf("%s: %v", errInvalidToken, err))
-			return
-		}
+		v.store(c, token)
+		c.Next()
+	}
+}
 
-		keyFunc := selectKeyFunc(unverifiedToken, config.SecretKey, rsaPublicKey)
-		if keyFunc == nil {
-			abortWithError(c, http.StatusUnauthorized, errInvalidSigningMethod)
-			return
-		}
+// validate extrae y verifica el token de la petición
+func (v *tokenValidator) validate(c *gin.Context) (*jwt.Token, error) {
+	token, err := extractToken(c, v.config)
+	if err != nil {
+		return nil, err
+	}
 
-		// Validar el token
-		parsedToken, err := jwt.Parse(token, keyFunc)
-		if err != nil || !parsedToken.Valid {
-			abortWithError(c, http.StatusUnauthorized, fmt.Sprintf("%s: %v", errInvalidToken, err))
-			return
-		}
+	// Parsear sin validar para determinar el algoritmo
+	unverifiedToken, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", errInvalidToken, err)
+	}
 
-		// Guardar token y claims en el contexto
-		contextKey := config.ContextKey
-		if contextKey == "" {
-			contextKey = DefaultContextKey
+	var keyFunc jwt.Keyfunc
+	var parserOpts []jwt.ParserOption
+	var issuer *trustedIssuer
+	if len(v.issuers) > 0 {
+		// Con federación la verificación se elige por el claim "iss" y solo se aceptan claves del JWKS del issuer
+		iss, _ := unverifiedToken.Claims.GetIssuer()
+		trusted, ok := v.issuers[iss]
+		if !ok {
+			return nil, fmt.Errorf(errIssuerNotAllowed)
 		}
+		issuer = trusted
+		keyFunc = selectKeyFunc(c, unverifiedToken, "", nil, issuer.keys)
+		parserOpts = append(parserOpts, jwt.WithIssuer(iss))
+	} else {
+		keyFunc = selectKeyFunc(c, unverifiedToken, v.config.SecretKey, v.rsaPublicKey, v.keySet)
+	}
+	if keyFunc == nil {
+		return nil, fmt.Errorf(errInvalidSigningMethod)
+	}
 
-		c.Set(contextKey, parsedToken)
-		c.Set(GetClaimsKey(contextKey), parsedToken.Claims)
+	// Validar el token
+	parsedToken, err := jwt.Parse(token, keyFunc, parserOpts...)
+	if err != nil || !parsedToken.Valid {
+		return nil, fmt.Errorf("%s: %v", errInvalidToken, err)
+	}
 
-		c.Next()
+	if issuer != nil && !audienceAllowed(parsedToken, issuer.config.Audiences) {
+		return nil, fmt.Errorf(errAudienceNotAllowed)
+	}
+
+	return parsedToken, nil
+}
+
+// store guarda token y claims en el contexto
+func (v *tokenValidator) store(c *gin.Context, token *jwt.Token) {
+	contextKey := v.config.ContextKey
+	if contextKey == "" {
+		contextKey = DefaultContextKey
 	}
+
+	c.Set(contextKey, token)
+	c.Set(GetClaimsKey(contextKey), token.Claims)
 }
 
 // ExtractClaim extrae un claim específico del token JWT
@@ -187,7 +275,7 @@ func extractFromQuery(c *gin.Context, param string) (string, error) {
 	return token, nil
 }
 
-func selectKeyFunc(token *jwt.Token, secretKey string, rsaKey *rsa.PublicKey) jwt.Keyfunc {
+func selectKeyFunc(c *gin.Context, token *jwt.Token, secretKey string, rsaKey *rsa.PublicKey, keySet *jwksCache) jwt.Keyfunc {
 	switch token.Method.(type) {
 	case *jwt.SigningMethodHMAC:
 		if secretKey == "" {
@@ -197,6 +285,12 @@ func selectKeyFunc(token *jwt.Token, secretKey string, rsaKey *rsa.PublicKey) jw
 			return []byte(secretKey), nil
 		}
 	case *jwt.SigningMethodRSA:
+		// Si hay JWKS configurado, la clave se resuelve por el kid del header
+		if kid, _ := token.Header["kid"].(string); kid != "" && keySet != nil {
+			return func(token *jwt.Token) (interface{}, error) {
+				return keySet.getKey(c.Request.Context(), kid)
+			}
+		}
 		if rsaKey == nil {
 			return nil
 		}
@@ -208,6 +302,27 @@ func selectKeyFunc(token *jwt.Token, secretKey string, rsaKey *rsa.PublicKey) jw
 	}
 }
 
+// audienceAllowed verifica que alguna audiencia del token esté en la lista permitida
+func audienceAllowed(token *jwt.Token, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	audiences, err := token.Claims.GetAudience()
+	if err != nil {
+		return false
+	}
+
+	for _, aud := range audiences {
+		for _, a := range allowed {
+			if aud == a {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func parseRSAPublicKey(pemStr string) (*rsa.PublicKey, error) {
 	block, _ := pem.Decode([]byte(pemStr))
 	if block == nil {
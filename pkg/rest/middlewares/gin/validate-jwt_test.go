@@ -0,0 +1,84 @@
+package pkgmwr_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+
+	mwr "github.com/devpablocristo/tech-house/pkg/rest/middlewares/gin"
+)
+
+func Test_Validate_MultipleIssuers(t *testing.T) {
+	keyA := newSigningKey(t, "idp-a-key")
+	keyB := newSigningKey(t, "idp-b-key")
+	keyRogue := newSigningKey(t, "rogue-key")
+
+	idpA := &fakeIdP{}
+	idpA.rotate(keyA)
+	serverA := httptest.NewServer(idpA)
+	defer serverA.Close()
+
+	idpB := &fakeIdP{}
+	idpB.rotate(keyB)
+	serverB := httptest.NewServer(idpB)
+	defer serverB.Close()
+
+	router := newProtectedRouter(mwr.Config{
+		TokenLookup:         "header:Authorization",
+		TokenPrefix:         "Bearer ",
+		JWKSCacheTTL:        time.Hour,
+		JWKSRefreshInterval: time.Hour,
+		Issuers: []mwr.IssuerConfig{
+			{Issuer: "https://idp-a.example.com", JWKSURL: serverA.URL, Audiences: []string{"customers-api"}},
+			{Issuer: "https://idp-b.example.com", JWKSURL: serverB.URL},
+		},
+	})
+
+	tests := []struct {
+		name     string
+		key      signingKey
+		claims   jwt.MapClaims
+		wantCode int
+	}{
+		{
+			name:     "should accept token from first issuer with allowed audience",
+			key:      keyA,
+			claims:   jwt.MapClaims{"iss": "https://idp-a.example.com", "aud": "customers-api"},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "should accept token from second issuer",
+			key:      keyB,
+			claims:   jwt.MapClaims{"iss": "https://idp-b.example.com", "aud": "anything"},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "should reject token from first issuer with foreign audience",
+			key:      keyA,
+			claims:   jwt.MapClaims{"iss": "https://idp-a.example.com", "aud": "billing-api"},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "should reject token claiming an issuer but signed with another issuer key",
+			key:      keyB,
+			claims:   jwt.MapClaims{"iss": "https://idp-a.example.com", "aud": "customers-api"},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "should reject token from unlisted issuer",
+			key:      keyRogue,
+			claims:   jwt.MapClaims{"iss": "https://rogue.example.com", "aud": "customers-api"},
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantCode, doRequest(router, signTokenWithClaims(t, tt.key, tt.claims)))
+		})
+	}
+}
@@ -11,15 +11,18 @@ type APIErrorType string
 
 // Constantes para APIErrorType
 const (
-	APIErrNotFound     APIErrorType = "NOT_FOUND"
-	APIErrConflict     APIErrorType = "CONFLICT"
-	APIErrBadRequest   APIErrorType = "BAD_REQUEST"
-	APIErrInternal     APIErrorType = "INTERNAL_ERROR"
-	APIErrValidation   APIErrorType = "VALIDATION_ERROR"
-	APIErrUnauthorized APIErrorType = "UNAUTHORIZED"
-	APIErrTimeout      APIErrorType = "TIMEOUT"
-	APIErrUnavailable  APIErrorType = "SERVICE_UNAVAILABLE"
-	APIErrForbidden    APIErrorType = "FORBIDDEN"
+	APIErrNotFound             APIErrorType = "NOT_FOUND"
+	APIErrConflict             APIErrorType = "CONFLICT"
+	APIErrBadRequest           APIErrorType = "BAD_REQUEST"
+	APIErrInternal             APIErrorType = "INTERNAL_ERROR"
+	APIErrValidation           APIErrorType = "VALIDATION_ERROR"
+	APIErrUnauthorized         APIErrorType = "UNAUTHORIZED"
+	APIErrTimeout              APIErrorType = "TIMEOUT"
+	APIErrUnavailable          APIErrorType = "SERVICE_UNAVAILABLE"
+	APIErrForbidden            APIErrorType = "FORBIDDEN"
+	APIErrUnsupportedMediaType APIErrorType = "UNSUPPORTED_MEDIA_TYPE"
+	APIErrTooManyRequests      APIErrorType = "TOO_MANY_REQUESTS"
+	APIErrPayloadTooLarge      APIErrorType = "PAYLOAD_TOO_LARGE"
 )
 
 // APIError representa un error de API
@@ -40,6 +43,20 @@ type APIErrorResponse struct {
 	Context map[string]any `json:"context,omitempty"`
 }
 
+// APIErrorEnvelope es el cuerpo de error {"error": {...}} para clientes que parsean la respuesta
+type APIErrorEnvelope struct {
+	Error APIErrorBody `json:"error"`
+}
+
+// APIErrorBody es el contenido de APIErrorEnvelope; details es una lista para que siempre tenga la misma forma
+type APIErrorBody struct {
+	Code    APIErrorType   `json:"code"`
+	Status  int            `json:"status"`
+	Message string         `json:"message"`
+	Details []string       `json:"details,omitempty"`
+	Context map[string]any `json:"context,omitempty"`
+}
+
 // Métodos para APIError
 func (e *APIError) Error() string {
 	if e.Details != "" {
@@ -59,27 +76,34 @@ func (e *APIErrorResponse) HasCode(code int) bool {
 
 // Mapeos
 var errorToAPIError = map[ErrorType]APIErrorType{
-	ErrNotFound:        APIErrNotFound,
-	ErrConflict:        APIErrConflict,
-	ErrInvalidInput:    APIErrBadRequest,
-	ErrValidation:      APIErrValidation,
-	ErrOperationFailed: APIErrInternal,
-	ErrConnection:      APIErrUnavailable,
-	ErrTimeout:         APIErrTimeout,
-	ErrAuthentication:  APIErrUnauthorized,
-	ErrAuthorization:   APIErrForbidden,
+	ErrNotFound:             APIErrNotFound,
+	ErrConflict:             APIErrConflict,
+	ErrInvalidInput:         APIErrBadRequest,
+	ErrValidation:           APIErrValidation,
+	ErrOperationFailed:      APIErrInternal,
+	ErrConnection:           APIErrUnavailable,
+	ErrTimeout:              APIErrTimeout,
+	ErrAuthentication:       APIErrUnauthorized,
+	ErrAuthorization:        APIErrForbidden,
+	ErrUnsupportedMediaType: APIErrUnsupportedMediaType,
+	ErrRateLimited:          APIErrTooManyRequests,
+	ErrPayloadTooLarge:      APIErrPayloadTooLarge,
+	ErrInternal:             APIErrInternal,
 }
 
 var httpStatus = map[APIErrorType]int{
-	APIErrBadRequest:   http.StatusBadRequest,
-	APIErrNotFound:     http.StatusNotFound,
-	APIErrConflict:     http.StatusConflict,
-	APIErrInternal:     http.StatusInternalServerError,
-	APIErrValidation:   http.StatusBadRequest,
-	APIErrUnauthorized: http.StatusUnauthorized,
-	APIErrTimeout:      http.StatusGatewayTimeout,
-	APIErrUnavailable:  http.StatusServiceUnavailable,
-	APIErrForbidden:    http.StatusForbidden,
+	APIErrBadRequest:           http.StatusBadRequest,
+	APIErrNotFound:             http.StatusNotFound,
+	APIErrConflict:             http.StatusConflict,
+	APIErrInternal:             http.StatusInternalServerError,
+	APIErrValidation:           http.StatusBadRequest,
+	APIErrUnauthorized:         http.StatusUnauthorized,
+	APIErrTimeout:              http.StatusGatewayTimeout,
+	APIErrUnavailable:          http.StatusServiceUnavailable,
+	APIErrForbidden:            http.StatusForbidden,
+	APIErrUnsupportedMediaType: http.StatusUnsupportedMediaType,
+	APIErrTooManyRequests:      http.StatusTooManyRequests,
+	APIErrPayloadTooLarge:      http.StatusRequestEntityTooLarge,
 }
 
 // Convertir Error a APIError
@@ -92,6 +116,9 @@ func NewAPIError(err error) (*APIError, int) {
 		}
 
 		code := httpStatus[apiType]
+		if domainErr.Code != "" {
+			apiType = APIErrorType(domainErr.Code)
+		}
 		apiError := &APIError{
 			Type:    apiType,
 			Code:    code,
@@ -125,3 +152,17 @@ func (e *APIError) ToResponse() *APIErrorResponse {
 		Context: e.Context,
 	}
 }
+
+// Convertir APIError a APIErrorEnvelope
+func (e *APIError) ToEnvelope() *APIErrorEnvelope {
+	body := APIErrorBody{
+		Code:    e.Type,
+		Status:  e.Code,
+		Message: e.Message,
+		Context: e.Context,
+	}
+	if e.Details != "" {
+		body.Details = []string{e.Details}
+	}
+	return &APIErrorEnvelope{Error: body}
+}
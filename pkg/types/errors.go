@@ -10,17 +10,20 @@ type ErrorType string
 
 // Constantes para ErrorType
 const (
-	ErrNotFound        ErrorType = "NOT_FOUND"
-	ErrConflict        ErrorType = "CONFLICT"
-	ErrInvalidInput    ErrorType = "INVALID_INPUT"
-	ErrOperationFailed ErrorType = "OPERATION_FAILED"
-	ErrValidation      ErrorType = "VALIDATION_ERROR"
-	ErrConnection      ErrorType = "CONNECTION_ERROR"
-	ErrTimeout         ErrorType = "TIMEOUT"
-	ErrUnavailable     ErrorType = "SERVICE_UNAVAILABLE"
-	ErrAuthentication  ErrorType = "AUTHENTICATION_ERROR"
-	ErrAuthorization   ErrorType = "AUTHORIZATION_ERROR"
-	ErrInternal        ErrorType = "INTERNAL_ERROR"
+	ErrNotFound             ErrorType = "NOT_FOUND"
+	ErrConflict             ErrorType = "CONFLICT"
+	ErrInvalidInput         ErrorType = "INVALID_INPUT"
+	ErrOperationFailed      ErrorType = "OPERATION_FAILED"
+	ErrValidation           ErrorType = "VALIDATION_ERROR"
+	ErrConnection           ErrorType = "CONNECTION_ERROR"
+	ErrTimeout              ErrorType = "TIMEOUT"
+	ErrUnavailable          ErrorType = "SERVICE_UNAVAILABLE"
+	ErrAuthentication       ErrorType = "AUTHENTICATION_ERROR"
+	ErrAuthorization        ErrorType = "AUTHORIZATION_ERROR"
+	ErrInternal             ErrorType = "INTERNAL_ERROR"
+	ErrUnsupportedMediaType ErrorType = "UNSUPPORTED_MEDIA_TYPE"
+	ErrRateLimited          ErrorType = "RATE_LIMITED"
+	ErrPayloadTooLarge      ErrorType = "PAYLOAD_TOO_LARGE"
 )
 
 // Error representa un error del dominio
@@ -29,6 +32,18 @@ type Error struct {
 	Message string         `json:"message"`
 	Details error          `json:"-"`
 	Context map[string]any `json:"context,omitempty"`
+	// Code reemplaza al tipo como código de la respuesta de API (por ejemplo CUSTOMER_NOT_FOUND) para
+	// que el cliente distinga casos del mismo tipo; el status HTTP sigue saliendo de Type
+	Code string `json:"code,omitempty"`
+}
+
+// CodeResourceNotFound es el código de un 404 por una ruta inexistente, a diferencia de un recurso inexistente
+const CodeResourceNotFound = "RESOURCE_NOT_FOUND"
+
+// WithCode asigna el código específico del error y lo retorna, para encadenarlo con los constructores
+func (e *Error) WithCode(code string) *Error {
+	e.Code = code
+	return e
 }
 
 // Métodos para Error
@@ -61,6 +76,21 @@ func NewErrorWithContext(errType ErrorType, message string, details error, conte
 	}
 }
 
+// FieldErrorsKey es la clave del contexto de un ErrValidation donde van los campos inválidos
+const FieldErrorsKey = "fields"
+
+// FieldError describe la falla de validación de un campo del request
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// NewFieldValidationError crea un ErrValidation con los campos inválidos en el contexto (ver FieldErrorsKey)
+func NewFieldValidationError(message string, details error, fields []FieldError) *Error {
+	return NewErrorWithContext(ErrValidation, message, details, map[string]any{FieldErrorsKey: fields})
+}
+
 // Funciones helper para verificar tipos de error
 func IsNotFound(err error) bool {
 	var e *Error
@@ -77,6 +107,25 @@ func IsValidationError(err error) bool {
 	return errors.As(err, &e) && e.Type == ErrValidation
 }
 
+func IsRateLimited(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Type == ErrRateLimited
+}
+
+// IsRetryable indica si el error es transitorio y la operación se puede reintentar
+func IsRetryable(err error) bool {
+	var e *Error
+	if !errors.As(err, &e) {
+		return false
+	}
+	switch e.Type {
+	case ErrRateLimited, ErrTimeout, ErrUnavailable, ErrConnection:
+		return true
+	default:
+		return false
+	}
+}
+
 // GetErrorType extrae el tipo de error
 func GetErrorType(err error) (ErrorType, bool) {
 	var e *Error
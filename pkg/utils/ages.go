@@ -1,15 +1,24 @@
 package pkgutils
 
-import "fmt"
+import (
+	"fmt"
 
-func ValidateAge(age int, minAge, maxAge int) error {
-	if age < minAge {
-		return fmt.Errorf("age must be between %d and %d", minAge, maxAge)
-	}
+	types "github.com/devpablocristo/tech-house/pkg/types"
+)
 
-	if age > maxAge {
-		return fmt.Errorf("age must be between %d and %d", minAge, maxAge)
-	}
+// DefaultMaxAge es la edad máxima que se acepta cuando el llamador no tiene otra configurada
+const DefaultMaxAge = 150
 
+// ValidateAge verifica que age esté entre minAge y maxAge, ambos inclusive; una edad negativa se rechaza
+// aunque minAge lo sea. Los errores son types.ErrValidation.
+func ValidateAge(age int, minAge, maxAge int) error {
+	if age < 0 || age < minAge || age > maxAge {
+		return types.NewErrorWithContext(
+			types.ErrValidation,
+			fmt.Sprintf("age must be between %d and %d", max(minAge, 0), maxAge),
+			nil,
+			map[string]any{"age": age, "min": minAge, "max": maxAge},
+		)
+	}
 	return nil
 }
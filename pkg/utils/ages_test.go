@@ -0,0 +1,37 @@
+package pkgutils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	pkgutils "github.com/devpablocristo/tech-house/pkg/utils"
+)
+
+func Test_ValidateAge(t *testing.T) {
+	tests := []struct {
+		name    string
+		age     int
+		minAge  int
+		wantErr bool
+	}{
+		{name: "should reject a negative age", age: -1, minAge: -10, wantErr: true},
+		{name: "should reject 0 below the minimum", age: 0, minAge: 1, wantErr: true},
+		{name: "should accept 0 when the minimum allows it", age: 0, minAge: 0},
+		{name: "should accept the minimum", age: 1, minAge: 1},
+		{name: "should accept the maximum", age: pkgutils.DefaultMaxAge, minAge: 1},
+		{name: "should reject one above the maximum", age: pkgutils.DefaultMaxAge + 1, minAge: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := pkgutils.ValidateAge(tt.age, tt.minAge, pkgutils.DefaultMaxAge)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, types.IsValidationError(err), "got %v", err)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package pkgutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// RunConcurrent ejecuta fn sobre cada item con un pool de maxWorkers goroutines.
+// Los resultados se devuelven en el mismo orden que items; si algún item falla,
+// el resultado de esa posición queda en su valor cero y los errores se agregan con errors.Join.
+// Si el contexto se cancela no se despachan más items y se incluye ctx.Err() en el error.
+func RunConcurrent[T, R any](ctx context.Context, items []T, maxWorkers int, fn func(context.Context, T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	if len(items) == 0 {
+		return results, nil
+	}
+	if maxWorkers <= 0 || maxWorkers > len(items) {
+		maxWorkers = len(items)
+	}
+
+	errs := make([]error, len(items))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < maxWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result, err := fn(ctx, items[i])
+				if err != nil {
+					errs[i] = fmt.Errorf("item %d: %w", i, err)
+					continue
+				}
+				results[i] = result
+			}
+		}()
+	}
+
+	var ctxErr error
+dispatch:
+	for i := range items {
+		select {
+		case <-ctx.Done():
+			ctxErr = ctx.Err()
+			break dispatch
+		case indexes <- i:
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	// Cada goroutine escribe solo en su propia posición de errs, por lo que se puede leer sin lock tras el Wait
+	return results, errors.Join(append(errs, ctxErr)...)
+}
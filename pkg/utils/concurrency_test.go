@@ -0,0 +1,82 @@
+package pkgutils_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgutils "github.com/devpablocristo/tech-house/pkg/utils"
+)
+
+func Test_RunConcurrent(t *testing.T) {
+	t.Run("should preserve result order", func(t *testing.T) {
+		items := []int{5, 1, 4, 2, 3}
+		results, err := pkgutils.RunConcurrent(context.Background(), items, 3, func(ctx context.Context, n int) (int, error) {
+			time.Sleep(time.Duration(n) * time.Millisecond)
+			return n * 10, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []int{50, 10, 40, 20, 30}, results)
+	})
+
+	t.Run("should never exceed max workers", func(t *testing.T) {
+		var running, peak atomic.Int32
+		items := make([]int, 20)
+		_, err := pkgutils.RunConcurrent(context.Background(), items, 4, func(ctx context.Context, _ int) (struct{}, error) {
+			current := running.Add(1)
+			for {
+				old := peak.Load()
+				if current <= old || peak.CompareAndSwap(old, current) {
+					break
+				}
+			}
+			time.Sleep(2 * time.Millisecond)
+			running.Add(-1)
+			return struct{}{}, nil
+		})
+		require.NoError(t, err)
+		assert.LessOrEqual(t, peak.Load(), int32(4))
+	})
+
+	t.Run("should aggregate errors from every failing item", func(t *testing.T) {
+		errOdd := errors.New("odd number")
+		results, err := pkgutils.RunConcurrent(context.Background(), []int{1, 2, 3, 4}, 2, func(ctx context.Context, n int) (int, error) {
+			if n%2 != 0 {
+				return 0, errOdd
+			}
+			return n, nil
+		})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, errOdd)
+		assert.Contains(t, err.Error(), "item 0")
+		assert.Contains(t, err.Error(), "item 2")
+		assert.Equal(t, []int{0, 2, 0, 4}, results)
+	})
+
+	t.Run("should stop dispatching when context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var processed atomic.Int32
+		items := make([]int, 100)
+		_, err := pkgutils.RunConcurrent(ctx, items, 1, func(ctx context.Context, _ int) (int, error) {
+			if processed.Add(1) == 3 {
+				cancel()
+			}
+			return 0, nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, processed.Load(), int32(100))
+	})
+
+	t.Run("should handle empty input", func(t *testing.T) {
+		results, err := pkgutils.RunConcurrent(context.Background(), []int{}, 4, func(ctx context.Context, n int) (int, error) {
+			return n, nil
+		})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
@@ -2,24 +2,50 @@ package pkgutils
 
 import (
 	"fmt"
-	"regexp"
+	"net/mail"
+	"strings"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
 )
 
-var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+// maxEmailLength is the maximum address length allowed by RFC 5321
+const maxEmailLength = 254
 
-// ValidateEmail checks if the email format is valid
+// ValidateEmail checks that email is a bare address ("user@example.com", without display name) whose domain
+// has at least two non-empty labels. Every failure is a types.ErrValidation.
 func ValidateEmail(email string) error {
 	if email == "" {
-		return fmt.Errorf("email cannot be empty")
+		return invalidEmail(email, "email cannot be empty")
+	}
+
+	if len(email) > maxEmailLength {
+		return invalidEmail(email, fmt.Sprintf("email exceeds maximum length of %d characters", maxEmailLength))
 	}
 
-	if len(email) > 254 { // RFC 5321
-		return fmt.Errorf("email exceeds maximum length of 254 characters")
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Name != "" || addr.Address != email {
+		return invalidEmail(email, fmt.Sprintf("invalid email format: %s", email))
 	}
 
-	if !emailRegex.MatchString(email) {
-		return fmt.Errorf("invalid email format: %s", email)
+	at := strings.LastIndex(email, "@")
+	labels := strings.Split(email[at+1:], ".")
+	if len(labels) < 2 || len(labels[len(labels)-1]) < 2 {
+		return invalidEmail(email, fmt.Sprintf("invalid email domain: %s", email))
+	}
+	for _, label := range labels {
+		if label == "" || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return invalidEmail(email, fmt.Sprintf("invalid email domain: %s", email))
+		}
 	}
 
 	return nil
 }
+
+func invalidEmail(email, message string) error {
+	return types.NewErrorWithContext(
+		types.ErrValidation,
+		message,
+		nil,
+		map[string]any{"email": email},
+	)
+}
@@ -0,0 +1,40 @@
+package pkgutils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	pkgutils "github.com/devpablocristo/tech-house/pkg/utils"
+)
+
+func Test_ValidateEmail(t *testing.T) {
+	tests := []struct {
+		name    string
+		email   string
+		wantErr bool
+	}{
+		{name: "should accept a simple address", email: "homero@springfield.com"},
+		{name: "should accept tags and subdomains", email: "homero.j+news@mail.springfield.co.uk"},
+		{name: "should reject an empty address", email: "", wantErr: true},
+		{name: "should reject an address without @", email: "homerospringfield.com", wantErr: true},
+		{name: "should reject a domain with a trailing dot", email: "homero@springfield.com.", wantErr: true},
+		{name: "should reject consecutive dots in the domain", email: "homero@springfield..com", wantErr: true},
+		{name: "should reject a domain without a dot", email: "homero@localhost", wantErr: true},
+		{name: "should reject a display name", email: "Homero <homero@springfield.com>", wantErr: true},
+		{name: "should reject a label starting with a hyphen", email: "homero@-springfield.com", wantErr: true},
+		{name: "should reject an address that is too long", email: string(make([]byte, 250)) + "@a.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := pkgutils.ValidateEmail(tt.email)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, types.IsValidationError(err), "got %v", err)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package pkgutils_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgutils "github.com/devpablocristo/tech-house/pkg/utils"
+)
+
+func Test_EncryptForRecipient(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	publicPEM, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	publicKey, err := pkgutils.ParseRSAPublicKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicPEM}))
+	require.NoError(t, err)
+	privateKey, err := pkgutils.ParseRSAPrivateKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	require.NoError(t, err)
+
+	t.Run("should round trip a value", func(t *testing.T) {
+		// Más largo que lo que admite RSA-OAEP directo con una clave de 2048 bits
+		plaintext := "a-very-long-email-address-" + string(make([]byte, 300)) + "@example.com"
+
+		ciphertext, err := pkgutils.EncryptForRecipient(publicKey, plaintext)
+		require.NoError(t, err)
+		assert.NotContains(t, ciphertext, "example.com")
+
+		decrypted, err := pkgutils.DecryptWithPrivateKey(privateKey, ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+	})
+
+	t.Run("should produce a different ciphertext each time", func(t *testing.T) {
+		first, err := pkgutils.EncryptForRecipient(publicKey, "secret")
+		require.NoError(t, err)
+		second, err := pkgutils.EncryptForRecipient(publicKey, "secret")
+		require.NoError(t, err)
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("should fail on tampered ciphertext", func(t *testing.T) {
+		ciphertext, err := pkgutils.EncryptForRecipient(publicKey, "secret")
+		require.NoError(t, err)
+
+		tampered := []byte(ciphertext)
+		tampered[len(tampered)-3] ^= 0x01
+		_, err = pkgutils.DecryptWithPrivateKey(privateKey, string(tampered))
+		assert.Error(t, err)
+	})
+}
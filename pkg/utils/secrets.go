@@ -0,0 +1,15 @@
+package pkgutils
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+)
+
+// SecureCompare compara dos secretos (API keys, firmas HMAC, tokens) en tiempo constante.
+// Ambos valores se hashean antes de comparar para no filtrar tampoco la longitud del secreto.
+// Usar siempre en lugar de == al comparar secretos.
+func SecureCompare(a, b string) bool {
+	hashA := sha256.Sum256([]byte(a))
+	hashB := sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(hashA[:], hashB[:]) == 1
+}
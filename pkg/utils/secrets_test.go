@@ -0,0 +1,32 @@
+package pkgutils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pkgutils "github.com/devpablocristo/tech-house/pkg/utils"
+)
+
+func Test_SecureCompare(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want bool
+	}{
+		{name: "should match equal secrets", a: "s3cr3t-key", b: "s3cr3t-key", want: true},
+		{name: "should match empty secrets", a: "", b: "", want: true},
+		{name: "should not match different secrets", a: "s3cr3t-key", b: "s3cr3t-kez", want: false},
+		{name: "should not match prefix", a: "s3cr3t-key", b: "s3cr3t", want: false},
+		{name: "should not match against empty", a: "s3cr3t-key", b: "", want: false},
+		{name: "should be case sensitive", a: "ABC", b: "abc", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, pkgutils.SecureCompare(tt.a, tt.b))
+			assert.Equal(t, tt.want, pkgutils.SecureCompare(tt.b, tt.a))
+		})
+	}
+}
@@ -4,11 +4,16 @@ import (
 	"context"
 	"log"
 
+	pkgflags "github.com/devpablocristo/tech-house/pkg/flags"
+	pkgutils "github.com/devpablocristo/tech-house/pkg/utils"
+
 	config "github.com/devpablocristo/tech-house/projects/customers-manager/internal/config"
 
 	custin "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound"
+	custtransport "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound/transport"
 	custout "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/outbound"
 	custcore "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core"
+	custdomain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
 )
 
 func init() {
@@ -21,14 +26,94 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	customerRepository, err := custout.NewRepository()
+	customerRepository, err := custout.NewRepository(
+		custout.WithSlowQueryThreshold(config.SlowQueryThreshold()),
+		custout.WithStatementCache(config.StatementCache()),
+		custout.WithQueryTimeout(config.QueryTimeout()),
+	)
+	if err != nil {
+		log.Fatalf("SQLite error: %v", err)
+	}
+	defer customerRepository.Close()
+
+	customerEvents, err := custout.NewEventStore(customerRepository)
+	if err != nil {
+		log.Fatalf("SQLite error: %v", err)
+	}
+
+	customerIdempotency, err := custout.NewIdempotencyStore(customerRepository)
 	if err != nil {
 		log.Fatalf("SQLite error: %v", err)
 	}
 
-	customerUsecases := custcore.NewUseCases(customerRepository)
+	defaultSort, err := custdomain.ParseCustomerSort(config.DefaultSort())
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	nameCollation, err := custdomain.ParseNameCollation(config.NameCollation())
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	customerUsecases := custcore.NewUseCases(
+		customerRepository,
+		custcore.WithEventStore(customerEvents),
+		custcore.WithSkippedEnrichers(config.SkippedEnrichers()...),
+		custcore.WithDefaultSort(defaultSort),
+		custcore.WithNameCollation(nameCollation),
+		custcore.WithExpansionLimits(config.ExpandMaxValues(), config.ExpandMaxConcurrency()),
+		custcore.WithIdempotencyStore(customerIdempotency, config.IdempotencyTTL()),
+		custcore.WithKPICoalescingWindow(config.KPICoalescingWindow()),
+		custcore.WithMaxAgeBuckets(config.AgeBucketsMax()),
+	)
+
+	if err := custtransport.SetDisplayNameTemplate(config.DisplayNameTemplate()); err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	queryPolicy, err := custin.ParseDuplicateQueryPolicy(config.DuplicateQueryPolicy())
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	slashPolicy, err := custin.ParseTrailingSlashPolicy(config.TrailingSlashPolicy())
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	emptyListStatus, err := custin.ParseEmptyListStatus(config.EmptyListStatus())
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	fieldEncryption, err := custin.ParseFieldEncryption(config.FieldEncryptionPrivateKey(), config.FieldEncryptionFields(), config.FieldEncryptionPublicKeys())
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	handlerOpts := []custin.HandlerOption{
+		custin.WithDuplicateQueryPolicy(queryPolicy),
+		custin.WithTrailingSlashPolicy(slashPolicy),
+		custin.WithStrictContentType(config.StrictContentType()),
+		custin.WithEmptyListStatus(emptyListStatus),
+		custin.WithFieldEncryption(fieldEncryption),
+	}
+
+	if interval := config.FlagsRefreshInterval(); interval > 0 {
+		envFiles, err := pkgutils.FilesFinder("config/.env")
+		if err != nil {
+			log.Fatalf("Flags error: %v", err)
+		}
+		flags, err := pkgflags.NewPollingStore(ctx, pkgflags.EnvFileSource(envFiles[0]), interval)
+		if err != nil {
+			log.Fatalf("Flags error: %v", err)
+		}
+		flags.Start(ctx)
+		handlerOpts = append(handlerOpts, custin.WithFlags(flags))
+	}
 
-	customerHandler, err := custin.NewHandler(customerUsecases)
+	customerHandler, err := custin.NewHandler(customerUsecases, handlerOpts...)
 	if err != nil {
 		log.Fatalf("Costumer Handler error: %v", err)
 	}
@@ -8,8 +8,11 @@ import (
 	config "github.com/devpablocristo/tech-house/projects/customers-manager/internal/config"
 
 	custin "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound"
+	custtransport "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound/transport"
 	custout "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/outbound"
 	custcore "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core"
+	custdomain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+	custports "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/ports"
 )
 
 func init() {
@@ -18,14 +21,103 @@ func init() {
 	}
 }
 func main() {
-	customerRepository, err := custout.NewRepository()
+	customerRepository, err := custout.NewRepository(
+		custout.WithSlowQueryThreshold(config.SlowQueryThreshold()),
+		custout.WithStatementCache(config.StatementCache()),
+		custout.WithQueryTimeout(config.QueryTimeout()),
+	)
 	if err != nil {
 		log.Fatalf("SQLite error: %v", err)
 	}
 
-	customerUsecases := custcore.NewUseCases(customerRepository)
+	customerEvents, err := custout.NewEventStore(customerRepository)
+	if err != nil {
+		log.Fatalf("SQLite error: %v", err)
+	}
+
+	customerIdempotency, err := custout.NewIdempotencyStore(customerRepository)
+	if err != nil {
+		log.Fatalf("SQLite error: %v", err)
+	}
+
+	defaultSort, err := custdomain.ParseCustomerSort(config.DefaultSort())
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	nameCollation, err := custdomain.ParseNameCollation(config.NameCollation())
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	customerUsecases := custcore.NewUseCases(
+		customerRepository,
+		custcore.WithEventStore(customerEvents),
+		custcore.WithSkippedEnrichers(config.SkippedEnrichers()...),
+		custcore.WithDefaultSort(defaultSort),
+		custcore.WithNameCollation(nameCollation),
+		custcore.WithExpansionLimits(config.ExpandMaxValues(), config.ExpandMaxConcurrency()),
+		custcore.WithIdempotencyStore(customerIdempotency, config.IdempotencyTTL()),
+		custcore.WithKPICoalescingWindow(config.KPICoalescingWindow()),
+		custcore.WithMaxAgeBuckets(config.AgeBucketsMax()),
+	)
+
+	if err := custtransport.SetDisplayNameTemplate(config.DisplayNameTemplate()); err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	queryPolicy, err := custin.ParseDuplicateQueryPolicy(config.DuplicateQueryPolicy())
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	slashPolicy, err := custin.ParseTrailingSlashPolicy(config.TrailingSlashPolicy())
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	emptyListStatus, err := custin.ParseEmptyListStatus(config.EmptyListStatus())
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	routeTimeouts, err := custin.ParseRouteTimeouts(config.RouteTimeouts())
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	maxBodySize, err := custin.ParseByteSize(config.MaxBodySize())
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	routeBodyLimits, err := custin.ParseRouteBodyLimits(config.RouteMaxBodySizes())
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	fieldEncryption, err := custin.ParseFieldEncryption(config.FieldEncryptionPrivateKey(), config.FieldEncryptionFields(), config.FieldEncryptionPublicKeys())
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
 
-	lambdaHandler, err := custin.NewLambdaHandler(customerUsecases)
+	lambdaHandler, err := custin.NewLambdaHandler(
+		customerUsecases,
+		custin.WithResponseHeaders(config.ResponseHeaders()),
+		custin.WithLambdaDuplicateQueryPolicy(queryPolicy),
+		custin.WithLambdaTrailingSlashPolicy(slashPolicy),
+		custin.WithLambdaStrictContentType(config.StrictContentType()),
+		custin.WithLambdaRequestIDHeader(config.RequestIDHeader()),
+		custin.WithLambdaTracing(config.TracingEnabled()),
+		custin.WithLambdaCORS(config.CORS()),
+		custin.WithLambdaEmptyListStatus(emptyListStatus),
+		custin.WithLambdaTimeouts(config.RequestTimeout(), routeTimeouts),
+		custin.WithLambdaBodyLimits(maxBodySize, routeBodyLimits),
+		custin.WithLambdaFieldEncryption(fieldEncryption),
+		custin.WithLambdaHealthChecks(config.HealthCheckTimeout(), map[string]custports.Pinger{
+			"repository": customerRepository,
+		}),
+	)
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+
+	config "github.com/devpablocristo/tech-house/projects/customers-manager/internal/config"
+
+	custout "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/outbound"
+	custcore "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core"
+)
+
+func init() {
+	if err := config.Load(); err != nil {
+		log.Fatalf("Error loading config: %s", err)
+	}
+}
+
+// Reconstruye las proyecciones de customers desde el log de eventos (secuencia 0).
+// El checkpoint de la proyección vive en memoria: si la reconstrucción falla, volver a correr
+// el comando la repite completa.
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	customerRepository, err := custout.NewRepository(
+		custout.WithQueryTimeout(config.QueryTimeout()),
+	)
+	if err != nil {
+		log.Fatalf("SQLite error: %v", err)
+	}
+	defer customerRepository.Close()
+
+	customerEvents, err := custout.NewEventStore(customerRepository)
+	if err != nil {
+		log.Fatalf("SQLite error: %v", err)
+	}
+
+	counter := custcore.NewCustomerCounter()
+
+	applied, err := custcore.Replay(ctx, customerEvents, counter)
+	if err != nil {
+		log.Fatalf("Rebuild of %s failed after %d events: %v", counter.Name(), applied, err)
+	}
+
+	checkpoint, _ := counter.Checkpoint(ctx)
+	log.Printf("Rebuilt %s: %d events applied, last seq %d, %d active customers",
+		counter.Name(), applied, checkpoint, counter.Count())
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	pkglogger "github.com/devpablocristo/tech-house/pkg/config/logger"
+
+	config "github.com/devpablocristo/tech-house/projects/customers-manager/internal/config"
+
+	custout "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/outbound"
+	custcore "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core"
+	custdomain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+func init() {
+	if err := config.Load(); err != nil {
+		log.Fatalf("Error loading config: %s", err)
+	}
+}
+
+// Aplica la política de retención a un lote de customers inactivos. Se ejecuta de forma programada
+// (cron o EventBridge); cada ejecución procesa como máximo RETENTION_BATCH_SIZE customers y volver a
+// correrla no repite acciones sobre los ya procesados.
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if config.RetentionPeriod() <= 0 {
+		log.Printf("Retention policy disabled: RETENTION_PERIOD is not set")
+		return
+	}
+
+	customerRepository, err := custout.NewRepository(
+		custout.WithQueryTimeout(config.QueryTimeout()),
+	)
+	if err != nil {
+		log.Fatalf("SQLite error: %v", err)
+	}
+	defer customerRepository.Close()
+
+	customerEvents, err := custout.NewEventStore(customerRepository)
+	if err != nil {
+		log.Fatalf("SQLite error: %v", err)
+	}
+
+	customerUsecases := custcore.NewUseCases(
+		customerRepository,
+		custcore.WithEventStore(customerEvents),
+	)
+
+	policy := custdomain.RetentionPolicy{
+		MaxInactivity: config.RetentionPeriod(),
+		Action:        config.RetentionAction(),
+		BatchSize:     config.RetentionBatchSize(),
+	}
+
+	// sin un logger en el contexto los fallos por customer que loguea ApplyRetention se descartan
+	ctx = pkglogger.WithFields(ctx, "job", "retention")
+
+	report, err := custcore.ApplyRetention(ctx, customerRepository, customerUsecases, policy, time.Now())
+	if err != nil {
+		log.Fatalf("Retention run failed: %v", err)
+	}
+
+	log.Printf("Retention %s: %d customers inactive since before %s processed, %d failed, more pending: %t",
+		report.Action, len(report.Processed), report.Cutoff.Format(time.RFC3339), len(report.Failed), report.More)
+	if len(report.Failed) > 0 {
+		os.Exit(1)
+	}
+}
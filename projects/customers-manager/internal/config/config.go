@@ -1,10 +1,14 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	initconf "github.com/devpablocristo/tech-house/pkg/config/init-config"
 	mwr "github.com/devpablocristo/tech-house/pkg/rest/middlewares/gin"
@@ -16,7 +20,42 @@ var (
 )
 
 type Config struct {
-	auth mwr.Config
+	auth            mwr.Config
+	apiKeys         mwr.APIKeyStore
+	apiKeyCount     int
+	fieldEncryption map[string]fieldEncryptionPartner
+	encryptionKey   string
+	responseHeaders map[string]string
+	cors            mwr.CORSConfig
+	duplicateQuery  string
+	trailingSlash   string
+	strictContent   bool
+	tracing         bool
+	slowQuery       time.Duration
+	stmtCache       bool
+	queryTimeout    time.Duration
+	displayName     string
+	requestID       string
+	skipEnrichers   []string
+	stage           string
+	selfTest        bool
+	retention       time.Duration
+	retentionAction string
+	retentionBatch  int
+	defaultSort     string
+	nameCollation   string
+	emptyList       string
+	expandMax       int
+	expandParallel  int
+	idempotencyTTL  time.Duration
+	kpiWindow       time.Duration
+	ageBucketsMax   int
+	flagsRefresh    time.Duration
+	requestTimeout  time.Duration
+	routeTimeouts   map[string]string
+	maxBodySize     string
+	routeBodySizes  map[string]string
+	healthTimeout   time.Duration
 }
 
 func Load() error {
@@ -30,11 +69,207 @@ func Load() error {
 			return
 		}
 
+		jwksCacheTTL, err := getDurationEnv("JWT_JWKS_CACHE_TTL")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		jwksRefreshInterval, err := getDurationEnv("JWT_JWKS_REFRESH_INTERVAL")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		jwksForcedRefreshCooldown, err := getDurationEnv("JWT_JWKS_FORCED_REFRESH_COOLDOWN")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		issuers, err := getIssuersEnv("JWT_TRUSTED_ISSUERS")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		apiKeys, err := getAPIKeysEnv("API_KEYS")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		fieldEncryption, err := getFieldEncryptionEnv("FIELD_ENCRYPTION_PARTNERS")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		responseHeaders, err := getResponseHeadersEnv("RESPONSE_HEADERS")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		cors, err := getCORSEnv()
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		strictContent, err := getBoolEnv("STRICT_CONTENT_TYPE")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		tracing, err := getBoolEnv("AWS_XRAY_ENABLED")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		slowQuery, err := getDurationEnv("SLOW_QUERY_THRESHOLD")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		stmtCache, err := getBoolEnv("SQL_STATEMENT_CACHE")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		queryTimeout, err := getDurationEnv("QUERY_TIMEOUT")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		selfTest, err := getBoolEnv("SELFTEST_ENABLED")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		retention, err := getDurationEnv("RETENTION_PERIOD")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		retentionBatch, err := getIntEnv("RETENTION_BATCH_SIZE")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		expandMax, err := getIntEnv("EXPAND_MAX_VALUES")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		expandParallel, err := getIntEnv("EXPAND_MAX_CONCURRENCY")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		idempotencyTTL, err := getDurationEnv("IDEMPOTENCY_TTL")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		kpiWindow, err := getDurationEnv("KPI_COALESCING_WINDOW")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		ageBucketsMax, err := getIntEnv("AGE_BUCKETS_MAX")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		flagsRefresh, err := getDurationEnv("FLAGS_REFRESH_INTERVAL")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		requestTimeout, err := getDurationEnv("REQUEST_TIMEOUT")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		routeTimeouts, err := getRouteMapEnv("ROUTE_TIMEOUTS")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		routeBodySizes, err := getRouteMapEnv("ROUTE_MAX_BODY_SIZES")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		healthTimeout, err := getDurationEnv("HEALTH_CHECK_TIMEOUT")
+		if err != nil {
+			loadErr = err
+			return
+		}
+
 		cfg = &Config{
+			apiKeys:         mwr.NewStaticAPIKeyStore(apiKeys),
+			apiKeyCount:     len(apiKeys),
+			fieldEncryption: fieldEncryption,
+			encryptionKey:   os.Getenv("FIELD_ENCRYPTION_PRIVATE_KEY"),
+			responseHeaders: responseHeaders,
+			cors:            cors,
+			duplicateQuery:  os.Getenv("QUERY_DUPLICATE_PARAMS"),
+			trailingSlash:   os.Getenv("TRAILING_SLASH_POLICY"),
+			strictContent:   strictContent,
+			tracing:         tracing,
+			slowQuery:       slowQuery,
+			stmtCache:       stmtCache,
+			queryTimeout:    queryTimeout,
+			displayName:     os.Getenv("DISPLAY_NAME_TEMPLATE"),
+			requestID:       os.Getenv("REQUEST_ID_HEADER"),
+			skipEnrichers:   getListEnv("ENRICHMENT_SKIP"),
+			stage:           os.Getenv("STAGE"),
+			selfTest:        selfTest,
+			retention:       retention,
+			retentionAction: os.Getenv("RETENTION_ACTION"),
+			retentionBatch:  retentionBatch,
+			defaultSort:     os.Getenv("LIST_DEFAULT_SORT"),
+			nameCollation:   os.Getenv("NAME_COLLATION"),
+			emptyList:       os.Getenv("EMPTY_LIST_STATUS"),
+			expandMax:       expandMax,
+			expandParallel:  expandParallel,
+			idempotencyTTL:  idempotencyTTL,
+			kpiWindow:       kpiWindow,
+			ageBucketsMax:   ageBucketsMax,
+			flagsRefresh:    flagsRefresh,
+			requestTimeout:  requestTimeout,
+			routeTimeouts:   routeTimeouts,
+			maxBodySize:     os.Getenv("MAX_BODY_SIZE"),
+			routeBodySizes:  routeBodySizes,
+			healthTimeout:   healthTimeout,
 			auth: mwr.Config{
-				SecretKey:   secretKey,
-				TokenLookup: "header:Authorization",
-				TokenPrefix: "Bearer ",
+				SecretKey:                 secretKey,
+				TokenLookup:               "header:Authorization",
+				TokenPrefix:               "Bearer ",
+				JWKSURL:                   os.Getenv("JWT_JWKS_URL"),
+				JWKSCacheTTL:              jwksCacheTTL,
+				JWKSRefreshInterval:       jwksRefreshInterval,
+				JWKSForcedRefreshCooldown: jwksForcedRefreshCooldown,
+				Issuers:                   issuers,
 			},
 		}
 	})
@@ -49,6 +284,208 @@ func getEnv(key string) (string, error) {
 	return value, nil
 }
 
+// getDurationEnv reads an optional duration (e.g. "10m"); empty returns zero so the default applies
+func getDurationEnv(key string) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("environment variable %s is not a valid duration: %w", key, err)
+	}
+	return d, nil
+}
+
+// getIssuersEnv reads the trusted issuers as a JSON array, e.g.
+// [{"issuer":"https://idp.example.com","jwks_url":"https://idp.example.com/jwks","audiences":["customers"]}]
+func getIssuersEnv(key string) ([]mwr.IssuerConfig, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil, nil
+	}
+	var issuers []mwr.IssuerConfig
+	if err := json.Unmarshal([]byte(value), &issuers); err != nil {
+		return nil, fmt.Errorf("environment variable %s is not a valid issuer list: %w", key, err)
+	}
+	for _, issuer := range issuers {
+		if issuer.Issuer == "" || issuer.JWKSURL == "" {
+			return nil, fmt.Errorf("environment variable %s: issuer and jwks_url are required", key)
+		}
+	}
+	return issuers, nil
+}
+
+// apiKeyEntry is a single API key as stored at rest: only the SHA-256 hash, never the key itself
+type apiKeyEntry struct {
+	Hash      string   `json:"hash"`
+	Principal string   `json:"principal"`
+	Scopes    []string `json:"scopes"`
+}
+
+// getAPIKeysEnv reads the API keys as a JSON array, e.g.
+// [{"hash":"<sha256 hex>","principal":"billing-service","scopes":["customers:read"]}]
+func getAPIKeysEnv(key string) (map[string]mwr.Principal, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil, nil
+	}
+	var entries []apiKeyEntry
+	if err := json.Unmarshal([]byte(value), &entries); err != nil {
+		return nil, fmt.Errorf("environment variable %s is not a valid api key list: %w", key, err)
+	}
+	keys := make(map[string]mwr.Principal, len(entries))
+	for _, entry := range entries {
+		if entry.Hash == "" || entry.Principal == "" {
+			return nil, fmt.Errorf("environment variable %s: hash and principal are required", key)
+		}
+		keys[entry.Hash] = mwr.Principal{ID: entry.Principal, Scopes: entry.Scopes}
+	}
+	return keys, nil
+}
+
+// fieldEncryptionPartner is the field-level encryption of a partner: the fields and its RSA public key (PEM)
+type fieldEncryptionPartner struct {
+	Fields    []string `json:"fields"`
+	PublicKey string   `json:"public_key"`
+}
+
+// getFieldEncryptionEnv reads the field-level encryption partners as a JSON object keyed by principal, e.g.
+// {"billing-service":{"fields":["email","phone"],"public_key":"-----BEGIN PUBLIC KEY-----\n..."}}
+func getFieldEncryptionEnv(key string) (map[string]fieldEncryptionPartner, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil, nil
+	}
+	var partners map[string]fieldEncryptionPartner
+	if err := json.Unmarshal([]byte(value), &partners); err != nil {
+		return nil, fmt.Errorf("environment variable %s is not a valid partner map: %w", key, err)
+	}
+	for partner, p := range partners {
+		if len(p.Fields) == 0 || p.PublicKey == "" {
+			return nil, fmt.Errorf("environment variable %s: fields and public_key are required for partner %q", key, partner)
+		}
+	}
+	if len(partners) > 0 && os.Getenv("FIELD_ENCRYPTION_PRIVATE_KEY") == "" {
+		return nil, fmt.Errorf("environment variable FIELD_ENCRYPTION_PRIVATE_KEY is required when %s is set", key)
+	}
+	return partners, nil
+}
+
+// getResponseHeadersEnv reads extra static response headers as a JSON object merged over
+// the security baseline, e.g. {"X-Frame-Options":"SAMEORIGIN"}; an empty value drops a default header
+func getResponseHeadersEnv(key string) (map[string]string, error) {
+	headers := mwr.DefaultSecurityHeaders()
+	value := os.Getenv(key)
+	if value == "" {
+		return headers, nil
+	}
+	var overrides map[string]string
+	if err := json.Unmarshal([]byte(value), &overrides); err != nil {
+		return nil, fmt.Errorf("environment variable %s is not a valid header map: %w", key, err)
+	}
+	for name, v := range overrides {
+		if v == "" {
+			delete(headers, name)
+			continue
+		}
+		headers[name] = v
+	}
+	return headers, nil
+}
+
+// getRouteMapEnv reads per-route overrides as a JSON object keyed by "METHOD /resource", e.g.
+// {"GET /customers/kpi":"30s"}; the keys and values are validated by the handler that applies them
+func getRouteMapEnv(key string) (map[string]string, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil, nil
+	}
+	var routes map[string]string
+	if err := json.Unmarshal([]byte(value), &routes); err != nil {
+		return nil, fmt.Errorf("environment variable %s is not a valid route map: %w", key, err)
+	}
+	return routes, nil
+}
+
+// getCORSEnv reads the CORS settings over the package defaults.
+// Credentials mode requires explicit origins, a wildcard is rejected.
+func getCORSEnv() (mwr.CORSConfig, error) {
+	cors := mwr.DefaultCORSConfig()
+
+	if origins := getListEnv("CORS_ALLOWED_ORIGINS"); origins != nil {
+		cors.AllowedOrigins = origins
+	}
+	if expose := getListEnv("CORS_EXPOSE_HEADERS"); expose != nil {
+		cors.ExposeHeaders = expose
+	}
+
+	credentials, err := getBoolEnv("CORS_ALLOW_CREDENTIALS")
+	if err != nil {
+		return mwr.CORSConfig{}, err
+	}
+	cors.AllowCredentials = credentials
+
+	maxAge, err := getDurationEnv("CORS_MAX_AGE")
+	if err != nil {
+		return mwr.CORSConfig{}, err
+	}
+	if maxAge > 0 {
+		cors.MaxAge = maxAge
+	}
+
+	if cors.AllowCredentials {
+		for _, origin := range cors.AllowedOrigins {
+			if origin == "*" {
+				return mwr.CORSConfig{}, fmt.Errorf("environment variable CORS_ALLOWED_ORIGINS cannot contain \"*\" when CORS_ALLOW_CREDENTIALS is enabled")
+			}
+		}
+	}
+
+	return cors, nil
+}
+
+// getBoolEnv reads an optional boolean; empty returns false
+func getBoolEnv(key string) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("environment variable %s is not a valid boolean: %w", key, err)
+	}
+	return b, nil
+}
+
+// getIntEnv reads an optional integer; empty returns zero so the default applies
+func getIntEnv(key string) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("environment variable %s is not a valid integer: %w", key, err)
+	}
+	return n, nil
+}
+
+// getListEnv reads an optional comma separated list; empty returns nil so the default applies
+func getListEnv(key string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 // Auth returns middleware auth configuration
 func Auth() mwr.Config {
 	if cfg == nil {
@@ -57,6 +494,313 @@ func Auth() mwr.Config {
 	return cfg.auth
 }
 
+// APIKeys returns the store used to resolve API keys
+func APIKeys() mwr.APIKeyStore {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.apiKeys
+}
+
+// FieldEncryptionFields returns, per partner principal, the customer fields encrypted in its payloads
+func FieldEncryptionFields() map[string][]string {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	fields := make(map[string][]string, len(cfg.fieldEncryption))
+	for partner, p := range cfg.fieldEncryption {
+		fields[partner] = p.Fields
+	}
+	return fields
+}
+
+// FieldEncryptionPublicKeys returns, per partner principal, the RSA public key (PEM) its payloads are encrypted with
+func FieldEncryptionPublicKeys() map[string]string {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	keys := make(map[string]string, len(cfg.fieldEncryption))
+	for partner, p := range cfg.fieldEncryption {
+		keys[partner] = p.PublicKey
+	}
+	return keys
+}
+
+// FieldEncryptionPrivateKey returns the RSA private key (PEM) that decrypts the fields partners send encrypted
+func FieldEncryptionPrivateKey() string {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.encryptionKey
+}
+
+// ResponseHeaders returns the static headers added to every response
+func ResponseHeaders() map[string]string {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.responseHeaders
+}
+
+// CORS returns the CORS configuration
+func CORS() mwr.CORSConfig {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.cors
+}
+
+// DuplicateQueryPolicy returns the policy for repeated query params ("reject" by default, or "last")
+func DuplicateQueryPolicy() string {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.duplicateQuery
+}
+
+// TrailingSlashPolicy returns how routes with a trailing slash are handled ("redirect" by default, "strip" or "strict")
+func TrailingSlashPolicy() string {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.trailingSlash
+}
+
+// StrictContentType reports whether writes without Content-Type: application/json are rejected with 415
+func StrictContentType() bool {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.strictContent
+}
+
+// TracingEnabled reports whether the Lambda handler and the AWS clients are instrumented with X-Ray
+func TracingEnabled() bool {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.tracing
+}
+
+// SlowQueryThreshold returns the duration above which repository queries are logged as slow (0 disables it)
+func SlowQueryThreshold() time.Duration {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.slowQuery
+}
+
+// StatementCache reports whether the repository reuses prepared statements
+func StatementCache() bool {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.stmtCache
+}
+
+// QueryTimeout returns the per-query timeout for the repository (0 uses the repository default)
+func QueryTimeout() time.Duration {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.queryTimeout
+}
+
+// DisplayNameTemplate returns the template for the computed display_name (empty uses the default)
+func DisplayNameTemplate() string {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.displayName
+}
+
+// RequestIDHeader returns the header carrying the correlation ID (empty uses X-Request-ID)
+func RequestIDHeader() string {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.requestID
+}
+
+// SkippedEnrichers returns the names of the create enrichment steps that are disabled
+func SkippedEnrichers() []string {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.skipEnrichers
+}
+
+// SelfTestAllowed reports whether POST /admin/selftest may run: when SELFTEST_ENABLED is set, or when
+// STAGE names a non-production stage. An unset STAGE counts as production.
+func SelfTestAllowed() bool {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.selfTestAllowed()
+}
+
+// RetentionPeriod returns how long a customer may stay inactive before the retention job acts on it (0 disables the job)
+func RetentionPeriod() time.Duration {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.retention
+}
+
+// RetentionAction returns what the retention job does with inactive customers ("anonymize" by default, or "delete")
+func RetentionAction() string {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	if cfg.retentionAction == "" {
+		return "anonymize"
+	}
+	return cfg.retentionAction
+}
+
+// RetentionBatchSize returns the maximum number of customers processed per retention run (0 uses the job default)
+func RetentionBatchSize() int {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.retentionBatch
+}
+
+// DefaultSort returns the order of GET /customers when the client does not ask for one, as "field [asc|desc]"
+// (empty uses "id asc", i.e. creation order)
+func DefaultSort() string {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.defaultSort
+}
+
+// NameCollation returns the BCP 47 locale used to sort by name or last_name (empty sorts by byte order)
+func NameCollation() string {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.nameCollation
+}
+
+// EmptyListStatus returns the status of list endpoints with no results ("200" by default, or "204")
+func EmptyListStatus() string {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.emptyList
+}
+
+// ExpandMaxValues returns how many ?expand= values a request may ask for (0 uses the use case default)
+func ExpandMaxValues() int {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.expandMax
+}
+
+// ExpandMaxConcurrency returns how many expansion resolvers may run at once per request (0 uses the use case default)
+func ExpandMaxConcurrency() int {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.expandParallel
+}
+
+// IdempotencyTTL returns how long an Idempotency-Key replays the original customer creation (0 uses the use case default)
+func IdempotencyTTL() time.Duration {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.idempotencyTTL
+}
+
+// KPICoalescingWindow returns how long a computed KPI is reused by later requests (0 only coalesces concurrent ones)
+func KPICoalescingWindow() time.Duration {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.kpiWindow
+}
+
+// AgeBucketsMax returns how many age buckets a client may define in /customers/aggregate (0 uses the use case default)
+func AgeBucketsMax() int {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.ageBucketsMax
+}
+
+// FlagsRefreshInterval returns how often the env file is re-read for flags that change without a restart (0 disables it)
+func FlagsRefreshInterval() time.Duration {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.flagsRefresh
+}
+
+// RequestTimeout returns the default time budget of a request, used by routes without an override (0 disables it)
+func RequestTimeout() time.Duration {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.requestTimeout
+}
+
+// RouteTimeouts returns the per-route timeout overrides keyed by "METHOD /resource", as raw duration strings
+func RouteTimeouts() map[string]string {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.routeTimeouts
+}
+
+// MaxBodySize returns the default request body limit as a raw size such as "1MB" (empty or 0 disables it)
+func MaxBodySize() string {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.maxBodySize
+}
+
+// RouteMaxBodySizes returns the per-route body limit overrides keyed by "METHOD /resource", as raw sizes
+func RouteMaxBodySizes() map[string]string {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.routeBodySizes
+}
+
+// HealthCheckTimeout returns how long the deep health check waits for each dependency (0 uses the handler default)
+func HealthCheckTimeout() time.Duration {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.healthTimeout
+}
+
+func (c *Config) selfTestAllowed() bool {
+	return c.selfTest || (c.stage != "" && !isProductionStage(c.stage))
+}
+
+// isProductionStage reports whether the STAGE value names a production deployment
+func isProductionStage(stage string) bool {
+	switch strings.ToLower(stage) {
+	case "prod", "production":
+		return true
+	default:
+		return false
+	}
+}
+
+// Effective returns the resolved configuration for diagnostics, with secrets redacted
+func Effective() map[string]any {
+	if cfg == nil {
+		log.Fatal("configuration not loaded")
+	}
+	return cfg.effective()
+}
+
 // MustLoad loads the configuration or panics
 func MustLoad() {
 	if err := Load(); err != nil {
@@ -0,0 +1,85 @@
+package config
+
+// redacted replaces secret values in the effective configuration
+const redacted = "[REDACTED]"
+
+// effective builds the diagnostic view of the configuration. Secrets (JWT signing key, public key
+// material, API key hashes, field encryption keys) are never included: only whether they are set, or how many there are.
+func (c *Config) effective() map[string]any {
+	issuers := make([]map[string]any, len(c.auth.Issuers))
+	for i, issuer := range c.auth.Issuers {
+		issuers[i] = map[string]any{
+			"issuer":    issuer.Issuer,
+			"jwks_url":  issuer.JWKSURL,
+			"audiences": issuer.Audiences,
+		}
+	}
+
+	return map[string]any{
+		"auth": map[string]any{
+			"secret_key":                   redactSecret(c.auth.SecretKey),
+			"public_key_pem":               redactSecret(c.auth.PublicKeyPEM),
+			"token_lookup":                 c.auth.TokenLookup,
+			"jwks_url":                     c.auth.JWKSURL,
+			"jwks_cache_ttl":               c.auth.JWKSCacheTTL.String(),
+			"jwks_refresh_interval":        c.auth.JWKSRefreshInterval.String(),
+			"jwks_forced_refresh_cooldown": c.auth.JWKSForcedRefreshCooldown.String(),
+			"trusted_issuers":              issuers,
+			"api_keys":                     c.apiKeyCount,
+		},
+		"cors": map[string]any{
+			"allowed_origins":   c.cors.AllowedOrigins,
+			"allowed_methods":   c.cors.AllowedMethods,
+			"allowed_headers":   c.cors.AllowedHeaders,
+			"expose_headers":    c.cors.ExposeHeaders,
+			"allow_credentials": c.cors.AllowCredentials,
+			"max_age":           c.cors.MaxAge.String(),
+		},
+		"field_encryption": map[string]any{
+			"partners":    len(c.fieldEncryption),
+			"private_key": redactSecret(c.encryptionKey),
+		},
+		"response_headers":       c.responseHeaders,
+		"duplicate_query_params": c.duplicateQuery,
+		"trailing_slash_policy":  c.trailingSlash,
+		"strict_content_type":    c.strictContent,
+		"tracing":                c.tracing,
+		"slow_query_threshold":   c.slowQuery.String(),
+		"statement_cache":        c.stmtCache,
+		"query_timeout":          c.queryTimeout.String(),
+		"display_name_template":  c.displayName,
+		"request_id_header":      c.requestID,
+		"skipped_enrichers":      c.skipEnrichers,
+		"stage":                  c.stage,
+		"selftest_enabled":       c.selfTest,
+		"list_default_sort":      c.defaultSort,
+		"name_collation":         c.nameCollation,
+		"empty_list_status":      c.emptyList,
+		"expand": map[string]any{
+			"max_values":      c.expandMax,
+			"max_concurrency": c.expandParallel,
+		},
+		"idempotency_ttl":        c.idempotencyTTL.String(),
+		"kpi_coalescing_window":  c.kpiWindow.String(),
+		"age_buckets_max":        c.ageBucketsMax,
+		"flags_refresh_interval": c.flagsRefresh.String(),
+		"request_timeout":        c.requestTimeout.String(),
+		"route_timeouts":         c.routeTimeouts,
+		"max_body_size":          c.maxBodySize,
+		"route_max_body_sizes":   c.routeBodySizes,
+		"health_check_timeout":   c.healthTimeout.String(),
+		"retention": map[string]any{
+			"period":     c.retention.String(),
+			"action":     c.retentionAction,
+			"batch_size": c.retentionBatch,
+		},
+	}
+}
+
+// redactSecret hides a secret value but keeps whether it is configured
+func redactSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return redacted
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mwr "github.com/devpablocristo/tech-house/pkg/rest/middlewares/gin"
+)
+
+func Test_Config_Effective(t *testing.T) {
+	apiKeys := map[string]mwr.Principal{
+		mwr.HashAPIKey("super-secret-api-key"): {ID: "billing-service", Scopes: []string{"admin"}},
+	}
+	c := &Config{
+		auth: mwr.Config{
+			SecretKey:    "super-secret-signing-key",
+			PublicKeyPEM: "-----BEGIN PUBLIC KEY-----\nMIIB\n-----END PUBLIC KEY-----",
+			JWKSURL:      "https://idp.example.com/jwks",
+			JWKSCacheTTL: 10 * time.Minute,
+		},
+		apiKeys:      mwr.NewStaticAPIKeyStore(apiKeys),
+		apiKeyCount:  len(apiKeys),
+		queryTimeout: 5 * time.Second,
+	}
+
+	body, err := json.Marshal(c.effective())
+	require.NoError(t, err)
+	dump := string(body)
+
+	t.Run("should redact secrets", func(t *testing.T) {
+		assert.NotContains(t, dump, "super-secret-signing-key")
+		assert.NotContains(t, dump, "BEGIN PUBLIC KEY")
+		assert.NotContains(t, dump, mwr.HashAPIKey("super-secret-api-key"))
+		assert.NotContains(t, dump, "billing-service")
+		assert.Contains(t, dump, `"secret_key":"[REDACTED]"`)
+	})
+
+	t.Run("should show non secret settings", func(t *testing.T) {
+		assert.Contains(t, dump, `"jwks_url":"https://idp.example.com/jwks"`)
+		assert.Contains(t, dump, `"query_timeout":"5s"`)
+		assert.Contains(t, dump, `"api_keys":1`)
+	})
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Config_SelfTestAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		stage    string
+		selfTest bool
+		want     bool
+	}{
+		{name: "should allow a non production stage", stage: "dev", want: true},
+		{name: "should deny production", stage: "prod", want: false},
+		{name: "should deny an unset stage", stage: "", want: false},
+		{name: "should allow production with the flag", stage: "production", selfTest: true, want: true},
+		{name: "should allow an unset stage with the flag", stage: "", selfTest: true, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{stage: tt.stage, selfTest: tt.selfTest}
+			assert.Equal(t, tt.want, c.selfTestAllowed())
+		})
+	}
+}
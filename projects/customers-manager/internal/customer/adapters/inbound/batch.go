@@ -0,0 +1,80 @@
+package inbound
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	transport "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound/transport"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+	ports "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/ports"
+)
+
+// maxCustomerBatch es la cantidad máxima de customers de POST /customers/batch
+const maxCustomerBatch = 500
+
+// createCustomerBatch atiende POST /customers/batch, compartido por Gin y Lambda. El lote es todo o nada:
+//   - si algún item no pasa la validación no se crea ninguno y se responde 400 con el error de cada item
+//     inválido y los válidos como aborted;
+//   - si falla el alta de un item se hace rollback del lote y se responde con el status de ese error,
+//     el item como failed y el resto como aborted;
+//   - si todos se crean se responde 201 con el ID de cada uno.
+//
+// El error retornado es el de un body que no es un array de customers; se responde en el formato de error
+// de cada handler. Los items de un partner con cifrado a nivel campo se descifran con encryption.
+func createCustomerBatch(ctx context.Context, ucs ports.UseCases, encryption *transport.FieldEncryption, body string) (int, *transport.BatchResponse, error) {
+	if strings.TrimSpace(body) == "" {
+		return 0, nil, decodeError(io.EOF)
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal([]byte(body), &items); err != nil {
+		return 0, nil, decodeError(err)
+	}
+	if len(items) == 0 || len(items) > maxCustomerBatch {
+		return 0, nil, types.NewErrorWithContext(
+			types.ErrValidation,
+			fmt.Sprintf("batch must have between 1 and %d customers", maxCustomerBatch),
+			nil,
+			map[string]any{"size": len(items)},
+		)
+	}
+
+	customers := make([]domain.Customer, len(items))
+	results := make([]transport.ItemResult, len(items))
+	invalid := false
+	for i, item := range items {
+		var req transport.CustomerJson
+		if err := customerBodyError(decodeLambdaBody(string(item), &req), &req, encryption); err != nil {
+			results[i] = transport.NewItemFailure(i, err)
+			invalid = true
+			continue
+		}
+		customers[i] = *transport.CustomerJsonToDomain(&req)
+		results[i] = transport.NewItemAborted(i)
+	}
+	if invalid {
+		return http.StatusBadRequest, transport.NewBatchResponse(results), nil
+	}
+
+	created, err := ucs.CreateCustomers(ctx, customers)
+	if err != nil {
+		var itemErr *domain.BatchItemError
+		if !errors.As(err, &itemErr) {
+			return 0, nil, err
+		}
+		results[itemErr.Index] = transport.NewItemFailure(itemErr.Index, itemErr.Err)
+		_, status := types.NewAPIError(itemErr.Err)
+		return status, transport.NewBatchResponse(results), nil
+	}
+
+	for i, customer := range created {
+		results[i] = transport.NewItemSuccess(i, customer.ID)
+	}
+	return http.StatusCreated, transport.NewBatchResponse(results), nil
+}
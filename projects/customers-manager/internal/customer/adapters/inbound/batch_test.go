@@ -0,0 +1,130 @@
+package inbound
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	transport "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound/transport"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+func Test_LambdaHandler_CreateCustomers(t *testing.T) {
+	birthDate := time.Now().AddDate(-39, 0, 0).Format(time.RFC3339)
+	customerBody := func(email string, age any) string {
+		return fmt.Sprintf(`{"name":"Homero","last_name":"Simpson","email":%q,"phone":"1234567890","age":%v,"birth_date":%q}`,
+			email, age, birthDate)
+	}
+	batchBody := func(items ...string) string {
+		return "[" + strings.Join(items, ",") + "]"
+	}
+
+	tests := []struct {
+		name          string
+		ucs           *lambdaUcsStub
+		body          string
+		wantStatus    int
+		wantStatuses  []string
+		wantIDs       map[int]int64
+		wantErrors    map[int]types.APIErrorType
+		wantUseCase   bool
+		wantAPIError  types.APIErrorType
+		wantSucceeded int
+	}{
+		{
+			name:          "should create every customer",
+			ucs:           &lambdaUcsStub{},
+			body:          batchBody(customerBody("a@example.com", 39), customerBody("b@example.com", 39)),
+			wantStatus:    http.StatusCreated,
+			wantStatuses:  []string{transport.ItemStatusSucceeded, transport.ItemStatusSucceeded},
+			wantIDs:       map[int]int64{0: 100, 1: 101},
+			wantUseCase:   true,
+			wantSucceeded: 2,
+		},
+		{
+			name:         "should create none when an item is invalid",
+			ucs:          &lambdaUcsStub{},
+			body:         batchBody(customerBody("a@example.com", 39), customerBody("not-an-email", 39), customerBody("c@example.com", `"x"`)),
+			wantStatus:   http.StatusBadRequest,
+			wantStatuses: []string{transport.ItemStatusAborted, transport.ItemStatusFailed, transport.ItemStatusFailed},
+			wantErrors:   map[int]types.APIErrorType{1: types.APIErrValidation, 2: types.APIErrValidation},
+		},
+		{
+			name: "should report the item that made the batch roll back",
+			ucs: &lambdaUcsStub{err: &domain.BatchItemError{
+				Index: 1,
+				Err:   types.NewError(types.ErrConflict, "email already exists", nil),
+			}},
+			body:         batchBody(customerBody("a@example.com", 39), customerBody("a@example.com", 39)),
+			wantStatus:   http.StatusConflict,
+			wantStatuses: []string{transport.ItemStatusAborted, transport.ItemStatusFailed},
+			wantErrors:   map[int]types.APIErrorType{1: types.APIErrConflict},
+			wantUseCase:  true,
+		},
+		{
+			name:         "should reject a body that is not an array",
+			ucs:          &lambdaUcsStub{},
+			body:         customerBody("a@example.com", 39),
+			wantStatus:   http.StatusBadRequest,
+			wantAPIError: types.APIErrValidation,
+		},
+		{
+			name:         "should reject an empty batch",
+			ucs:          &lambdaUcsStub{},
+			body:         "[]",
+			wantStatus:   http.StatusBadRequest,
+			wantAPIError: types.APIErrValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestLambdaHandler(tt.ucs)
+
+			response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodPost,
+				Resource:   "/customers/batch",
+				Body:       tt.body,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, response.StatusCode)
+			assert.Equal(t, tt.wantUseCase, tt.ucs.batch != nil, "the use case must only be called with a valid batch")
+
+			if tt.wantAPIError != "" {
+				var envelope types.APIErrorEnvelope
+				require.NoError(t, json.Unmarshal([]byte(response.Body), &envelope))
+				assert.Equal(t, tt.wantAPIError, envelope.Error.Code)
+				return
+			}
+
+			var batch transport.BatchResponse
+			require.NoError(t, json.Unmarshal([]byte(response.Body), &batch))
+			require.Len(t, batch.Results, len(tt.wantStatuses))
+			assert.Equal(t, tt.wantSucceeded, batch.Succeeded)
+			assert.Equal(t, len(tt.wantStatuses)-tt.wantSucceeded, batch.Failed)
+			for i, result := range batch.Results {
+				assert.Equal(t, i, result.Index)
+				assert.Equal(t, tt.wantStatuses[i], result.Status, "item %d", i)
+				if id, ok := tt.wantIDs[i]; ok {
+					require.NotNil(t, result.ID)
+					assert.Equal(t, id, *result.ID)
+				}
+				if errType, ok := tt.wantErrors[i]; ok {
+					require.NotNil(t, result.Error)
+					assert.Equal(t, errType, result.Error.Type)
+				} else {
+					assert.Nil(t, result.Error)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package inbound
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+)
+
+// WithLambdaBodyLimits acota el tamaño del body de cada request: el límite de su ruta en routes (ver
+// ParseRouteBodyLimits) o, si la ruta no tiene override, defaultLimit. Un límite en 0 no acota la ruta.
+func WithLambdaBodyLimits(defaultLimit int64, routes map[string]int64) LambdaOption {
+	return func(h *LambdaHandler) {
+		h.defaultBodyLimit = defaultLimit
+		h.routeBodyLimits = routes
+	}
+}
+
+// ParseRouteBodyLimits convierte los overrides de configuración, con claves "MÉTODO /resource" (por ejemplo
+// "POST /customers/batch") y tamaños como "5MB" (ver ParseByteSize), en el mapa de WithLambdaBodyLimits. Una
+// clave que no es una ruta del handler o un tamaño que no es positivo es un error.
+func ParseRouteBodyLimits(values map[string]string) (map[string]int64, error) {
+	routes := make(map[string]int64, len(values))
+	for key, value := range values {
+		route, err := parseRouteKey(key)
+		if err != nil {
+			return nil, err
+		}
+		limit, err := ParseByteSize(value)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid body limit %q for route %q, expected a positive size", value, key)
+		}
+		routes[route] = limit
+	}
+	return routes, nil
+}
+
+// byteUnits son los sufijos de ParseByteSize, en múltiplos de 1024, del mayor al menor
+var byteUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{suffix: "GB", size: 1 << 30},
+	{suffix: "MB", size: 1 << 20},
+	{suffix: "KB", size: 1 << 10},
+	{suffix: "B", size: 1},
+}
+
+// ParseByteSize convierte un tamaño de configuración ("512KB", "5MB", "1048576") en bytes; los sufijos no
+// distinguen mayúsculas y sin sufijo el valor está en bytes. Vacío es 0, es decir, sin límite.
+func ParseByteSize(value string) (int64, error) {
+	number := strings.ToUpper(strings.TrimSpace(value))
+	if number == "" {
+		return 0, nil
+	}
+
+	unit := int64(1)
+	for _, u := range byteUnits {
+		if trimmed, ok := strings.CutSuffix(number, u.suffix); ok {
+			number, unit = strings.TrimSpace(trimmed), u.size
+			break
+		}
+	}
+
+	size, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size %q, expected a number of bytes with an optional KB, MB or GB suffix", value)
+	}
+	return size * unit, nil
+}
+
+// formatByteSize escribe el tamaño con la mayor unidad de byteUnits que lo divide, como en la configuración
+func formatByteSize(size int64) string {
+	for _, u := range byteUnits {
+		if size >= u.size && size%u.size == 0 {
+			return strconv.FormatInt(size/u.size, 10) + u.suffix
+		}
+	}
+	return strconv.FormatInt(size, 10) + "B"
+}
+
+// bodyLimitFor retorna el límite de body de la ruta: su override o el límite por defecto
+func (h *LambdaHandler) bodyLimitFor(method, resource string) int64 {
+	if limit, ok := h.routeBodyLimits[routeKey(method, resource)]; ok {
+		return limit
+	}
+	return h.defaultBodyLimit
+}
+
+// bodyLimitMiddleware rechaza con ErrPayloadTooLarge (413) el request cuyo body supera el límite de su ruta,
+// antes de que el handler lo decodifique
+func (h *LambdaHandler) bodyLimitMiddleware(next LambdaHandlerFunc) LambdaHandlerFunc {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		limit := h.bodyLimitFor(request.HTTPMethod, request.Resource)
+		size := int64(len(request.Body))
+		if limit <= 0 || size <= limit {
+			return next(ctx, request)
+		}
+
+		route := routeKey(request.HTTPMethod, request.Resource)
+		apiErr, status := types.NewAPIError(types.NewErrorWithContext(
+			types.ErrPayloadTooLarge,
+			fmt.Sprintf("request body exceeds the %s limit of %s", formatByteSize(limit), route),
+			nil,
+			map[string]any{"route": route, "limit_bytes": limit, "size_bytes": size},
+		))
+		return writeError(status, apiErr), nil
+	}
+}
@@ -0,0 +1,134 @@
+package inbound
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+)
+
+func Test_ParseByteSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "", want: 0},
+		{value: "1048576", want: 1 << 20},
+		{value: "512B", want: 512},
+		{value: "512kb", want: 512 << 10},
+		{value: "5MB", want: 5 << 20},
+		{value: " 1 GB ", want: 1 << 30},
+		{value: "five", wantErr: true},
+		{value: "-1MB", wantErr: true},
+		{value: "1TB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseByteSize(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_ParseRouteBodyLimits(t *testing.T) {
+	t.Run("should normalize the keys and parse the sizes", func(t *testing.T) {
+		routes, err := ParseRouteBodyLimits(map[string]string{"post /customers/batch": "5MB"})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]int64{"POST /customers/batch": 5 << 20}, routes)
+	})
+
+	tests := []struct {
+		name   string
+		values map[string]string
+	}{
+		{name: "should reject an unknown route", values: map[string]string{"POST /orders": "5MB"}},
+		{name: "should reject an invalid size", values: map[string]string{"POST /customers/batch": "big"}},
+		{name: "should reject a non positive size", values: map[string]string{"POST /customers/batch": "0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRouteBodyLimits(tt.values)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_LambdaHandler_BodyLimits(t *testing.T) {
+	const defaultLimit = 1 << 10
+	routes := map[string]int64{"POST /customers/batch": 4 << 10}
+
+	tests := []struct {
+		name      string
+		resource  string
+		size      int
+		wantLimit string
+	}{
+		{name: "should reject a single create over the default limit", resource: "/customers", size: defaultLimit + 1, wantLimit: "1KB"},
+		{name: "should accept a batch over the default limit", resource: "/customers/batch", size: 2 << 10},
+		{name: "should reject a batch over its route limit", resource: "/customers/batch", size: 4<<10 + 1, wantLimit: "4KB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decoded bool
+			observe := func(next LambdaHandlerFunc) LambdaHandlerFunc {
+				return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+					decoded = true
+					return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+				}
+			}
+			h := newTestLambdaHandler(&lambdaUcsStub{}, WithLambdaBodyLimits(defaultLimit, routes), WithLambdaMiddlewares(observe))
+
+			response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodPost,
+				Resource:   tt.resource,
+				Body:       strings.Repeat("a", tt.size),
+			})
+			require.NoError(t, err)
+
+			if tt.wantLimit == "" {
+				assert.Equal(t, http.StatusOK, response.StatusCode)
+				assert.True(t, decoded)
+				return
+			}
+
+			assert.False(t, decoded)
+			assert.Equal(t, http.StatusRequestEntityTooLarge, response.StatusCode)
+			var envelope types.APIErrorEnvelope
+			require.NoError(t, json.Unmarshal([]byte(response.Body), &envelope))
+			assert.Equal(t, types.APIErrPayloadTooLarge, envelope.Error.Code)
+			assert.Contains(t, envelope.Error.Message, tt.wantLimit)
+			assert.Contains(t, envelope.Error.Message, "POST "+tt.resource)
+			assert.NotEmpty(t, response.Headers[h.requestIDHeader])
+		})
+	}
+
+	t.Run("should not bound the body without a limit", func(t *testing.T) {
+		h := newTestLambdaHandler(&lambdaUcsStub{})
+
+		response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod: http.MethodPost,
+			Resource:   "/customers/batch",
+			Body:       strings.Repeat("a", 8<<10),
+		})
+		require.NoError(t, err)
+
+		assert.NotEqual(t, http.StatusRequestEntityTooLarge, response.StatusCode)
+	})
+}
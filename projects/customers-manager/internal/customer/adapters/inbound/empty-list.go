@@ -0,0 +1,28 @@
+package inbound
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// ParseEmptyListStatus convierte el valor de configuración en el status de las listas vacías:
+// 200 (por defecto) responde {"customers":[],"total":0} y 204 responde sin body
+func ParseEmptyListStatus(value string) (int, error) {
+	if value == "" {
+		return http.StatusOK, nil
+	}
+	status, err := strconv.Atoi(value)
+	if err != nil || (status != http.StatusOK && status != http.StatusNoContent) {
+		return 0, fmt.Errorf("invalid empty list status %q, expected %d or %d", value, http.StatusOK, http.StatusNoContent)
+	}
+	return status, nil
+}
+
+// listStatus retorna el status de una respuesta de lista: 204 solo si la lista está vacía y así se configuró
+func listStatus(emptyStatus, count int) int {
+	if count == 0 && emptyStatus == http.StatusNoContent {
+		return http.StatusNoContent
+	}
+	return http.StatusOK
+}
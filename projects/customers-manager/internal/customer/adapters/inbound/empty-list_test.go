@@ -0,0 +1,99 @@
+package inbound
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+func Test_ParseEmptyListStatus(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{value: "", want: http.StatusOK},
+		{value: "200", want: http.StatusOK},
+		{value: "204", want: http.StatusNoContent},
+		{value: "404", wantErr: true},
+		{value: "no-content", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			status, err := ParseEmptyListStatus(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, status)
+		})
+	}
+}
+
+func Test_LambdaHandler_EmptyList(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []LambdaOption
+		customers  []domain.Customer
+		resource   string
+		query      map[string]string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "should answer 200 with an empty array by default",
+			resource:   "/customers",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"customers":[],"total":0}`,
+		},
+		{
+			name:       "should answer 204 without body when configured",
+			opts:       []LambdaOption{WithLambdaEmptyListStatus(http.StatusNoContent)},
+			resource:   "/customers",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "should apply the setting to the top customers list",
+			opts:       []LambdaOption{WithLambdaEmptyListStatus(http.StatusNoContent)},
+			resource:   "/customers/kpi/top",
+			query:      map[string]string{"by": "age", "n": "5"},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "should answer 200 for a non empty list when 204 is configured",
+			opts:       []LambdaOption{WithLambdaEmptyListStatus(http.StatusNoContent)},
+			customers:  []domain.Customer{{ID: 1, Name: "Ana"}},
+			resource:   "/customers",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestLambdaHandler(&lambdaUcsStub{customers: tt.customers}, tt.opts...)
+
+			response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod:            http.MethodGet,
+				Resource:              tt.resource,
+				QueryStringParameters: tt.query,
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantStatus, response.StatusCode)
+			switch {
+			case tt.wantStatus == http.StatusNoContent:
+				assert.Empty(t, response.Body)
+			case tt.wantBody != "":
+				assert.JSONEq(t, tt.wantBody, response.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package inbound
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	etagHeader         = "ETag"
+	ifNoneMatchHeader  = "If-None-Match"
+	cacheControlHeader = "Cache-Control"
+)
+
+// revalidateCacheControl reemplaza en las respuestas con ETag el "no-store" de los headers de seguridad:
+// con no-store el cliente no guarda la respuesta y nunca manda If-None-Match. no-cache obliga a revalidar
+// en cada uso y private evita que la guarden caches compartidos.
+const revalidateCacheControl = "private, no-cache"
+
+// contentETag es el ETag fuerte del body serializado: el mismo contenido siempre da el mismo ETag, así
+// que las respuestas reutilizadas por el coalescing de KPIs conservan la versión que ya tiene el cliente
+func contentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified indica si el If-None-Match del request incluye etag, en cuyo caso se responde 304 sin body.
+// Acepta "*", listas separadas por coma y ETags débiles (W/"..."), que según RFC 9110 se comparan en forma débil.
+func notModified(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
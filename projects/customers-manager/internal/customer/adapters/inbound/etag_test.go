@@ -0,0 +1,110 @@
+package inbound
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_notModified(t *testing.T) {
+	etag := contentETag([]byte(`{"average_age":40}`))
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        bool
+	}{
+		{name: "should not match without header", ifNoneMatch: "", want: false},
+		{name: "should match the same etag", ifNoneMatch: etag, want: true},
+		{name: "should match a weak etag", ifNoneMatch: "W/" + etag, want: true},
+		{name: "should match inside a list", ifNoneMatch: `"other", ` + etag, want: true},
+		{name: "should match a wildcard", ifNoneMatch: "*", want: true},
+		{name: "should not match another etag", ifNoneMatch: `"other"`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, notModified(tt.ifNoneMatch, etag))
+		})
+	}
+
+	t.Run("should derive the etag from the content", func(t *testing.T) {
+		assert.Equal(t, etag, contentETag([]byte(`{"average_age":40}`)))
+		assert.NotEqual(t, etag, contentETag([]byte(`{"average_age":41}`)))
+	})
+}
+
+func Test_LambdaHandler_GetKPI_NotModified(t *testing.T) {
+	h := newTestLambdaHandler(&lambdaUcsStub{})
+	request := events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Resource: "/customers/kpi"}
+
+	first, err := h.HandleRequest(context.Background(), request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, first.StatusCode)
+	etag := first.Headers[etagHeader]
+	require.NotEmpty(t, etag)
+	assert.Equal(t, revalidateCacheControl, first.Headers[cacheControlHeader])
+
+	t.Run("should answer 304 without body when the KPI did not change", func(t *testing.T) {
+		request.Headers = map[string]string{"if-none-match": etag}
+
+		response, err := h.HandleRequest(context.Background(), request)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusNotModified, response.StatusCode)
+		assert.Equal(t, etag, response.Headers[etagHeader])
+		assert.Equal(t, revalidateCacheControl, response.Headers[cacheControlHeader])
+		assert.Empty(t, response.Body)
+	})
+
+	t.Run("should answer 200 when the KPI changed", func(t *testing.T) {
+		request.Headers = map[string]string{ifNoneMatchHeader: etag}
+		request.QueryStringParameters = map[string]string{"from": time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)}
+
+		response, err := h.HandleRequest(context.Background(), request)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.NotEqual(t, etag, response.Headers[etagHeader])
+		assert.NotEmpty(t, response.Body)
+	})
+}
+
+func Test_Handler_GetKPI_NotModified(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler, err := NewHandler(&lambdaUcsStub{})
+	require.NoError(t, err)
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/api/v1/customers/kpi", nil)
+		// Lo que escribe el middleware de headers de seguridad antes del handler
+		c.Header(cacheControlHeader, "no-store")
+		if ifNoneMatch != "" {
+			c.Request.Header.Set(ifNoneMatchHeader, ifNoneMatch)
+		}
+		handler.GetKPI(c)
+		c.Writer.WriteHeaderNow()
+		return w
+	}
+
+	first := get("")
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get(etagHeader)
+	require.NotEmpty(t, etag)
+	assert.Equal(t, revalidateCacheControl, first.Header().Get(cacheControlHeader))
+
+	second := get(etag)
+	assert.Equal(t, http.StatusNotModified, second.Code)
+	assert.Equal(t, etag, second.Header().Get(etagHeader))
+	assert.Empty(t, second.Body.String())
+}
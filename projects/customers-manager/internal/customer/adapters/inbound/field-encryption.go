@@ -0,0 +1,112 @@
+package inbound
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/gin-gonic/gin"
+
+	mwr "github.com/devpablocristo/tech-house/pkg/rest/middlewares/gin"
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	utils "github.com/devpablocristo/tech-house/pkg/utils"
+	transport "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound/transport"
+)
+
+// lambdaPrincipalKey es la clave del contexto del authorizer de API Gateway con la identidad del caller
+const lambdaPrincipalKey = "principalId"
+
+// WithFieldEncryption cifra a nivel campo los customers de las respuestas y descifra los de los requests
+// para los partners configurados (ver ParseFieldEncryption). El partner es el principal autenticado; los
+// demás callers reciben y envían los campos en claro.
+func WithFieldEncryption(partners map[string]*transport.FieldEncryption) HandlerOption {
+	return func(h *Handler) {
+		h.fieldEncryption = partners
+	}
+}
+
+// WithLambdaFieldEncryption es WithFieldEncryption para el LambdaHandler; el partner es el principalId del
+// authorizer de API Gateway
+func WithLambdaFieldEncryption(partners map[string]*transport.FieldEncryption) LambdaOption {
+	return func(h *LambdaHandler) {
+		h.fieldEncryption = partners
+	}
+}
+
+// ParseFieldEncryption arma el cifrado de cada partner a partir de la configuración: los campos a cifrar y
+// la clave pública (PEM) de cada partner, y nuestra clave privada (PEM), con la que los partners cifran los
+// requests. Sin partners retorna nil; un partner sin campos o sin clave, o una clave inválida, es un error.
+func ParseFieldEncryption(privateKeyPEM string, fields map[string][]string, publicKeys map[string]string) (map[string]*transport.FieldEncryption, error) {
+	if len(fields) == 0 && len(publicKeys) == 0 {
+		return nil, nil
+	}
+
+	privateKey, err := utils.ParseRSAPrivateKeyPEM([]byte(privateKeyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("invalid field encryption private key: %w", err)
+	}
+
+	for partner := range publicKeys {
+		if len(fields[partner]) == 0 {
+			return nil, fmt.Errorf("field encryption partner %q has a public key but no fields", partner)
+		}
+	}
+
+	partners := make(map[string]*transport.FieldEncryption, len(fields))
+	for partner, partnerFields := range fields {
+		publicKey, err := utils.ParseRSAPublicKeyPEM([]byte(publicKeys[partner]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key for field encryption partner %q: %w", partner, err)
+		}
+		encryption, err := transport.NewFieldEncryption(partnerFields, publicKey, privateKey)
+		if err != nil {
+			return nil, fmt.Errorf("field encryption partner %q: %w", partner, err)
+		}
+		partners[partner] = encryption
+	}
+	return partners, nil
+}
+
+// encryptionFor retorna el cifrado del principal autenticado; nil, que no cifra, si no es un partner
+func (h *Handler) encryptionFor(c *gin.Context) *transport.FieldEncryption {
+	principal, ok := mwr.GetPrincipal(c)
+	if !ok {
+		return nil
+	}
+	return h.fieldEncryption[principal.ID]
+}
+
+// encryptionFor retorna el cifrado del caller del request; nil, que no cifra, si no es un partner
+func (h *LambdaHandler) encryptionFor(request events.APIGatewayProxyRequest) *transport.FieldEncryption {
+	partner, _ := request.RequestContext.Authorizer[lambdaPrincipalKey].(string)
+	if partner == "" {
+		return nil
+	}
+	return h.fieldEncryption[partner]
+}
+
+// decryptJSONPatch descifra los valores de las operaciones de un JSON Patch sobre campos cifrados, así las
+// operaciones (incluidas las test) se aplican sobre los valores en claro
+func decryptJSONPatch(encryption *transport.FieldEncryption, ops []jsonPatchOperation) error {
+	for i, op := range ops {
+		field, err := jsonPatchField(op.Path)
+		if err != nil || op.Value == nil || !encryption.Encrypts(field) {
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(*op.Value, &value); err != nil {
+			continue
+		}
+		decrypted, err := encryption.DecryptField(field, value)
+		if err != nil {
+			return err
+		}
+		raw, err := json.Marshal(decrypted)
+		if err != nil {
+			return types.NewError(types.ErrInternal, "error decoding operation value", err)
+		}
+		message := json.RawMessage(raw)
+		ops[i].Value = &message
+	}
+	return nil
+}
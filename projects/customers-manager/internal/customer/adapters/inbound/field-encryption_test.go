@@ -0,0 +1,274 @@
+package inbound
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mwr "github.com/devpablocristo/tech-house/pkg/rest/middlewares/gin"
+	utils "github.com/devpablocristo/tech-house/pkg/utils"
+	transport "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound/transport"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+const encryptionPartner = "billing-service"
+
+// encryptionKeys son las claves del servicio y del partner, con sus PEM como llegan de la configuración
+type encryptionKeys struct {
+	service    *rsa.PrivateKey
+	partner    *rsa.PrivateKey
+	servicePEM string
+	partnerPEM string
+}
+
+func newEncryptionKeys(t *testing.T) encryptionKeys {
+	t.Helper()
+	service, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	partner, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	partnerPublic, err := x509.MarshalPKIXPublicKey(&partner.PublicKey)
+	require.NoError(t, err)
+	return encryptionKeys{
+		service:    service,
+		partner:    partner,
+		servicePEM: string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(service)})),
+		partnerPEM: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: partnerPublic})),
+	}
+}
+
+// partnerEncryption arma el cifrado del servicio para el partner a partir de la configuración
+func (k encryptionKeys) partnerEncryption(t *testing.T) map[string]*transport.FieldEncryption {
+	t.Helper()
+	partners, err := ParseFieldEncryption(k.servicePEM,
+		map[string][]string{encryptionPartner: {"email"}},
+		map[string]string{encryptionPartner: k.partnerPEM},
+	)
+	require.NoError(t, err)
+	return partners
+}
+
+// encryptForService cifra un valor como lo hace el partner, con la clave pública del servicio
+func (k encryptionKeys) encryptForService(t *testing.T, value string) string {
+	t.Helper()
+	encrypted, err := utils.EncryptForRecipient(&k.service.PublicKey, value)
+	require.NoError(t, err)
+	return "enc:" + encrypted
+}
+
+// decryptAsPartner descifra un valor de la respuesta como lo hace el partner
+func (k encryptionKeys) decryptAsPartner(t *testing.T, value string) string {
+	t.Helper()
+	require.True(t, strings.HasPrefix(value, "enc:"), "expected an encrypted value, got %q", value)
+	decrypted, err := utils.DecryptWithPrivateKey(k.partner, strings.TrimPrefix(value, "enc:"))
+	require.NoError(t, err)
+	return decrypted
+}
+
+func Test_ParseFieldEncryption(t *testing.T) {
+	keys := newEncryptionKeys(t)
+
+	t.Run("should not encrypt without partners", func(t *testing.T) {
+		partners, err := ParseFieldEncryption("", nil, nil)
+		require.NoError(t, err)
+		assert.Nil(t, partners)
+	})
+
+	t.Run("should build the encryption of each partner", func(t *testing.T) {
+		partners := keys.partnerEncryption(t)
+		require.Contains(t, partners, encryptionPartner)
+		assert.True(t, partners[encryptionPartner].Encrypts("email"))
+		assert.False(t, partners[encryptionPartner].Encrypts("name"))
+	})
+
+	tests := []struct {
+		name       string
+		privateKey string
+		fields     map[string][]string
+		publicKeys map[string]string
+	}{
+		{
+			name:       "should reject a missing private key",
+			fields:     map[string][]string{encryptionPartner: {"email"}},
+			publicKeys: map[string]string{encryptionPartner: keys.partnerPEM},
+		},
+		{
+			name:       "should reject a partner without public key",
+			privateKey: keys.servicePEM,
+			fields:     map[string][]string{encryptionPartner: {"email"}},
+		},
+		{
+			name:       "should reject a partner without fields",
+			privateKey: keys.servicePEM,
+			publicKeys: map[string]string{encryptionPartner: keys.partnerPEM},
+		},
+		{
+			name:       "should reject a field that cannot be encrypted",
+			privateKey: keys.servicePEM,
+			fields:     map[string][]string{encryptionPartner: {"age"}},
+			publicKeys: map[string]string{encryptionPartner: keys.partnerPEM},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFieldEncryption(tt.privateKey, tt.fields, tt.publicKeys)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_LambdaHandler_FieldEncryption(t *testing.T) {
+	keys := newEncryptionKeys(t)
+	customer := newPatchableCustomer()
+	partnerContext := events.APIGatewayProxyRequestContext{Authorizer: map[string]any{"principalId": encryptionPartner}}
+
+	t.Run("should encrypt the configured fields for the partner", func(t *testing.T) {
+		h := newTestLambdaHandler(&lambdaUcsStub{customer: customer}, WithLambdaFieldEncryption(keys.partnerEncryption(t)))
+
+		response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod:     http.MethodGet,
+			Resource:       "/customers/{id}",
+			PathParameters: map[string]string{"id": "1"},
+			RequestContext: partnerContext,
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, response.StatusCode, response.Body)
+
+		var body transport.GetCustomerResponse
+		require.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+		assert.Equal(t, customer.Email, keys.decryptAsPartner(t, body.Customers.Email))
+		assert.Equal(t, customer.Name, body.Customers.Name)
+	})
+
+	t.Run("should answer other callers in plain text", func(t *testing.T) {
+		h := newTestLambdaHandler(&lambdaUcsStub{customer: customer}, WithLambdaFieldEncryption(keys.partnerEncryption(t)))
+
+		response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod:     http.MethodGet,
+			Resource:       "/customers/{id}",
+			PathParameters: map[string]string{"id": "1"},
+			RequestContext: events.APIGatewayProxyRequestContext{Authorizer: map[string]any{"principalId": "web-app"}},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, response.Body, `"email":"homero@springfield.com"`)
+	})
+
+	t.Run("should decrypt the partner request before validating it", func(t *testing.T) {
+		ucs := &lambdaUcsStub{customer: customer}
+		h := newTestLambdaHandler(ucs, WithLambdaFieldEncryption(keys.partnerEncryption(t)))
+		body := `{"name":"Homero","last_name":"Simpson","email":"` + keys.encryptForService(t, "homero@springfield.com") +
+			`","phone":"1234567890","age":39,"birth_date":"` + time.Now().AddDate(-39, 0, 0).Format(time.RFC3339) + `"}`
+
+		response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod:     http.MethodPut,
+			Resource:       "/customers/{id}",
+			PathParameters: map[string]string{"id": "1"},
+			Body:           body,
+			RequestContext: partnerContext,
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, response.StatusCode, response.Body)
+		require.NotNil(t, ucs.updated)
+		assert.Equal(t, "homero@springfield.com", ucs.updated.Email)
+	})
+
+	t.Run("should reject a configured field sent in plain text", func(t *testing.T) {
+		ucs := &lambdaUcsStub{customer: customer}
+		h := newTestLambdaHandler(ucs, WithLambdaFieldEncryption(keys.partnerEncryption(t)))
+
+		response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod:     http.MethodPatch,
+			Resource:       "/customers/{id}",
+			PathParameters: map[string]string{"id": "1"},
+			Headers:        map[string]string{"content-type": "application/merge-patch+json"},
+			Body:           `{"email":"marge@springfield.com"}`,
+			RequestContext: partnerContext,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+		assert.Contains(t, response.Body, "field email must be encrypted")
+		assert.Nil(t, ucs.updated)
+	})
+
+	t.Run("should decrypt the values of a partner JSON Patch", func(t *testing.T) {
+		ucs := &lambdaUcsStub{customer: customer}
+		h := newTestLambdaHandler(ucs, WithLambdaFieldEncryption(keys.partnerEncryption(t)))
+		body := `[{"op":"test","path":"/email","value":"` + keys.encryptForService(t, customer.Email) + `"},` +
+			`{"op":"replace","path":"/email","value":"` + keys.encryptForService(t, "marge@springfield.com") + `"}]`
+
+		response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod:     http.MethodPatch,
+			Resource:       "/customers/{id}",
+			PathParameters: map[string]string{"id": "1"},
+			Headers:        map[string]string{"content-type": "application/json-patch+json"},
+			Body:           body,
+			RequestContext: partnerContext,
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, response.StatusCode, response.Body)
+		require.NotNil(t, ucs.updated)
+		assert.Equal(t, "marge@springfield.com", ucs.updated.Email)
+	})
+
+	t.Run("should take the partner from the HTTP API authorizer", func(t *testing.T) {
+		h := newTestLambdaHandler(&lambdaUcsStub{customers: []domain.Customer{*customer}}, WithLambdaFieldEncryption(keys.partnerEncryption(t)))
+		request := events.APIGatewayV2HTTPRequest{RouteKey: "GET /customers", RawPath: "/customers"}
+		request.RequestContext.HTTP.Method = http.MethodGet
+		request.RequestContext.Authorizer = &events.APIGatewayV2HTTPRequestContextAuthorizerDescription{
+			JWT: &events.APIGatewayV2HTTPRequestContextAuthorizerJWTDescription{Claims: map[string]string{"sub": encryptionPartner}},
+		}
+
+		response, err := h.HandleRequestV2(context.Background(), request)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, response.StatusCode, response.Body)
+
+		var body transport.GetCustomersResponse
+		require.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+		require.Len(t, body.Customers, 1)
+		assert.Equal(t, customer.Email, keys.decryptAsPartner(t, body.Customers[0].Email))
+	})
+}
+
+func Test_Handler_FieldEncryption(t *testing.T) {
+	keys := newEncryptionKeys(t)
+	customer := newPatchableCustomer()
+
+	h, err := NewHandler(&lambdaUcsStub{customer: customer}, WithFieldEncryption(keys.partnerEncryption(t)))
+	require.NoError(t, err)
+
+	for _, principal := range []string{encryptionPartner, "web-app"} {
+		t.Run("should answer "+principal, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/customers/1", nil)
+			c.Params = []gin.Param{{Key: "id", Value: "1"}}
+			c.Set(mwr.PrincipalContextKey, &mwr.Principal{ID: principal})
+
+			h.GetCustomer(c)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var body transport.GetCustomerResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			if principal == encryptionPartner {
+				assert.Equal(t, customer.Email, keys.decryptAsPartner(t, body.Customers.Email))
+				return
+			}
+			assert.Equal(t, customer.Email, body.Customers.Email)
+		})
+	}
+}
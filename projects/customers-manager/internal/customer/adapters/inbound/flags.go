@@ -0,0 +1,22 @@
+package inbound
+
+import (
+	"strconv"
+	"sync/atomic"
+
+	pkgflags "github.com/devpablocristo/tech-house/pkg/flags"
+)
+
+// FlagStrictContentType activa en caliente el rechazo con 415 de las escrituras sin Content-Type: application/json
+const FlagStrictContentType = "STRICT_CONTENT_TYPE"
+
+// watchBoolFlag carga en target el valor actual del flag y lo actualiza con cada cambio, así el próximo
+// request ya usa el valor nuevo. Un valor que no es booleano, o quitar el flag, conserva el anterior.
+func watchBoolFlag(store pkgflags.Store, name string, target *atomic.Bool) {
+	target.Store(store.Bool(name, target.Load()))
+	store.Subscribe(name, func(_, value string) {
+		if b, err := strconv.ParseBool(value); err == nil {
+			target.Store(b)
+		}
+	})
+}
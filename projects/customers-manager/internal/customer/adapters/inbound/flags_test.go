@@ -0,0 +1,44 @@
+package inbound
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgflags "github.com/devpablocristo/tech-house/pkg/flags"
+)
+
+func Test_LambdaHandler_Flags(t *testing.T) {
+	body := `{"name":"Homero","last_name":"Simpson","email":"homero@springfield.com","phone":"1234567890","age":39,"birth_date":"` +
+		time.Now().AddDate(-39, 0, 0).Format(time.RFC3339) + `"}`
+	create := events.APIGatewayProxyRequest{
+		HTTPMethod: http.MethodPost,
+		Resource:   "/customers",
+		Headers:    map[string]string{"Content-Type": "text/plain"},
+		Body:       body,
+	}
+
+	store := pkgflags.NewMemoryStore(map[string]string{FlagStrictContentType: "false"})
+	h := newTestLambdaHandler(&lambdaUcsStub{}, WithLambdaStrictContentType(true), WithLambdaFlags(store))
+
+	response, err := h.HandleRequest(context.Background(), create)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, response.StatusCode, "the flag overrides the configured value")
+
+	store.Set(FlagStrictContentType, "true")
+
+	response, err = h.HandleRequest(context.Background(), create)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, response.StatusCode, "the next request uses the new value")
+
+	store.Set(FlagStrictContentType, "not-a-bool")
+
+	response, err = h.HandleRequest(context.Background(), create)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, response.StatusCode, "an invalid value keeps the previous one")
+}
@@ -7,13 +7,18 @@ package inbound
 
 import (
 	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
-	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	pkgflags "github.com/devpablocristo/tech-house/pkg/flags"
 	mwr "github.com/devpablocristo/tech-house/pkg/rest/middlewares/gin"
 	ginserver "github.com/devpablocristo/tech-house/pkg/rest/servers/gin"
 	gindefs "github.com/devpablocristo/tech-house/pkg/rest/servers/gin/defs"
@@ -25,6 +30,7 @@ import (
 
 	config "github.com/devpablocristo/tech-house/projects/customers-manager/internal/config"
 	transport "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound/transport"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
 	ports "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/ports"
 )
 
@@ -32,9 +38,56 @@ type Handler struct {
 	Ucs ports.UseCases
 	Svr gindefs.Server
 	Swg swagdefs.Service
+
+	queryPolicy       DuplicateQueryPolicy
+	strictContentType atomic.Bool
+	trailingSlash     TrailingSlashPolicy
+	emptyListStatus   int
+	fieldEncryption   map[string]*transport.FieldEncryption
+	// shutdown se cierra al apagar el servidor y detiene el refresco de los JWKS de la autenticación
+	shutdown <-chan struct{}
+}
+
+// HandlerOption configura opciones del Handler
+type HandlerOption func(*Handler)
+
+// WithDuplicateQueryPolicy define cómo se tratan los query params repetidos
+func WithDuplicateQueryPolicy(policy DuplicateQueryPolicy) HandlerOption {
+	return func(h *Handler) {
+		h.queryPolicy = policy
+	}
+}
+
+// WithStrictContentType rechaza con 415 las escrituras que no envían Content-Type: application/json
+func WithStrictContentType(strict bool) HandlerOption {
+	return func(h *Handler) {
+		h.strictContentType.Store(strict)
+	}
+}
+
+// WithFlags toma de store los flags que se pueden cambiar sin reiniciar (ver FlagStrictContentType) y los
+// actualiza cuando cambian; el valor configurado por las otras opciones es el default si el flag no está
+func WithFlags(store pkgflags.Store) HandlerOption {
+	return func(h *Handler) {
+		watchBoolFlag(store, FlagStrictContentType, &h.strictContentType)
+	}
+}
+
+// WithTrailingSlashPolicy define cómo se tratan las rutas con barra final
+func WithTrailingSlashPolicy(policy TrailingSlashPolicy) HandlerOption {
+	return func(h *Handler) {
+		h.trailingSlash = policy
+	}
+}
+
+// WithEmptyListStatus define el status de los listados vacíos: 200 con la lista vacía (por defecto) o 204 sin body
+func WithEmptyListStatus(status int) HandlerOption {
+	return func(h *Handler) {
+		h.emptyListStatus = status
+	}
 }
 
-func NewHandler(u ports.UseCases) (*Handler, error) {
+func NewHandler(u ports.UseCases, opts ...HandlerOption) (*Handler, error) {
 	s, err := ginserver.Bootstrap(false)
 	if err != nil {
 		return nil, err
@@ -45,11 +98,19 @@ func NewHandler(u ports.UseCases) (*Handler, error) {
 		return nil, err
 	}
 
-	return &Handler{
-		Ucs: u,
-		Svr: s,
-		Swg: g,
-	}, nil
+	h := &Handler{
+		Ucs:             u,
+		Svr:             s,
+		Swg:             g,
+		queryPolicy:     DuplicateQueryReject,
+		trailingSlash:   TrailingSlashRedirect,
+		emptyListStatus: http.StatusOK,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h, nil
 }
 
 func (h *Handler) GetRouter() *gin.Engine {
@@ -57,15 +118,33 @@ func (h *Handler) GetRouter() *gin.Engine {
 }
 
 func (h *Handler) Start(ctx context.Context) error {
+	h.shutdown = ctx.Done()
 	h.Routes()
 	return h.Svr.RunServer(ctx)
 }
 
+// routeNotFound responde el 404 JSON de las rutas inexistentes, con código RESOURCE_NOT_FOUND
+func routeNotFound(c *gin.Context) {
+	apiErr, status := types.NewAPIError(resourceNotFound(c.Request.Method, c.Request.URL.Path))
+	c.JSON(status, apiErr)
+}
+
 func (h *Handler) Routes() {
 	router := h.Svr.GetRouter()
+	applyTrailingSlashPolicy(router, h.trailingSlash)
+
+	router.Use(mwr.RequestID(config.RequestIDHeader()))
+	router.Use(mwr.SecurityHeaders(config.ResponseHeaders()))
+	router.Use(mwr.CORS(config.CORS()))
 
 	router.GET("/health", h.Health)
 
+	// Un único middleware de autenticación para todas las rutas que la exigen: cada instancia arma su propio
+	// cache de JWKS, con su fetch inicial y su refresco en segundo plano
+	authConfig := config.Auth()
+	authConfig.Done = h.shutdown
+	authenticate := mwr.Authenticate(authConfig, config.APIKeys())
+
 	apiVersion := h.Svr.GetApiVersion()
 	apiBase := "/api/" + apiVersion
 
@@ -74,19 +153,36 @@ func (h *Handler) Routes() {
 		customers.GET("", h.GetCustomers)
 		customers.GET("/:id", h.GetCustomer)
 		customers.POST("", h.CreateCustomer)
+		customers.POST("/batch", h.CreateCustomers)
 		customers.PUT("/:id", h.UpdateCustomer)
+		customers.PATCH("/:id", h.PatchCustomer)
 		customers.DELETE("/:id", h.DeleteCustomer)
+		customers.POST("/:id/restore", h.RestoreCustomer)
+		customers.GET("/:id/export", authenticate, mwr.RequireScope(adminScope), h.ExportCustomer)
+		customers.POST("/:id/anonymize", authenticate, mwr.RequireScope(adminScope), h.AnonymizeCustomer)
 		customers.GET("/kpi", h.GetKPI)
+		customers.GET("/kpi/top", h.GetTopCustomers)
+		customers.GET("/aggregate", h.CountByField)
 	}
 
 	router.GET(apiBase+"/ping", h.Ping)
 
 	protected := router.Group(apiBase + "/protected")
-	protected.Use(mwr.Validate(config.Auth()))
+	protected.Use(authenticate)
 	{
 		protected.GET("/ping", h.ProtectedPing)
 	}
 
+	admin := router.Group(apiBase + "/admin")
+	admin.Use(authenticate, mwr.RequireScope(adminScope))
+	{
+		admin.GET("/config", h.AdminConfig)
+		admin.POST("/selftest", h.AdminSelfTest)
+		admin.DELETE("/customers/:id", h.HardDeleteCustomer)
+		// Métricas de expvar (memstats, cmdline, customers_slow_queries): solo para admins, no en /debug/vars público
+		admin.GET("/debug/vars", gin.WrapH(expvar.Handler()))
+	}
+
 	// Configurar Swagger
 	if err := swagin.SetupSwagger(router, h.Swg); err != nil {
 		panic(err)
@@ -128,30 +224,144 @@ func (h *Handler) ProtectedPing(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "protected pong"})
 }
 
+// @Summary     Effective configuration
+// @Description Configuración efectiva del servicio con los secretos ocultos; requiere el scope admin
+// @Tags        admin
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} map[string]any
+// @Failure     401 {object} types.APIError
+// @Failure     403 {object} types.APIError
+// @Router      /admin/config [get]
+func (h *Handler) AdminConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, config.Effective())
+}
+
+// @Summary     Self-test
+// @Description Ejecuta un ciclo create/read/update/delete con un cliente de prueba que se borra al terminar.
+// @Description Solo corre con SELFTEST_ENABLED activo o con un STAGE distinto de producción; requiere el scope admin
+// @Tags        admin
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Success     200 {object} transport.SelfTestResponse
+// @Failure     401 {object} types.APIError
+// @Failure     403 {object} types.APIError
+// @Failure     500 {object} transport.SelfTestResponse
+// @Router      /admin/selftest [post]
+func (h *Handler) AdminSelfTest(c *gin.Context) {
+	if !config.SelfTestAllowed() {
+		apiErr, status := types.NewAPIError(types.NewError(
+			types.ErrAuthorization,
+			"self-test is disabled in this stage",
+			nil,
+		))
+		c.JSON(status, apiErr)
+		return
+	}
+
+	result := runSelfTest(c.Request.Context(), h.Ucs, time.Now)
+	if !result.OK {
+		c.JSON(http.StatusInternalServerError, result)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
 // @Summary     Get list of customers
-// @Description Obtiene la lista de todos los clientes
+// @Description Obtiene una página de la lista de clientes en el orden configurado en LIST_DEFAULT_SORT
+// @Description (por defecto por id, es decir, por orden de alta); los empates se resuelven por id
 // @Tags        customers
 // @Produce     json
+// @Param       q      query string false "Busca en nombre, apellido y email sin distinguir mayúsculas ni acentos"
+// @Param       sort   query string false "Campo de ordenamiento (id, name, last_name, age, created_at); no se admite con q"
+// @Param       order  query string false "Dirección del ordenamiento (asc, desc)" default(asc)
+// @Param       limit  query int false "Tamaño de página (máximo 500)" default(50)
+// @Param       offset query int false "Cantidad de clientes a saltear" default(0)
+// @Param       include query string false "Datos a embeber en meta, separados por coma (kpi)"
+// @Param       expand  query string false "Datos relacionados a expandir en cada cliente, separados por coma (audit_summary)"
+// @Param       include_deleted query bool false "Incluye los clientes eliminados lógicamente; no se admite con q ni sort" default(false)
 // @Success     200 {object} transport.GetCustomersResponse
+// @Success     204 "Lista vacía con EMPTY_LIST_STATUS=204"
+// @Failure     400 {object} types.APIError
 // @Failure     500 {object} types.APIError
 // @Router      /customers [get]
 func (h *Handler) GetCustomers(c *gin.Context) {
-	customers, err := h.Ucs.GetCustomers(c.Request.Context())
+	params, err := parseQuery(c.Request.URL.Query(), h.queryPolicy)
 	if err != nil {
 		apiErr, status := types.NewAPIError(err)
 		c.JSON(status, apiErr)
 		return
 	}
-	c.JSON(http.StatusOK, transport.GetCustomersResponse{
-		Customers: transport.DomainListToCustomerJsonList(customers),
-	})
+
+	limit, offset, err := parsePageQuery(params)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	sort, err := parseSortQuery(params)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	include, err := parseIncludeQuery(params)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	includeDeleted, err := parseIncludeDeletedQuery(params)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	page, err := getCustomersPage(c.Request.Context(), h.Ucs, params["q"], sort, includeDeleted, limit, offset)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	response := transport.GetCustomersPagePresenter(page)
+	if expand := parseExpandQuery(params); len(expand) > 0 {
+		expanded, err := h.Ucs.ExpandCustomers(c.Request.Context(), page.Customers, expand)
+		if err != nil {
+			apiErr, status := types.NewAPIError(err)
+			c.JSON(status, apiErr)
+			return
+		}
+		response = transport.GetCustomersExpandedPagePresenter(page, expanded)
+	}
+	if include[includeKPI] {
+		kpi, err := h.Ucs.GetKPI(c.Request.Context())
+		if err != nil {
+			apiErr, status := types.NewAPIError(err)
+			c.JSON(status, apiErr)
+			return
+		}
+		response.Meta = &transport.ListMeta{KPI: transport.ToGetKPIJson(kpi)}
+	}
+	if err := h.encryptionFor(c).EncryptList(response.Customers); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+	h.writeList(c, response)
 }
 
 // @Summary     Get customer by ID
 // @Description Obtiene un cliente por su ID
 // @Tags        customers
 // @Produce     json
-// @Param       id path int true "Customer ID"
+// @Param       id     path  int    true  "Customer ID"
+// @Param       expand query string false "Datos relacionados a expandir, separados por coma (audit_summary)"
+// @Param       include_deleted query bool false "Obtiene el cliente aunque esté eliminado lógicamente; no se admite con expand" default(false)
 // @Success     200 {object} transport.GetCustomerResponse
 // @Failure     400 {object} types.APIError
 // @Failure     404 {object} types.APIError
@@ -183,69 +393,210 @@ func (h *Handler) GetCustomer(c *gin.Context) {
 		return
 	}
 
-	customer, err := h.Ucs.GetCustomerByID(c.Request.Context(), ID)
+	params, err := parseQuery(c.Request.URL.Query(), h.queryPolicy)
 	if err != nil {
 		apiErr, status := types.NewAPIError(err)
 		c.JSON(status, apiErr)
 		return
 	}
 
-	c.JSON(http.StatusOK, transport.GetCustomerResponse{
-		Customers: *transport.DomainToCustomerJson(customer),
-	})
+	includeDeleted, err := parseIncludeDeletedQuery(params)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	expanded, err := getCustomer(c.Request.Context(), h.Ucs, ID, parseExpandQuery(params), includeDeleted)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	response := transport.GetCustomerExpandedPresenter(expanded)
+	if err := h.encryptionFor(c).Encrypt(&response.Customers); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary     Export customer data
+// @Description Exporta todos los datos del cliente (registro e historial de eventos) para una solicitud
+// @Description de acceso del titular; requiere el scope admin
+// @Tags        customers
+// @Produce     json
+// @Security    ApiKeyAuth
+// @Param       id path int true "Customer ID"
+// @Success     200 {object} transport.CustomerExportResponse
+// @Failure     400 {object} types.APIError
+// @Failure     401 {object} types.APIError
+// @Failure     403 {object} types.APIError
+// @Failure     404 {object} types.APIError
+// @Failure     500 {object} types.APIError
+// @Router      /customers/{id}/export [get]
+func (h *Handler) ExportCustomer(c *gin.Context) {
+	ID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err == nil {
+		err = utils.ValidateID(ID)
+	}
+	if err != nil {
+		apiErr, status := types.NewAPIError(
+			types.NewError(
+				types.ErrInvalidInput,
+				"invalid customer ID format",
+				err,
+			),
+		)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	var requestedBy string
+	if principal, ok := mwr.GetPrincipal(c); ok {
+		requestedBy = principal.ID
+	}
+
+	export, err := h.Ucs.ExportCustomer(c.Request.Context(), ID, requestedBy)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="customer-%d-export.json"`, ID))
+	c.JSON(http.StatusOK, transport.CustomerExportPresenter(export))
+}
+
+// @Summary     Anonymize customer
+// @Description Borra los datos personales del cliente (derecho de supresión) conservando edad y fecha de alta
+// @Description para los KPIs. El cliente deja de aparecer en el detalle y el listado; requiere el scope admin
+// @Tags        customers
+// @Security    ApiKeyAuth
+// @Param       id path int true "Customer ID"
+// @Success     204
+// @Failure     400 {object} types.APIError
+// @Failure     401 {object} types.APIError
+// @Failure     403 {object} types.APIError
+// @Failure     404 {object} types.APIError
+// @Failure     500 {object} types.APIError
+// @Router      /customers/{id}/anonymize [post]
+func (h *Handler) AnonymizeCustomer(c *gin.Context) {
+	ID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err == nil {
+		err = utils.ValidateID(ID)
+	}
+	if err != nil {
+		apiErr, status := types.NewAPIError(
+			types.NewError(
+				types.ErrInvalidInput,
+				"invalid customer ID format",
+				err,
+			),
+		)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	if err := h.Ucs.AnonymizeCustomer(c.Request.Context(), ID); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
 }
 
 // @Summary     Create customer
-// @Description Crea un nuevo cliente
+// @Description Crea un nuevo cliente. Con Idempotency-Key, un reintento con la misma key retorna el cliente
+// @Description original (con el header Idempotent-Replayed) en lugar de crear otro.
 // @Tags        customers
 // @Accept      json
 // @Produce     json
+// @Param       Idempotency-Key header string false "Key del alta para reintentos seguros (máximo 255 caracteres)"
 // @Param       customer body transport.CustomerJson true "Customer Data"
-// @Success     201
+// @Success     201 {object} transport.CreateCustomerResponse
+// @Header      201 {string} Location "Ruta del cliente creado"
 // @Failure     400 {object} types.APIError
+// @Failure     415 {object} types.APIError
 // @Failure     500 {object} types.APIError
 // @Router      /customers [post]
 func (h *Handler) CreateCustomer(c *gin.Context) {
+	if err := validateContentType(c.GetHeader("Content-Type"), h.strictContentType.Load()); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
 	var req transport.CustomerJson
-	if err := c.ShouldBindJSON(&req); err != nil {
-		errStr := err.Error()
-		var message string
-		switch {
-		case strings.Contains(errStr, "Email' failed on the 'required' tag"):
-			message = "invalid email format"
-		case strings.Contains(errStr, "Age' failed on the 'required' tag"):
-			message = "invalid age"
-		case strings.Contains(errStr, "failed on the 'required' tag"):
-			message = "missing required field"
-		case strings.Contains(errStr, "cannot unmarshal"):
-			message = "invalid data type"
-		default:
-			message = "request cannot be nil"
-		}
+	if err := customerBodyError(c.ShouldBindJSON(&req), &req, h.encryptionFor(c)); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
 
-		apiErr, status := types.NewAPIError(
-			types.NewError(
-				types.ErrValidation,
-				message,
-				err,
-			),
-		)
+	customer := transport.CustomerJsonToDomain(&req)
+	warnings, replayed, err := createCustomer(c.Request.Context(), h.Ucs, c.GetHeader(idempotencyKeyHeader), customer)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
 		c.JSON(status, apiErr)
 		return
 	}
+	if replayed {
+		c.Header(idempotentReplayedHeader, "true")
+	}
+	response := transport.ToCreateCustomerResponse(customer, warnings)
+	if err := h.encryptionFor(c).Encrypt(&response.Customer); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+	c.Header("Location", customerLocation(c.Request.URL.Path, customer.ID))
+	c.JSON(http.StatusCreated, response)
+}
 
-	if err := validateRequest(&req); err != nil {
+// @Summary     Create customers in batch
+// @Description Crea un lote de clientes en una única transacción: si alguno es inválido o falla no se crea
+// @Description ninguno. La respuesta informa el resultado de cada item por su posición (failed o aborted).
+// @Tags        customers
+// @Accept      json
+// @Produce     json
+// @Param       customers body []transport.CustomerJson true "Customers Data (máximo 500)"
+// @Success     201 {object} transport.BatchResponse
+// @Failure     400 {object} transport.BatchResponse
+// @Failure     409 {object} transport.BatchResponse
+// @Failure     415 {object} types.APIError
+// @Failure     500 {object} types.APIError
+// @Router      /customers/batch [post]
+func (h *Handler) CreateCustomers(c *gin.Context) {
+	if err := validateContentType(c.GetHeader("Content-Type"), h.strictContentType.Load()); err != nil {
 		apiErr, status := types.NewAPIError(err)
 		c.JSON(status, apiErr)
 		return
 	}
 
-	if err := h.Ucs.CreateCustomer(c.Request.Context(), transport.CustomerJsonToDomain(&req)); err != nil {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		apiErr, status := types.NewAPIError(
+			types.NewError(
+				types.ErrInvalidInput,
+				"invalid request body",
+				err,
+			),
+		)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	status, response, err := createCustomerBatch(c.Request.Context(), h.Ucs, h.encryptionFor(c), string(body))
+	if err != nil {
 		apiErr, status := types.NewAPIError(err)
 		c.JSON(status, apiErr)
 		return
 	}
-	c.JSON(http.StatusCreated, nil)
+	c.JSON(status, response)
 }
 
 // @Summary     Update customer
@@ -258,9 +609,16 @@ func (h *Handler) CreateCustomer(c *gin.Context) {
 // @Success     200
 // @Failure     400 {object} types.APIError
 // @Failure     404 {object} types.APIError
+// @Failure     415 {object} types.APIError
 // @Failure     500 {object} types.APIError
 // @Router      /customers/{id} [put]
 func (h *Handler) UpdateCustomer(c *gin.Context) {
+	if err := validateContentType(c.GetHeader("Content-Type"), h.strictContentType.Load()); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
 	ID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		apiErr, status := types.NewAPIError(
@@ -281,11 +639,45 @@ func (h *Handler) UpdateCustomer(c *gin.Context) {
 	}
 
 	var req transport.CustomerJson
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := customerBodyError(c.ShouldBindJSON(&req), &req, h.encryptionFor(c)); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	customer := transport.CustomerJsonToDomain(&req)
+	customer.ID = ID
+
+	if err := h.Ucs.UpdateCustomer(c.Request.Context(), customer); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// @Summary     Patch customer
+// @Description Actualiza parcialmente un cliente con JSON Merge Patch (RFC 7386) o JSON Patch (RFC 6902)
+// @Tags        customers
+// @Accept      application/merge-patch+json
+// @Accept      application/json-patch+json
+// @Produce     json
+// @Param       id path int true "Customer ID"
+// @Param       patch body object true "Merge patch o lista de operaciones JSON Patch"
+// @Success     200 {object} transport.GetCustomerResponse
+// @Failure     400 {object} types.APIError
+// @Failure     404 {object} types.APIError
+// @Failure     409 {object} types.APIError
+// @Failure     415 {object} types.APIError
+// @Failure     500 {object} types.APIError
+// @Router      /customers/{id} [patch]
+func (h *Handler) PatchCustomer(c *gin.Context) {
+	ID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
 		apiErr, status := types.NewAPIError(
 			types.NewError(
-				types.ErrValidation,
-				"invalid request body",
+				types.ErrInvalidInput,
+				"invalid customer ID format",
 				err,
 			),
 		)
@@ -293,25 +685,38 @@ func (h *Handler) UpdateCustomer(c *gin.Context) {
 		return
 	}
 
-	if err := validateRequest(&req); err != nil {
+	if err := utils.ValidateID(ID); err != nil {
 		apiErr, status := types.NewAPIError(err)
 		c.JSON(status, apiErr)
 		return
 	}
 
-	customer := transport.CustomerJsonToDomain(&req)
-	customer.ID = ID
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		apiErr, status := types.NewAPIError(
+			types.NewError(
+				types.ErrInvalidInput,
+				"invalid request body",
+				err,
+			),
+		)
+		c.JSON(status, apiErr)
+		return
+	}
 
-	if err := h.Ucs.UpdateCustomer(c.Request.Context(), customer); err != nil {
+	customer, err := patchCustomer(c.Request.Context(), h.Ucs, h.encryptionFor(c), ID, c.GetHeader("Content-Type"), body)
+	if err != nil {
 		apiErr, status := types.NewAPIError(err)
 		c.JSON(status, apiErr)
 		return
 	}
-	c.Status(http.StatusOK)
+
+	h.writeCustomer(c, customer)
 }
 
 // @Summary     Delete customer
-// @Description Elimina un cliente
+// @Description Elimina un cliente de forma lógica: deja de aparecer en el detalle, el listado y los KPIs, pero
+// @Description conserva su email y se puede recuperar con POST /customers/{id}/restore
 // @Tags        customers
 // @Param       id path int true "Customer ID"
 // @Success     204
@@ -339,6 +744,87 @@ func (h *Handler) DeleteCustomer(c *gin.Context) {
 		return
 	}
 
+	if err := h.Ucs.SoftDeleteCustomer(c.Request.Context(), ID); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary     Restore customer
+// @Description Recupera un cliente eliminado lógicamente; si no estaba eliminado lo retorna sin cambios
+// @Tags        customers
+// @Produce     json
+// @Param       id path int true "Customer ID"
+// @Success     200 {object} transport.GetCustomerResponse
+// @Failure     400 {object} types.APIError
+// @Failure     404 {object} types.APIError
+// @Failure     500 {object} types.APIError
+// @Router      /customers/{id}/restore [post]
+func (h *Handler) RestoreCustomer(c *gin.Context) {
+	ID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		apiErr, status := types.NewAPIError(
+			types.NewError(
+				types.ErrInvalidInput,
+				"invalid customer ID format",
+				err,
+			),
+		)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	if err := utils.ValidateID(ID); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	customer, err := h.Ucs.RestoreCustomer(c.Request.Context(), ID)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	h.writeCustomer(c, customer)
+}
+
+// @Summary     Hard delete customer
+// @Description Borra el registro del cliente de forma definitiva, esté o no eliminado lógicamente; requiere
+// @Description el scope admin
+// @Tags        admin
+// @Security    ApiKeyAuth
+// @Param       id path int true "Customer ID"
+// @Success     204
+// @Failure     400 {object} types.APIError
+// @Failure     401 {object} types.APIError
+// @Failure     403 {object} types.APIError
+// @Failure     404 {object} types.APIError
+// @Failure     500 {object} types.APIError
+// @Router      /admin/customers/{id} [delete]
+func (h *Handler) HardDeleteCustomer(c *gin.Context) {
+	ID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		apiErr, status := types.NewAPIError(
+			types.NewError(
+				types.ErrInvalidInput,
+				"invalid customer ID format",
+				err,
+			),
+		)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	if err := utils.ValidateID(ID); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
 	if err := h.Ucs.DeleteCustomer(c.Request.Context(), ID); err != nil {
 		apiErr, status := types.NewAPIError(err)
 		c.JSON(status, apiErr)
@@ -348,18 +834,148 @@ func (h *Handler) DeleteCustomer(c *gin.Context) {
 }
 
 // @Summary     Get KPIs
-// @Description Obtiene los KPIs de clientes
+// @Description Obtiene los KPIs de clientes; con from y/o to, solo de los dados de alta en ese rango
 // @Tags        customers
 // @Produce     json
+// @Param       from query string false "Inicio del rango de alta, inclusive (RFC3339)"
+// @Param       to   query string false "Fin del rango de alta, inclusive (RFC3339)"
+// @Param       If-None-Match header string false "ETag de la versión que ya tiene el cliente"
 // @Success     200 {object} transport.GetKPIJson
+// @Success     304 "Los KPIs no cambiaron desde el ETag informado"
+// @Failure     400 {object} types.APIError
 // @Failure     500 {object} types.APIError
 // @Router      /customers/kpi [get]
 func (h *Handler) GetKPI(c *gin.Context) {
-	kpi, err := h.Ucs.GetKPI(c.Request.Context())
+	params, err := parseQuery(c.Request.URL.Query(), h.queryPolicy)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	from, to, err := parseRangeQuery(params)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	kpi, err := getKPI(c.Request.Context(), h.Ucs, from, to)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	body, err := json.Marshal(transport.ToGetKPIJson(kpi))
+	if err != nil {
+		apiErr, status := types.NewAPIError(types.NewError(types.ErrInternal, "Error marshalling response", err))
+		c.JSON(status, apiErr)
+		return
+	}
+
+	etag := contentETag(body)
+	c.Header(etagHeader, etag)
+	c.Header(cacheControlHeader, revalidateCacheControl)
+	if notModified(c.GetHeader(ifNoneMatchHeader), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// @Summary     Get top customers
+// @Description Obtiene los N clientes con mayor edad o alta más reciente; los empates se ordenan por id
+// @Tags        customers
+// @Produce     json
+// @Param       by query string false "Dimensión del ranking (age, created_at)" default(age)
+// @Param       n  query int    false "Cantidad de clientes (máximo 100)"       default(10)
+// @Success     200 {object} transport.GetCustomersResponse
+// @Success     204 "Lista vacía con EMPTY_LIST_STATUS=204"
+// @Failure     400 {object} types.APIError
+// @Failure     500 {object} types.APIError
+// @Router      /customers/kpi/top [get]
+func (h *Handler) GetTopCustomers(c *gin.Context) {
+	params, err := parseQuery(c.Request.URL.Query(), h.queryPolicy)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	by, n, err := parseTopQuery(params)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	customers, err := h.Ucs.GetTopCustomers(c.Request.Context(), by, n)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+	response := transport.GetTopCustomersPresenter(customers)
+	if err := h.encryptionFor(c).EncryptList(response.Customers); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+	h.writeList(c, response)
+}
+
+// writeCustomer responde el customer con los campos cifrados para el partner, si lo es
+func (h *Handler) writeCustomer(c *gin.Context, customer *domain.Customer) {
+	response, err := h.encryptionFor(c).DomainToCustomerJson(customer)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, transport.GetCustomerResponse{Customers: *response})
+}
+
+// writeList responde un listado; vacío responde con el status configurado (ver WithEmptyListStatus)
+func (h *Handler) writeList(c *gin.Context, response transport.GetCustomersResponse) {
+	status := listStatus(h.emptyListStatus, len(response.Customers))
+	if status == http.StatusNoContent {
+		c.Status(status)
+		return
+	}
+	c.JSON(status, response)
+}
+
+// @Summary     Count customers by field
+// @Description Cuenta los clientes agrupados por una dimensión; si hay más de 50 grupos el resto se suma en "other"
+// @Tags        customers
+// @Produce     json
+// @Param       group_by query string true "Dimensión de agrupación (age_bucket, email_domain)"
+// @Param       buckets  query string false "Rangos de edades con group_by=age_bucket, por ejemplo 1-17,18-64,65-150 (máximo 20)"
+// @Success     200 {object} transport.AggregateJson
+// @Failure     400 {object} types.APIError
+// @Failure     500 {object} types.APIError
+// @Router      /customers/aggregate [get]
+func (h *Handler) CountByField(c *gin.Context) {
+	params, err := parseQuery(c.Request.URL.Query(), h.queryPolicy)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	groupBy, buckets, err := parseAggregateQuery(params)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		c.JSON(status, apiErr)
+		return
+	}
+
+	counts, err := countCustomers(c.Request.Context(), h.Ucs, groupBy, buckets)
 	if err != nil {
 		apiErr, status := types.NewAPIError(err)
 		c.JSON(status, apiErr)
 		return
 	}
-	c.JSON(http.StatusOK, transport.ToGetKPIJson(kpi))
+	c.JSON(http.StatusOK, transport.ToAggregateJson(groupBy, counts))
 }
@@ -14,8 +14,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	mwr "github.com/devpablocristo/tech-house/pkg/rest/middlewares/gin"
 	types "github.com/devpablocristo/tech-house/pkg/types"
 	inbound "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound"
+	transport "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound/transport"
 	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
 )
 
@@ -44,6 +46,18 @@ func (h ucsMock) GetCustomers(ctx context.Context) ([]domain.Customer, error) {
 	}, nil
 }
 
+func (h ucsMock) GetCustomersPaged(ctx context.Context, limit, offset int) (*domain.CustomerPage, error) {
+	customers, err := h.GetCustomers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.CustomerPage{Customers: customers, Total: len(customers), Offset: offset}, nil
+}
+
+func (h ucsMock) GetCustomersSorted(ctx context.Context, sort domain.CustomerSort, limit, offset int) (*domain.CustomerPage, error) {
+	return h.GetCustomersPaged(ctx, limit, offset)
+}
+
 func (h ucsMock) GetCustomerByID(ctx context.Context, id int64) (*domain.Customer, error) {
 	if h.err != nil {
 		return nil, h.err
@@ -59,6 +73,37 @@ func (h ucsMock) GetCustomerByID(ctx context.Context, id int64) (*domain.Custome
 	}, nil
 }
 
+func (h ucsMock) GetCustomerExpanded(ctx context.Context, id int64, expand []string) (*domain.ExpandedCustomer, error) {
+	customer, err := h.GetCustomerByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ExpandedCustomer{Customer: customer}, nil
+}
+
+func (h ucsMock) ExpandCustomers(ctx context.Context, customers []domain.Customer, expand []string) ([]domain.ExpandedCustomer, error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+	expanded := make([]domain.ExpandedCustomer, len(customers))
+	for i := range customers {
+		expanded[i] = domain.ExpandedCustomer{Customer: &customers[i]}
+	}
+	return expanded, nil
+}
+
+func (h ucsMock) CreateCustomers(ctx context.Context, customers []domain.Customer) ([]domain.Customer, error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+	created := make([]domain.Customer, len(customers))
+	for i, customer := range customers {
+		customer.ID = int64(i + 1)
+		created[i] = customer
+	}
+	return created, nil
+}
+
 func (h ucsMock) GetCustomerByEmail(ctx context.Context, email string) (*domain.Customer, error) {
 	if h.err != nil {
 		return nil, h.err
@@ -74,18 +119,97 @@ func (h ucsMock) GetCustomerByEmail(ctx context.Context, email string) (*domain.
 	}, nil
 }
 
-func (h ucsMock) CreateCustomer(ctx context.Context, customer *domain.Customer) error {
-	return h.err
+func (h ucsMock) SearchCustomers(ctx context.Context, query string, limit, offset int) (*domain.CustomerPage, error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+	return &domain.CustomerPage{Customers: []domain.Customer{}, Offset: offset}, nil
+}
+
+func (h ucsMock) CreateCustomer(ctx context.Context, customer *domain.Customer) ([]domain.Warning, error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+	customer.ID = 1
+	return nil, nil
+}
+
+func (h ucsMock) CreateCustomerIdempotent(ctx context.Context, key string, customer *domain.Customer) ([]domain.Warning, bool, error) {
+	return nil, false, h.err
 }
 
 func (h ucsMock) UpdateCustomer(ctx context.Context, customer *domain.Customer) error {
 	return h.err
 }
 
+func (h ucsMock) PatchCustomer(ctx context.Context, id int64, patch domain.CustomerPatch) (*domain.Customer, error) {
+	customer, err := h.GetCustomerByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if patch.Precondition != nil {
+		if err := patch.Precondition(*customer); err != nil {
+			return nil, err
+		}
+	}
+	patch.Apply(customer)
+	return customer, nil
+}
+
 func (h ucsMock) DeleteCustomer(ctx context.Context, id int64) error {
 	return h.err
 }
 
+func (h ucsMock) SoftDeleteCustomer(ctx context.Context, id int64) error {
+	return h.err
+}
+
+func (h ucsMock) RestoreCustomer(ctx context.Context, id int64) (*domain.Customer, error) {
+	return h.GetCustomerByID(ctx, id)
+}
+
+func (h ucsMock) GetCustomerIncludingDeleted(ctx context.Context, id int64) (*domain.Customer, error) {
+	return h.GetCustomerByID(ctx, id)
+}
+
+func (h ucsMock) GetCustomersIncludingDeleted(ctx context.Context, limit, offset int) (*domain.CustomerPage, error) {
+	return h.GetCustomersPaged(ctx, limit, offset)
+}
+
+func (h ucsMock) GetTopCustomers(ctx context.Context, by string, n int) ([]domain.Customer, error) {
+	return h.GetCustomers(ctx)
+}
+
+func (h ucsMock) CountByField(ctx context.Context, field string) (map[string]int64, error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+	return map[string]int64{"30-39": 2}, nil
+}
+
+func (h ucsMock) CountByAgeBuckets(ctx context.Context, buckets []domain.AgeBucket) (map[string]int64, error) {
+	return map[string]int64{}, h.err
+}
+
+func (h ucsMock) ExportCustomer(ctx context.Context, id int64, requestedBy string) (*domain.CustomerExport, error) {
+	customer, err := h.GetCustomerByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.CustomerExport{
+		Customer: customer,
+		Events: []domain.Event{
+			{Seq: 1, Type: domain.EventCustomerCreated, CustomerID: customer.ID, Customer: customer, OccurredAt: birthDate},
+		},
+		ExportedAt:  birthDate,
+		RequestedBy: requestedBy,
+	}, nil
+}
+
+func (h ucsMock) AnonymizeCustomer(ctx context.Context, id int64) error {
+	return h.err
+}
+
 func (h ucsMock) GetKPI(ctx context.Context) (*domain.KPI, error) {
 	if h.err != nil {
 		return nil, h.err
@@ -96,6 +220,10 @@ func (h ucsMock) GetKPI(ctx context.Context) (*domain.KPI, error) {
 	}, nil
 }
 
+func (h ucsMock) GetKPIForRange(ctx context.Context, from, to time.Time) (*domain.KPI, error) {
+	return h.GetKPI(ctx)
+}
+
 type expectedResponse struct {
 	code int
 	body *types.APIErrorResponse
@@ -115,15 +243,17 @@ func Test_Handler_GetCustomers(t *testing.T) {
 			wantBody: map[string]any{
 				"customers": []any{
 					map[string]any{
-						"id":         float64(1),
-						"name":       "Homero",
-						"last_name":  "Simpson",
-						"email":      "homero@springfield.com",
-						"phone":      "1234567890",
-						"age":        float64(39),
-						"birth_date": birthDate.Format(time.RFC3339),
+						"id":           float64(1),
+						"name":         "Homero",
+						"last_name":    "Simpson",
+						"display_name": "Homero Simpson",
+						"email":        "homero@springfield.com",
+						"phone":        "1234567890",
+						"age":          float64(39),
+						"birth_date":   birthDate.Format(time.RFC3339),
 					},
 				},
+				"total": float64(1),
 			},
 		},
 		{
@@ -177,6 +307,114 @@ func Test_Handler_GetCustomers(t *testing.T) {
 	}
 }
 
+func Test_Handler_GetCustomers_DuplicateQueryParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []inbound.HandlerOption
+		query    string
+		wantCode int
+	}{
+		{
+			name:     "should reject duplicate params by default",
+			query:    "?limit=10&limit=20",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "should accept duplicate params with last policy",
+			opts:     []inbound.HandlerOption{inbound.WithDuplicateQueryPolicy(inbound.DuplicateQueryLast)},
+			query:    "?limit=10&limit=20",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "should accept distinct params",
+			query:    "?limit=10&offset=20",
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/api/v1/customers"+tt.query, nil)
+
+			gin.SetMode(gin.TestMode)
+
+			handler, err := inbound.NewHandler(ucsMock{}, tt.opts...)
+			require.NoError(t, err)
+
+			handler.GetCustomers(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode == http.StatusBadRequest {
+				var response types.APIErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, types.APIErrValidation, response.Type)
+				assert.Equal(t, "duplicate query parameter: limit", response.Message)
+			}
+		})
+	}
+}
+
+func Test_Handler_GetCustomers_IncludeKPI(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+		wantKPI  bool
+	}{
+		{
+			name:     "should not include the KPI by default",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "should include the KPI under meta when requested",
+			query:    "?include=kpi",
+			wantCode: http.StatusOK,
+			wantKPI:  true,
+		},
+		{
+			name:     "should reject unknown include values",
+			query:    "?include=kpi,orders",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/api/v1/customers"+tt.query, nil)
+
+			gin.SetMode(gin.TestMode)
+
+			handler, err := inbound.NewHandler(ucsMock{})
+			require.NoError(t, err)
+
+			handler.GetCustomers(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode != http.StatusOK {
+				var response types.APIErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, types.APIErrValidation, response.Type)
+				return
+			}
+
+			var response transport.GetCustomersResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Len(t, response.Customers, 1)
+			if !tt.wantKPI {
+				assert.Nil(t, response.Meta)
+				assert.NotContains(t, w.Body.String(), `"meta"`)
+				return
+			}
+			require.NotNil(t, response.Meta)
+			assert.Equal(t, &transport.GetKPIJson{AverageAge: 35.5, AgeStdDeviation: 30.5}, response.Meta.KPI)
+		})
+	}
+}
+
 func Test_Handler_GetCustomer(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -192,13 +430,14 @@ func Test_Handler_GetCustomer(t *testing.T) {
 			wantCode: http.StatusOK,
 			wantBody: map[string]any{
 				"customer": map[string]any{
-					"id":         float64(1),
-					"name":       "Homero",
-					"last_name":  "Simpson",
-					"email":      "homero@springfield.com",
-					"phone":      "1234567890",
-					"age":        float64(39),
-					"birth_date": birthDate.Format(time.RFC3339),
+					"id":           float64(1),
+					"name":         "Homero",
+					"last_name":    "Simpson",
+					"display_name": "Homero Simpson",
+					"email":        "homero@springfield.com",
+					"phone":        "1234567890",
+					"age":          float64(39),
+					"birth_date":   birthDate.Format(time.RFC3339),
 				},
 			},
 		},
@@ -226,6 +465,17 @@ func Test_Handler_GetCustomer(t *testing.T) {
 				Details: "id must be greater than 0",
 			},
 		},
+		{
+			name:     "should return a distinct code when the customer does not exist",
+			id:       "1",
+			mock:     ucsMock{err: types.NewError(types.ErrNotFound, "customer not found", nil).WithCode(domain.CodeCustomerNotFound)},
+			wantCode: http.StatusNotFound,
+			wantBody: &types.APIErrorResponse{
+				Type:    domain.CodeCustomerNotFound,
+				Code:    http.StatusNotFound,
+				Message: "customer not found",
+			},
+		},
 		{
 			name:     "should return error when service fails",
 			id:       "1",
@@ -278,6 +528,42 @@ func Test_Handler_GetCustomer(t *testing.T) {
 	}
 }
 
+func Test_Handler_ExportCustomer(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/customers/1/export", nil)
+	c.Request = req
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+	c.Set(mwr.PrincipalContextKey, &mwr.Principal{ID: "privacy-officer", Scopes: []string{"admin"}})
+
+	handler, err := inbound.NewHandler(ucsMock{})
+	require.NoError(t, err)
+
+	handler.ExportCustomer(c)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "customer-1-export.json")
+
+	var bundle map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bundle))
+	assert.Contains(t, bundle, "metadata")
+	assert.Contains(t, bundle, "customer")
+	assert.Contains(t, bundle, "events")
+
+	var export transport.CustomerExportResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &export))
+	assert.Equal(t, transport.CustomerExportFormat, export.Metadata.Format)
+	assert.Equal(t, int64(1), export.Metadata.CustomerID)
+	assert.Equal(t, "privacy-officer", export.Metadata.RequestedBy)
+	assert.False(t, export.Metadata.ExportedAt.IsZero())
+	assert.Equal(t, "homero@springfield.com", export.Customer.Email)
+	require.Len(t, export.Events, 1)
+	assert.Equal(t, domain.EventCustomerCreated, export.Events[0].Type)
+	require.NotNil(t, export.Events[0].Customer)
+	assert.Equal(t, int64(1), export.Events[0].Customer.ID)
+}
+
 func Test_Handler_CreateCustomer(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -294,7 +580,7 @@ func Test_Handler_CreateCustomer(t *testing.T) {
 				"email":      "homero@springfield.com",
 				"phone":      "1234567890",
 				"age":        31,
-				"birth_date": "1993-01-01T00:00:00Z",
+				"birth_date": time.Now().AddDate(-31, 0, 0).Format(time.RFC3339),
 			},
 			mock:     ucsMock{err: nil},
 			wantCode: http.StatusCreated,
@@ -308,7 +594,7 @@ func Test_Handler_CreateCustomer(t *testing.T) {
 				"email":      "homero@springfield.com",
 				"phone":      "1234567890",
 				"age":        float64(39),
-				"birth_date": "1993-01-01T00:00:00Z",
+				"birth_date": birthDate.Format(time.RFC3339),
 			},
 			mock:     ucsMock{err: nil},
 			wantCode: http.StatusBadRequest,
@@ -326,7 +612,7 @@ func Test_Handler_CreateCustomer(t *testing.T) {
 				"email":      "homeroinvalidemail",
 				"phone":      "1234567890",
 				"age":        float64(39),
-				"birth_date": "1993-01-01T00:00:00Z",
+				"birth_date": birthDate.Format(time.RFC3339),
 			},
 			mock:     ucsMock{err: nil},
 			wantCode: http.StatusBadRequest,
@@ -344,7 +630,25 @@ func Test_Handler_CreateCustomer(t *testing.T) {
 				"email":      "homero@springfield.com",
 				"phone":      "123",
 				"age":        25,
-				"birth_date": "1999-01-01T00:00:00Z",
+				"birth_date": time.Now().AddDate(-25, 0, 0).Format(time.RFC3339),
+			},
+			mock:     ucsMock{err: nil},
+			wantCode: http.StatusBadRequest,
+			wantBody: &types.APIErrorResponse{
+				Type:    types.APIErrValidation,
+				Code:    http.StatusBadRequest,
+				Message: "invalid phone format",
+			},
+		},
+		{
+			name: "should fail without phone",
+			body: map[string]any{
+				"name":       "Homero",
+				"last_name":  "Simpson",
+				"email":      "homero@springfield.com",
+				"phone":      "",
+				"age":        25,
+				"birth_date": time.Now().AddDate(-25, 0, 0).Format(time.RFC3339),
 			},
 			mock:     ucsMock{err: nil},
 			wantCode: http.StatusBadRequest,
@@ -409,7 +713,7 @@ func Test_Handler_CreateCustomer(t *testing.T) {
 				"email":      "homero@springfield.com",
 				"phone":      "1234567890",
 				"age":        31,
-				"birth_date": "1993-01-01T00:00:00Z",
+				"birth_date": time.Now().AddDate(-31, 0, 0).Format(time.RFC3339),
 			},
 			mock:     ucsMock{err: errors.New("service error")},
 			wantCode: http.StatusInternalServerError,
@@ -449,6 +753,13 @@ func Test_Handler_CreateCustomer(t *testing.T) {
 			t.Logf("Response Body: %s", w.Body.String()) // Log del body de respuesta
 
 			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode == http.StatusCreated {
+				assert.Equal(t, "/api/v1/customers/1", w.Header().Get("Location"))
+
+				var created transport.CreateCustomerResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+				assert.Equal(t, int64(1), created.Customer.ID)
+			}
 			if tt.wantBody != nil {
 				var response types.APIErrorResponse
 				err := json.Unmarshal(w.Body.Bytes(), &response)
@@ -465,6 +776,126 @@ func Test_Handler_CreateCustomer(t *testing.T) {
 	}
 }
 
+func Test_Handler_CreateCustomer_FieldErrors(t *testing.T) {
+	body, err := json.Marshal(map[string]any{
+		"name":       "Homero",
+		"last_name":  "Simpson",
+		"email":      "homeroinvalidemail",
+		"phone":      "1234567890",
+		"birth_date": birthDate,
+	})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/customers", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	c.Request = req
+
+	handler, err := inbound.NewHandler(ucsMock{})
+	require.NoError(t, err)
+
+	handler.CreateCustomer(c)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response struct {
+		Type    types.APIErrorType `json:"type"`
+		Message string             `json:"message"`
+		Context struct {
+			Fields []types.FieldError `json:"fields"`
+		} `json:"context"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, types.APIErrValidation, response.Type)
+	assert.Equal(t, "invalid request fields", response.Message)
+	assert.ElementsMatch(t, []string{"age", "email"}, fieldNames(response.Context.Fields))
+	for _, field := range response.Context.Fields {
+		if field.Field == "age" {
+			assert.Equal(t, "required", field.Rule)
+		}
+		if field.Field == "email" {
+			assert.Equal(t, "invalid email format: homeroinvalidemail", field.Message)
+		}
+		assert.NotEmpty(t, field.Message)
+	}
+}
+
+func fieldNames(fields []types.FieldError) []string {
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.Field
+	}
+	return names
+}
+
+func Test_Handler_CreateCustomer_StrictContentType(t *testing.T) {
+	body, err := json.Marshal(map[string]any{
+		"name":       "Homero",
+		"last_name":  "Simpson",
+		"email":      "homero@springfield.com",
+		"phone":      "1234567890",
+		"age":        39,
+		"birth_date": birthDate,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		strict      bool
+		contentType string
+		wantCode    int
+	}{
+		{
+			name:     "should reject missing content type in strict mode",
+			strict:   true,
+			wantCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "should reject wrong content type in strict mode",
+			strict:      true,
+			contentType: "text/plain",
+			wantCode:    http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "should accept json content type with charset in strict mode",
+			strict:      true,
+			contentType: "application/json; charset=utf-8",
+			wantCode:    http.StatusCreated,
+		},
+		{
+			name:        "should accept wrong content type when strict mode is off",
+			contentType: "text/plain",
+			wantCode:    http.StatusCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodPost, "/api/v1/customers", bytes.NewReader(body))
+			if tt.contentType != "" {
+				c.Request.Header.Set("Content-Type", tt.contentType)
+			}
+
+			gin.SetMode(gin.TestMode)
+
+			handler, err := inbound.NewHandler(ucsMock{}, inbound.WithStrictContentType(tt.strict))
+			require.NoError(t, err)
+
+			handler.CreateCustomer(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode == http.StatusUnsupportedMediaType {
+				var response types.APIErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, types.APIErrUnsupportedMediaType, response.Type)
+			}
+		})
+	}
+}
+
 // func Test_Handler_UpdateCustomer(t *testing.T) {
 // 	validBirthDate := time.Date(1993, 1, 1, 0, 0, 0, 0, time.UTC)
 
@@ -0,0 +1,131 @@
+package inbound
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	transport "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound/transport"
+	ports "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/ports"
+)
+
+// defaultHealthCheckTimeout acota cada dependencia de GET /health/deep si no se configura otro timeout
+const defaultHealthCheckTimeout = 2 * time.Second
+
+// WithLambdaHealthChecks registra las dependencias que verifica GET /health/deep, por nombre (por ejemplo
+// "repository"). Cada una tiene timeout para responder; con 0 se usa defaultHealthCheckTimeout.
+func WithLambdaHealthChecks(timeout time.Duration, dependencies map[string]ports.Pinger) LambdaOption {
+	return func(h *LambdaHandler) {
+		h.healthTimeout = timeout
+		h.healthChecks = dependencies
+	}
+}
+
+// Health es el health check liviano: responde 200 sin tocar ninguna dependencia
+func (h *LambdaHandler) Health(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return writeHealth(http.StatusOK, transport.HealthResponse{Status: transport.HealthStatusOK}), nil
+}
+
+// DeepHealth verifica en paralelo las dependencias registradas con WithLambdaHealthChecks y reporta el estado
+// de cada una. Responde 200 si todas responden y 503 si alguna falla o no responde dentro del timeout.
+func (h *LambdaHandler) DeepHealth(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	response := transport.HealthResponse{
+		Status:       transport.HealthStatusOK,
+		Dependencies: checkDependencies(ctx, h.healthChecks, h.healthCheckTimeout()),
+	}
+
+	status := http.StatusOK
+	for _, dependency := range response.Dependencies {
+		if dependency.Status != transport.HealthStatusOK {
+			response.Status = transport.HealthStatusDegraded
+			status = http.StatusServiceUnavailable
+		}
+	}
+	return writeHealth(status, response), nil
+}
+
+// healthCheckTimeout retorna el timeout de cada dependencia: el configurado o defaultHealthCheckTimeout
+func (h *LambdaHandler) healthCheckTimeout() time.Duration {
+	if h.healthTimeout > 0 {
+		return h.healthTimeout
+	}
+	return defaultHealthCheckTimeout
+}
+
+// checkDependencies hace ping a cada dependencia en su propia goroutine. Una dependencia que no responde dentro
+// del timeout se reporta como error sin esperarla, así una base lenta no cuelga el health check.
+func checkDependencies(ctx context.Context, dependencies map[string]ports.Pinger, timeout time.Duration) map[string]transport.DependencyJson {
+	results := make(map[string]transport.DependencyJson, len(dependencies))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, dependency := range dependencies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := checkDependency(ctx, dependency, timeout)
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// checkDependency hace ping a la dependencia con timeout y mide cuánto tardó en responder
+func checkDependency(ctx context.Context, dependency ports.Pinger, timeout time.Duration) transport.DependencyJson {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- dependency.Ping(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("no response within %s: %w", timeout, ctx.Err())
+	}
+
+	result := transport.DependencyJson{
+		Status:    transport.HealthStatusOK,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = transport.HealthStatusError
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// writeHealth arma la respuesta JSON de un health check
+func writeHealth(status int, response transport.HealthResponse) events.APIGatewayProxyResponse {
+	body, err := json.Marshal(response)
+	if err != nil {
+		apiErr, status := types.NewAPIError(
+			types.NewError(
+				types.ErrInternal,
+				"Error marshalling response",
+				err,
+			),
+		)
+		return writeError(status, apiErr)
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+		Body: string(body),
+	}
+}
@@ -0,0 +1,106 @@
+package inbound
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	transport "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound/transport"
+	ports "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/ports"
+)
+
+// pingerFunc adapta una función a ports.Pinger
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) Ping(ctx context.Context) error { return f(ctx) }
+
+func Test_LambdaHandler_Health(t *testing.T) {
+	t.Run("should answer ok without touching the dependencies", func(t *testing.T) {
+		var pinged bool
+		h := newTestLambdaHandler(&lambdaUcsStub{}, WithLambdaHealthChecks(0, map[string]ports.Pinger{
+			"repository": pingerFunc(func(ctx context.Context) error {
+				pinged = true
+				return nil
+			}),
+		}))
+
+		response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Resource: "/health"})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.JSONEq(t, `{"status":"ok"}`, response.Body)
+		assert.False(t, pinged)
+	})
+}
+
+func Test_LambdaHandler_DeepHealth(t *testing.T) {
+	healthy := pingerFunc(func(ctx context.Context) error { return nil })
+	failing := pingerFunc(func(ctx context.Context) error { return errors.New("database is locked") })
+	// hanging ignora el contexto, como una base que no responde
+	hanging := pingerFunc(func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	tests := []struct {
+		name         string
+		dependencies map[string]ports.Pinger
+		wantCode     int
+		wantStatus   string
+		wantDeps     map[string]string
+	}{
+		{
+			name:         "should answer ok when every dependency responds",
+			dependencies: map[string]ports.Pinger{"repository": healthy},
+			wantCode:     http.StatusOK,
+			wantStatus:   transport.HealthStatusOK,
+			wantDeps:     map[string]string{"repository": transport.HealthStatusOK},
+		},
+		{
+			name:         "should report the failing dependency",
+			dependencies: map[string]ports.Pinger{"repository": failing, "cache": healthy},
+			wantCode:     http.StatusServiceUnavailable,
+			wantStatus:   transport.HealthStatusDegraded,
+			wantDeps:     map[string]string{"repository": transport.HealthStatusError, "cache": transport.HealthStatusOK},
+		},
+		{
+			name:         "should not wait for a dependency past the timeout",
+			dependencies: map[string]ports.Pinger{"repository": hanging},
+			wantCode:     http.StatusServiceUnavailable,
+			wantStatus:   transport.HealthStatusDegraded,
+			wantDeps:     map[string]string{"repository": transport.HealthStatusError},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestLambdaHandler(&lambdaUcsStub{}, WithLambdaHealthChecks(20*time.Millisecond, tt.dependencies))
+
+			start := time.Now()
+			response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Resource: "/health/deep"})
+			require.NoError(t, err)
+			assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+			require.Equal(t, tt.wantCode, response.StatusCode, response.Body)
+			var body transport.HealthResponse
+			require.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+			assert.Equal(t, tt.wantStatus, body.Status)
+
+			statuses := make(map[string]string, len(body.Dependencies))
+			for name, dependency := range body.Dependencies {
+				statuses[name] = dependency.Status
+				if dependency.Status != transport.HealthStatusOK {
+					assert.NotEmpty(t, dependency.Error)
+				}
+			}
+			assert.Equal(t, tt.wantDeps, statuses)
+		})
+	}
+}
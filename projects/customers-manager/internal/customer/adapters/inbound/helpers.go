@@ -1,78 +1,161 @@
 package inbound
 
 import (
+	"context"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+
 	types "github.com/devpablocristo/tech-house/pkg/types"
 	utils "github.com/devpablocristo/tech-house/pkg/utils"
-	transport "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound/transport"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+	ports "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/ports"
 )
 
+// adminScope es el scope requerido por los endpoints de /admin
+const adminScope = "admin"
+
 const (
 	minNameLength  = 2
 	maxNameLength  = 100
-	maxAge         = 150
+	maxAge         = utils.DefaultMaxAge
 	minAge         = 1
 	minPhoneLength = 7
 	maxEmailLength = 254
 )
 
-// validateRequest valida el request completo del customer
-func validateRequest(req *transport.CustomerJson) error {
-	if req == nil {
+// validateContentType exige "application/json" en los requests de escritura cuando el modo estricto está activo.
+// Con el modo estricto apagado (por defecto) no valida nada, para no romper clientes existentes.
+func validateContentType(contentType string, strict bool) error {
+	if !strict {
+		return nil
+	}
+
+	if contentType == "" {
 		return types.NewError(
-			types.ErrInvalidInput,
-			"request cannot be nil",
+			types.ErrUnsupportedMediaType,
+			"content type application/json is required",
 			nil,
 		)
 	}
 
-	// Sanitizar y asignar
-	name := utils.BasicInputSanitizer(req.Name)
-	email := utils.BasicInputSanitizer(req.Email)
-	phone := utils.BasicInputSanitizer(req.Phone)
-
-	req.Name = name
-	req.Email = email
-	req.Phone = phone
-
-	if err := utils.ValidateName(name, minNameLength, maxNameLength); err != nil {
-		return types.NewError(
-			types.ErrValidation,
-			"invalid name format",
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "application/json" {
+		return types.NewErrorWithContext(
+			types.ErrUnsupportedMediaType,
+			"content type application/json is required",
 			err,
+			map[string]any{"content_type": contentType},
 		)
 	}
 
-	if err := utils.ValidateEmail(email); err != nil {
-		return types.NewError(
-			types.ErrValidation,
-			"invalid email format",
-			err,
+	return nil
+}
+
+// resourceNotFound es el error de una ruta inexistente; el código RESOURCE_NOT_FOUND lo distingue del
+// CUSTOMER_NOT_FOUND de una ruta válida con un customer inexistente
+func resourceNotFound(method, path string) error {
+	return types.NewErrorWithContext(
+		types.ErrNotFound,
+		"resource not found",
+		nil,
+		map[string]any{"method": method, "path": path},
+	).WithCode(types.CodeResourceNotFound)
+}
+
+// getCustomersPage retorna una página del listado, en el orden pedido si sort viene informado, o, si q viene
+// informado, la página de la búsqueda; con includeDeleted el listado también trae los eliminados lógicamente.
+// q, sort e includeDeleted no se combinan entre sí.
+func getCustomersPage(ctx context.Context, ucs ports.UseCases, q string, sort *domain.CustomerSort, includeDeleted bool, limit, offset int) (*domain.CustomerPage, error) {
+	switch {
+	case q != "" && sort != nil:
+		return nil, types.NewError(
+			types.ErrInvalidInput,
+			"sort is not supported together with q",
+			nil,
 		)
+	case includeDeleted && (q != "" || sort != nil):
+		return nil, types.NewError(
+			types.ErrInvalidInput,
+			"include_deleted is not supported together with q or sort",
+			nil,
+		)
+	case includeDeleted:
+		return ucs.GetCustomersIncludingDeleted(ctx, limit, offset)
+	case q != "":
+		return ucs.SearchCustomers(ctx, q, limit, offset)
+	case sort != nil:
+		return ucs.GetCustomersSorted(ctx, *sort, limit, offset)
+	default:
+		return ucs.GetCustomersPaged(ctx, limit, offset)
 	}
+}
 
-	if err := utils.ValidatePhone(phone, minPhoneLength); err != nil {
-		return types.NewError(
-			types.ErrValidation,
-			"invalid phone format",
-			err,
+// getCustomer retorna el detalle del customer con las expansiones pedidas o, con includeDeleted, aunque esté
+// eliminado lógicamente; includeDeleted no admite expansiones
+func getCustomer(ctx context.Context, ucs ports.UseCases, ID int64, expand []string, includeDeleted bool) (*domain.ExpandedCustomer, error) {
+	if !includeDeleted {
+		return ucs.GetCustomerExpanded(ctx, ID, expand)
+	}
+	if len(expand) > 0 {
+		return nil, types.NewError(
+			types.ErrInvalidInput,
+			"expand is not supported together with include_deleted",
+			nil,
 		)
 	}
+	customer, err := ucs.GetCustomerIncludingDeleted(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ExpandedCustomer{Customer: customer}, nil
+}
 
-	if err := utils.ValidateAge(req.Age, minAge, maxAge); err != nil {
-		return types.NewError(
-			types.ErrValidation,
-			"invalid age",
-			err,
-		)
+// getKPI retorna los KPIs de todos los customers o, si from o to vienen informados, los del rango
+func getKPI(ctx context.Context, ucs ports.UseCases, from, to time.Time) (*domain.KPI, error) {
+	if from.IsZero() && to.IsZero() {
+		return ucs.GetKPI(ctx)
 	}
+	return ucs.GetKPIForRange(ctx, from, to)
+}
 
-	if err := utils.ValidateBirthDate(req.BirthDate, req.Age); err != nil {
-		return types.NewError(
-			types.ErrValidation,
-			"invalid birth date",
-			err,
-		)
+const (
+	// idempotencyKeyHeader identifica un alta para que los reintentos no creen otro customer
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotentReplayedHeader marca la respuesta de un alta repetida con la misma Idempotency-Key
+	idempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// createCustomer da de alta el customer; con una Idempotency-Key informada, un reintento con la misma key
+// retorna el customer original con replayed en true en lugar de crear otro
+func createCustomer(ctx context.Context, ucs ports.UseCases, key string, customer *domain.Customer) ([]domain.Warning, bool, error) {
+	if key == "" {
+		warnings, err := ucs.CreateCustomer(ctx, customer)
+		return warnings, false, err
 	}
+	return ucs.CreateCustomerIdempotent(ctx, key, customer)
+}
 
-	return nil
+// countCustomers cuenta los customers por group_by o, si vienen informados, por los rangos de edades del cliente
+func countCustomers(ctx context.Context, ucs ports.UseCases, groupBy string, buckets []domain.AgeBucket) (map[string]int64, error) {
+	if buckets == nil {
+		return ucs.CountByField(ctx, groupBy)
+	}
+	return ucs.CountByAgeBuckets(ctx, buckets)
+}
+
+// customerLocation es el header Location del customer creado, bajo la ruta de la colección del request
+func customerLocation(collectionPath string, id int64) string {
+	return strings.TrimSuffix(collectionPath, "/") + "/" + strconv.FormatInt(id, 10)
+}
+
+// headerValue busca un header sin distinguir mayúsculas, como llegan desde API Gateway
+func headerValue(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
 }
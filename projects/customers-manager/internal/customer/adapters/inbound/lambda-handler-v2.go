@@ -0,0 +1,125 @@
+package inbound
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// defaultRouteKey es la ruta catch-all de un HTTP API; con ella el resource se deduce del path
+const defaultRouteKey = "$default"
+
+// staticResources son los resources sin parámetros; tienen prioridad sobre "/customers/{id}"
+var staticResources = map[string]bool{
+	"/customers":           true,
+	"/customers/batch":     true,
+	"/customers/kpi":       true,
+	"/customers/kpi/top":   true,
+	"/customers/aggregate": true,
+}
+
+// idSubResources son los sub-resources de un customer, como "/customers/{id}/restore"
+var idSubResources = map[string]bool{
+	"restore": true,
+}
+
+// HandleRequestV2 atiende eventos de un HTTP API de API Gateway (payload 2.0). Convierte el evento al
+// formato v1 y pasa por el mismo pipeline que HandleRequest (request ID, tracing, headers, routing).
+func (h *LambdaHandler) HandleRequestV2(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	response, err := h.HandleRequest(ctx, proxyRequestFromV2(request))
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode:        response.StatusCode,
+		Headers:           response.Headers,
+		MultiValueHeaders: response.MultiValueHeaders,
+		Body:              response.Body,
+		IsBase64Encoded:   response.IsBase64Encoded,
+	}, err
+}
+
+// proxyRequestFromV2 mapea el evento v2 a v1: el método sale de RequestContext.HTTP.Method y el resource
+// de RouteKey ("GET /customers/{id}"), o del path si la ruta es $default. Los query params se leen de
+// RawQueryString para conservar los repetidos, que en QueryStringParameters llegan unidos por comas.
+func proxyRequestFromV2(request events.APIGatewayV2HTTPRequest) events.APIGatewayProxyRequest {
+	method := request.RequestContext.HTTP.Method
+	path := request.RawPath
+	if path == "" {
+		path = request.RequestContext.HTTP.Path
+	}
+
+	resource, pathParameters := resourceFromRouteKey(request.RouteKey), request.PathParameters
+	if resource == "" {
+		resource, pathParameters = resourceFromPath(path)
+	}
+
+	proxy := events.APIGatewayProxyRequest{
+		HTTPMethod:            method,
+		Resource:              resource,
+		Path:                  path,
+		Headers:               request.Headers,
+		QueryStringParameters: request.QueryStringParameters,
+		PathParameters:        pathParameters,
+		StageVariables:        request.StageVariables,
+		Body:                  request.Body,
+		IsBase64Encoded:       request.IsBase64Encoded,
+	}
+	if query, err := url.ParseQuery(request.RawQueryString); err == nil && len(query) > 0 {
+		proxy.MultiValueQueryStringParameters = query
+	}
+	if principal := principalFromV2(request.RequestContext.Authorizer); principal != "" {
+		proxy.RequestContext.Authorizer = map[string]any{lambdaPrincipalKey: principal}
+	}
+	return proxy
+}
+
+// principalFromV2 toma la identidad del caller del authorizer v2: el principalId de un authorizer Lambda o
+// el "sub" de un authorizer JWT, como lo informa el authorizer de una REST API en el payload v1
+func principalFromV2(authorizer *events.APIGatewayV2HTTPRequestContextAuthorizerDescription) string {
+	if authorizer == nil {
+		return ""
+	}
+	if principal, ok := authorizer.Lambda[lambdaPrincipalKey].(string); ok && principal != "" {
+		return principal
+	}
+	if authorizer.JWT != nil {
+		return authorizer.JWT.Claims["sub"]
+	}
+	return ""
+}
+
+// resourceFromRouteKey extrae el resource de "METHOD /path"; vacío para $default o un route key inválido
+func resourceFromRouteKey(routeKey string) string {
+	if routeKey == "" || routeKey == defaultRouteKey {
+		return ""
+	}
+	_, resource, ok := strings.Cut(routeKey, " ")
+	if !ok {
+		return ""
+	}
+	return resource
+}
+
+// resourceFromPath deduce el resource de un path concreto ("/customers/7" -> "/customers/{id}",
+// "/customers/7/restore" -> "/customers/{id}/restore")
+func resourceFromPath(path string) (string, map[string]string) {
+	normalized := trimTrailingSlash(path)
+	if staticResources[normalized] {
+		return path, nil
+	}
+
+	rest, ok := strings.CutPrefix(normalized, "/customers/")
+	if !ok {
+		return path, nil
+	}
+	id, sub, hasSub := strings.Cut(rest, "/")
+	if id == "" || (hasSub && !idSubResources[sub]) {
+		return path, nil
+	}
+
+	resource := "/customers/{id}"
+	if hasSub {
+		resource += "/" + sub
+	}
+	return resource + path[len(normalized):], map[string]string{"id": id}
+}
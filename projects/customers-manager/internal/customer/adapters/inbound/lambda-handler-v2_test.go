@@ -0,0 +1,171 @@
+package inbound
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+func Test_LambdaHandler_HandleRequestV2(t *testing.T) {
+	body := `{"name":"Homero","last_name":"Simpson","email":"homero@springfield.com","phone":"1234567890","age":39,"birth_date":"` +
+		time.Now().AddDate(-39, 0, 0).Format(time.RFC3339) + `"}`
+	mergePatch := map[string]string{"content-type": "application/merge-patch+json"}
+	customer := &domain.Customer{
+		ID:        1,
+		Name:      "Homero",
+		LastName:  "Simpson",
+		Email:     "homero@springfield.com",
+		Phone:     "1234567890",
+		Age:       39,
+		BirthDate: time.Now().AddDate(-39, 0, 0),
+	}
+
+	routes := []struct {
+		method   string
+		resource string
+		path     string
+		query    string
+		body     string
+		headers  map[string]string
+		wantCode int
+	}{
+		{http.MethodGet, "/customers", "/customers", "", "", nil, http.StatusOK},
+		{http.MethodPost, "/customers", "/customers", "", body, nil, http.StatusCreated},
+		{http.MethodPost, "/customers/batch", "/customers/batch", "", "[" + body + "]", nil, http.StatusCreated},
+		{http.MethodGet, "/customers/{id}", "/customers/1", "", "", nil, http.StatusOK},
+		{http.MethodPut, "/customers/{id}", "/customers/1", "", body, nil, http.StatusOK},
+		{http.MethodPatch, "/customers/{id}", "/customers/1", "", `{"name":"Marge"}`, mergePatch, http.StatusOK},
+		{http.MethodDelete, "/customers/{id}", "/customers/1", "", "", nil, http.StatusNoContent},
+		{http.MethodPost, "/customers/{id}/restore", "/customers/1/restore", "", "", nil, http.StatusOK},
+		{http.MethodGet, "/customers/kpi", "/customers/kpi", "", "", nil, http.StatusOK},
+		{http.MethodGet, "/customers/kpi/top", "/customers/kpi/top", "by=age&n=1", "", nil, http.StatusOK},
+		{http.MethodGet, "/customers/aggregate", "/customers/aggregate", "group_by=age_bucket", "", nil, http.StatusOK},
+	}
+
+	for _, route := range routes {
+		v2Requests := map[string]events.APIGatewayV2HTTPRequest{
+			"route key": {
+				RouteKey:       route.method + " " + route.resource,
+				RawPath:        route.path,
+				RawQueryString: route.query,
+				Headers:        route.headers,
+				Body:           route.body,
+			},
+			"default route": {
+				RouteKey:       defaultRouteKey,
+				RawPath:        route.path,
+				RawQueryString: route.query,
+				Headers:        route.headers,
+				Body:           route.body,
+			},
+		}
+
+		for name, request := range v2Requests {
+			t.Run(name+" "+route.method+" "+route.resource, func(t *testing.T) {
+				if strings.HasPrefix(route.resource, "/customers/{id}") && request.RouteKey != defaultRouteKey {
+					request.PathParameters = map[string]string{"id": "1"}
+				}
+				request.RequestContext.HTTP.Method = route.method
+				request.RequestContext.HTTP.Path = route.path
+
+				h := newTestLambdaHandler(&lambdaUcsStub{customers: []domain.Customer{*customer}, customer: customer})
+
+				response, err := h.HandleRequestV2(context.Background(), request)
+				require.NoError(t, err)
+
+				assert.Equal(t, route.wantCode, response.StatusCode, response.Body)
+				assert.NotEmpty(t, response.Headers)
+			})
+		}
+	}
+}
+
+func Test_proxyRequestFromV2(t *testing.T) {
+	tests := []struct {
+		name           string
+		request        events.APIGatewayV2HTTPRequest
+		wantMethod     string
+		wantResource   string
+		wantPathParams map[string]string
+		wantQuery      map[string][]string
+	}{
+		{
+			name: "should take the resource from the route key",
+			request: events.APIGatewayV2HTTPRequest{
+				RouteKey:       "GET /customers/{id}",
+				RawPath:        "/customers/7",
+				PathParameters: map[string]string{"id": "7"},
+			},
+			wantMethod:     http.MethodGet,
+			wantResource:   "/customers/{id}",
+			wantPathParams: map[string]string{"id": "7"},
+		},
+		{
+			name:           "should derive the resource from the path on the default route",
+			request:        events.APIGatewayV2HTTPRequest{RouteKey: defaultRouteKey, RawPath: "/customers/7"},
+			wantMethod:     http.MethodGet,
+			wantResource:   "/customers/{id}",
+			wantPathParams: map[string]string{"id": "7"},
+		},
+		{
+			name:         "should prefer static resources over the id pattern",
+			request:      events.APIGatewayV2HTTPRequest{RouteKey: defaultRouteKey, RawPath: "/customers/kpi"},
+			wantMethod:   http.MethodGet,
+			wantResource: "/customers/kpi",
+		},
+		{
+			name:         "should not take the batch resource for a customer id",
+			request:      events.APIGatewayV2HTTPRequest{RouteKey: defaultRouteKey, RawPath: "/customers/batch"},
+			wantMethod:   http.MethodGet,
+			wantResource: "/customers/batch",
+		},
+		{
+			name:           "should derive a sub-resource of the customer on the default route",
+			request:        events.APIGatewayV2HTTPRequest{RouteKey: defaultRouteKey, RawPath: "/customers/7/restore"},
+			wantMethod:     http.MethodGet,
+			wantResource:   "/customers/{id}/restore",
+			wantPathParams: map[string]string{"id": "7"},
+		},
+		{
+			name:         "should leave an unknown sub-resource unresolved",
+			request:      events.APIGatewayV2HTTPRequest{RouteKey: defaultRouteKey, RawPath: "/customers/7/orders"},
+			wantMethod:   http.MethodGet,
+			wantResource: "/customers/7/orders",
+		},
+		{
+			name: "should keep repeated query params from the raw query string",
+			request: events.APIGatewayV2HTTPRequest{
+				RouteKey:       "GET /customers/kpi/top",
+				RawPath:        "/customers/kpi/top",
+				RawQueryString: "by=age&by=name",
+			},
+			wantMethod:   http.MethodGet,
+			wantResource: "/customers/kpi/top",
+			wantQuery:    map[string][]string{"by": {"age", "name"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.request.RequestContext.HTTP.Method = http.MethodGet
+
+			proxy := proxyRequestFromV2(tt.request)
+
+			assert.Equal(t, tt.wantMethod, proxy.HTTPMethod)
+			assert.Equal(t, tt.wantResource, proxy.Resource)
+			assert.Equal(t, tt.request.RawPath, proxy.Path)
+			assert.Equal(t, tt.wantPathParams, proxy.PathParameters)
+			if tt.wantQuery != nil {
+				assert.Equal(t, tt.wantQuery, proxy.MultiValueQueryStringParameters)
+			}
+		})
+	}
+}
@@ -3,15 +3,19 @@ package inbound
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
-	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 
 	pkgaws "github.com/devpablocristo/tech-house/pkg/aws"
 	awsdefs "github.com/devpablocristo/tech-house/pkg/aws/defs"
+	pkgflags "github.com/devpablocristo/tech-house/pkg/flags"
+	mwr "github.com/devpablocristo/tech-house/pkg/rest/middlewares/gin"
 	types "github.com/devpablocristo/tech-house/pkg/types"
 	utils "github.com/devpablocristo/tech-house/pkg/utils"
 	transport "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound/transport"
@@ -19,11 +23,97 @@ import (
 )
 
 type LambdaHandler struct {
-	useCases     ports.UseCases
-	lambdaClient awsdefs.LambdaClient
+	useCases          ports.UseCases
+	lambdaClient      awsdefs.LambdaClient
+	responseHeaders   map[string]string
+	queryPolicy       DuplicateQueryPolicy
+	strictContentType atomic.Bool
+	tracing           bool
+	requestIDHeader   string
+	trailingSlash     TrailingSlashPolicy
+	cors              mwr.CORSConfig
+	emptyListStatus   int
+	defaultTimeout    time.Duration
+	routeTimeouts     map[string]time.Duration
+	defaultBodyLimit  int64
+	routeBodyLimits   map[string]int64
+	healthTimeout     time.Duration
+	healthChecks      map[string]ports.Pinger
+	fieldEncryption   map[string]*transport.FieldEncryption
+	middlewares       []LambdaMiddleware
 }
 
-func NewLambdaHandler(useCases ports.UseCases) (*LambdaHandler, error) {
+// LambdaOption configura opciones del LambdaHandler
+type LambdaOption func(*LambdaHandler)
+
+// WithLambdaDuplicateQueryPolicy define cómo se tratan los query params repetidos
+func WithLambdaDuplicateQueryPolicy(policy DuplicateQueryPolicy) LambdaOption {
+	return func(h *LambdaHandler) {
+		h.queryPolicy = policy
+	}
+}
+
+// WithLambdaStrictContentType rechaza con 415 las escrituras que no envían Content-Type: application/json
+func WithLambdaStrictContentType(strict bool) LambdaOption {
+	return func(h *LambdaHandler) {
+		h.strictContentType.Store(strict)
+	}
+}
+
+// WithLambdaFlags toma de store los flags que se pueden cambiar sin reiniciar (ver FlagStrictContentType) y los
+// actualiza cuando cambian; el valor configurado por las otras opciones es el default si el flag no está
+func WithLambdaFlags(store pkgflags.Store) LambdaOption {
+	return func(h *LambdaHandler) {
+		watchBoolFlag(store, FlagStrictContentType, &h.strictContentType)
+	}
+}
+
+// WithLambdaTracing envuelve cada invocación en un subsegmento de X-Ray.
+// Sin un segmento en el contexto (X-Ray deshabilitado en la función) no tiene efecto.
+func WithLambdaTracing(enabled bool) LambdaOption {
+	return func(h *LambdaHandler) {
+		h.tracing = enabled
+	}
+}
+
+// WithLambdaRequestIDHeader define el header del que se toma el request ID y bajo el que se devuelve
+func WithLambdaRequestIDHeader(name string) LambdaOption {
+	return func(h *LambdaHandler) {
+		if name != "" {
+			h.requestIDHeader = name
+		}
+	}
+}
+
+// WithLambdaTrailingSlashPolicy define cómo se tratan los paths con barra final
+func WithLambdaTrailingSlashPolicy(policy TrailingSlashPolicy) LambdaOption {
+	return func(h *LambdaHandler) {
+		h.trailingSlash = policy
+	}
+}
+
+// WithLambdaEmptyListStatus define el status de los listados vacíos: 200 con la lista vacía (por defecto) o 204 sin body
+func WithLambdaEmptyListStatus(status int) LambdaOption {
+	return func(h *LambdaHandler) {
+		h.emptyListStatus = status
+	}
+}
+
+// WithLambdaCORS define los orígenes, métodos y headers CORS de las respuestas y del preflight
+func WithLambdaCORS(config mwr.CORSConfig) LambdaOption {
+	return func(h *LambdaHandler) {
+		h.cors = config
+	}
+}
+
+// WithResponseHeaders reemplaza los headers estáticos agregados a todas las respuestas
+func WithResponseHeaders(headers map[string]string) LambdaOption {
+	return func(h *LambdaHandler) {
+		h.responseHeaders = headers
+	}
+}
+
+func NewLambdaHandler(useCases ports.UseCases, opts ...LambdaOption) (*LambdaHandler, error) {
 	stack, err := pkgaws.Bootstrap()
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize AWS stack: %w", err)
@@ -34,46 +124,88 @@ func NewLambdaHandler(useCases ports.UseCases) (*LambdaHandler, error) {
 		return nil, fmt.Errorf("failed to create Lambda client")
 	}
 
-	return &LambdaHandler{
-		useCases:     useCases,
-		lambdaClient: lambdaClient,
-	}, nil
+	h := &LambdaHandler{
+		useCases:        useCases,
+		lambdaClient:    lambdaClient,
+		responseHeaders: mwr.DefaultSecurityHeaders(),
+		queryPolicy:     DuplicateQueryReject,
+		requestIDHeader: mwr.DefaultRequestIDHeader,
+		cors:            mwr.DefaultCORSConfig(),
+		emptyListStatus: http.StatusOK,
+		trailingSlash:   TrailingSlashRedirect,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h, nil
 }
 
+// HandleRequest atiende un request de API Gateway (REST API, payload v1) pasándolo por pipeline
 func (h *LambdaHandler) HandleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	switch {
-	case request.HTTPMethod == "GET" && request.Resource == "/customers":
-		return h.GetCustomers(ctx)
-	case request.HTTPMethod == "GET" && request.Resource == "/customers/{id}":
-		return h.GetCustomer(ctx, request)
-	case request.HTTPMethod == "POST" && request.Resource == "/customers":
-		return h.CreateCustomer(ctx, request)
-	case request.HTTPMethod == "PUT" && request.Resource == "/customers/{id}":
-		return h.UpdateCustomer(ctx, request)
-	case request.HTTPMethod == "DELETE" && request.Resource == "/customers/{id}":
-		return h.DeleteCustomer(ctx, request)
-	case request.HTTPMethod == "GET" && request.Resource == "/customers/kpi":
-		return h.GetKPI(ctx)
-	default:
-		return events.APIGatewayProxyResponse{
-			StatusCode: http.StatusNotFound,
-			Body:       "Not Found",
-		}, nil
+	return h.pipeline()(ctx, request)
+}
+
+// addResponseHeaders agrega los headers estáticos sin pisar los que ya definió el handler
+func (h *LambdaHandler) addResponseHeaders(response *events.APIGatewayProxyResponse) {
+	if len(h.responseHeaders) == 0 {
+		return
+	}
+	if response.Headers == nil {
+		response.Headers = make(map[string]string, len(h.responseHeaders))
+	}
+	for name, value := range h.responseHeaders {
+		if _, exists := response.Headers[name]; !exists {
+			response.Headers[name] = value
+		}
 	}
 }
 
-func (h *LambdaHandler) GetCustomers(ctx context.Context) (events.APIGatewayProxyResponse, error) {
-	customers, err := h.useCases.GetCustomers(ctx)
-	if err != nil {
-		apiErr, status := types.NewAPIError(err)
-		return events.APIGatewayProxyResponse{
-			StatusCode: status,
-			Body:       apiErr.Error(),
-		}, nil
+// withCORS agrega los headers CORS a la respuesta final, exitosa o de error, sin pisar los del handler.
+// Además del origen, las respuestas normales llevan los métodos y headers permitidos, no solo el preflight.
+func (h *LambdaHandler) withCORS(response *events.APIGatewayProxyResponse, request events.APIGatewayProxyRequest) {
+	origin := headerValue(request.Headers, "Origin")
+	headers := mwr.CORSHeaders(h.cors, origin, true)
+	if headers == nil {
+		return
+	}
+	if !isPreflight(request) {
+		delete(headers, "Access-Control-Max-Age")
+		for name, value := range mwr.CORSHeaders(h.cors, origin, false) {
+			headers[name] = value
+		}
 	}
 
-	response := transport.GetCustomersResponse{
-		Customers: transport.DomainListToCustomerJsonList(customers),
+	if response.Headers == nil {
+		response.Headers = make(map[string]string, len(headers))
+	}
+	for name, value := range headers {
+		if _, exists := response.Headers[name]; !exists {
+			response.Headers[name] = value
+		}
+	}
+}
+
+// isPreflight indica si el request es un preflight CORS
+func isPreflight(request events.APIGatewayProxyRequest) bool {
+	return request.HTTPMethod == http.MethodOptions && headerValue(request.Headers, "Access-Control-Request-Method") != ""
+}
+
+// Preflight responde el OPTIONS de cualquier ruta: 204 (withCORS agrega los headers) o 403 si el
+// origen no está permitido, como el middleware CORS de Gin
+func (h *LambdaHandler) Preflight(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	origin := headerValue(request.Headers, "Origin")
+	if origin != "" && mwr.CORSHeaders(h.cors, origin, true) == nil {
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusForbidden}, nil
+	}
+	return events.APIGatewayProxyResponse{StatusCode: http.StatusNoContent}, nil
+}
+
+// writeList responde un listado; vacío responde con el status configurado (ver WithLambdaEmptyListStatus)
+func (h *LambdaHandler) writeList(response transport.GetCustomersResponse) events.APIGatewayProxyResponse {
+	status := listStatus(h.emptyListStatus, len(response.Customers))
+	if status == http.StatusNoContent {
+		return events.APIGatewayProxyResponse{StatusCode: status}
 	}
 
 	body, err := json.Marshal(response)
@@ -85,19 +217,150 @@ func (h *LambdaHandler) GetCustomers(ctx context.Context) (events.APIGatewayProx
 				err,
 			),
 		)
-		return events.APIGatewayProxyResponse{
-			StatusCode: status,
-			Body:       apiErr.Error(),
-		}, nil
+		return writeError(status, apiErr)
 	}
 
 	return events.APIGatewayProxyResponse{
-		StatusCode: http.StatusOK,
+		StatusCode: status,
 		Headers: map[string]string{
 			"Content-Type": "application/json",
 		},
 		Body: string(body),
-	}, nil
+	}
+}
+
+// writeError arma la respuesta de error con el cuerpo JSON {"error": {...}}, el mismo en todas las ramas.
+// err se convierte a APIError si todavía no lo es; el status de la respuesta es siempre el recibido.
+func writeError(status int, err error) events.APIGatewayProxyResponse {
+	var apiErr *types.APIError
+	if !errors.As(err, &apiErr) {
+		apiErr, _ = types.NewAPIError(err)
+	}
+
+	envelope := apiErr.ToEnvelope()
+	envelope.Error.Status = status
+	body, marshalErr := json.Marshal(envelope)
+	if marshalErr != nil {
+		// El contexto del error puede traer valores no serializables; se responde sin él
+		envelope.Error.Context = nil
+		body, _ = json.Marshal(envelope)
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+		Body: string(body),
+	}
+}
+
+// dispatch ejecuta el handler de la ruta del request o responde 404
+func (h *LambdaHandler) dispatch(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	handler := h.route(request.HTTPMethod, request.Resource)
+	if handler == nil {
+		return writeError(http.StatusNotFound, resourceNotFound(request.HTTPMethod, request.Resource)), nil
+	}
+	return handler(ctx, request)
+}
+
+// route resuelve el handler para el método y el resource (con el formato de API Gateway, "/customers/{id}").
+// Lo comparten HandleRequest (REST API, payload v1) y HandleRequestV2 (HTTP API, payload v2).
+func (h *LambdaHandler) route(method, resource string) LambdaHandlerFunc {
+	switch {
+	case method == http.MethodOptions:
+		return h.Preflight
+	case method == "GET" && resource == "/health":
+		return h.Health
+	case method == "GET" && resource == "/health/deep":
+		return h.DeepHealth
+	case method == "GET" && resource == "/customers":
+		return h.GetCustomers
+	case method == "GET" && resource == "/customers/{id}":
+		return h.GetCustomer
+	case method == "POST" && resource == "/customers":
+		return h.CreateCustomer
+	case method == "POST" && resource == "/customers/batch":
+		return h.CreateCustomers
+	case method == "PUT" && resource == "/customers/{id}":
+		return h.UpdateCustomer
+	case method == "PATCH" && resource == "/customers/{id}":
+		return h.PatchCustomer
+	case method == "DELETE" && resource == "/customers/{id}":
+		return h.DeleteCustomer
+	case method == "POST" && resource == "/customers/{id}/restore":
+		return h.RestoreCustomer
+	case method == "GET" && resource == "/customers/kpi":
+		return h.GetKPI
+	case method == "GET" && resource == "/customers/kpi/top":
+		return h.GetTopCustomers
+	case method == "GET" && resource == "/customers/aggregate":
+		return h.CountByField
+	default:
+		return nil
+	}
+}
+
+func (h *LambdaHandler) GetCustomers(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	query := lambdaQueryValues(request.MultiValueQueryStringParameters, request.QueryStringParameters)
+	params, err := parseQuery(query, h.queryPolicy)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	limit, offset, err := parsePageQuery(params)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	sort, err := parseSortQuery(params)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	include, err := parseIncludeQuery(params)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	includeDeleted, err := parseIncludeDeletedQuery(params)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	page, err := getCustomersPage(ctx, h.useCases, params["q"], sort, includeDeleted, limit, offset)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	response := transport.GetCustomersPagePresenter(page)
+	if expand := parseExpandQuery(params); len(expand) > 0 {
+		expanded, err := h.useCases.ExpandCustomers(ctx, page.Customers, expand)
+		if err != nil {
+			apiErr, status := types.NewAPIError(err)
+			return writeError(status, apiErr), nil
+		}
+		response = transport.GetCustomersExpandedPagePresenter(page, expanded)
+	}
+	if include[includeKPI] {
+		kpi, err := h.useCases.GetKPI(ctx)
+		if err != nil {
+			apiErr, status := types.NewAPIError(err)
+			return writeError(status, apiErr), nil
+		}
+		response.Meta = &transport.ListMeta{KPI: transport.ToGetKPIJson(kpi)}
+	}
+	if err := h.encryptionFor(request).EncryptList(response.Customers); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+	return h.writeList(response), nil
 }
 
 func (h *LambdaHandler) GetCustomer(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
@@ -110,31 +373,37 @@ func (h *LambdaHandler) GetCustomer(ctx context.Context, request events.APIGatew
 				err,
 			),
 		)
-		return events.APIGatewayProxyResponse{
-			StatusCode: status,
-			Body:       apiErr.Error(),
-		}, nil
+		return writeError(status, apiErr), nil
 	}
 
 	if err := utils.ValidateID(ID); err != nil {
 		apiErr, status := types.NewAPIError(err)
-		return events.APIGatewayProxyResponse{
-			StatusCode: status,
-			Body:       apiErr.Error(),
-		}, nil
+		return writeError(status, apiErr), nil
 	}
 
-	customer, err := h.useCases.GetCustomerByID(ctx, ID)
+	query := lambdaQueryValues(request.MultiValueQueryStringParameters, request.QueryStringParameters)
+	params, err := parseQuery(query, h.queryPolicy)
 	if err != nil {
 		apiErr, status := types.NewAPIError(err)
-		return events.APIGatewayProxyResponse{
-			StatusCode: status,
-			Body:       apiErr.Error(),
-		}, nil
+		return writeError(status, apiErr), nil
+	}
+
+	includeDeleted, err := parseIncludeDeletedQuery(params)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	expanded, err := getCustomer(ctx, h.useCases, ID, parseExpandQuery(params), includeDeleted)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
 	}
 
-	response := transport.GetCustomerResponse{
-		Customers: *transport.DomainToCustomerJson(customer),
+	response := transport.GetCustomerExpandedPresenter(expanded)
+	if err := h.encryptionFor(request).Encrypt(&response.Customers); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
 	}
 
 	body, err := json.Marshal(response)
@@ -146,10 +415,7 @@ func (h *LambdaHandler) GetCustomer(ctx context.Context, request events.APIGatew
 				err,
 			),
 		)
-		return events.APIGatewayProxyResponse{
-			StatusCode: status,
-			Body:       apiErr.Error(),
-		}, nil
+		return writeError(status, apiErr), nil
 	}
 
 	return events.APIGatewayProxyResponse{
@@ -162,61 +428,97 @@ func (h *LambdaHandler) GetCustomer(ctx context.Context, request events.APIGatew
 }
 
 func (h *LambdaHandler) CreateCustomer(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	if err := validateContentType(headerValue(request.Headers, "Content-Type"), h.strictContentType.Load()); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
 	var req transport.CustomerJson
-	if err := json.Unmarshal([]byte(request.Body), &req); err != nil {
-		errStr := err.Error()
-		var message string
-		switch {
-		case strings.Contains(errStr, "Email' failed on the 'required' tag"):
-			message = "invalid email format"
-		case strings.Contains(errStr, "Age' failed on the 'required' tag"):
-			message = "invalid age"
-		case strings.Contains(errStr, "failed on the 'required' tag"):
-			message = "missing required field"
-		case strings.Contains(errStr, "cannot unmarshal"):
-			message = "invalid data type"
-		default:
-			message = "request cannot be nil"
-		}
+	if err := customerBodyError(decodeLambdaBody(request.Body, &req), &req, h.encryptionFor(request)); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
 
+	customer := transport.CustomerJsonToDomain(&req)
+	warnings, replayed, err := createCustomer(ctx, h.useCases, headerValue(request.Headers, idempotencyKeyHeader), customer)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	response := transport.ToCreateCustomerResponse(customer, warnings)
+	if err := h.encryptionFor(request).Encrypt(&response.Customer); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
 		apiErr, status := types.NewAPIError(
 			types.NewError(
-				types.ErrValidation,
-				message,
+				types.ErrInternal,
+				"Error marshalling response",
 				err,
 			),
 		)
-		return events.APIGatewayProxyResponse{
-			StatusCode: status,
-			Body:       apiErr.Error(),
-		}, nil
+		return writeError(status, apiErr), nil
 	}
 
-	if err := validateRequest(&req); err != nil {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"Location":     customerLocation(request.Resource, customer.ID),
+	}
+	if replayed {
+		headers[idempotentReplayedHeader] = "true"
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusCreated,
+		Headers:    headers,
+		Body:       string(body),
+	}, nil
+}
+
+// CreateCustomers da de alta un lote de customers todo o nada (ver createCustomerBatch)
+func (h *LambdaHandler) CreateCustomers(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	if err := validateContentType(headerValue(request.Headers, "Content-Type"), h.strictContentType.Load()); err != nil {
 		apiErr, status := types.NewAPIError(err)
-		return events.APIGatewayProxyResponse{
-			StatusCode: status,
-			Body:       apiErr.Error(),
-		}, nil
+		return writeError(status, apiErr), nil
 	}
 
-	if err := h.useCases.CreateCustomer(ctx, transport.CustomerJsonToDomain(&req)); err != nil {
+	status, response, err := createCustomerBatch(ctx, h.useCases, h.encryptionFor(request), request.Body)
+	if err != nil {
 		apiErr, status := types.NewAPIError(err)
-		return events.APIGatewayProxyResponse{
-			StatusCode: status,
-			Body:       apiErr.Error(),
-		}, nil
+		return writeError(status, apiErr), nil
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		apiErr, status := types.NewAPIError(
+			types.NewError(
+				types.ErrInternal,
+				"Error marshalling response",
+				err,
+			),
+		)
+		return writeError(status, apiErr), nil
 	}
 
 	return events.APIGatewayProxyResponse{
-		StatusCode: http.StatusCreated,
+		StatusCode: status,
 		Headers: map[string]string{
 			"Content-Type": "application/json",
 		},
+		Body: string(body),
 	}, nil
 }
 
 func (h *LambdaHandler) UpdateCustomer(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	if err := validateContentType(headerValue(request.Headers, "Content-Type"), h.strictContentType.Load()); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
 	ID, err := strconv.ParseInt(request.PathParameters["id"], 10, 64)
 	if err != nil {
 		apiErr, status := types.NewAPIError(
@@ -226,52 +528,78 @@ func (h *LambdaHandler) UpdateCustomer(ctx context.Context, request events.APIGa
 				err,
 			),
 		)
-		return events.APIGatewayProxyResponse{
-			StatusCode: status,
-			Body:       apiErr.Error(),
-		}, nil
+		return writeError(status, apiErr), nil
 	}
 
 	if err := utils.ValidateID(ID); err != nil {
 		apiErr, status := types.NewAPIError(err)
-		return events.APIGatewayProxyResponse{
-			StatusCode: status,
-			Body:       apiErr.Error(),
-		}, nil
+		return writeError(status, apiErr), nil
 	}
 
 	var req transport.CustomerJson
-	if err := json.Unmarshal([]byte(request.Body), &req); err != nil {
+	if err := customerBodyError(decodeLambdaBody(request.Body, &req), &req, h.encryptionFor(request)); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	customer := transport.CustomerJsonToDomain(&req)
+	customer.ID = ID
+
+	if err := h.useCases.UpdateCustomer(ctx, customer); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+	}, nil
+}
+
+func (h *LambdaHandler) PatchCustomer(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	ID, err := strconv.ParseInt(request.PathParameters["id"], 10, 64)
+	if err != nil {
 		apiErr, status := types.NewAPIError(
 			types.NewError(
-				types.ErrValidation,
-				"invalid request body",
+				types.ErrInvalidInput,
+				"invalid customer ID format",
 				err,
 			),
 		)
-		return events.APIGatewayProxyResponse{
-			StatusCode: status,
-			Body:       apiErr.Error(),
-		}, nil
+		return writeError(status, apiErr), nil
 	}
 
-	if err := validateRequest(&req); err != nil {
+	if err := utils.ValidateID(ID); err != nil {
 		apiErr, status := types.NewAPIError(err)
-		return events.APIGatewayProxyResponse{
-			StatusCode: status,
-			Body:       apiErr.Error(),
-		}, nil
+		return writeError(status, apiErr), nil
 	}
 
-	customer := transport.CustomerJsonToDomain(&req)
-	customer.ID = ID
+	customer, err := patchCustomer(ctx, h.useCases, h.encryptionFor(request), ID, headerValue(request.Headers, "Content-Type"), []byte(request.Body))
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
 
-	if err := h.useCases.UpdateCustomer(ctx, customer); err != nil {
+	customerJson, err := h.encryptionFor(request).DomainToCustomerJson(customer)
+	if err != nil {
 		apiErr, status := types.NewAPIError(err)
-		return events.APIGatewayProxyResponse{
-			StatusCode: status,
-			Body:       apiErr.Error(),
-		}, nil
+		return writeError(status, apiErr), nil
+	}
+
+	body, err := json.Marshal(transport.GetCustomerResponse{
+		Customers: *customerJson,
+	})
+	if err != nil {
+		apiErr, status := types.NewAPIError(
+			types.NewError(
+				types.ErrInternal,
+				"Error marshalling response",
+				err,
+			),
+		)
+		return writeError(status, apiErr), nil
 	}
 
 	return events.APIGatewayProxyResponse{
@@ -279,6 +607,7 @@ func (h *LambdaHandler) UpdateCustomer(ctx context.Context, request events.APIGa
 		Headers: map[string]string{
 			"Content-Type": "application/json",
 		},
+		Body: string(body),
 	}, nil
 }
 
@@ -292,26 +621,17 @@ func (h *LambdaHandler) DeleteCustomer(ctx context.Context, request events.APIGa
 				err,
 			),
 		)
-		return events.APIGatewayProxyResponse{
-			StatusCode: status,
-			Body:       apiErr.Error(),
-		}, nil
+		return writeError(status, apiErr), nil
 	}
 
 	if err := utils.ValidateID(ID); err != nil {
 		apiErr, status := types.NewAPIError(err)
-		return events.APIGatewayProxyResponse{
-			StatusCode: status,
-			Body:       apiErr.Error(),
-		}, nil
+		return writeError(status, apiErr), nil
 	}
 
-	if err := h.useCases.DeleteCustomer(ctx, ID); err != nil {
+	if err := h.useCases.SoftDeleteCustomer(ctx, ID); err != nil {
 		apiErr, status := types.NewAPIError(err)
-		return events.APIGatewayProxyResponse{
-			StatusCode: status,
-			Body:       apiErr.Error(),
-		}, nil
+		return writeError(status, apiErr), nil
 	}
 
 	return events.APIGatewayProxyResponse{
@@ -322,14 +642,79 @@ func (h *LambdaHandler) DeleteCustomer(ctx context.Context, request events.APIGa
 	}, nil
 }
 
-func (h *LambdaHandler) GetKPI(ctx context.Context) (events.APIGatewayProxyResponse, error) {
-	kpi, err := h.useCases.GetKPI(ctx)
+// RestoreCustomer recupera un customer eliminado lógicamente y lo retorna; si no estaba eliminado no hace nada
+func (h *LambdaHandler) RestoreCustomer(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	ID, err := strconv.ParseInt(request.PathParameters["id"], 10, 64)
 	if err != nil {
+		apiErr, status := types.NewAPIError(
+			types.NewError(
+				types.ErrInvalidInput,
+				"invalid customer ID format",
+				err,
+			),
+		)
+		return writeError(status, apiErr), nil
+	}
+
+	if err := utils.ValidateID(ID); err != nil {
 		apiErr, status := types.NewAPIError(err)
-		return events.APIGatewayProxyResponse{
-			StatusCode: status,
-			Body:       apiErr.Error(),
-		}, nil
+		return writeError(status, apiErr), nil
+	}
+
+	customer, err := h.useCases.RestoreCustomer(ctx, ID)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	customerJson, err := h.encryptionFor(request).DomainToCustomerJson(customer)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	body, err := json.Marshal(transport.GetCustomerResponse{
+		Customers: *customerJson,
+	})
+	if err != nil {
+		apiErr, status := types.NewAPIError(
+			types.NewError(
+				types.ErrInternal,
+				"Error marshalling response",
+				err,
+			),
+		)
+		return writeError(status, apiErr), nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+		Body: string(body),
+	}, nil
+}
+
+// GetKPI retorna los KPIs de todos los customers o, con "from" y/o "to", de los dados de alta en ese rango
+func (h *LambdaHandler) GetKPI(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	query := lambdaQueryValues(request.MultiValueQueryStringParameters, request.QueryStringParameters)
+	params, err := parseQuery(query, h.queryPolicy)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	from, to, err := parseRangeQuery(params)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	kpi, err := getKPI(ctx, h.useCases, from, to)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
 	}
 
 	// Usar directamente el mismo formato que en Gin
@@ -343,12 +728,88 @@ func (h *LambdaHandler) GetKPI(ctx context.Context) (events.APIGatewayProxyRespo
 				err,
 			),
 		)
+		return writeError(status, apiErr), nil
+	}
+
+	etag := contentETag(body)
+	if notModified(headerValue(request.Headers, ifNoneMatchHeader), etag) {
 		return events.APIGatewayProxyResponse{
-			StatusCode: status,
-			Body:       apiErr.Error(),
+			StatusCode: http.StatusNotModified,
+			Headers:    map[string]string{etagHeader: etag, cacheControlHeader: revalidateCacheControl},
 		}, nil
 	}
 
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Headers: map[string]string{
+			"Content-Type":     "application/json",
+			etagHeader:         etag,
+			cacheControlHeader: revalidateCacheControl,
+		},
+		Body: string(body),
+	}, nil
+}
+
+func (h *LambdaHandler) GetTopCustomers(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	query := lambdaQueryValues(request.MultiValueQueryStringParameters, request.QueryStringParameters)
+	params, err := parseQuery(query, h.queryPolicy)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	by, n, err := parseTopQuery(params)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	customers, err := h.useCases.GetTopCustomers(ctx, by, n)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	response := transport.GetTopCustomersPresenter(customers)
+	if err := h.encryptionFor(request).EncryptList(response.Customers); err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+	return h.writeList(response), nil
+}
+
+func (h *LambdaHandler) CountByField(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	query := lambdaQueryValues(request.MultiValueQueryStringParameters, request.QueryStringParameters)
+	params, err := parseQuery(query, h.queryPolicy)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	groupBy, buckets, err := parseAggregateQuery(params)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	counts, err := countCustomers(ctx, h.useCases, groupBy, buckets)
+	if err != nil {
+		apiErr, status := types.NewAPIError(err)
+		return writeError(status, apiErr), nil
+	}
+
+	body, err := json.Marshal(transport.ToAggregateJson(groupBy, counts))
+	if err != nil {
+		apiErr, status := types.NewAPIError(
+			types.NewError(
+				types.ErrInternal,
+				"Error marshalling response",
+				err,
+			),
+		)
+		return writeError(status, apiErr), nil
+	}
+
 	return events.APIGatewayProxyResponse{
 		StatusCode: http.StatusOK,
 		Headers: map[string]string{
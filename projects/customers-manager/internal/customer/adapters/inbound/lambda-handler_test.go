@@ -0,0 +1,1109 @@
+package inbound
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mwr "github.com/devpablocristo/tech-house/pkg/rest/middlewares/gin"
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	transport "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound/transport"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+// lambdaUcsStub implementa ports.UseCases para los tests internos del LambdaHandler
+type lambdaUcsStub struct {
+	customers []domain.Customer
+	customer  *domain.Customer
+	err       error
+	updated   *domain.Customer
+	warnings  []domain.Warning
+	page      [2]int
+	expand    []string
+	kpiRange  []time.Time
+	batch     []domain.Customer
+	idemKey   string
+	buckets   []domain.AgeBucket
+	search    string
+	sort      *domain.CustomerSort
+	// softDeleted y restored registran el id recibido; includeDeleted, si se pidieron los eliminados
+	softDeleted    int64
+	restored       int64
+	includeDeleted bool
+}
+
+func (s *lambdaUcsStub) GetCustomers(ctx context.Context) ([]domain.Customer, error) {
+	return s.customers, s.err
+}
+
+func (s *lambdaUcsStub) GetCustomersPaged(ctx context.Context, limit, offset int) (*domain.CustomerPage, error) {
+	s.page = [2]int{limit, offset}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.CustomerPage{Customers: s.customers, Total: len(s.customers), Offset: offset}, nil
+}
+
+func (s *lambdaUcsStub) GetCustomersSorted(ctx context.Context, sort domain.CustomerSort, limit, offset int) (*domain.CustomerPage, error) {
+	s.sort = &sort
+	return s.GetCustomersPaged(ctx, limit, offset)
+}
+
+func (s *lambdaUcsStub) GetCustomerByID(ctx context.Context, id int64) (*domain.Customer, error) {
+	return s.customer, s.err
+}
+
+func (s *lambdaUcsStub) GetCustomerExpanded(ctx context.Context, id int64, expand []string) (*domain.ExpandedCustomer, error) {
+	s.expand = expand
+	if s.err != nil {
+		return nil, s.err
+	}
+	expanded := &domain.ExpandedCustomer{Customer: s.customer}
+	if slices.Contains(expand, domain.ExpandAuditSummary) {
+		expanded.AuditSummary = &domain.AuditSummary{EventCount: 2, LastEventType: domain.EventCustomerUpdated}
+	}
+	return expanded, nil
+}
+
+func (s *lambdaUcsStub) ExpandCustomers(ctx context.Context, customers []domain.Customer, expand []string) ([]domain.ExpandedCustomer, error) {
+	s.expand = expand
+	if s.err != nil {
+		return nil, s.err
+	}
+	expanded := make([]domain.ExpandedCustomer, len(customers))
+	for i := range customers {
+		expanded[i] = domain.ExpandedCustomer{Customer: &customers[i]}
+		if slices.Contains(expand, domain.ExpandAuditSummary) {
+			expanded[i].AuditSummary = &domain.AuditSummary{EventCount: 1, LastEventType: domain.EventCustomerCreated}
+		}
+	}
+	return expanded, nil
+}
+
+func (s *lambdaUcsStub) CreateCustomers(ctx context.Context, customers []domain.Customer) ([]domain.Customer, error) {
+	s.batch = customers
+	if s.err != nil {
+		return nil, s.err
+	}
+	created := make([]domain.Customer, len(customers))
+	for i, customer := range customers {
+		customer.ID = int64(100 + i)
+		created[i] = customer
+	}
+	return created, nil
+}
+
+func (s *lambdaUcsStub) SearchCustomers(ctx context.Context, query string, limit, offset int) (*domain.CustomerPage, error) {
+	s.search = query
+	s.page = [2]int{limit, offset}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.CustomerPage{Customers: s.customers, Total: len(s.customers), Offset: offset}, nil
+}
+
+func (s *lambdaUcsStub) GetCustomerByEmail(ctx context.Context, email string) (*domain.Customer, error) {
+	return s.customer, s.err
+}
+
+func (s *lambdaUcsStub) CreateCustomer(ctx context.Context, customer *domain.Customer) ([]domain.Warning, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	customer.ID = 1
+	return s.warnings, nil
+}
+
+// CreateCustomerIdempotent repite el alta de s.customer cuando está informado
+func (s *lambdaUcsStub) CreateCustomerIdempotent(ctx context.Context, key string, customer *domain.Customer) ([]domain.Warning, bool, error) {
+	s.idemKey = key
+	if s.err != nil || s.customer == nil {
+		return s.warnings, false, s.err
+	}
+	*customer = *s.customer
+	return nil, true, nil
+}
+
+func (s *lambdaUcsStub) UpdateCustomer(ctx context.Context, customer *domain.Customer) error {
+	s.updated = customer
+	return s.err
+}
+
+func (s *lambdaUcsStub) PatchCustomer(ctx context.Context, id int64, patch domain.CustomerPatch) (*domain.Customer, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	patched := *s.customer
+	patched.ID = id
+	if patch.Precondition != nil {
+		if err := patch.Precondition(patched); err != nil {
+			return nil, err
+		}
+	}
+	patch.Apply(&patched)
+	s.updated = &patched
+	return &patched, nil
+}
+
+func (s *lambdaUcsStub) DeleteCustomer(ctx context.Context, id int64) error {
+	return s.err
+}
+
+func (s *lambdaUcsStub) SoftDeleteCustomer(ctx context.Context, id int64) error {
+	s.softDeleted = id
+	return s.err
+}
+
+func (s *lambdaUcsStub) RestoreCustomer(ctx context.Context, id int64) (*domain.Customer, error) {
+	s.restored = id
+	return s.customer, s.err
+}
+
+func (s *lambdaUcsStub) GetCustomerIncludingDeleted(ctx context.Context, id int64) (*domain.Customer, error) {
+	s.includeDeleted = true
+	return s.customer, s.err
+}
+
+func (s *lambdaUcsStub) GetCustomersIncludingDeleted(ctx context.Context, limit, offset int) (*domain.CustomerPage, error) {
+	s.includeDeleted = true
+	return s.GetCustomersPaged(ctx, limit, offset)
+}
+
+func (s *lambdaUcsStub) GetKPI(ctx context.Context) (*domain.KPI, error) {
+	return &domain.KPI{}, s.err
+}
+
+func (s *lambdaUcsStub) GetKPIForRange(ctx context.Context, from, to time.Time) (*domain.KPI, error) {
+	s.kpiRange = []time.Time{from, to}
+	return &domain.KPI{AverageAge: 40}, s.err
+}
+
+func (s *lambdaUcsStub) GetTopCustomers(ctx context.Context, by string, n int) ([]domain.Customer, error) {
+	return s.customers, s.err
+}
+
+func (s *lambdaUcsStub) CountByField(ctx context.Context, field string) (map[string]int64, error) {
+	return map[string]int64{}, s.err
+}
+
+func (s *lambdaUcsStub) CountByAgeBuckets(ctx context.Context, buckets []domain.AgeBucket) (map[string]int64, error) {
+	s.buckets = buckets
+	return map[string]int64{}, s.err
+}
+
+func (s *lambdaUcsStub) ExportCustomer(ctx context.Context, id int64, requestedBy string) (*domain.CustomerExport, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.CustomerExport{Customer: s.customer, RequestedBy: requestedBy}, nil
+}
+
+func (s *lambdaUcsStub) AnonymizeCustomer(ctx context.Context, id int64) error {
+	return s.err
+}
+
+// newTestLambdaHandler arma el handler sin pasar por pkgaws.Bootstrap
+func newTestLambdaHandler(ucs *lambdaUcsStub, opts ...LambdaOption) *LambdaHandler {
+	h := &LambdaHandler{
+		useCases:        ucs,
+		responseHeaders: mwr.DefaultSecurityHeaders(),
+		requestIDHeader: mwr.DefaultRequestIDHeader,
+		trailingSlash:   TrailingSlashRedirect,
+		cors:            mwr.DefaultCORSConfig(),
+		emptyListStatus: http.StatusOK,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func Test_LambdaHandler_ResponseHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		ucs      *lambdaUcsStub
+		opts     []LambdaOption
+		request  events.APIGatewayProxyRequest
+		wantCode int
+		want     map[string]string
+	}{
+		{
+			name:     "should add security headers to successful responses",
+			ucs:      &lambdaUcsStub{customers: []domain.Customer{{ID: 1, Name: "Homero"}}},
+			request:  events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Resource: "/customers"},
+			wantCode: http.StatusOK,
+			want:     mwr.DefaultSecurityHeaders(),
+		},
+		{
+			name:     "should add security headers to error responses",
+			ucs:      &lambdaUcsStub{err: types.NewError(types.ErrNotFound, "customer not found", nil)},
+			request:  events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Resource: "/customers/{id}", PathParameters: map[string]string{"id": "1"}},
+			wantCode: http.StatusNotFound,
+			want:     mwr.DefaultSecurityHeaders(),
+		},
+		{
+			name:     "should add security headers to unknown routes",
+			ucs:      &lambdaUcsStub{},
+			request:  events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Resource: "/unknown"},
+			wantCode: http.StatusNotFound,
+			want:     mwr.DefaultSecurityHeaders(),
+		},
+		{
+			name:     "should use configured headers",
+			ucs:      &lambdaUcsStub{customers: []domain.Customer{}},
+			opts:     []LambdaOption{WithResponseHeaders(map[string]string{"X-Frame-Options": "SAMEORIGIN"})},
+			request:  events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Resource: "/customers"},
+			wantCode: http.StatusOK,
+			want:     map[string]string{"X-Frame-Options": "SAMEORIGIN", "Content-Type": "application/json"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestLambdaHandler(tt.ucs, tt.opts...)
+
+			response, err := h.HandleRequest(context.Background(), tt.request)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantCode, response.StatusCode)
+			for name, value := range tt.want {
+				assert.Equal(t, value, response.Headers[name], name)
+			}
+		})
+	}
+
+	t.Run("should not override headers set by the handler", func(t *testing.T) {
+		h := newTestLambdaHandler(&lambdaUcsStub{}, WithResponseHeaders(map[string]string{"Content-Type": "text/plain"}))
+
+		response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Resource: "/customers"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "application/json", response.Headers["Content-Type"])
+	})
+}
+
+func Test_LambdaHandler_RequestID(t *testing.T) {
+	request := events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Resource: "/customers"}
+
+	t.Run("should echo the id under a custom header name", func(t *testing.T) {
+		h := newTestLambdaHandler(&lambdaUcsStub{}, WithLambdaRequestIDHeader("X-Correlation-ID"))
+		request.Headers = map[string]string{"x-correlation-id": "abc-123"}
+
+		response, err := h.HandleRequest(context.Background(), request)
+		require.NoError(t, err)
+		assert.Equal(t, "abc-123", response.Headers["X-Correlation-ID"])
+		assert.NotContains(t, response.Headers, mwr.DefaultRequestIDHeader)
+	})
+
+	t.Run("should generate an id when none is received", func(t *testing.T) {
+		h := newTestLambdaHandler(&lambdaUcsStub{}, WithLambdaRequestIDHeader("X-Correlation-ID"))
+		request.Headers = nil
+
+		response, err := h.HandleRequest(context.Background(), request)
+		require.NoError(t, err)
+		assert.Len(t, response.Headers["X-Correlation-ID"], 32)
+	})
+}
+
+func Test_LambdaHandler_DuplicateQueryParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []LambdaOption
+		request  events.APIGatewayProxyRequest
+		wantCode int
+	}{
+		{
+			name: "should reject duplicate params by default",
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod:                      http.MethodGet,
+				Resource:                        "/customers",
+				QueryStringParameters:           map[string]string{"limit": "20"},
+				MultiValueQueryStringParameters: map[string][]string{"limit": {"10", "20"}},
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "should accept duplicate params with last policy",
+			opts: []LambdaOption{WithLambdaDuplicateQueryPolicy(DuplicateQueryLast)},
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod:                      http.MethodGet,
+				Resource:                        "/customers",
+				MultiValueQueryStringParameters: map[string][]string{"limit": {"10", "20"}},
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "should accept single value params",
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod:            http.MethodGet,
+				Resource:              "/customers",
+				QueryStringParameters: map[string]string{"limit": "10"},
+			},
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestLambdaHandler(&lambdaUcsStub{customers: []domain.Customer{}}, tt.opts...)
+
+			response, err := h.HandleRequest(context.Background(), tt.request)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantCode, response.StatusCode)
+		})
+	}
+}
+
+func Test_parseQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  url.Values
+		policy  DuplicateQueryPolicy
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "should flatten single values",
+			values: url.Values{"limit": {"10"}, "sort": {"name"}},
+			policy: DuplicateQueryReject,
+			want:   map[string]string{"limit": "10", "sort": "name"},
+		},
+		{
+			name:    "should reject duplicates",
+			values:  url.Values{"limit": {"10", "20"}},
+			policy:  DuplicateQueryReject,
+			wantErr: true,
+		},
+		{
+			name:    "should reject duplicates with unset policy",
+			values:  url.Values{"limit": {"10", "20"}},
+			wantErr: true,
+		},
+		{
+			name:   "should take last duplicate",
+			values: url.Values{"limit": {"10", "20"}},
+			policy: DuplicateQueryLast,
+			want:   map[string]string{"limit": "20"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseQuery(tt.values, tt.policy)
+			if tt.wantErr {
+				assert.True(t, types.IsValidationError(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_LambdaHandler_StrictContentType(t *testing.T) {
+	body := `{"name":"Homero","last_name":"Simpson","email":"homero@springfield.com","phone":"1234567890","age":39,"birth_date":"` +
+		time.Now().AddDate(-39, 0, 0).Format(time.RFC3339) + `"}`
+
+	tests := []struct {
+		name     string
+		strict   bool
+		request  events.APIGatewayProxyRequest
+		wantCode int
+	}{
+		{
+			name:     "should reject missing content type in strict mode",
+			strict:   true,
+			request:  events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Resource: "/customers", Body: body},
+			wantCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:   "should reject wrong content type in strict mode",
+			strict: true,
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodPut,
+				Resource:   "/customers/{id}",
+				Headers:    map[string]string{"content-type": "text/plain"},
+				Body:       body,
+			},
+			wantCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:   "should accept json content type with any header case in strict mode",
+			strict: true,
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodPost,
+				Resource:   "/customers",
+				Headers:    map[string]string{"content-type": "application/json"},
+				Body:       body,
+			},
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "should accept missing content type when strict mode is off",
+			request:  events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Resource: "/customers", Body: body},
+			wantCode: http.StatusCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestLambdaHandler(&lambdaUcsStub{}, WithLambdaStrictContentType(tt.strict))
+
+			response, err := h.HandleRequest(context.Background(), tt.request)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantCode, response.StatusCode)
+		})
+	}
+}
+
+func Test_LambdaHandler_CreateCustomer_Warnings(t *testing.T) {
+	body := `{"name":"Homero","last_name":"Simpson","email":"homero@springfield.com","phone":"1234567890","age":39,"birth_date":"` +
+		time.Now().AddDate(-39, 0, 0).Format(time.RFC3339) + `"}`
+
+	h := newTestLambdaHandler(&lambdaUcsStub{
+		warnings: []domain.Warning{{Step: "phone_verification", Message: "provider unavailable"}},
+	})
+
+	response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: http.MethodPost,
+		Resource:   "/customers",
+		Body:       body,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+
+	var created transport.CreateCustomerResponse
+	require.NoError(t, json.Unmarshal([]byte(response.Body), &created))
+	assert.Equal(t, "homero@springfield.com", created.Customer.Email)
+	assert.Equal(t, "/customers/1", response.Headers["Location"])
+	assert.Equal(t, []transport.WarningJson{{Step: "phone_verification", Message: "provider unavailable"}}, created.Warnings)
+}
+
+func Test_LambdaHandler_CreateCustomer_IdempotencyKey(t *testing.T) {
+	body := `{"name":"Homero","last_name":"Simpson","email":"homero@springfield.com","phone":"1234567890","age":39,"birth_date":"` +
+		time.Now().AddDate(-39, 0, 0).Format(time.RFC3339) + `"}`
+
+	t.Run("should return the original customer for a repeated key", func(t *testing.T) {
+		ucs := &lambdaUcsStub{customer: &domain.Customer{ID: 42, Email: "homero@springfield.com"}}
+		h := newTestLambdaHandler(ucs)
+
+		response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod: http.MethodPost,
+			Resource:   "/customers",
+			Headers:    map[string]string{"idempotency-key": "retry-1"},
+			Body:       body,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, response.StatusCode)
+		assert.Equal(t, "retry-1", ucs.idemKey)
+		assert.Equal(t, "true", response.Headers[idempotentReplayedHeader])
+		assert.Equal(t, "/customers/42", response.Headers["Location"])
+
+		var created transport.CreateCustomerResponse
+		require.NoError(t, json.Unmarshal([]byte(response.Body), &created))
+		assert.Equal(t, int64(42), created.Customer.ID)
+	})
+
+	t.Run("should create normally without the header", func(t *testing.T) {
+		ucs := &lambdaUcsStub{customer: &domain.Customer{ID: 42}}
+		h := newTestLambdaHandler(ucs)
+
+		response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod: http.MethodPost,
+			Resource:   "/customers",
+			Body:       body,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, response.StatusCode)
+		assert.Empty(t, ucs.idemKey)
+		assert.NotContains(t, response.Headers, idempotentReplayedHeader)
+	})
+}
+
+func Test_LambdaHandler_GetCustomers_Pagination(t *testing.T) {
+	customers := []domain.Customer{{ID: 1, Name: "Homero"}, {ID: 2, Name: "Marge"}}
+
+	tests := []struct {
+		name     string
+		query    map[string]string
+		wantCode int
+		wantPage [2]int
+	}{
+		{name: "should use the default limit without params", wantCode: http.StatusOK, wantPage: [2]int{defaultPageLimit, 0}},
+		{name: "should pass limit and offset", query: map[string]string{"limit": "2", "offset": "4"}, wantCode: http.StatusOK, wantPage: [2]int{2, 4}},
+		{name: "should reject a non numeric limit", query: map[string]string{"limit": "ten"}, wantCode: http.StatusBadRequest},
+		{name: "should reject a non numeric offset", query: map[string]string{"offset": "x"}, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ucs := &lambdaUcsStub{customers: customers}
+			h := newTestLambdaHandler(ucs)
+
+			response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod:            http.MethodGet,
+				Resource:              "/customers",
+				QueryStringParameters: tt.query,
+			})
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCode, response.StatusCode, response.Body)
+			if tt.wantCode != http.StatusOK {
+				assert.Contains(t, response.Body, "must be an integer")
+				return
+			}
+
+			assert.Equal(t, tt.wantPage, ucs.page)
+
+			var page transport.GetCustomersResponse
+			require.NoError(t, json.Unmarshal([]byte(response.Body), &page))
+			assert.Len(t, page.Customers, 2)
+			assert.Equal(t, 2, page.Total)
+		})
+	}
+}
+
+func Test_LambdaHandler_GetCustomers_Sort(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    map[string]string
+		wantCode int
+		wantSort *domain.CustomerSort
+	}{
+		{name: "should use the default order without sort", wantCode: http.StatusOK},
+		{name: "should sort ascending by default", query: map[string]string{"sort": "age"}, wantCode: http.StatusOK, wantSort: &domain.CustomerSort{Field: "age"}},
+		{name: "should pass the descending order", query: map[string]string{"sort": "created_at", "order": "DESC"}, wantCode: http.StatusOK, wantSort: &domain.CustomerSort{Field: "created_at", Desc: true}},
+		{name: "should reject an unknown order", query: map[string]string{"sort": "age", "order": "up"}, wantCode: http.StatusBadRequest},
+		{name: "should reject order without sort", query: map[string]string{"order": "desc"}, wantCode: http.StatusBadRequest},
+		{name: "should reject sort together with q", query: map[string]string{"sort": "age", "q": "ana"}, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ucs := &lambdaUcsStub{customers: []domain.Customer{{ID: 1}}}
+			h := newTestLambdaHandler(ucs)
+
+			response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod:            http.MethodGet,
+				Resource:              "/customers",
+				QueryStringParameters: tt.query,
+			})
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCode, response.StatusCode, response.Body)
+			assert.Equal(t, tt.wantSort, ucs.sort)
+		})
+	}
+}
+
+func Test_LambdaHandler_GetCustomers_Search(t *testing.T) {
+	customers := []domain.Customer{{ID: 1, Name: "José"}}
+
+	t.Run("should search when q is present", func(t *testing.T) {
+		ucs := &lambdaUcsStub{customers: customers}
+		h := newTestLambdaHandler(ucs)
+
+		response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod:            http.MethodGet,
+			Resource:              "/customers",
+			QueryStringParameters: map[string]string{"q": "jose", "limit": "5"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, response.StatusCode, response.Body)
+
+		assert.Equal(t, "jose", ucs.search)
+		assert.Equal(t, [2]int{5, 0}, ucs.page)
+
+		var page transport.GetCustomersResponse
+		require.NoError(t, json.Unmarshal([]byte(response.Body), &page))
+		assert.Len(t, page.Customers, 1)
+	})
+
+	t.Run("should list when q is empty", func(t *testing.T) {
+		ucs := &lambdaUcsStub{customers: customers}
+		h := newTestLambdaHandler(ucs)
+
+		response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod:            http.MethodGet,
+			Resource:              "/customers",
+			QueryStringParameters: map[string]string{"q": ""},
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, response.StatusCode, response.Body)
+
+		assert.Empty(t, ucs.search)
+		assert.Equal(t, [2]int{defaultPageLimit, 0}, ucs.page)
+	})
+}
+
+func Test_LambdaHandler_SoftDelete(t *testing.T) {
+	deletedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should soft delete on DELETE", func(t *testing.T) {
+		ucs := &lambdaUcsStub{}
+		h := newTestLambdaHandler(ucs)
+
+		response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod:     http.MethodDelete,
+			Resource:       "/customers/{id}",
+			PathParameters: map[string]string{"id": "7"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNoContent, response.StatusCode, response.Body)
+		assert.Equal(t, int64(7), ucs.softDeleted)
+	})
+
+	t.Run("should restore and return the customer", func(t *testing.T) {
+		ucs := &lambdaUcsStub{customer: &domain.Customer{ID: 7, Name: "Ana"}}
+		h := newTestLambdaHandler(ucs)
+
+		response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod:     http.MethodPost,
+			Resource:       "/customers/{id}/restore",
+			PathParameters: map[string]string{"id": "7"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, response.StatusCode, response.Body)
+		assert.Equal(t, int64(7), ucs.restored)
+
+		var body transport.GetCustomerResponse
+		require.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+		assert.Equal(t, "Ana", body.Customers.Name)
+		assert.Nil(t, body.Customers.DeletedAt)
+	})
+
+	t.Run("should answer not found when restoring an unknown customer", func(t *testing.T) {
+		ucs := &lambdaUcsStub{err: types.NewError(types.ErrNotFound, "customer not found", nil)}
+		h := newTestLambdaHandler(ucs)
+
+		response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod:     http.MethodPost,
+			Resource:       "/customers/{id}/restore",
+			PathParameters: map[string]string{"id": "7"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	})
+
+	t.Run("should show a deleted customer with include_deleted", func(t *testing.T) {
+		ucs := &lambdaUcsStub{customer: &domain.Customer{ID: 7, DeletedAt: &deletedAt}}
+		h := newTestLambdaHandler(ucs)
+
+		response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod:            http.MethodGet,
+			Resource:              "/customers/{id}",
+			PathParameters:        map[string]string{"id": "7"},
+			QueryStringParameters: map[string]string{"include_deleted": "true"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, response.StatusCode, response.Body)
+		assert.True(t, ucs.includeDeleted)
+
+		var body transport.GetCustomerResponse
+		require.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+		require.NotNil(t, body.Customers.DeletedAt)
+		assert.True(t, deletedAt.Equal(*body.Customers.DeletedAt))
+	})
+
+	tests := []struct {
+		name               string
+		resource           string
+		query              map[string]string
+		wantCode           int
+		wantIncludeDeleted bool
+	}{
+		{name: "should list the deleted customers with include_deleted", resource: "/customers", query: map[string]string{"include_deleted": "true"}, wantCode: http.StatusOK, wantIncludeDeleted: true},
+		{name: "should list only the active customers with include_deleted false", resource: "/customers", query: map[string]string{"include_deleted": "false"}, wantCode: http.StatusOK},
+		{name: "should reject a non boolean include_deleted", resource: "/customers", query: map[string]string{"include_deleted": "maybe"}, wantCode: http.StatusBadRequest},
+		{name: "should reject include_deleted together with q", resource: "/customers", query: map[string]string{"include_deleted": "true", "q": "ana"}, wantCode: http.StatusBadRequest},
+		{name: "should reject include_deleted together with sort", resource: "/customers", query: map[string]string{"include_deleted": "true", "sort": "age"}, wantCode: http.StatusBadRequest},
+		{name: "should reject include_deleted together with expand", resource: "/customers/{id}", query: map[string]string{"include_deleted": "true", "expand": domain.ExpandAuditSummary}, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ucs := &lambdaUcsStub{customers: []domain.Customer{{ID: 7}}, customer: &domain.Customer{ID: 7}}
+			h := newTestLambdaHandler(ucs)
+
+			response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod:            http.MethodGet,
+				Resource:              tt.resource,
+				PathParameters:        map[string]string{"id": "7"},
+				QueryStringParameters: tt.query,
+			})
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCode, response.StatusCode, response.Body)
+			assert.Equal(t, tt.wantIncludeDeleted, ucs.includeDeleted)
+		})
+	}
+}
+
+func Test_LambdaHandler_ErrorBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		ucs         *lambdaUcsStub
+		request     events.APIGatewayProxyRequest
+		wantStatus  int
+		wantCode    types.APIErrorType
+		wantDetails bool
+	}{
+		{
+			name:        "should wrap invalid input",
+			ucs:         &lambdaUcsStub{},
+			request:     events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Resource: "/customers/{id}", PathParameters: map[string]string{"id": "abc"}},
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    types.APIErrBadRequest,
+			wantDetails: true,
+		},
+		{
+			name:       "should wrap use case errors",
+			ucs:        &lambdaUcsStub{err: types.NewError(types.ErrNotFound, "customer not found", nil)},
+			request:    events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Resource: "/customers/{id}", PathParameters: map[string]string{"id": "7"}},
+			wantStatus: http.StatusNotFound,
+			wantCode:   types.APIErrNotFound,
+		},
+		{
+			name:       "should tell a missing customer apart",
+			ucs:        &lambdaUcsStub{err: types.NewError(types.ErrNotFound, "customer not found", nil).WithCode(domain.CodeCustomerNotFound)},
+			request:    events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Resource: "/customers/{id}", PathParameters: map[string]string{"id": "7"}},
+			wantStatus: http.StatusNotFound,
+			wantCode:   domain.CodeCustomerNotFound,
+		},
+		{
+			name:       "should tell an unknown route apart",
+			ucs:        &lambdaUcsStub{},
+			request:    events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Resource: "/orders"},
+			wantStatus: http.StatusNotFound,
+			wantCode:   types.CodeResourceNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestLambdaHandler(tt.ucs)
+
+			response, err := h.HandleRequest(context.Background(), tt.request)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantStatus, response.StatusCode)
+			assert.Equal(t, "application/json", response.Headers["Content-Type"])
+
+			var envelope types.APIErrorEnvelope
+			require.NoError(t, json.Unmarshal([]byte(response.Body), &envelope))
+			assert.Equal(t, tt.wantCode, envelope.Error.Code)
+			assert.Equal(t, tt.wantStatus, envelope.Error.Status)
+			assert.NotEmpty(t, envelope.Error.Message)
+			if tt.wantDetails {
+				assert.NotEmpty(t, envelope.Error.Details)
+			}
+		})
+	}
+}
+
+func Test_LambdaHandler_GetCustomer_Expand(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      map[string]string
+		wantExpand []string
+		wantAudit  bool
+	}{
+		{
+			name: "should not expand by default",
+		},
+		{
+			name:       "should embed the audit summary when requested",
+			query:      map[string]string{"expand": "audit_summary"},
+			wantExpand: []string{domain.ExpandAuditSummary},
+			wantAudit:  true,
+		},
+		{
+			name:       "should pass every requested expansion to the use case",
+			query:      map[string]string{"expand": "audit_summary, tags"},
+			wantExpand: []string{domain.ExpandAuditSummary, "tags"},
+			wantAudit:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ucs := &lambdaUcsStub{customer: &domain.Customer{ID: 7, Name: "Ana"}}
+			h := newTestLambdaHandler(ucs)
+
+			response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod:            http.MethodGet,
+				Resource:              "/customers/{id}",
+				PathParameters:        map[string]string{"id": "7"},
+				QueryStringParameters: tt.query,
+			})
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, response.StatusCode)
+			assert.Equal(t, tt.wantExpand, ucs.expand)
+
+			var body transport.GetCustomerResponse
+			require.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+			assert.Equal(t, int64(7), body.Customers.ID)
+			if !tt.wantAudit {
+				assert.Nil(t, body.Customers.AuditSummary)
+				assert.NotContains(t, response.Body, "audit_summary")
+				return
+			}
+			require.NotNil(t, body.Customers.AuditSummary)
+			assert.Equal(t, 2, body.Customers.AuditSummary.EventCount)
+			assert.Equal(t, domain.EventCustomerUpdated, body.Customers.AuditSummary.LastEventType)
+		})
+	}
+
+	t.Run("should reject unknown expansions", func(t *testing.T) {
+		ucs := &lambdaUcsStub{err: types.NewError(types.ErrValidation, "invalid expand: \"tags\"", nil)}
+		h := newTestLambdaHandler(ucs)
+
+		response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod:            http.MethodGet,
+			Resource:              "/customers/{id}",
+			PathParameters:        map[string]string{"id": "7"},
+			QueryStringParameters: map[string]string{"expand": "tags"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	})
+}
+
+func Test_LambdaHandler_GetKPI_Range(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 6, 30, 23, 59, 59, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		query      map[string]string
+		wantStatus int
+		wantRange  []time.Time
+	}{
+		{
+			name:       "should return all-time KPIs without a range",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "should scope KPIs to the range",
+			query:      map[string]string{"from": from.Format(time.RFC3339), "to": to.Format(time.RFC3339)},
+			wantStatus: http.StatusOK,
+			wantRange:  []time.Time{from, to},
+		},
+		{
+			name:       "should accept an open-ended range",
+			query:      map[string]string{"from": from.Format(time.RFC3339)},
+			wantStatus: http.StatusOK,
+			wantRange:  []time.Time{from, {}},
+		},
+		{
+			name:       "should reject dates that are not RFC3339",
+			query:      map[string]string{"from": "2025-01-01"},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ucs := &lambdaUcsStub{}
+			h := newTestLambdaHandler(ucs)
+
+			response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod:            http.MethodGet,
+				Resource:              "/customers/kpi",
+				QueryStringParameters: tt.query,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, response.StatusCode)
+			assert.Equal(t, tt.wantRange, ucs.kpiRange)
+			if tt.wantStatus != http.StatusOK {
+				var envelope types.APIErrorEnvelope
+				require.NoError(t, json.Unmarshal([]byte(response.Body), &envelope))
+				assert.Equal(t, types.APIErrBadRequest, envelope.Error.Code)
+				return
+			}
+
+			var kpi transport.GetKPIJson
+			require.NoError(t, json.Unmarshal([]byte(response.Body), &kpi))
+			if tt.wantRange != nil {
+				assert.Equal(t, 40.0, kpi.AverageAge)
+			}
+		})
+	}
+}
+
+func Test_LambdaHandler_CreateCustomer_FieldErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantMessage string
+		wantFields  map[string]string
+	}{
+		{
+			name:        "should report a bad email and a missing age together",
+			body:        `{"name":"Homero","last_name":"Simpson","email":"homeroinvalidemail","phone":"1234567890","birth_date":"` + time.Now().AddDate(-39, 0, 0).Format(time.RFC3339) + `"}`,
+			wantMessage: "invalid request fields",
+			wantFields:  map[string]string{"email": "format", "age": "required"},
+		},
+		{
+			name:        "should report the field with the wrong type",
+			body:        `{"name":"Homero","last_name":"Simpson","email":"homero@springfield.com","age":"39"}`,
+			wantMessage: "invalid data type",
+			wantFields:  map[string]string{"age": "type"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestLambdaHandler(&lambdaUcsStub{})
+
+			response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodPost,
+				Resource:   "/customers",
+				Body:       tt.body,
+			})
+			require.NoError(t, err)
+			require.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+			var envelope struct {
+				Error struct {
+					Message string `json:"message"`
+					Context struct {
+						Fields []types.FieldError `json:"fields"`
+					} `json:"context"`
+				} `json:"error"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(response.Body), &envelope))
+			assert.Equal(t, tt.wantMessage, envelope.Error.Message)
+
+			got := make(map[string]string, len(envelope.Error.Context.Fields))
+			for _, field := range envelope.Error.Context.Fields {
+				got[field.Field] = field.Rule
+			}
+			assert.Equal(t, tt.wantFields, got)
+		})
+	}
+}
+
+func Test_LambdaHandler_CORS(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []LambdaOption
+		request     events.APIGatewayProxyRequest
+		wantStatus  int
+		wantOrigin  string
+		wantMaxAge  bool
+		wantExposed bool
+	}{
+		{
+			name: "should answer preflight with no content",
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodOptions,
+				Resource:   "/customers/{id}",
+				Headers:    map[string]string{"Origin": "https://app.example.com", "Access-Control-Request-Method": http.MethodPatch},
+			},
+			wantStatus: http.StatusNoContent,
+			wantOrigin: "*",
+			wantMaxAge: true,
+		},
+		{
+			name: "should reject preflight from a disallowed origin",
+			opts: []LambdaOption{WithLambdaCORS(mwr.CORSConfig{AllowedOrigins: []string{"https://app.example.com"}})},
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodOptions,
+				Resource:   "/customers",
+				Headers:    map[string]string{"Origin": "https://evil.example.com", "Access-Control-Request-Method": http.MethodGet},
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:        "should add headers to successful responses",
+			opts:        []LambdaOption{WithLambdaCORS(mwr.CORSConfig{AllowedOrigins: []string{"https://app.example.com"}, AllowedMethods: []string{http.MethodGet}, AllowedHeaders: []string{"Content-Type"}, ExposeHeaders: []string{"ETag"}})},
+			request:     events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Resource: "/customers/{id}", PathParameters: map[string]string{"id": "7"}, Headers: map[string]string{"origin": "https://app.example.com"}},
+			wantStatus:  http.StatusOK,
+			wantOrigin:  "https://app.example.com",
+			wantExposed: true,
+		},
+		{
+			name:        "should add headers to error responses",
+			request:     events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Resource: "/orders", Headers: map[string]string{"Origin": "https://app.example.com"}},
+			wantStatus:  http.StatusNotFound,
+			wantOrigin:  "*",
+			wantExposed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ucs := &lambdaUcsStub{customer: &domain.Customer{ID: 7, Name: "John", LastName: "Doe", Email: "john@example.com"}}
+			h := newTestLambdaHandler(ucs, tt.opts...)
+
+			response, err := h.HandleRequest(context.Background(), tt.request)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantStatus, response.StatusCode)
+			if tt.wantOrigin == "" {
+				assert.NotContains(t, response.Headers, "Access-Control-Allow-Origin")
+				return
+			}
+			assert.Equal(t, tt.wantOrigin, response.Headers["Access-Control-Allow-Origin"])
+			assert.NotEmpty(t, response.Headers["Access-Control-Allow-Methods"])
+			assert.NotEmpty(t, response.Headers["Access-Control-Allow-Headers"])
+			assert.Equal(t, tt.wantMaxAge, response.Headers["Access-Control-Max-Age"] != "")
+			assert.Equal(t, tt.wantExposed, response.Headers["Access-Control-Expose-Headers"] != "")
+		})
+	}
+}
+
+func Test_LambdaHandler_CountByField_AgeBuckets(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       map[string]string
+		wantStatus  int
+		wantBuckets []domain.AgeBucket
+	}{
+		{
+			name:       "should group by decades without buckets",
+			query:      map[string]string{"group_by": "age_bucket"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "should pass the client buckets",
+			query:       map[string]string{"group_by": "age_bucket", "buckets": "1-17, 18-64,65-150"},
+			wantStatus:  http.StatusOK,
+			wantBuckets: []domain.AgeBucket{{Min: 1, Max: 17}, {Min: 18, Max: 64}, {Min: 65, Max: 150}},
+		},
+		{
+			name:       "should reject malformed buckets",
+			query:      map[string]string{"group_by": "age_bucket", "buckets": "1-17,adults"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "should reject buckets for another field",
+			query:      map[string]string{"group_by": "email_domain", "buckets": "1-150"},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ucs := &lambdaUcsStub{}
+			h := newTestLambdaHandler(ucs)
+
+			response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod:            http.MethodGet,
+				Resource:              "/customers/aggregate",
+				QueryStringParameters: tt.query,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, response.StatusCode, response.Body)
+			assert.Equal(t, tt.wantBuckets, ucs.buckets)
+		})
+	}
+}
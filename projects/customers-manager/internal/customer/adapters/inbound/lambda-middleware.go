@@ -0,0 +1,106 @@
+package inbound
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	pkgtracing "github.com/devpablocristo/tech-house/pkg/aws/tracing"
+	pkglogger "github.com/devpablocristo/tech-house/pkg/config/logger"
+	mwr "github.com/devpablocristo/tech-house/pkg/rest/middlewares/gin"
+)
+
+// LambdaHandlerFunc atiende un request de API Gateway; es la firma de los handlers de ruta y de HandleRequest
+type LambdaHandlerFunc func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+// LambdaMiddleware envuelve un LambdaHandlerFunc para agregar un comportamiento transversal (auth, logging,
+// métricas, rate limiting, recovery) antes y/o después del handler que recibe
+type LambdaMiddleware func(next LambdaHandlerFunc) LambdaHandlerFunc
+
+// WithLambdaMiddlewares registra middlewares que se ejecutan en el orden dado, el primero por fuera, después de
+// los del handler (request ID, headers, CORS, barra final, límite de body, tracing y timeout) y justo antes de
+// rutear el request. Así ven el request ya normalizado y sus respuestas, incluso las de error, llevan los
+// headers comunes.
+func WithLambdaMiddlewares(middlewares ...LambdaMiddleware) LambdaOption {
+	return func(h *LambdaHandler) {
+		h.middlewares = append(h.middlewares, middlewares...)
+	}
+}
+
+// chainLambda compone los middlewares alrededor de final: el primero de la lista es el más externo, así que
+// es el primero en ver el request y el último en ver la respuesta
+func chainLambda(final LambdaHandlerFunc, middlewares ...LambdaMiddleware) LambdaHandlerFunc {
+	handler := final
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// pipeline retorna la cadena completa de HandleRequest: los middlewares propios en orden fijo y luego los
+// registrados con WithLambdaMiddlewares; el final es dispatch
+func (h *LambdaHandler) pipeline() LambdaHandlerFunc {
+	middlewares := make([]LambdaMiddleware, 0, 6+len(h.middlewares))
+	middlewares = append(middlewares, h.requestIDMiddleware, h.headersMiddleware, h.trailingSlashMiddleware, h.bodyLimitMiddleware, h.tracingMiddleware, h.timeoutMiddleware)
+	middlewares = append(middlewares, h.middlewares...)
+	return chainLambda(h.dispatch, middlewares...)
+}
+
+// requestIDMiddleware propaga el request ID en el contexto y el logger, y lo devuelve en la respuesta
+func (h *LambdaHandler) requestIDMiddleware(next LambdaHandlerFunc) LambdaHandlerFunc {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		requestID := mwr.ResolveRequestID(headerValue(request.Headers, h.requestIDHeader))
+		ctx = mwr.ContextWithRequestID(ctx, requestID)
+		ctx = pkglogger.WithFields(ctx, "request_id", requestID)
+
+		response, err := next(ctx, request)
+		if response.Headers == nil {
+			response.Headers = make(map[string]string, 1)
+		}
+		response.Headers[h.requestIDHeader] = requestID
+		return response, err
+	}
+}
+
+// headersMiddleware agrega los headers estáticos y los de CORS a toda respuesta, incluidas las redirecciones
+func (h *LambdaHandler) headersMiddleware(next LambdaHandlerFunc) LambdaHandlerFunc {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		response, err := next(ctx, request)
+		h.addResponseHeaders(&response)
+		h.withCORS(&response, request)
+		return response, err
+	}
+}
+
+// trailingSlashMiddleware aplica la política de barra final: redirige o sigue con el request normalizado.
+// Los navegadores no siguen redirecciones en el preflight, así que ahí la barra final se quita.
+func (h *LambdaHandler) trailingSlashMiddleware(next LambdaHandlerFunc) LambdaHandlerFunc {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		slashPolicy := h.trailingSlash
+		if request.HTTPMethod == http.MethodOptions && slashPolicy == TrailingSlashRedirect {
+			slashPolicy = TrailingSlashStrip
+		}
+		if redirect := normalizeLambdaRequest(&request, slashPolicy); redirect != nil {
+			return *redirect, nil
+		}
+		return next(ctx, request)
+	}
+}
+
+// tracingMiddleware envuelve el resto de la cadena en un subsegmento de X-Ray si el tracing está habilitado
+func (h *LambdaHandler) tracingMiddleware(next LambdaHandlerFunc) LambdaHandlerFunc {
+	if !h.tracing {
+		return next
+	}
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		var response events.APIGatewayProxyResponse
+		err := pkgtracing.Capture(ctx, "customers."+strings.ToLower(request.HTTPMethod), func(ctx context.Context) error {
+			var err error
+			response, err = next(ctx, request)
+			return err
+		})
+		return response, err
+	}
+}
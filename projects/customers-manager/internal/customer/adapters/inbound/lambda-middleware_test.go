@@ -0,0 +1,114 @@
+package inbound
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mwr "github.com/devpablocristo/tech-house/pkg/rest/middlewares/gin"
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+// recordingMiddleware anota en calls cuándo entra y sale del request
+func recordingMiddleware(name string, calls *[]string) LambdaMiddleware {
+	return func(next LambdaHandlerFunc) LambdaHandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			*calls = append(*calls, name+":before")
+			response, err := next(ctx, request)
+			*calls = append(*calls, name+":after")
+			return response, err
+		}
+	}
+}
+
+func Test_chainLambda(t *testing.T) {
+	var calls []string
+	final := func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		calls = append(calls, "handler")
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+	}
+
+	handler := chainLambda(final, recordingMiddleware("first", &calls), recordingMiddleware("second", &calls))
+	response, err := handler(context.Background(), events.APIGatewayProxyRequest{})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, []string{"first:before", "second:before", "handler", "second:after", "first:after"}, calls)
+}
+
+func Test_LambdaHandler_Middlewares(t *testing.T) {
+	t.Run("should run the registered middlewares in order around the route", func(t *testing.T) {
+		var calls []string
+		ucs := &lambdaUcsStub{customers: []domain.Customer{{ID: 1}}}
+		h := newTestLambdaHandler(ucs,
+			WithLambdaMiddlewares(recordingMiddleware("auth", &calls), recordingMiddleware("metrics", &calls)),
+			WithLambdaMiddlewares(recordingMiddleware("logging", &calls)),
+		)
+
+		response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod: http.MethodGet,
+			Resource:   "/customers",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, []string{
+			"auth:before", "metrics:before", "logging:before",
+			"logging:after", "metrics:after", "auth:after",
+		}, calls)
+	})
+
+	t.Run("should receive the normalized request", func(t *testing.T) {
+		var resource string
+		capture := func(next LambdaHandlerFunc) LambdaHandlerFunc {
+			return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				resource = request.Resource
+				return next(ctx, request)
+			}
+		}
+		h := newTestLambdaHandler(&lambdaUcsStub{}, WithLambdaTrailingSlashPolicy(TrailingSlashStrip), WithLambdaMiddlewares(capture))
+
+		_, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod: http.MethodGet,
+			Resource:   "/customers/",
+			Path:       "/customers/",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "/customers", resource)
+	})
+
+	t.Run("should add the common headers when a middleware short-circuits", func(t *testing.T) {
+		var routed bool
+		deny := func(next LambdaHandlerFunc) LambdaHandlerFunc {
+			return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				apiErr, status := types.NewAPIError(types.NewError(types.ErrAuthentication, "missing credentials", nil))
+				return writeError(status, apiErr), nil
+			}
+		}
+		spy := func(next LambdaHandlerFunc) LambdaHandlerFunc {
+			return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				routed = true
+				return next(ctx, request)
+			}
+		}
+		h := newTestLambdaHandler(&lambdaUcsStub{}, WithLambdaMiddlewares(deny, spy))
+
+		response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod: http.MethodGet,
+			Resource:   "/customers",
+			Headers:    map[string]string{mwr.DefaultRequestIDHeader: "req-1"},
+		})
+		require.NoError(t, err)
+
+		assert.False(t, routed)
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+		assert.Equal(t, "req-1", response.Headers[mwr.DefaultRequestIDHeader])
+		assert.Equal(t, "nosniff", response.Headers["X-Content-Type-Options"])
+	})
+}
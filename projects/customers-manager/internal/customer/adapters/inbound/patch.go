@@ -0,0 +1,356 @@
+package inbound
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"reflect"
+	"strings"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	transport "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound/transport"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+	ports "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/ports"
+)
+
+// Content-Types soportados por el PATCH
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+// Operaciones de JSON Patch soportadas
+const (
+	jsonPatchAdd     = "add"
+	jsonPatchRemove  = "remove"
+	jsonPatchReplace = "replace"
+	jsonPatchTest    = "test"
+)
+
+// jsonPatchOperation es una operación de un documento JSON Patch (RFC 6902)
+type jsonPatchOperation struct {
+	Op    string           `json:"op"`
+	Path  string           `json:"path"`
+	Value *json.RawMessage `json:"value,omitempty"`
+}
+
+// customerPatchFields indica, para cada campo modificable vía PATCH, si acepta null (borrar el valor)
+var customerPatchFields = map[string]bool{
+	"name":       false,
+	"last_name":  false,
+	"email":      false,
+	"phone":      true,
+	"age":        false,
+	"birth_date": false,
+}
+
+// customerImmutableFields son campos que ningún patch puede tocar
+var customerImmutableFields = map[string]bool{
+	"id": true,
+}
+
+// patchCustomer traduce el patch a los campos a modificar según el Content-Type, valida solo esos
+// campos y delega en PatchCustomer, que los aplica sin tocar el resto. Las operaciones test de un JSON
+// Patch viajan como Precondition, así PatchCustomer las evalúa en la misma transacción que el guardado.
+// Los valores de los campos cifrados para el partner se descifran con encryption antes de aplicarlos.
+func patchCustomer(ctx context.Context, ucs ports.UseCases, encryption *transport.FieldEncryption, ID int64, contentType string, body []byte) (*domain.Customer, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || (mediaType != mergePatchContentType && mediaType != jsonPatchContentType) {
+		return nil, types.NewErrorWithContext(
+			types.ErrUnsupportedMediaType,
+			fmt.Sprintf("content type %s or %s is required", mergePatchContentType, jsonPatchContentType),
+			err,
+			map[string]any{"content_type": contentType},
+		)
+	}
+
+	var patch *transport.CustomerPatchJson
+	var ops []jsonPatchOperation
+	if mediaType == jsonPatchContentType {
+		ops, err = decodeJSONPatch(body)
+		if err != nil {
+			return nil, err
+		}
+		if err := decryptJSONPatch(encryption, ops); err != nil {
+			return nil, err
+		}
+		// Las operaciones remove y replace necesitan el documento actual; el patch son los campos que cambiaron
+		current, err := ucs.GetCustomerByID(ctx, ID)
+		if err != nil {
+			return nil, err
+		}
+		currentJson := transport.DomainToCustomerJson(current)
+		patched, err := applyJSONPatch(currentJson, ops)
+		if err != nil {
+			return nil, err
+		}
+		patch = transport.CustomerPatchFromChanges(currentJson, patched)
+	} else {
+		patch, err = decodeMergePatch(body)
+		if err != nil {
+			return nil, err
+		}
+		if err := encryption.DecryptPatch(patch); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validatePatch(patch); err != nil {
+		return nil, err
+	}
+
+	domainPatch := transport.CustomerPatchJsonToDomain(patch)
+	if hasJSONPatchTests(ops) {
+		domainPatch.Precondition = func(current domain.Customer) error {
+			return testJSONPatch(transport.DomainToCustomerJson(&current), ops)
+		}
+	}
+	return ucs.PatchCustomer(ctx, ID, domainPatch)
+}
+
+// decodeMergePatch decodifica un JSON Merge Patch (RFC 7386): un campo ausente se deja como está,
+// un campo en null se borra (solo si es nullable) y cualquier otro valor lo reemplaza
+func decodeMergePatch(patch []byte) (*transport.CustomerPatchJson, error) {
+	var patchDoc map[string]any
+	if err := json.Unmarshal(patch, &patchDoc); err != nil || patchDoc == nil {
+		return nil, types.NewError(
+			types.ErrValidation,
+			"merge patch must be a JSON object",
+			err,
+		)
+	}
+
+	for field, value := range patchDoc {
+		if customerImmutableFields[field] {
+			return nil, types.NewErrorWithContext(
+				types.ErrValidation,
+				fmt.Sprintf("field %s is immutable", field),
+				nil,
+				map[string]any{"field": field},
+			)
+		}
+		nullable, known := customerPatchFields[field]
+		if !known {
+			return nil, types.NewErrorWithContext(
+				types.ErrValidation,
+				fmt.Sprintf("unknown field %s", field),
+				nil,
+				map[string]any{"field": field},
+			)
+		}
+		if value == nil && !nullable {
+			return nil, types.NewErrorWithContext(
+				types.ErrValidation,
+				fmt.Sprintf("field %s cannot be null", field),
+				nil,
+				map[string]any{"field": field},
+			)
+		}
+	}
+
+	var result transport.CustomerPatchJson
+	if err := json.Unmarshal(patch, &result); err != nil {
+		return nil, decodeError(err)
+	}
+
+	// En el DTO null y ausente son nil; un null en un campo nullable significa borrarlo
+	if value, present := patchDoc["phone"]; present && value == nil {
+		empty := ""
+		result.Phone = &empty
+	}
+
+	return &result, nil
+}
+
+// decodeJSONPatch decodifica el documento JSON Patch (RFC 6902) en su lista de operaciones
+func decodeJSONPatch(patch []byte) ([]jsonPatchOperation, error) {
+	var ops []jsonPatchOperation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, types.NewError(
+			types.ErrValidation,
+			"json patch must be an array of operations",
+			err,
+		)
+	}
+	return ops, nil
+}
+
+// applyJSONPatch aplica las operaciones add, remove y replace de un JSON Patch. Las operaciones test
+// solo se validan acá; se evalúan con testJSONPatch sobre el customer que se va a guardar.
+func applyJSONPatch(current *transport.CustomerJson, ops []jsonPatchOperation) (*transport.CustomerJson, error) {
+	doc, err := toJSONDocument(current)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		field, err := jsonPatchField(op.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		opContext := map[string]any{"index": i, "op": op.Op, "path": op.Path}
+
+		if op.Op != jsonPatchTest && customerImmutableFields[field] {
+			return nil, types.NewErrorWithContext(
+				types.ErrValidation,
+				fmt.Sprintf("field %s is immutable", field),
+				nil,
+				opContext,
+			)
+		}
+
+		switch op.Op {
+		case jsonPatchAdd, jsonPatchReplace, jsonPatchTest:
+			if op.Value == nil {
+				return nil, types.NewErrorWithContext(
+					types.ErrValidation,
+					fmt.Sprintf("operation %s requires a value", op.Op),
+					nil,
+					opContext,
+				)
+			}
+			var value any
+			if err := json.Unmarshal(*op.Value, &value); err != nil {
+				return nil, types.NewErrorWithContext(types.ErrValidation, "invalid operation value", err, opContext)
+			}
+
+			if op.Op == jsonPatchTest {
+				continue
+			}
+
+			if _, exists := doc[field]; op.Op == jsonPatchReplace && !exists {
+				return nil, types.NewErrorWithContext(
+					types.ErrValidation,
+					fmt.Sprintf("cannot replace missing field %s", field),
+					nil,
+					opContext,
+				)
+			}
+			doc[field] = value
+
+		case jsonPatchRemove:
+			if !customerPatchFields[field] {
+				return nil, types.NewErrorWithContext(
+					types.ErrValidation,
+					fmt.Sprintf("field %s cannot be removed", field),
+					nil,
+					opContext,
+				)
+			}
+			delete(doc, field)
+
+		default:
+			return nil, types.NewErrorWithContext(
+				types.ErrValidation,
+				fmt.Sprintf("unsupported operation %q", op.Op),
+				nil,
+				opContext,
+			)
+		}
+	}
+
+	return fromJSONDocument(doc)
+}
+
+// hasJSONPatchTests indica si el JSON Patch tiene alguna operación test
+func hasJSONPatchTests(ops []jsonPatchOperation) bool {
+	for _, op := range ops {
+		if op.Op == jsonPatchTest {
+			return true
+		}
+	}
+	return false
+}
+
+// testJSONPatch evalúa las operaciones test del JSON Patch, ya validadas por applyJSONPatch, sobre el
+// customer. Un test fallido retorna ErrConflict, lo que permite usarlo como compare-and-swap.
+func testJSONPatch(current *transport.CustomerJson, ops []jsonPatchOperation) error {
+	doc, err := toJSONDocument(current)
+	if err != nil {
+		return err
+	}
+
+	for i, op := range ops {
+		if op.Op != jsonPatchTest {
+			continue
+		}
+		field, err := jsonPatchField(op.Path)
+		if err != nil {
+			return err
+		}
+		var value any
+		if err := json.Unmarshal(*op.Value, &value); err != nil {
+			return types.NewErrorWithContext(types.ErrValidation, "invalid operation value", err, nil)
+		}
+		if !reflect.DeepEqual(doc[field], value) {
+			return types.NewErrorWithContext(
+				types.ErrConflict,
+				fmt.Sprintf("test operation failed for %s", op.Path),
+				nil,
+				map[string]any{"index": i, "op": op.Op, "path": op.Path},
+			)
+		}
+	}
+	return nil
+}
+
+// jsonPatchField convierte un JSON Pointer ("/last_name") en el nombre del campo.
+// El customer es un documento plano, por lo que solo se aceptan paths de un nivel.
+func jsonPatchField(path string) (string, error) {
+	if !strings.HasPrefix(path, "/") || strings.Count(path, "/") != 1 {
+		return "", types.NewErrorWithContext(
+			types.ErrValidation,
+			fmt.Sprintf("invalid path %q", path),
+			nil,
+			map[string]any{"path": path},
+		)
+	}
+
+	field := strings.NewReplacer("~1", "/", "~0", "~").Replace(strings.TrimPrefix(path, "/"))
+	if _, known := customerPatchFields[field]; !known && !customerImmutableFields[field] {
+		return "", types.NewErrorWithContext(
+			types.ErrValidation,
+			fmt.Sprintf("unknown field %s", field),
+			nil,
+			map[string]any{"path": path},
+		)
+	}
+
+	return field, nil
+}
+
+// toJSONDocument convierte el customer a su representación JSON genérica
+func toJSONDocument(customer *transport.CustomerJson) (map[string]any, error) {
+	raw, err := json.Marshal(customer)
+	if err != nil {
+		return nil, types.NewError(types.ErrInternal, "error encoding customer", err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, types.NewError(types.ErrInternal, "error decoding customer", err)
+	}
+
+	return doc, nil
+}
+
+// fromJSONDocument convierte el documento parcheado de vuelta al DTO del customer
+func fromJSONDocument(doc any) (*transport.CustomerJson, error) {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, types.NewError(types.ErrInternal, "error applying patch", err)
+	}
+
+	var result transport.CustomerJson
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, types.NewError(
+			types.ErrValidation,
+			"invalid data type",
+			err,
+		)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,300 @@
+package inbound
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+func newPatchableCustomer() *domain.Customer {
+	return &domain.Customer{
+		ID:        1,
+		Name:      "Homero",
+		LastName:  "Simpson",
+		Email:     "homero@springfield.com",
+		Phone:     "1234567890",
+		Age:       39,
+		BirthDate: time.Now().AddDate(-39, 0, 0).Truncate(time.Second),
+	}
+}
+
+func Test_patchCustomer_MergePatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantErr     types.ErrorType
+		check       func(t *testing.T, customer *domain.Customer)
+	}{
+		{
+			name:        "should replace present fields and leave absent ones",
+			contentType: "application/merge-patch+json",
+			body:        `{"name":"Marge"}`,
+			check: func(t *testing.T, customer *domain.Customer) {
+				assert.Equal(t, "Marge", customer.Name)
+				assert.Equal(t, "Simpson", customer.LastName)
+				assert.Equal(t, "1234567890", customer.Phone)
+			},
+		},
+		{
+			name:        "should patch only the age and leave the name untouched",
+			contentType: "application/merge-patch+json",
+			body:        `{"age":40}`,
+			check: func(t *testing.T, customer *domain.Customer) {
+				assert.Equal(t, 40, customer.Age)
+				assert.Equal(t, "Homero", customer.Name)
+				assert.Equal(t, "Simpson", customer.LastName)
+				assert.Equal(t, "homero@springfield.com", customer.Email)
+			},
+		},
+		{
+			name:        "should reject blank last name",
+			contentType: "application/merge-patch+json",
+			body:        `{"last_name":"  "}`,
+			wantErr:     types.ErrValidation,
+		},
+		{
+			name:        "should reject wrong field type",
+			contentType: "application/merge-patch+json",
+			body:        `{"age":"forty"}`,
+			wantErr:     types.ErrValidation,
+		},
+		{
+			name:        "should clear nullable field with null",
+			contentType: "application/merge-patch+json",
+			body:        `{"phone":null}`,
+			check: func(t *testing.T, customer *domain.Customer) {
+				assert.Empty(t, customer.Phone)
+				assert.Equal(t, "Homero", customer.Name)
+			},
+		},
+		{
+			name:        "should reject null on required field",
+			contentType: "application/merge-patch+json",
+			body:        `{"email":null}`,
+			wantErr:     types.ErrValidation,
+		},
+		{
+			name:        "should reject immutable field",
+			contentType: "application/merge-patch+json",
+			body:        `{"id":2}`,
+			wantErr:     types.ErrValidation,
+		},
+		{
+			name:        "should reject unknown field",
+			contentType: "application/merge-patch+json",
+			body:        `{"nickname":"Homie"}`,
+			wantErr:     types.ErrValidation,
+		},
+		{
+			name:        "should validate provided fields",
+			contentType: "application/merge-patch+json",
+			body:        `{"email":"not-an-email"}`,
+			wantErr:     types.ErrValidation,
+		},
+		{
+			name:        "should reject non object patch",
+			contentType: "application/merge-patch+json",
+			body:        `["name"]`,
+			wantErr:     types.ErrValidation,
+		},
+		{
+			name:        "should reject plain json content type",
+			contentType: "application/json",
+			body:        `{"name":"Marge"}`,
+			wantErr:     types.ErrUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ucs := &lambdaUcsStub{customer: newPatchableCustomer()}
+
+			customer, err := patchCustomer(context.Background(), ucs, nil, 1, tt.contentType, []byte(tt.body))
+			if tt.wantErr != "" {
+				errType, ok := types.GetErrorType(err)
+				require.True(t, ok, "expected domain error, got %v", err)
+				assert.Equal(t, tt.wantErr, errType)
+				assert.Nil(t, ucs.updated, "invalid patch must not be persisted")
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, ucs.updated)
+			assert.Equal(t, int64(1), ucs.updated.ID)
+			tt.check(t, customer)
+			tt.check(t, ucs.updated)
+		})
+	}
+}
+
+func Test_patchCustomer_JSONPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr types.ErrorType
+		check   func(t *testing.T, customer *domain.Customer)
+	}{
+		{
+			name: "should replace field",
+			body: `[{"op":"replace","path":"/email","value":"marge@springfield.com"}]`,
+			check: func(t *testing.T, customer *domain.Customer) {
+				assert.Equal(t, "marge@springfield.com", customer.Email)
+				assert.Equal(t, "Homero", customer.Name)
+			},
+		},
+		{
+			name: "should remove nullable field",
+			body: `[{"op":"remove","path":"/phone"}]`,
+			check: func(t *testing.T, customer *domain.Customer) {
+				assert.Empty(t, customer.Phone)
+			},
+		},
+		{
+			name: "should apply ops after a passing test",
+			body: `[{"op":"test","path":"/email","value":"homero@springfield.com"},{"op":"replace","path":"/name","value":"Marge"}]`,
+			check: func(t *testing.T, customer *domain.Customer) {
+				assert.Equal(t, "Marge", customer.Name)
+			},
+		},
+		{
+			name:    "should return conflict when test fails",
+			body:    `[{"op":"test","path":"/email","value":"old@springfield.com"},{"op":"replace","path":"/name","value":"Marge"}]`,
+			wantErr: types.ErrConflict,
+		},
+		{
+			name:    "should reject ops on immutable path",
+			body:    `[{"op":"replace","path":"/id","value":2}]`,
+			wantErr: types.ErrValidation,
+		},
+		{
+			name:    "should reject removing required field",
+			body:    `[{"op":"remove","path":"/name"}]`,
+			wantErr: types.ErrValidation,
+		},
+		{
+			name:    "should reject unsupported op",
+			body:    `[{"op":"move","path":"/name"}]`,
+			wantErr: types.ErrValidation,
+		},
+		{
+			name:    "should reject unknown path",
+			body:    `[{"op":"add","path":"/nickname","value":"Homie"}]`,
+			wantErr: types.ErrValidation,
+		},
+		{
+			name:    "should validate patched result",
+			body:    `[{"op":"replace","path":"/age","value":500}]`,
+			wantErr: types.ErrValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ucs := &lambdaUcsStub{customer: newPatchableCustomer()}
+
+			customer, err := patchCustomer(context.Background(), ucs, nil, 1, "application/json-patch+json", []byte(tt.body))
+			if tt.wantErr != "" {
+				errType, ok := types.GetErrorType(err)
+				require.True(t, ok, "expected domain error, got %v", err)
+				assert.Equal(t, tt.wantErr, errType)
+				assert.Nil(t, ucs.updated, "invalid patch must not be persisted")
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, ucs.updated)
+			tt.check(t, customer)
+		})
+	}
+}
+
+// racingPatchUcs simula un PATCH concurrente que cambia el email entre la lectura previa de patchCustomer
+// y la lectura que hace PatchCustomer en su transacción
+type racingPatchUcs struct {
+	*lambdaUcsStub
+	email string
+}
+
+func (s *racingPatchUcs) PatchCustomer(ctx context.Context, id int64, patch domain.CustomerPatch) (*domain.Customer, error) {
+	s.customer.Email = s.email
+	return s.lambdaUcsStub.PatchCustomer(ctx, id, patch)
+}
+
+func Test_patchCustomer_JSONPatchTestIsAtomic(t *testing.T) {
+	t.Run("should evaluate test ops on the customer read by the patch", func(t *testing.T) {
+		ucs := &racingPatchUcs{lambdaUcsStub: &lambdaUcsStub{customer: newPatchableCustomer()}, email: "marge@springfield.com"}
+		body := `[{"op":"test","path":"/email","value":"homero@springfield.com"},{"op":"replace","path":"/name","value":"Bart"}]`
+
+		_, err := patchCustomer(context.Background(), ucs, nil, 1, "application/json-patch+json", []byte(body))
+
+		errType, ok := types.GetErrorType(err)
+		require.True(t, ok, "expected domain error, got %v", err)
+		assert.Equal(t, types.ErrConflict, errType)
+		assert.Nil(t, ucs.updated)
+	})
+
+	t.Run("should check a test-only patch without changing the customer", func(t *testing.T) {
+		ucs := &racingPatchUcs{lambdaUcsStub: &lambdaUcsStub{customer: newPatchableCustomer()}, email: "marge@springfield.com"}
+		body := `[{"op":"test","path":"/email","value":"homero@springfield.com"}]`
+
+		_, err := patchCustomer(context.Background(), ucs, nil, 1, "application/json-patch+json", []byte(body))
+
+		errType, ok := types.GetErrorType(err)
+		require.True(t, ok, "expected domain error, got %v", err)
+		assert.Equal(t, types.ErrConflict, errType)
+	})
+}
+
+func Test_LambdaHandler_PatchCustomer(t *testing.T) {
+	h := newTestLambdaHandler(&lambdaUcsStub{customer: newPatchableCustomer()})
+
+	response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:     http.MethodPatch,
+		Resource:       "/customers/{id}",
+		PathParameters: map[string]string{"id": "1"},
+		Headers:        map[string]string{"content-type": "application/merge-patch+json"},
+		Body:           `{"phone":null,"last_name":"Bouvier"}`,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Contains(t, response.Body, `"last_name":"Bouvier"`)
+	assert.Contains(t, response.Body, `"phone":""`)
+}
+
+func Test_LambdaHandler_PhoneOnlyClearedByPatch(t *testing.T) {
+	body := `{"name":"Homero","last_name":"Simpson","email":"homero@springfield.com","phone":"","age":39,"birth_date":"` +
+		time.Now().AddDate(-39, 0, 0).Format(time.RFC3339) + `"}`
+
+	for _, method := range []string{http.MethodPost, http.MethodPut} {
+		t.Run("should require the phone on "+method, func(t *testing.T) {
+			ucs := &lambdaUcsStub{customer: newPatchableCustomer()}
+			h := newTestLambdaHandler(ucs)
+
+			resource, pathParameters := "/customers", map[string]string(nil)
+			if method == http.MethodPut {
+				resource, pathParameters = "/customers/{id}", map[string]string{"id": "1"}
+			}
+			response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod:     method,
+				Resource:       resource,
+				PathParameters: pathParameters,
+				Body:           body,
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+			assert.Contains(t, response.Body, "invalid phone format")
+			assert.Nil(t, ucs.updated)
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package inbound
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+// DuplicateQueryPolicy define qué hacer cuando un query param llega repetido (?limit=10&limit=20)
+type DuplicateQueryPolicy string
+
+const (
+	// DuplicateQueryReject rechaza el request con ErrValidation. Es la política por defecto:
+	// un valor ambiguo nunca se resuelve en silencio y la clave de cache del request es estable.
+	DuplicateQueryReject DuplicateQueryPolicy = "reject"
+	// DuplicateQueryLast toma el último valor recibido
+	DuplicateQueryLast DuplicateQueryPolicy = "last"
+)
+
+// ParseDuplicateQueryPolicy convierte el valor de configuración en una política; vacío usa la de por defecto
+func ParseDuplicateQueryPolicy(value string) (DuplicateQueryPolicy, error) {
+	switch DuplicateQueryPolicy(value) {
+	case "", DuplicateQueryReject:
+		return DuplicateQueryReject, nil
+	case DuplicateQueryLast:
+		return DuplicateQueryLast, nil
+	default:
+		return "", fmt.Errorf("invalid duplicate query policy %q, expected %q or %q", value, DuplicateQueryReject, DuplicateQueryLast)
+	}
+}
+
+// parseQuery reduce los query params multivalor a un valor por clave aplicando la política de duplicados.
+// Es el único punto por el que los handlers deben leer query params.
+func parseQuery(values url.Values, policy DuplicateQueryPolicy) (map[string]string, error) {
+	params := make(map[string]string, len(values))
+	for key, vals := range values {
+		if len(vals) == 0 {
+			continue
+		}
+		if len(vals) > 1 && policy != DuplicateQueryLast {
+			return nil, types.NewErrorWithContext(
+				types.ErrValidation,
+				fmt.Sprintf("duplicate query parameter: %s", key),
+				nil,
+				map[string]any{"parameter": key},
+			)
+		}
+		params[key] = vals[len(vals)-1]
+	}
+	return params, nil
+}
+
+// lambdaQueryValues obtiene los query params de API Gateway, preservando los repetidos si vienen multivalor
+func lambdaQueryValues(multiValue map[string][]string, single map[string]string) url.Values {
+	if len(multiValue) > 0 {
+		return url.Values(multiValue)
+	}
+	values := make(url.Values, len(single))
+	for key, value := range single {
+		values.Set(key, value)
+	}
+	return values
+}
+
+// defaultPageLimit es el limit por defecto de GET /customers
+const defaultPageLimit = 50
+
+// parsePageQuery lee "limit" y "offset" de GET /customers. Sin params se pide la primera página de
+// defaultPageLimit; los rangos se validan en el caso de uso.
+func parsePageQuery(params map[string]string) (int, int, error) {
+	limit, err := intQueryParam(params, "limit", defaultPageLimit)
+	if err != nil {
+		return 0, 0, err
+	}
+	offset, err := intQueryParam(params, "offset", 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	return limit, offset, nil
+}
+
+// parseSortQuery lee "sort" y "order" (asc o desc, por defecto asc) de GET /customers. Sin sort retorna nil y
+// el listado usa el orden por defecto; order sin sort es ErrInvalidInput. El campo se valida contra el
+// allowlist en el caso de uso.
+func parseSortQuery(params map[string]string) (*domain.CustomerSort, error) {
+	field, hasSort := params["sort"]
+	order, hasOrder := params["order"]
+	if !hasSort {
+		if hasOrder {
+			return nil, types.NewError(
+				types.ErrInvalidInput,
+				"order is only allowed with sort",
+				nil,
+			)
+		}
+		return nil, nil
+	}
+
+	sort := &domain.CustomerSort{Field: strings.ToLower(strings.TrimSpace(field))}
+	switch strings.ToLower(order) {
+	case "", "asc":
+	case "desc":
+		sort.Desc = true
+	default:
+		return nil, types.NewErrorWithContext(
+			types.ErrInvalidInput,
+			fmt.Sprintf("invalid order: %q, expected asc or desc", order),
+			nil,
+			map[string]any{"order": order},
+		)
+	}
+	return sort, nil
+}
+
+// includeKPI embebe el resumen de KPIs en meta.kpi de GET /customers
+const includeKPI = "kpi"
+
+// listIncludes son los valores permitidos en "include" de GET /customers
+var listIncludes = []string{includeKPI}
+
+// parseIncludeQuery lee "include" de GET /customers, una lista separada por comas de datos a embeber en la
+// respuesta; un valor fuera de listIncludes es ErrValidation
+func parseIncludeQuery(params map[string]string) (map[string]bool, error) {
+	include := map[string]bool{}
+	value, ok := params["include"]
+	if !ok {
+		return include, nil
+	}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if !slices.Contains(listIncludes, item) {
+			return nil, types.NewErrorWithContext(
+				types.ErrValidation,
+				fmt.Sprintf("invalid include: %q", item),
+				nil,
+				map[string]any{"include": item, "allowed": listIncludes},
+			)
+		}
+		include[item] = true
+	}
+	return include, nil
+}
+
+// parseExpandQuery lee "expand" del detalle de un customer, una lista separada por comas; los valores se
+// validan contra el allowlist en el caso de uso
+func parseExpandQuery(params map[string]string) []string {
+	value, ok := params["expand"]
+	if !ok {
+		return nil
+	}
+	expand := strings.Split(value, ",")
+	for i := range expand {
+		expand[i] = strings.TrimSpace(expand[i])
+	}
+	return expand
+}
+
+// parseIncludeDeletedQuery lee "include_deleted" (un booleano, por defecto false) de GET /customers y
+// GET /customers/{id}: en true también se ven los customers eliminados lógicamente
+func parseIncludeDeletedQuery(params map[string]string) (bool, error) {
+	value, ok := params["include_deleted"]
+	if !ok {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, types.NewErrorWithContext(
+			types.ErrValidation,
+			"include_deleted must be a boolean",
+			err,
+			map[string]any{"include_deleted": value},
+		)
+	}
+	return parsed, nil
+}
+
+// parseRangeQuery lee "from" y "to" (RFC3339) de GET /customers/kpi; los que no vienen quedan en cero.
+// Que from no sea posterior a to se valida en el caso de uso.
+func parseRangeQuery(params map[string]string) (time.Time, time.Time, error) {
+	from, err := timeQueryParam(params, "from")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := timeQueryParam(params, "to")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return from, to, nil
+}
+
+// timeQueryParam lee un query param RFC3339; si no viene retorna el tiempo cero
+func timeQueryParam(params map[string]string, key string) (time.Time, error) {
+	value, ok := params[key]
+	if !ok {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, types.NewErrorWithContext(
+			types.ErrInvalidInput,
+			fmt.Sprintf("%s must be an RFC3339 date-time", key),
+			err,
+			map[string]any{key: value},
+		)
+	}
+	return parsed, nil
+}
+
+// intQueryParam lee un query param entero; si no viene retorna fallback
+func intQueryParam(params map[string]string, key string, fallback int) (int, error) {
+	value, ok := params[key]
+	if !ok {
+		return fallback, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, types.NewErrorWithContext(
+			types.ErrInvalidInput,
+			fmt.Sprintf("%s must be an integer", key),
+			err,
+			map[string]any{key: value},
+		)
+	}
+	return parsed, nil
+}
+
+// defaultTopCustomers es el n por defecto de GET /customers/kpi/top
+const defaultTopCustomers = 10
+
+// parseTopQuery lee "by" y "n" de GET /customers/kpi/top. La dimensión se valida y n se limita en el caso de uso.
+func parseTopQuery(params map[string]string) (string, int, error) {
+	by := params["by"]
+	if by == "" {
+		by = domain.TopByAge
+	}
+
+	n := defaultTopCustomers
+	if value, ok := params["n"]; ok {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return "", 0, types.NewErrorWithContext(
+				types.ErrValidation,
+				"n must be an integer",
+				err,
+				map[string]any{"n": value},
+			)
+		}
+		n = parsed
+	}
+
+	return by, n, nil
+}
+
+// parseAggregateQuery lee "group_by" y "buckets" de GET /customers/aggregate. buckets ("1-17,18-64,65-150")
+// solo se admite con group_by=age_bucket; sin buckets se agrupa por décadas. El campo y que los rangos no se
+// solapen se validan en el caso de uso.
+func parseAggregateQuery(params map[string]string) (string, []domain.AgeBucket, error) {
+	groupBy := params["group_by"]
+	if groupBy == "" {
+		return "", nil, types.NewError(
+			types.ErrValidation,
+			"group_by is required",
+			nil,
+		)
+	}
+
+	value, ok := params["buckets"]
+	if !ok {
+		return groupBy, nil, nil
+	}
+	if groupBy != domain.GroupByAgeBucket {
+		return "", nil, types.NewErrorWithContext(
+			types.ErrValidation,
+			fmt.Sprintf("buckets is only allowed with group_by=%s", domain.GroupByAgeBucket),
+			nil,
+			map[string]any{"group_by": groupBy},
+		)
+	}
+	buckets, err := domain.ParseAgeBuckets(value)
+	if err != nil {
+		return "", nil, types.NewErrorWithContext(
+			types.ErrValidation,
+			err.Error(),
+			err,
+			map[string]any{"buckets": value},
+		)
+	}
+	return groupBy, buckets, nil
+}
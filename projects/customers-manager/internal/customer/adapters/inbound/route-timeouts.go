@@ -0,0 +1,104 @@
+package inbound
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+)
+
+// WithLambdaTimeouts acota cada request a su timeout: el de su ruta en routes (ver ParseRouteTimeouts) o, si la
+// ruta no tiene override, defaultTimeout. Un timeout en 0 no acota la ruta.
+func WithLambdaTimeouts(defaultTimeout time.Duration, routes map[string]time.Duration) LambdaOption {
+	return func(h *LambdaHandler) {
+		h.defaultTimeout = defaultTimeout
+		h.routeTimeouts = routes
+	}
+}
+
+// ParseRouteTimeouts convierte los overrides de configuración, con claves "MÉTODO /resource" (por ejemplo
+// "GET /customers/kpi") y duraciones como "30s", en el mapa de WithLambdaTimeouts. Una clave que no es una
+// ruta del handler o una duración que no es positiva es un error.
+func ParseRouteTimeouts(values map[string]string) (map[string]time.Duration, error) {
+	routes := make(map[string]time.Duration, len(values))
+	for key, value := range values {
+		route, err := parseRouteKey(key)
+		if err != nil {
+			return nil, err
+		}
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid timeout %q for route %q, expected a positive duration", value, key)
+		}
+		routes[route] = timeout
+	}
+	return routes, nil
+}
+
+// routeKey es la clave de una ruta en los overrides por ruta (timeouts y límites de body)
+func routeKey(method, resource string) string {
+	return strings.ToUpper(method) + " " + resource
+}
+
+// parseRouteKey normaliza una clave "MÉTODO /resource" de configuración; es un error si no es una ruta del handler
+func parseRouteKey(key string) (string, error) {
+	var h LambdaHandler
+	method, resource, ok := strings.Cut(strings.TrimSpace(key), " ")
+	resource = strings.TrimSpace(resource)
+	if !ok || h.route(strings.ToUpper(method), resource) == nil {
+		return "", fmt.Errorf("invalid route key %q, expected \"METHOD /resource\" of an existing route", key)
+	}
+	return routeKey(method, resource), nil
+}
+
+// timeoutFor retorna el timeout de la ruta: su override o el timeout por defecto
+func (h *LambdaHandler) timeoutFor(method, resource string) time.Duration {
+	if timeout, ok := h.routeTimeouts[routeKey(method, resource)]; ok {
+		return timeout
+	}
+	return h.defaultTimeout
+}
+
+// routeResult es la respuesta del resto de la cadena que corre bajo un timeout
+type routeResult struct {
+	response events.APIGatewayProxyResponse
+	err      error
+}
+
+// timeoutMiddleware corta el request con ErrTimeout (504) si el resto de la cadena no responde dentro del
+// timeout de la ruta. El contexto del handler se cancela, así que sus llamadas a la base se abortan aunque
+// el handler siga corriendo hasta notarlo.
+func (h *LambdaHandler) timeoutMiddleware(next LambdaHandlerFunc) LambdaHandlerFunc {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		timeout := h.timeoutFor(request.HTTPMethod, request.Resource)
+		if timeout <= 0 {
+			return next(ctx, request)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		done := make(chan routeResult, 1)
+		go func() {
+			response, err := next(ctx, request)
+			done <- routeResult{response: response, err: err}
+		}()
+
+		select {
+		case result := <-done:
+			return result.response, result.err
+		case <-ctx.Done():
+			apiErr, status := types.NewAPIError(types.NewErrorWithContext(
+				types.ErrTimeout,
+				fmt.Sprintf("request exceeded the %s timeout of %s", timeout, routeKey(request.HTTPMethod, request.Resource)),
+				ctx.Err(),
+				map[string]any{"route": routeKey(request.HTTPMethod, request.Resource), "timeout": timeout.String()},
+			))
+			return writeError(status, apiErr), nil
+		}
+	}
+}
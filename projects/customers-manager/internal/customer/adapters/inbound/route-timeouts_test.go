@@ -0,0 +1,127 @@
+package inbound
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+func Test_ParseRouteTimeouts(t *testing.T) {
+	t.Run("should normalize the keys and parse the durations", func(t *testing.T) {
+		routes, err := ParseRouteTimeouts(map[string]string{"get /customers/kpi": "30s", "GET /customers/{id}": "2s"})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]time.Duration{
+			"GET /customers/kpi":  30 * time.Second,
+			"GET /customers/{id}": 2 * time.Second,
+		}, routes)
+	})
+
+	tests := []struct {
+		name   string
+		values map[string]string
+	}{
+		{name: "should reject a key without method", values: map[string]string{"/customers/kpi": "30s"}},
+		{name: "should reject an unknown route", values: map[string]string{"GET /orders": "30s"}},
+		{name: "should reject an invalid duration", values: map[string]string{"GET /customers/kpi": "slow"}},
+		{name: "should reject a non positive duration", values: map[string]string{"GET /customers/kpi": "0s"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRouteTimeouts(tt.values)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_LambdaHandler_RouteTimeouts(t *testing.T) {
+	routes := map[string]time.Duration{"GET /customers/kpi": 30 * time.Second}
+
+	tests := []struct {
+		name        string
+		request     events.APIGatewayProxyRequest
+		wantTimeout time.Duration
+	}{
+		{
+			name:        "should apply the route override",
+			request:     events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Resource: "/customers/kpi"},
+			wantTimeout: 30 * time.Second,
+		},
+		{
+			name:        "should fall back to the default timeout",
+			request:     events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Resource: "/customers/{id}", PathParameters: map[string]string{"id": "1"}},
+			wantTimeout: 2 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// El middleware corre en otra goroutine, así que solo registra el deadline y se verifica después
+			var remaining time.Duration
+			var hasDeadline bool
+			capture := func(next LambdaHandlerFunc) LambdaHandlerFunc {
+				return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+					var deadline time.Time
+					deadline, hasDeadline = ctx.Deadline()
+					remaining = time.Until(deadline)
+					return next(ctx, request)
+				}
+			}
+			h := newTestLambdaHandler(&lambdaUcsStub{customer: &domain.Customer{ID: 1}}, WithLambdaTimeouts(2*time.Second, routes), WithLambdaMiddlewares(capture))
+
+			_, err := h.HandleRequest(context.Background(), tt.request)
+			require.NoError(t, err)
+
+			require.True(t, hasDeadline)
+			assert.InDelta(t, tt.wantTimeout, remaining, float64(time.Second))
+		})
+	}
+
+	t.Run("should not bound the request without a timeout", func(t *testing.T) {
+		var hasDeadline bool
+		capture := func(next LambdaHandlerFunc) LambdaHandlerFunc {
+			return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				_, hasDeadline = ctx.Deadline()
+				return next(ctx, request)
+			}
+		}
+		h := newTestLambdaHandler(&lambdaUcsStub{}, WithLambdaMiddlewares(capture))
+
+		_, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Resource: "/customers/kpi"})
+		require.NoError(t, err)
+
+		assert.False(t, hasDeadline)
+	})
+
+	t.Run("should answer ErrTimeout when the route exceeds its timeout", func(t *testing.T) {
+		slow := func(next LambdaHandlerFunc) LambdaHandlerFunc {
+			return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				<-ctx.Done()
+				return next(ctx, request)
+			}
+		}
+		h := newTestLambdaHandler(&lambdaUcsStub{},
+			WithLambdaTimeouts(time.Minute, map[string]time.Duration{"GET /customers/kpi": 10 * time.Millisecond}),
+			WithLambdaMiddlewares(slow),
+		)
+
+		response, err := h.HandleRequest(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Resource: "/customers/kpi"})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusGatewayTimeout, response.StatusCode)
+		var envelope types.APIErrorEnvelope
+		require.NoError(t, json.Unmarshal([]byte(response.Body), &envelope))
+		assert.Equal(t, types.APIErrTimeout, envelope.Error.Code)
+		assert.NotEmpty(t, response.Headers[h.requestIDHeader])
+	})
+}
@@ -0,0 +1,82 @@
+package inbound
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	transport "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound/transport"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+	ports "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/ports"
+)
+
+// Marcas del registro de prueba del self-test: el dominio .invalid (RFC 2606) nunca recibe correo
+// y el nombre permite identificar y purgar a mano cualquier resto si la limpieza falla
+const (
+	selfTestName        = "Selftest"
+	selfTestEmailDomain = "selftest.invalid"
+)
+
+// runSelfTest ejecuta un ciclo create/read/update/delete con un customer de prueba y retorna el
+// resultado de cada paso. Si un paso falla después del alta, el registro se borra igual.
+func runSelfTest(ctx context.Context, ucs ports.UseCases, now func() time.Time) transport.SelfTestResponse {
+	response := transport.SelfTestResponse{OK: true, Steps: []transport.SelfTestStepJson{}}
+
+	run := func(step string, fn func() error) bool {
+		start := now()
+		err := fn()
+		result := transport.SelfTestStepJson{
+			Step:       step,
+			OK:         err == nil,
+			DurationMs: now().Sub(start).Milliseconds(),
+		}
+		if err != nil {
+			result.Error = err.Error()
+			response.OK = false
+		}
+		response.Steps = append(response.Steps, result)
+		return err == nil
+	}
+
+	customer := &domain.Customer{
+		Name:      selfTestName,
+		LastName:  selfTestName,
+		Email:     fmt.Sprintf("selftest-%d@%s", now().UnixNano(), selfTestEmailDomain),
+		Age:       30,
+		BirthDate: now().AddDate(-30, 0, 0).UTC(),
+	}
+
+	if !run("create", func() error {
+		_, err := ucs.CreateCustomer(ctx, customer)
+		return err
+	}) {
+		return response
+	}
+
+	deleted := false
+	defer func() {
+		if !deleted {
+			_ = ucs.DeleteCustomer(context.WithoutCancel(ctx), customer.ID)
+		}
+	}()
+
+	if !run("read", func() error {
+		_, err := ucs.GetCustomerByID(ctx, customer.ID)
+		return err
+	}) {
+		return response
+	}
+
+	if !run("update", func() error {
+		updated := *customer
+		updated.Age++
+		return ucs.UpdateCustomer(ctx, &updated)
+	}) {
+		return response
+	}
+
+	deleted = run("delete", func() error {
+		return ucs.DeleteCustomer(ctx, customer.ID)
+	})
+	return response
+}
@@ -0,0 +1,105 @@
+package inbound
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+// memoryUcs guarda los customers en memoria y permite fallar un paso del CRUD
+type memoryUcs struct {
+	lambdaUcsStub
+	stored   map[int64]domain.Customer
+	failStep string
+}
+
+func (m *memoryUcs) fail(step string) error {
+	if m.failStep == step {
+		return errors.New(step + " failed")
+	}
+	return nil
+}
+
+func (m *memoryUcs) CreateCustomer(ctx context.Context, customer *domain.Customer) ([]domain.Warning, error) {
+	if err := m.fail("create"); err != nil {
+		return nil, err
+	}
+	customer.ID = int64(len(m.stored) + 1)
+	m.stored[customer.ID] = *customer
+	return nil, nil
+}
+
+func (m *memoryUcs) GetCustomerByID(ctx context.Context, id int64) (*domain.Customer, error) {
+	if err := m.fail("read"); err != nil {
+		return nil, err
+	}
+	customer, ok := m.stored[id]
+	if !ok {
+		return nil, types.NewError(types.ErrNotFound, "customer not found", nil)
+	}
+	return &customer, nil
+}
+
+func (m *memoryUcs) UpdateCustomer(ctx context.Context, customer *domain.Customer) error {
+	if err := m.fail("update"); err != nil {
+		return err
+	}
+	m.stored[customer.ID] = *customer
+	return nil
+}
+
+func (m *memoryUcs) DeleteCustomer(ctx context.Context, id int64) error {
+	if err := m.fail("delete"); err != nil {
+		return err
+	}
+	delete(m.stored, id)
+	return nil
+}
+
+func Test_runSelfTest(t *testing.T) {
+	tests := []struct {
+		name      string
+		failStep  string
+		wantOK    bool
+		wantSteps []string
+		wantLeft  int
+	}{
+		{name: "should run the full cycle and leave nothing behind", wantOK: true, wantSteps: []string{"create", "read", "update", "delete"}},
+		{name: "should stop when create fails", failStep: "create", wantSteps: []string{"create"}},
+		{name: "should clean up when a later step fails", failStep: "update", wantSteps: []string{"create", "read", "update"}},
+		{name: "should report a failed delete", failStep: "delete", wantSteps: []string{"create", "read", "update", "delete"}, wantLeft: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ucs := &memoryUcs{stored: map[int64]domain.Customer{}, failStep: tt.failStep}
+
+			var created domain.Customer
+			result := runSelfTest(context.Background(), ucs, time.Now)
+			for _, c := range ucs.stored {
+				created = c
+			}
+
+			assert.Equal(t, tt.wantOK, result.OK)
+			steps := make([]string, len(result.Steps))
+			for i, step := range result.Steps {
+				steps[i] = step.Step
+				assert.Equal(t, step.Step != tt.failStep, step.OK, step.Step)
+			}
+			assert.Equal(t, tt.wantSteps, steps)
+			require.Len(t, ucs.stored, tt.wantLeft)
+			if tt.wantLeft > 0 {
+				assert.True(t, strings.HasSuffix(created.Email, "@"+selfTestEmailDomain))
+				assert.Equal(t, selfTestName, created.Name)
+			}
+		})
+	}
+}
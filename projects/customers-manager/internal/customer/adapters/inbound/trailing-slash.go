@@ -0,0 +1,101 @@
+package inbound
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/gin-gonic/gin"
+)
+
+// TrailingSlashPolicy define cómo se tratan las rutas con barra final (/customers/ frente a /customers)
+type TrailingSlashPolicy string
+
+const (
+	// TrailingSlashRedirect redirige a la ruta sin barra (301 en GET, 307 en el resto). Es la política
+	// por defecto y el comportamiento estándar de gin.
+	TrailingSlashRedirect TrailingSlashPolicy = "redirect"
+	// TrailingSlashStrip quita la barra y atiende el request en el mismo handler, sin redirección
+	TrailingSlashStrip TrailingSlashPolicy = "strip"
+	// TrailingSlashStrict trata la ruta con barra como una ruta distinta (404)
+	TrailingSlashStrict TrailingSlashPolicy = "strict"
+)
+
+// ParseTrailingSlashPolicy convierte el valor de configuración en una política; vacío usa la de por defecto
+func ParseTrailingSlashPolicy(value string) (TrailingSlashPolicy, error) {
+	switch TrailingSlashPolicy(value) {
+	case "", TrailingSlashRedirect:
+		return TrailingSlashRedirect, nil
+	case TrailingSlashStrip, TrailingSlashStrict:
+		return TrailingSlashPolicy(value), nil
+	default:
+		return "", fmt.Errorf("invalid trailing slash policy %q, expected %q, %q or %q",
+			value, TrailingSlashRedirect, TrailingSlashStrip, TrailingSlashStrict)
+	}
+}
+
+// applyTrailingSlashPolicy configura el router de gin. Con strip, las rutas que no matchean y terminan
+// en barra se reprocesan sin ella (el routing de gin ocurre antes que los middlewares, por eso NoRoute).
+// Las que siguen sin matchear responden el 404 JSON de routeNotFound.
+func applyTrailingSlashPolicy(router *gin.Engine, policy TrailingSlashPolicy) {
+	router.RedirectTrailingSlash = policy == TrailingSlashRedirect
+	if policy != TrailingSlashStrip {
+		router.NoRoute(routeNotFound)
+		return
+	}
+
+	router.NoRoute(func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if stripped := trimTrailingSlash(path); stripped != path {
+			c.Request.URL.Path = stripped
+			router.HandleContext(c)
+			return
+		}
+		routeNotFound(c)
+	})
+}
+
+// normalizeLambdaRequest aplica la política a un request de API Gateway. Con redirect retorna la
+// respuesta de redirección a usar en lugar de rutear el request; sin Path no hay a dónde redirigir
+// y se normaliza como strip.
+func normalizeLambdaRequest(request *events.APIGatewayProxyRequest, policy TrailingSlashPolicy) *events.APIGatewayProxyResponse {
+	stripped := trimTrailingSlash(request.Path)
+	hasSlash := stripped != request.Path || trimTrailingSlash(request.Resource) != request.Resource
+	if !hasSlash {
+		return nil
+	}
+
+	if policy == TrailingSlashRedirect && request.Path == "" {
+		policy = TrailingSlashStrip
+	}
+
+	switch policy {
+	case TrailingSlashStrip:
+		request.Resource = trimTrailingSlash(request.Resource)
+		request.Path = stripped
+		return nil
+	case TrailingSlashRedirect:
+		status := http.StatusMovedPermanently
+		if request.HTTPMethod != http.MethodGet {
+			status = http.StatusTemporaryRedirect
+		}
+		return &events.APIGatewayProxyResponse{
+			StatusCode: status,
+			Headers:    map[string]string{"Location": stripped},
+		}
+	default:
+		return nil
+	}
+}
+
+// trimTrailingSlash quita las barras finales salvo en la raíz
+func trimTrailingSlash(path string) string {
+	if len(path) <= 1 {
+		return path
+	}
+	if trimmed := strings.TrimRight(path, "/"); trimmed != "" {
+		return trimmed
+	}
+	return "/"
+}
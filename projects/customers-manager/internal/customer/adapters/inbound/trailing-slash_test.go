@@ -0,0 +1,140 @@
+package inbound
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+// customerRoutes son las rutas de customers tal como las registra Routes, con un path concreto para probarlas
+var customerRoutes = []struct {
+	method   string
+	pattern  string
+	path     string
+	resource string
+}{
+	{http.MethodGet, "/api/v1/customers", "/api/v1/customers", "/customers"},
+	{http.MethodPost, "/api/v1/customers", "/api/v1/customers", "/customers"},
+	{http.MethodGet, "/api/v1/customers/:id", "/api/v1/customers/1", "/customers/{id}"},
+	{http.MethodPut, "/api/v1/customers/:id", "/api/v1/customers/1", "/customers/{id}"},
+	{http.MethodPatch, "/api/v1/customers/:id", "/api/v1/customers/1", "/customers/{id}"},
+	{http.MethodDelete, "/api/v1/customers/:id", "/api/v1/customers/1", "/customers/{id}"},
+	{http.MethodGet, "/api/v1/customers/kpi", "/api/v1/customers/kpi", "/customers/kpi"},
+	{http.MethodGet, "/api/v1/customers/kpi/top", "/api/v1/customers/kpi/top", "/customers/kpi/top"},
+	{http.MethodGet, "/api/v1/customers/aggregate", "/api/v1/customers/aggregate?group_by=age_bucket", "/customers/aggregate"},
+}
+
+func Test_applyTrailingSlashPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		policy        TrailingSlashPolicy
+		wantWithSlash func(method string) int
+	}{
+		{policy: TrailingSlashStrip, wantWithSlash: func(string) int { return http.StatusNoContent }},
+		{policy: TrailingSlashStrict, wantWithSlash: func(string) int { return http.StatusNotFound }},
+		{policy: TrailingSlashRedirect, wantWithSlash: func(method string) int {
+			if method == http.MethodGet {
+				return http.StatusMovedPermanently
+			}
+			return http.StatusTemporaryRedirect
+		}},
+	}
+
+	for _, tt := range tests {
+		router := gin.New()
+		applyTrailingSlashPolicy(router, tt.policy)
+		for _, route := range customerRoutes {
+			router.Handle(route.method, route.pattern, func(c *gin.Context) { c.Status(http.StatusNoContent) })
+		}
+
+		for _, route := range customerRoutes {
+			withSlash := strings.Replace(route.path, "?", "/?", 1)
+			if !strings.Contains(withSlash, "?") {
+				withSlash += "/"
+			}
+
+			t.Run(string(tt.policy)+" "+route.method+" "+withSlash, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, httptest.NewRequest(route.method, route.path, nil))
+				assert.Equal(t, http.StatusNoContent, w.Code)
+
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, httptest.NewRequest(route.method, withSlash, nil))
+				assert.Equal(t, tt.wantWithSlash(route.method), w.Code)
+			})
+		}
+	}
+}
+
+func Test_applyTrailingSlashPolicy_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, policy := range []TrailingSlashPolicy{TrailingSlashStrip, TrailingSlashStrict, TrailingSlashRedirect} {
+		router := gin.New()
+		applyTrailingSlashPolicy(router, policy)
+		router.GET("/api/v1/customers/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+		for _, path := range []string{"/api/v1/orders", "/api/v1/orders/"} {
+			t.Run(string(policy)+" "+path, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+				assert.Equal(t, http.StatusNotFound, w.Code)
+				var response types.APIErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, types.APIErrorType(types.CodeResourceNotFound), response.Type)
+				assert.Equal(t, http.StatusNotFound, response.Code)
+			})
+		}
+	}
+}
+
+func Test_LambdaHandler_TrailingSlash(t *testing.T) {
+	ucs := &lambdaUcsStub{customer: &domain.Customer{ID: 1}, customers: []domain.Customer{}}
+
+	for _, route := range customerRoutes {
+		path := strings.SplitN(route.path, "?", 2)[0] + "/"
+		request := events.APIGatewayProxyRequest{
+			HTTPMethod:            route.method,
+			Resource:              route.resource + "/",
+			Path:                  path,
+			PathParameters:        map[string]string{"id": "1"},
+			QueryStringParameters: map[string]string{"group_by": "age_bucket"},
+			Body:                  "{}",
+		}
+
+		t.Run("strip "+route.method+" "+request.Resource, func(t *testing.T) {
+			h := newTestLambdaHandler(ucs, WithLambdaTrailingSlashPolicy(TrailingSlashStrip))
+			response, err := h.HandleRequest(context.Background(), request)
+			require.NoError(t, err)
+			assert.NotEqual(t, http.StatusNotFound, response.StatusCode)
+		})
+
+		t.Run("redirect "+route.method+" "+request.Resource, func(t *testing.T) {
+			h := newTestLambdaHandler(ucs)
+			response, err := h.HandleRequest(context.Background(), request)
+			require.NoError(t, err)
+			assert.Contains(t, []int{http.StatusMovedPermanently, http.StatusTemporaryRedirect}, response.StatusCode)
+			assert.Equal(t, strings.TrimSuffix(path, "/"), response.Headers["Location"])
+		})
+
+		t.Run("strict "+route.method+" "+request.Resource, func(t *testing.T) {
+			h := newTestLambdaHandler(ucs, WithLambdaTrailingSlashPolicy(TrailingSlashStrict))
+			response, err := h.HandleRequest(context.Background(), request)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, response.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package transport
+
+// AggregateJson es la respuesta de GET /customers/aggregate
+type AggregateJson struct {
+	GroupBy string           `json:"group_by"`
+	Counts  map[string]int64 `json:"counts"`
+}
+
+func ToAggregateJson(groupBy string, counts map[string]int64) *AggregateJson {
+	return &AggregateJson{
+		GroupBy: groupBy,
+		Counts:  counts,
+	}
+}
@@ -0,0 +1,72 @@
+package transport
+
+import (
+	types "github.com/devpablocristo/tech-house/pkg/types"
+)
+
+// Estados posibles de un item dentro de una operación batch
+const (
+	ItemStatusSucceeded = "succeeded"
+	ItemStatusFailed    = "failed"
+	// ItemStatusAborted es un item válido que no se aplicó porque otro item hizo fallar el lote completo
+	ItemStatusAborted = "aborted"
+)
+
+// ItemResult es el resultado de un item individual de una operación batch.
+// Index es la posición del item en el request original.
+type ItemResult struct {
+	Index  int             `json:"index"`
+	Status string          `json:"status"`
+	ID     *int64          `json:"id,omitempty"`
+	Error  *types.APIError `json:"error,omitempty"`
+}
+
+// BatchResponse es la respuesta común de todos los endpoints batch
+type BatchResponse struct {
+	Results   []ItemResult `json:"results"`
+	Succeeded int          `json:"succeeded"`
+	Failed    int          `json:"failed"`
+}
+
+// NewItemSuccess crea el resultado de un item procesado correctamente
+func NewItemSuccess(index int, id int64) ItemResult {
+	return ItemResult{
+		Index:  index,
+		Status: ItemStatusSucceeded,
+		ID:     &id,
+	}
+}
+
+// NewItemFailure crea el resultado de un item fallido a partir del error de dominio
+func NewItemFailure(index int, err error) ItemResult {
+	apiErr, _ := types.NewAPIError(err)
+	return ItemResult{
+		Index:  index,
+		Status: ItemStatusFailed,
+		Error:  apiErr,
+	}
+}
+
+// NewItemAborted crea el resultado de un item que no se aplicó por el rollback del lote
+func NewItemAborted(index int) ItemResult {
+	return ItemResult{
+		Index:  index,
+		Status: ItemStatusAborted,
+	}
+}
+
+// NewBatchResponse arma la respuesta batch calculando los contadores; los items abortados cuentan como fallidos
+func NewBatchResponse(results []ItemResult) *BatchResponse {
+	response := &BatchResponse{Results: results}
+	if response.Results == nil {
+		response.Results = []ItemResult{}
+	}
+	for _, r := range response.Results {
+		if r.Status == ItemStatusSucceeded {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+	return response
+}
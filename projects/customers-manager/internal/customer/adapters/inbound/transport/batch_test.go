@@ -0,0 +1,81 @@
+package transport_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	"github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound/transport"
+)
+
+func Test_BatchResponse_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		results  []transport.ItemResult
+		expected string
+	}{
+		{
+			name: "should serialize mixed outcomes with summary counts",
+			results: []transport.ItemResult{
+				transport.NewItemSuccess(0, 10),
+				transport.NewItemFailure(1, types.NewError(types.ErrValidation, "invalid email", nil)),
+				transport.NewItemSuccess(2, 11),
+			},
+			expected: `{
+				"results": [
+					{"index": 0, "status": "succeeded", "id": 10},
+					{"index": 1, "status": "failed", "error": {"type": "VALIDATION_ERROR", "code": 400, "message": "invalid email"}},
+					{"index": 2, "status": "succeeded", "id": 11}
+				],
+				"succeeded": 2,
+				"failed": 1
+			}`,
+		},
+		{
+			name: "should map domain error types to api errors",
+			results: []transport.ItemResult{
+				transport.NewItemFailure(0, types.NewError(types.ErrNotFound, "customer not found", nil)),
+			},
+			expected: `{
+				"results": [
+					{"index": 0, "status": "failed", "error": {"type": "NOT_FOUND", "code": 404, "message": "customer not found"}}
+				],
+				"succeeded": 0,
+				"failed": 1
+			}`,
+		},
+		{
+			name:     "should serialize empty batch as empty array",
+			results:  nil,
+			expected: `{"results": [], "succeeded": 0, "failed": 0}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(transport.NewBatchResponse(tt.results))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(body))
+		})
+	}
+}
+
+func Test_BatchResponse_UnmarshalJSON(t *testing.T) {
+	body := `{"results":[{"index":0,"status":"succeeded","id":7},{"index":1,"status":"failed","error":{"type":"CONFLICT","code":409,"message":"email already exists"}}],"succeeded":1,"failed":1}`
+
+	var response transport.BatchResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &response))
+
+	require.Len(t, response.Results, 2)
+	require.NotNil(t, response.Results[0].ID)
+	assert.Equal(t, int64(7), *response.Results[0].ID)
+	assert.Nil(t, response.Results[0].Error)
+	assert.Nil(t, response.Results[1].ID)
+	require.NotNil(t, response.Results[1].Error)
+	assert.Equal(t, types.APIErrConflict, response.Results[1].Error.Type)
+	assert.Equal(t, 1, response.Succeeded)
+	assert.Equal(t, 1, response.Failed)
+}
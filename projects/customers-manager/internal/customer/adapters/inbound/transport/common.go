@@ -15,6 +15,14 @@ type CustomerJson struct {
 	Phone     string    `json:"phone"`
 	Age       int       `json:"age" binding:"required"`
 	BirthDate time.Time `json:"birth_date" binding:"required"`
+	// CreatedAt es solo de salida; se ignora en los requests
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	// DisplayName es solo de salida y se calcula con la plantilla configurada (ver SetDisplayNameTemplate)
+	DisplayName string `json:"display_name,omitempty"`
+	// AuditSummary es solo de salida y se informa con ?expand=audit_summary
+	AuditSummary *AuditSummaryJson `json:"audit_summary,omitempty"`
+	// DeletedAt es solo de salida; solo lo tienen los customers eliminados lógicamente (ver ?include_deleted)
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 // Mappers
@@ -31,15 +39,22 @@ func CustomerJsonToDomain(c *CustomerJson) *domain.Customer {
 }
 
 func DomainToCustomerJson(customer *domain.Customer) *CustomerJson {
-	return &CustomerJson{
-		ID:        customer.ID,
-		Name:      customer.Name,
-		LastName:  customer.LastName,
-		Email:     customer.Email,
-		Phone:     customer.Phone,
-		Age:       customer.Age,
-		BirthDate: customer.BirthDate,
+	c := &CustomerJson{
+		ID:          customer.ID,
+		Name:        customer.Name,
+		LastName:    customer.LastName,
+		Email:       customer.Email,
+		Phone:       customer.Phone,
+		Age:         customer.Age,
+		BirthDate:   customer.BirthDate,
+		DisplayName: displayName(customer),
+		DeletedAt:   customer.DeletedAt,
 	}
+	if !customer.CreatedAt.IsZero() {
+		createdAt := customer.CreatedAt
+		c.CreatedAt = &createdAt
+	}
+	return c
 }
 
 func CustomerJsonListToDomainList(customers []CustomerJson) []domain.Customer {
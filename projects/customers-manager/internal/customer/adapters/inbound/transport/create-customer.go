@@ -0,0 +1,31 @@
+package transport
+
+import (
+	"github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+// WarningJson describe un paso opcional del alta que falló
+type WarningJson struct {
+	Step    string `json:"step"`
+	Message string `json:"message"`
+}
+
+// Response
+type CreateCustomerResponse struct {
+	Customer CustomerJson  `json:"customer"`
+	Warnings []WarningJson `json:"warnings"`
+}
+
+func ToCreateCustomerResponse(customer *domain.Customer, warnings []domain.Warning) *CreateCustomerResponse {
+	response := &CreateCustomerResponse{
+		Customer: *DomainToCustomerJson(customer),
+		Warnings: make([]WarningJson, len(warnings)),
+	}
+	for i, warning := range warnings {
+		response.Warnings[i] = WarningJson{
+			Step:    warning.Step,
+			Message: warning.Message,
+		}
+	}
+	return response
+}
@@ -0,0 +1,59 @@
+package transport
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+// DefaultDisplayNameTemplate es la plantilla de display_name si no se configura otra
+const DefaultDisplayNameTemplate = "{name} {last_name}"
+
+// displayNameFields son los placeholders admitidos en la plantilla de display_name
+var displayNameFields = map[string]func(*domain.Customer) string{
+	"{name}":      func(c *domain.Customer) string { return c.Name },
+	"{last_name}": func(c *domain.Customer) string { return c.LastName },
+}
+
+var placeholderPattern = regexp.MustCompile(`\{[^{}]*\}`)
+
+var displayNameTemplate atomic.Value
+
+func init() {
+	displayNameTemplate.Store(DefaultDisplayNameTemplate)
+}
+
+// SetDisplayNameTemplate configura la plantilla con la que se arma display_name (por ejemplo
+// "{last_name}, {name}"). Vacía restaura la plantilla por defecto. Se llama al arrancar, antes de servir requests.
+func SetDisplayNameTemplate(template string) error {
+	if strings.TrimSpace(template) == "" {
+		displayNameTemplate.Store(DefaultDisplayNameTemplate)
+		return nil
+	}
+
+	placeholders := placeholderPattern.FindAllString(template, -1)
+	if len(placeholders) == 0 {
+		return fmt.Errorf("display name template %q has no placeholders", template)
+	}
+	for _, placeholder := range placeholders {
+		if _, ok := displayNameFields[placeholder]; !ok {
+			return fmt.Errorf("display name template %q has unknown placeholder %s", template, placeholder)
+		}
+	}
+
+	displayNameTemplate.Store(template)
+	return nil
+}
+
+// displayName arma el nombre para mostrar con la plantilla configurada. Los espacios sobrantes
+// (por un campo vacío) se colapsan y los separadores que quedan en los extremos se recortan.
+func displayName(customer *domain.Customer) string {
+	template := displayNameTemplate.Load().(string)
+	formatted := placeholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		return displayNameFields[placeholder](customer)
+	})
+	return strings.Trim(strings.Join(strings.Fields(formatted), " "), " ,")
+}
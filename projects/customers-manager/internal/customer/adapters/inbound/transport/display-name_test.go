@@ -0,0 +1,55 @@
+package transport_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound/transport"
+	"github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+func Test_DisplayName(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, transport.SetDisplayNameTemplate("")) })
+
+	tests := []struct {
+		name     string
+		template string
+		customer domain.Customer
+		expected string
+	}{
+		{
+			name:     "should use name and last name by default",
+			template: "",
+			customer: domain.Customer{Name: "Marge", LastName: "Simpson"},
+			expected: "Marge Simpson",
+		},
+		{
+			name:     "should apply a custom template",
+			template: "{last_name}, {name}",
+			customer: domain.Customer{Name: "Marge", LastName: "Simpson"},
+			expected: "Simpson, Marge",
+		},
+		{
+			name:     "should trim separators left by an empty field",
+			template: "{last_name}, {name}",
+			customer: domain.Customer{Name: "Marge"},
+			expected: "Marge",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, transport.SetDisplayNameTemplate(tt.template))
+
+			out := transport.DomainToCustomerJson(&tt.customer)
+			assert.Equal(t, tt.expected, out.DisplayName)
+		})
+	}
+
+	t.Run("should reject invalid templates", func(t *testing.T) {
+		assert.Error(t, transport.SetDisplayNameTemplate("customer"))
+		assert.Error(t, transport.SetDisplayNameTemplate("{name} {email}"))
+	})
+}
@@ -0,0 +1,59 @@
+package transport
+
+import (
+	"time"
+
+	"github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+// CustomerExportFormat identifica la versión del formato del bundle de exportación
+const CustomerExportFormat = "customer-export/v1"
+
+// ExportMetadataJson describe la exportación: formato, momento y quién la pidió
+type ExportMetadataJson struct {
+	Format      string    `json:"format"`
+	CustomerID  int64     `json:"customer_id"`
+	ExportedAt  time.Time `json:"exported_at"`
+	RequestedBy string    `json:"requested_by"`
+}
+
+// CustomerEventJson es un evento del historial del customer; customer es el estado después del cambio
+type CustomerEventJson struct {
+	Seq        int64         `json:"seq"`
+	Type       string        `json:"type"`
+	OccurredAt time.Time     `json:"occurred_at"`
+	Customer   *CustomerJson `json:"customer,omitempty"`
+}
+
+// Presenter
+func CustomerExportPresenter(export *domain.CustomerExport) CustomerExportResponse {
+	events := make([]CustomerEventJson, len(export.Events))
+	for i, event := range export.Events {
+		events[i] = CustomerEventJson{
+			Seq:        event.Seq,
+			Type:       event.Type,
+			OccurredAt: event.OccurredAt,
+		}
+		if event.Customer != nil {
+			events[i].Customer = DomainToCustomerJson(event.Customer)
+		}
+	}
+
+	return CustomerExportResponse{
+		Metadata: ExportMetadataJson{
+			Format:      CustomerExportFormat,
+			CustomerID:  export.Customer.ID,
+			ExportedAt:  export.ExportedAt,
+			RequestedBy: export.RequestedBy,
+		},
+		Customer: *DomainToCustomerJson(export.Customer),
+		Events:   events,
+	}
+}
+
+// Response
+type CustomerExportResponse struct {
+	Metadata ExportMetadataJson  `json:"metadata"`
+	Customer CustomerJson        `json:"customer"`
+	Events   []CustomerEventJson `json:"events"`
+}
@@ -0,0 +1,198 @@
+package transport
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"strings"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	utils "github.com/devpablocristo/tech-house/pkg/utils"
+	"github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+// encryptedPrefix marca los valores cifrados a nivel campo
+const encryptedPrefix = "enc:"
+
+// encryptableFields son los campos de CustomerJson que admiten cifrado (solo los de tipo string)
+var encryptableFields = map[string]func(*CustomerJson) *string{
+	"name":      func(c *CustomerJson) *string { return &c.Name },
+	"last_name": func(c *CustomerJson) *string { return &c.LastName },
+	"email":     func(c *CustomerJson) *string { return &c.Email },
+	"phone":     func(c *CustomerJson) *string { return &c.Phone },
+}
+
+// encryptablePatchFields son los mismos campos en el body de un PATCH, donde nil es un campo ausente
+var encryptablePatchFields = map[string]func(*CustomerPatchJson) **string{
+	"name":      func(p *CustomerPatchJson) **string { return &p.Name },
+	"last_name": func(p *CustomerPatchJson) **string { return &p.LastName },
+	"email":     func(p *CustomerPatchJson) **string { return &p.Email },
+	"phone":     func(p *CustomerPatchJson) **string { return &p.Phone },
+}
+
+// FieldEncryption cifra campos de CustomerJson para payloads que pasan por intermediarios no confiables
+// (además de TLS). En la salida los campos configurados se cifran con la clave pública del partner; en la
+// entrada el partner los envía cifrados con nuestra clave pública y se descifran con nuestra clave privada.
+// Un *FieldEncryption nil no cifra nada, así que los mappers se pueden usar igual sin configuración.
+type FieldEncryption struct {
+	fields     []string
+	partnerKey *rsa.PublicKey
+	privateKey *rsa.PrivateKey
+}
+
+// NewFieldEncryption valida los campos (por nombre JSON) y las claves
+func NewFieldEncryption(fields []string, partnerKey *rsa.PublicKey, privateKey *rsa.PrivateKey) (*FieldEncryption, error) {
+	if partnerKey == nil || privateKey == nil {
+		return nil, fmt.Errorf("partner public key and private key are required")
+	}
+	for _, field := range fields {
+		if _, ok := encryptableFields[field]; !ok {
+			return nil, fmt.Errorf("field %q cannot be encrypted", field)
+		}
+	}
+	return &FieldEncryption{
+		fields:     fields,
+		partnerKey: partnerKey,
+		privateKey: privateKey,
+	}, nil
+}
+
+// Encrypt cifra en el lugar los campos configurados de un CustomerJson de salida
+func (e *FieldEncryption) Encrypt(c *CustomerJson) error {
+	if e == nil {
+		return nil
+	}
+	for _, field := range e.fields {
+		value := encryptableFields[field](c)
+		if *value == "" {
+			continue
+		}
+		encrypted, err := utils.EncryptForRecipient(e.partnerKey, *value)
+		if err != nil {
+			return types.NewError(types.ErrInternal, fmt.Sprintf("failed to encrypt field %s", field), err)
+		}
+		*value = encryptedPrefix + encrypted
+	}
+	return e.encryptDisplayName(c)
+}
+
+// encryptDisplayName cifra display_name si se deriva de algún campo cifrado, para no exponerlo en claro
+func (e *FieldEncryption) encryptDisplayName(c *CustomerJson) error {
+	if c.DisplayName == "" {
+		return nil
+	}
+	for _, field := range e.fields {
+		if field != "name" && field != "last_name" {
+			continue
+		}
+		encrypted, err := utils.EncryptForRecipient(e.partnerKey, c.DisplayName)
+		if err != nil {
+			return types.NewError(types.ErrInternal, "failed to encrypt field display_name", err)
+		}
+		c.DisplayName = encryptedPrefix + encrypted
+		return nil
+	}
+	return nil
+}
+
+// Decrypt descifra en el lugar los campos configurados de un CustomerJson de entrada.
+// Un campo configurado que llega en texto plano se rechaza.
+func (e *FieldEncryption) Decrypt(c *CustomerJson) error {
+	if e == nil {
+		return nil
+	}
+	for _, field := range e.fields {
+		value := encryptableFields[field](c)
+		decrypted, err := e.DecryptField(field, *value)
+		if err != nil {
+			return err
+		}
+		*value = decrypted
+	}
+	return nil
+}
+
+// EncryptList cifra en el lugar los campos configurados de cada customer de un listado
+func (e *FieldEncryption) EncryptList(customers []CustomerJson) error {
+	for i := range customers {
+		if err := e.Encrypt(&customers[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecryptPatch descifra en el lugar los campos configurados que informa un patch
+func (e *FieldEncryption) DecryptPatch(p *CustomerPatchJson) error {
+	if e == nil {
+		return nil
+	}
+	for _, field := range e.fields {
+		value := encryptablePatchFields[field](p)
+		if *value == nil {
+			continue
+		}
+		decrypted, err := e.DecryptField(field, **value)
+		if err != nil {
+			return err
+		}
+		*value = &decrypted
+	}
+	return nil
+}
+
+// Encrypts indica si el campo (por nombre JSON) se cifra
+func (e *FieldEncryption) Encrypts(field string) bool {
+	if e == nil {
+		return false
+	}
+	for _, f := range e.fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// DecryptField descifra el valor de entrada de un campo configurado. Vacío queda igual y un valor en texto
+// plano se rechaza.
+func (e *FieldEncryption) DecryptField(field, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return "", types.NewErrorWithContext(
+			types.ErrValidation,
+			fmt.Sprintf("field %s must be encrypted", field),
+			nil,
+			map[string]any{"field": field},
+		)
+	}
+	decrypted, err := utils.DecryptWithPrivateKey(e.privateKey, strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil {
+		return "", types.NewErrorWithContext(
+			types.ErrValidation,
+			fmt.Sprintf("field %s could not be decrypted", field),
+			err,
+			map[string]any{"field": field},
+		)
+	}
+	return decrypted, nil
+}
+
+// DomainToCustomerJson mapea a CustomerJson cifrando los campos configurados
+func (e *FieldEncryption) DomainToCustomerJson(customer *domain.Customer) (*CustomerJson, error) {
+	c := DomainToCustomerJson(customer)
+	if err := e.Encrypt(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// CustomerJsonToDomain descifra los campos configurados y mapea a dominio
+func (e *FieldEncryption) CustomerJsonToDomain(c *CustomerJson) (*domain.Customer, error) {
+	decrypted := *c
+	if err := e.Decrypt(&decrypted); err != nil {
+		return nil, err
+	}
+	return CustomerJsonToDomain(&decrypted), nil
+}
@@ -0,0 +1,120 @@
+package transport_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	"github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound/transport"
+	"github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+// newEncryptionPair arma las dos puntas: el servicio (cifra para el partner) y el partner (cifra para el servicio)
+func newEncryptionPair(t *testing.T, fields []string) (service, partner *transport.FieldEncryption) {
+	t.Helper()
+
+	serviceKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	partnerKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	service, err = transport.NewFieldEncryption(fields, &partnerKey.PublicKey, serviceKey)
+	require.NoError(t, err)
+	partner, err = transport.NewFieldEncryption(fields, &serviceKey.PublicKey, partnerKey)
+	require.NoError(t, err)
+	return service, partner
+}
+
+func newEncryptionCustomer() *domain.Customer {
+	return &domain.Customer{
+		ID:        7,
+		Name:      "Marge",
+		LastName:  "Simpson",
+		Email:     "marge@springfield.com",
+		Phone:     "5551234",
+		Age:       36,
+		BirthDate: time.Date(1988, 3, 19, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func Test_FieldEncryption_RoundTrip(t *testing.T) {
+	service, partner := newEncryptionPair(t, []string{"email", "phone"})
+	customer := newEncryptionCustomer()
+
+	t.Run("should encrypt only the configured fields on output", func(t *testing.T) {
+		out, err := service.DomainToCustomerJson(customer)
+		require.NoError(t, err)
+
+		assert.True(t, strings.HasPrefix(out.Email, "enc:"))
+		assert.True(t, strings.HasPrefix(out.Phone, "enc:"))
+		assert.NotContains(t, out.Email, customer.Email)
+		assert.Equal(t, customer.Name, out.Name)
+		assert.Equal(t, customer.LastName, out.LastName)
+
+		require.NoError(t, partner.Decrypt(out))
+		assert.Equal(t, customer.Email, out.Email)
+		assert.Equal(t, customer.Phone, out.Phone)
+	})
+
+	t.Run("should encrypt display_name when it derives from an encrypted field", func(t *testing.T) {
+		service, _ := newEncryptionPair(t, []string{"last_name"})
+
+		out, err := service.DomainToCustomerJson(customer)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(out.DisplayName, "enc:"))
+		assert.NotContains(t, out.DisplayName, customer.LastName)
+	})
+
+	t.Run("should decrypt the configured fields on input", func(t *testing.T) {
+		in := transport.DomainToCustomerJson(customer)
+		require.NoError(t, partner.Encrypt(in))
+
+		decoded, err := service.CustomerJsonToDomain(in)
+		require.NoError(t, err)
+		assert.Equal(t, customer, decoded)
+		assert.True(t, strings.HasPrefix(in.Email, "enc:"), "the request DTO must not be modified")
+	})
+
+	t.Run("should reject a configured field sent in plaintext", func(t *testing.T) {
+		_, err := service.CustomerJsonToDomain(transport.DomainToCustomerJson(customer))
+		assert.True(t, types.IsValidationError(err))
+	})
+
+	t.Run("should reject a value encrypted for another key", func(t *testing.T) {
+		_, otherPartner := newEncryptionPair(t, []string{"email", "phone"})
+		in := transport.DomainToCustomerJson(customer)
+		require.NoError(t, otherPartner.Encrypt(in))
+
+		_, err := service.CustomerJsonToDomain(in)
+		assert.True(t, types.IsValidationError(err))
+	})
+
+	t.Run("should map in plaintext without configuration", func(t *testing.T) {
+		var none *transport.FieldEncryption
+
+		out, err := none.DomainToCustomerJson(customer)
+		require.NoError(t, err)
+		assert.Equal(t, customer.Email, out.Email)
+
+		decoded, err := none.CustomerJsonToDomain(out)
+		require.NoError(t, err)
+		assert.Equal(t, customer, decoded)
+	})
+}
+
+func Test_NewFieldEncryption(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	_, err = transport.NewFieldEncryption([]string{"age"}, &key.PublicKey, key)
+	assert.Error(t, err, "non string fields cannot be encrypted")
+
+	_, err = transport.NewFieldEncryption([]string{"email"}, nil, key)
+	assert.Error(t, err)
+}
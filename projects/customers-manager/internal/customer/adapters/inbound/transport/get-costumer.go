@@ -1,5 +1,44 @@
 package transport
 
+import (
+	"time"
+
+	"github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+// AuditSummaryJson es la expansión audit_summary de un customer
+type AuditSummaryJson struct {
+	EventCount    int        `json:"event_count"`
+	LastEventType string     `json:"last_event_type,omitempty"`
+	FirstEventAt  *time.Time `json:"first_event_at,omitempty"`
+	LastEventAt   *time.Time `json:"last_event_at,omitempty"`
+}
+
+// Mappers
+
+// ExpandedToCustomerJson mapea el customer con sus expansiones; las no pedidas se omiten
+func ExpandedToCustomerJson(expanded *domain.ExpandedCustomer) *CustomerJson {
+	c := DomainToCustomerJson(expanded.Customer)
+	if summary := expanded.AuditSummary; summary != nil {
+		c.AuditSummary = &AuditSummaryJson{
+			EventCount:    summary.EventCount,
+			LastEventType: summary.LastEventType,
+			FirstEventAt:  summary.FirstEventAt,
+			LastEventAt:   summary.LastEventAt,
+		}
+	}
+	return c
+}
+
+// Presenter
+
+// GetCustomerExpandedPresenter arma el detalle del customer con las expansiones pedidas
+func GetCustomerExpandedPresenter(expanded *domain.ExpandedCustomer) GetCustomerResponse {
+	return GetCustomerResponse{
+		Customers: *ExpandedToCustomerJson(expanded),
+	}
+}
+
 // Response
 type GetCustomerResponse struct {
 	Customers CustomerJson `json:"customer"`
@@ -16,7 +16,41 @@ func GetCustomersPresenter(customers []domain.Customer) []CustomerJson {
 	return response
 }
 
+// GetCustomersPagePresenter arma la respuesta paginada; NextOffset se omite en la última página
+func GetCustomersPagePresenter(page *domain.CustomerPage) GetCustomersResponse {
+	return GetCustomersResponse{
+		Customers:  DomainListToCustomerJsonList(page.Customers),
+		Total:      page.Total,
+		NextOffset: page.NextOffset(),
+	}
+}
+
+// GetCustomersExpandedPagePresenter arma la respuesta paginada con las expansiones pedidas de cada customer
+func GetCustomersExpandedPagePresenter(page *domain.CustomerPage, expanded []domain.ExpandedCustomer) GetCustomersResponse {
+	response := GetCustomersPagePresenter(page)
+	for i := range expanded {
+		response.Customers[i] = *ExpandedToCustomerJson(&expanded[i])
+	}
+	return response
+}
+
+// GetTopCustomersPresenter arma la respuesta del ranking; Total es la cantidad de customers del ranking
+func GetTopCustomersPresenter(customers []domain.Customer) GetCustomersResponse {
+	return GetCustomersResponse{
+		Customers: DomainListToCustomerJsonList(customers),
+		Total:     len(customers),
+	}
+}
+
 // Response
 type GetCustomersResponse struct {
-	Customers []CustomerJson `json:"customers"`
+	Customers  []CustomerJson `json:"customers"`
+	Total      int            `json:"total"`
+	NextOffset int            `json:"next_offset,omitempty"`
+	Meta       *ListMeta      `json:"meta,omitempty"`
+}
+
+// ListMeta son los datos pedidos con ?include=; solo se informan los pedidos
+type ListMeta struct {
+	KPI *GetKPIJson `json:"kpi,omitempty"`
 }
@@ -0,0 +1,23 @@
+package transport
+
+// Estados de GET /health y GET /health/deep
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusError    = "error"
+	HealthStatusDegraded = "degraded"
+)
+
+// DependencyJson es el estado de una dependencia en GET /health/deep
+type DependencyJson struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Response
+
+// HealthResponse es la respuesta de los health checks; Dependencies solo la informa GET /health/deep
+type HealthResponse struct {
+	Status       string                    `json:"status"`
+	Dependencies map[string]DependencyJson `json:"dependencies,omitempty"`
+}
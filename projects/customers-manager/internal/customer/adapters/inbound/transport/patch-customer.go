@@ -0,0 +1,52 @@
+package transport
+
+import (
+	"time"
+
+	"github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+// CustomerPatchJson es el body de un PATCH: los campos ausentes quedan en nil y no se modifican
+type CustomerPatchJson struct {
+	Name      *string    `json:"name"`
+	LastName  *string    `json:"last_name"`
+	Email     *string    `json:"email"`
+	Phone     *string    `json:"phone"`
+	Age       *int       `json:"age"`
+	BirthDate *time.Time `json:"birth_date"`
+}
+
+func CustomerPatchJsonToDomain(p *CustomerPatchJson) domain.CustomerPatch {
+	return domain.CustomerPatch{
+		Name:      p.Name,
+		LastName:  p.LastName,
+		Email:     p.Email,
+		Phone:     p.Phone,
+		Age:       p.Age,
+		BirthDate: p.BirthDate,
+	}
+}
+
+// CustomerPatchFromChanges arma el patch con los campos de patched que difieren de current
+func CustomerPatchFromChanges(current, patched *CustomerJson) *CustomerPatchJson {
+	patch := &CustomerPatchJson{}
+	if patched.Name != current.Name {
+		patch.Name = &patched.Name
+	}
+	if patched.LastName != current.LastName {
+		patch.LastName = &patched.LastName
+	}
+	if patched.Email != current.Email {
+		patch.Email = &patched.Email
+	}
+	if patched.Phone != current.Phone {
+		patch.Phone = &patched.Phone
+	}
+	if patched.Age != current.Age {
+		patch.Age = &patched.Age
+	}
+	if !patched.BirthDate.Equal(current.BirthDate) {
+		patch.BirthDate = &patched.BirthDate
+	}
+	return patch
+}
@@ -0,0 +1,15 @@
+package transport
+
+// SelfTestStepJson es el resultado de un paso de POST /admin/selftest
+type SelfTestStepJson struct {
+	Step       string `json:"step"`
+	OK         bool   `json:"ok"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Response
+type SelfTestResponse struct {
+	OK    bool               `json:"ok"`
+	Steps []SelfTestStepJson `json:"steps"`
+}
@@ -0,0 +1,253 @@
+package inbound
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	utils "github.com/devpablocristo/tech-house/pkg/utils"
+	transport "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/inbound/transport"
+)
+
+// Reglas de validación de negocio informadas en types.FieldError.Rule; las de binding usan el tag del validator
+const (
+	ruleFormat   = "format"
+	ruleRange    = "range"
+	ruleAgeMatch = "age_match"
+	ruleType     = "type"
+)
+
+// fieldMessages es el mensaje general del error cuando falla un único campo
+var fieldMessages = map[string]string{
+	"name":       "invalid name format",
+	"last_name":  "invalid last name format",
+	"email":      "invalid email format",
+	"phone":      "invalid phone format",
+	"age":        "invalid age",
+	"birth_date": "invalid birth date",
+}
+
+// validateRequest valida el request completo del customer y reporta todos los campos inválidos juntos
+func validateRequest(req *transport.CustomerJson) error {
+	if req == nil {
+		return types.NewError(
+			types.ErrInvalidInput,
+			"request cannot be nil",
+			nil,
+		)
+	}
+	return fieldValidationError(requestFieldErrors(req))
+}
+
+// validatePatch valida solo los campos informados en el patch; la edad y la fecha de nacimiento se
+// contrastan entre sí únicamente cuando vienen las dos
+func validatePatch(patch *transport.CustomerPatchJson) error {
+	var fields []types.FieldError
+	add := func(field, rule string, err error) {
+		fields = append(fields, types.FieldError{Field: field, Rule: rule, Message: fieldMessage(err)})
+	}
+
+	if patch.Name != nil {
+		*patch.Name = utils.BasicInputSanitizer(*patch.Name)
+		if err := utils.ValidateName(*patch.Name, minNameLength, maxNameLength); err != nil {
+			add("name", ruleFormat, err)
+		}
+	}
+
+	if patch.LastName != nil && strings.TrimSpace(*patch.LastName) == "" {
+		add("last_name", ruleFormat, errors.New("last name cannot be empty"))
+	}
+
+	if patch.Email != nil {
+		*patch.Email = utils.BasicInputSanitizer(*patch.Email)
+		if err := utils.ValidateEmail(*patch.Email); err != nil {
+			add("email", ruleFormat, err)
+		}
+	}
+
+	// Solo un PATCH puede borrar el teléfono, con null o remove; el valor vacío no se valida
+	if patch.Phone != nil && *patch.Phone != "" {
+		*patch.Phone = utils.BasicInputSanitizer(*patch.Phone)
+		if err := utils.ValidatePhone(*patch.Phone, minPhoneLength); err != nil {
+			add("phone", ruleFormat, err)
+		}
+	}
+
+	ageValid := false
+	if patch.Age != nil {
+		if err := utils.ValidateAge(*patch.Age, minAge, maxAge); err != nil {
+			add("age", ruleRange, err)
+		} else {
+			ageValid = true
+		}
+	}
+	if patch.BirthDate != nil && ageValid {
+		if err := utils.ValidateBirthDate(*patch.BirthDate, *patch.Age); err != nil {
+			add("birth_date", ruleAgeMatch, err)
+		}
+	}
+
+	return fieldValidationError(fields)
+}
+
+// customerBodyError combina el error de decodificación o de binding del body con las validaciones de
+// negocio del customer, para que el cliente reciba en una sola respuesta todos los campos a corregir.
+// Los campos que el partner envía cifrados se descifran antes de validarlos (ver WithFieldEncryption).
+func customerBodyError(decodeErr error, req *transport.CustomerJson, encryption *transport.FieldEncryption) error {
+	var fields []types.FieldError
+	if decodeErr != nil {
+		var validationErrs validator.ValidationErrors
+		if !errors.As(decodeErr, &validationErrs) {
+			return decodeError(decodeErr)
+		}
+		fields = bindingFieldErrors(validationErrs, reflect.TypeOf(*req))
+	}
+
+	if err := encryption.Decrypt(req); err != nil {
+		return err
+	}
+
+	reported := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		reported[field.Field] = true
+	}
+	for _, field := range requestFieldErrors(req) {
+		if !reported[field.Field] {
+			fields = append(fields, field)
+		}
+	}
+	return fieldValidationError(fields)
+}
+
+// decodeLambdaBody decodifica el body de API Gateway y aplica los tags binding, como ShouldBindJSON en Gin
+func decodeLambdaBody(body string, req any) error {
+	if strings.TrimSpace(body) == "" {
+		return io.EOF
+	}
+	if err := json.Unmarshal([]byte(body), req); err != nil {
+		return err
+	}
+	return binding.Validator.ValidateStruct(req)
+}
+
+// requestFieldErrors sanitiza el request y retorna las validaciones de negocio que no pasan
+func requestFieldErrors(req *transport.CustomerJson) []types.FieldError {
+	req.Name = utils.BasicInputSanitizer(req.Name)
+	req.Email = utils.BasicInputSanitizer(req.Email)
+	req.Phone = utils.BasicInputSanitizer(req.Phone)
+
+	var fields []types.FieldError
+	add := func(field, rule string, err error) {
+		fields = append(fields, types.FieldError{Field: field, Rule: rule, Message: fieldMessage(err)})
+	}
+
+	if err := utils.ValidateName(req.Name, minNameLength, maxNameLength); err != nil {
+		add("name", ruleFormat, err)
+	}
+
+	if err := utils.ValidateEmail(req.Email); err != nil {
+		add("email", ruleFormat, err)
+	}
+
+	if err := utils.ValidatePhone(req.Phone, minPhoneLength); err != nil {
+		add("phone", ruleFormat, err)
+	}
+
+	// La fecha de nacimiento se contrasta con la edad, así que solo se valida si la edad es válida
+	if err := utils.ValidateAge(req.Age, minAge, maxAge); err != nil {
+		add("age", ruleRange, err)
+	} else if err := utils.ValidateBirthDate(req.BirthDate, req.Age); err != nil {
+		add("birth_date", ruleAgeMatch, err)
+	}
+
+	return fields
+}
+
+// fieldMessage es el mensaje de un campo inválido; de un *types.Error (por ejemplo de utils.ValidateEmail)
+// se toma solo el mensaje, sin el tipo
+func fieldMessage(err error) string {
+	var typed *types.Error
+	if errors.As(err, &typed) {
+		return typed.Message
+	}
+	return err.Error()
+}
+
+// bindingFieldErrors traduce los errores del validator a campos con el nombre JSON del request
+func bindingFieldErrors(validationErrs validator.ValidationErrors, reqType reflect.Type) []types.FieldError {
+	fields := make([]types.FieldError, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		message := "is required"
+		if fieldErr.Tag() != "required" {
+			message = fmt.Sprintf("failed on the %s rule", fieldErr.Tag())
+		}
+		fields = append(fields, types.FieldError{
+			Field:   jsonFieldName(reqType, fieldErr.StructField()),
+			Rule:    fieldErr.Tag(),
+			Message: message,
+		})
+	}
+	return fields
+}
+
+// decodeError clasifica los errores de un body que no se pudo decodificar
+func decodeError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return types.NewError(
+			types.ErrValidation,
+			"request cannot be nil",
+			err,
+		)
+	case errors.As(err, &typeErr):
+		return types.NewFieldValidationError("invalid data type", err, []types.FieldError{{
+			Field:   typeErr.Field,
+			Rule:    ruleType,
+			Message: fmt.Sprintf("must be of type %s", typeErr.Type),
+		}})
+	default:
+		return types.NewError(
+			types.ErrValidation,
+			"malformed request body",
+			err,
+		)
+	}
+}
+
+// fieldValidationError arma el ErrValidation de los campos inválidos; nil si no hay ninguno.
+// Con un solo campo conserva el mensaje específico y su detalle.
+func fieldValidationError(fields []types.FieldError) error {
+	switch len(fields) {
+	case 0:
+		return nil
+	case 1:
+		message, ok := fieldMessages[fields[0].Field]
+		if !ok {
+			message = fmt.Sprintf("invalid %s", fields[0].Field)
+		}
+		return types.NewFieldValidationError(message, errors.New(fields[0].Message), fields)
+	default:
+		return types.NewFieldValidationError("invalid request fields", nil, fields)
+	}
+}
+
+// jsonFieldName retorna el nombre JSON del campo del struct, o el nombre del campo si no tiene tag
+func jsonFieldName(structType reflect.Type, name string) string {
+	field, ok := structType.FieldByName(name)
+	if !ok {
+		return name
+	}
+	tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if tag == "" || tag == "-" {
+		return name
+	}
+	return tag
+}
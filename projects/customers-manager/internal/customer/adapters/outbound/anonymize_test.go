@@ -0,0 +1,88 @@
+package outbound
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+func Test_repository_Anonymize(t *testing.T) {
+	r, id := newTestRepository(t)
+	ctx := context.Background()
+
+	customer, err := r.GetByID(ctx, id)
+	require.NoError(t, err)
+	customer.Anonymize("0123456789abcdef", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	require.NoError(t, r.Anonymize(ctx, customer))
+
+	t.Run("should replace the personal data and keep the aggregate fields", func(t *testing.T) {
+		var name, email, phone string
+		var age int
+		var anonymizedAt *time.Time
+		err := r.sqliteRepo.DB().QueryRow(`SELECT name, email, phone, age, anonymized_at FROM customers WHERE id = ?`, id).
+			Scan(&name, &email, &phone, &age, &anonymizedAt)
+		require.NoError(t, err)
+
+		assert.Equal(t, domain.AnonymizedName, name)
+		assert.Equal(t, "anonymized-0123456789abcdef@"+domain.AnonymizedEmailDomain, email)
+		assert.Empty(t, phone)
+		assert.Equal(t, 30, age)
+		require.NotNil(t, anonymizedAt)
+	})
+
+	t.Run("should hide the customer from detail, email lookup and listing", func(t *testing.T) {
+		_, err := r.GetByID(ctx, id)
+		assert.True(t, types.IsNotFound(err))
+
+		_, err = r.GetByEmail(ctx, "ana@example.com")
+		assert.True(t, types.IsNotFound(err))
+
+		total, err := r.Count(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, total)
+	})
+
+	t.Run("should not write personal data back on an anonymized customer", func(t *testing.T) {
+		// El update de un PUT que leyó el customer antes de que se anonimizara
+		result, err := r.sqliteRepo.DB().Exec(updateCustomerQuery,
+			"Ana", "Perez", "ana@example.com", "1234567", 30, time.Date(1994, 1, 1, 0, 0, 0, 0, time.UTC), time.Now().UTC(), "ana", id)
+		require.NoError(t, err)
+		affected, err := result.RowsAffected()
+		require.NoError(t, err)
+		assert.Zero(t, affected)
+
+		var name string
+		require.NoError(t, r.sqliteRepo.DB().QueryRow(`SELECT name FROM customers WHERE id = ?`, id).Scan(&name))
+		assert.Equal(t, domain.AnonymizedName, name)
+	})
+
+	t.Run("should not anonymize twice", func(t *testing.T) {
+		err := r.Anonymize(ctx, customer)
+		assert.True(t, types.IsNotFound(err))
+	})
+}
+
+func Test_EventStore_Redact(t *testing.T) {
+	_, events := newTestEventStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, events.Append(ctx, &domain.Event{Type: domain.EventCustomerCreated, CustomerID: 7, Customer: &domain.Customer{ID: 7, Name: "Ana"}}))
+	require.NoError(t, events.Append(ctx, &domain.Event{Type: domain.EventCustomerCreated, CustomerID: 8, Customer: &domain.Customer{ID: 8, Name: "Luis"}}))
+
+	require.NoError(t, events.Redact(ctx, 7))
+
+	read, err := events.Read(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, read, 2)
+	assert.Equal(t, domain.EventCustomerCreated, read[0].Type)
+	assert.Nil(t, read[0].Customer)
+	require.NotNil(t, read[1].Customer)
+	assert.Equal(t, "Luis", read[1].Customer.Name)
+}
@@ -0,0 +1,233 @@
+package outbound
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	transport "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/outbound/transport"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+	ports "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/ports"
+)
+
+const eventsSchema = `
+CREATE TABLE IF NOT EXISTS customer_events (
+	seq INTEGER PRIMARY KEY AUTOINCREMENT,
+	type TEXT NOT NULL,
+	customer_id INTEGER NOT NULL,
+	payload TEXT,
+	occurred_at DATETIME NOT NULL
+);`
+
+const (
+	insertEventQuery = `
+		INSERT INTO customer_events (type, customer_id, payload, occurred_at)
+		VALUES (?, ?, ?, ?)`
+
+	selectEventsFromQuery = `
+		SELECT seq, type, customer_id, payload, occurred_at
+		FROM customer_events
+		WHERE seq >= ?
+		ORDER BY seq`
+
+	selectEventsByCustomerQuery = `
+		SELECT seq, type, customer_id, payload, occurred_at
+		FROM customer_events
+		WHERE customer_id = ?
+		ORDER BY seq`
+
+	// selectEventsByCustomersQuery se completa con un placeholder por customer (ver eventsByCustomersQuery)
+	selectEventsByCustomersQuery = `
+		SELECT seq, type, customer_id, payload, occurred_at
+		FROM customer_events
+		WHERE customer_id IN (%s)
+		ORDER BY seq`
+
+	redactEventsQuery = `
+		UPDATE customer_events
+		SET payload = NULL
+		WHERE customer_id = ?`
+)
+
+// eventStore implementa ports.EventStore sobre la misma base SQLite que el repositorio,
+// para que los eventos se graben en la misma transacción que el cambio de estado
+type eventStore struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewEventStore crea el EventStore sobre la base del repositorio recibido (debe venir de NewRepository)
+func NewEventStore(repo ports.Repository) (ports.EventStore, error) {
+	r, ok := repo.(*repository)
+	if !ok {
+		return nil, types.NewError(
+			types.ErrInvalidInput,
+			"event store requires a sqlite repository",
+			nil,
+		)
+	}
+	return newEventStore(r.sqliteRepo.DB())
+}
+
+func newEventStore(db *sql.DB) (*eventStore, error) {
+	if _, err := db.Exec(eventsSchema); err != nil {
+		return nil, types.NewError(
+			types.ErrOperationFailed,
+			"failed to create events schema",
+			err,
+		)
+	}
+	return &eventStore{db: db, now: time.Now}, nil
+}
+
+// Append graba el evento en la transacción del contexto, si la hay, y le asigna la secuencia
+func (s *eventStore) Append(ctx context.Context, event *domain.Event) error {
+	if event == nil {
+		return types.NewError(
+			types.ErrInvalidInput,
+			"event cannot be nil",
+			nil,
+		)
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = s.now().UTC()
+	}
+
+	var payload sql.NullString
+	if event.Customer != nil {
+		data, err := json.Marshal(transport.DomainToCustomerDataModel(event.Customer))
+		if err != nil {
+			return types.NewError(
+				types.ErrInternal,
+				"failed to encode event payload",
+				err,
+			)
+		}
+		payload = sql.NullString{String: string(data), Valid: true}
+	}
+
+	exec := s.db.ExecContext
+	if tx := txFromContext(ctx); tx != nil {
+		exec = tx.ExecContext
+	}
+
+	result, err := exec(ctx, insertEventQuery, event.Type, event.CustomerID, payload, event.OccurredAt)
+	if err != nil {
+		return types.NewError(
+			types.ErrOperationFailed,
+			"failed to append event",
+			err,
+		)
+	}
+
+	seq, err := result.LastInsertId()
+	if err != nil {
+		return types.NewError(
+			types.ErrOperationFailed,
+			"failed to read event sequence",
+			err,
+		)
+	}
+	event.Seq = seq
+	return nil
+}
+
+// Read retorna los eventos desde fromSeq (inclusive) en el orden en que se agregaron
+func (s *eventStore) Read(ctx context.Context, fromSeq int64) ([]domain.Event, error) {
+	return s.query(ctx, selectEventsFromQuery, fromSeq)
+}
+
+// ReadByCustomer retorna el historial de eventos de un customer en el orden en que se agregaron
+func (s *eventStore) ReadByCustomer(ctx context.Context, customerID int64) ([]domain.Event, error) {
+	return s.query(ctx, selectEventsByCustomerQuery, customerID)
+}
+
+// ReadByCustomers retorna los eventos de todos los customers recibidos con una única consulta, en el orden
+// en que se agregaron; sin customers no consulta la base
+func (s *eventStore) ReadByCustomers(ctx context.Context, customerIDs []int64) ([]domain.Event, error) {
+	if len(customerIDs) == 0 {
+		return []domain.Event{}, nil
+	}
+	args := make([]any, len(customerIDs))
+	for i, id := range customerIDs {
+		args[i] = id
+	}
+	return s.query(ctx, eventsByCustomersQuery(len(customerIDs)), args...)
+}
+
+// eventsByCustomersQuery arma selectEventsByCustomersQuery con n placeholders
+func eventsByCustomersQuery(n int) string {
+	return fmt.Sprintf(selectEventsByCustomersQuery, strings.TrimSuffix(strings.Repeat("?,", n), ","))
+}
+
+// Redact borra el payload de los eventos del customer en la transacción del contexto, si la hay.
+// Se conservan tipo, secuencia y fecha para que las proyecciones se puedan seguir reconstruyendo.
+func (s *eventStore) Redact(ctx context.Context, customerID int64) error {
+	exec := s.db.ExecContext
+	if tx := txFromContext(ctx); tx != nil {
+		exec = tx.ExecContext
+	}
+
+	if _, err := exec(ctx, redactEventsQuery, customerID); err != nil {
+		return types.NewError(
+			types.ErrOperationFailed,
+			"failed to redact events",
+			err,
+		)
+	}
+	return nil
+}
+
+// query ejecuta una consulta de eventos y decodifica el payload de cada uno
+func (s *eventStore) query(ctx context.Context, query string, args ...any) ([]domain.Event, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, types.NewError(
+			types.ErrOperationFailed,
+			"failed to read events",
+			err,
+		)
+	}
+	defer rows.Close()
+
+	events := []domain.Event{}
+	for rows.Next() {
+		var (
+			event   domain.Event
+			payload sql.NullString
+		)
+		if err := rows.Scan(&event.Seq, &event.Type, &event.CustomerID, &payload, &event.OccurredAt); err != nil {
+			return nil, types.NewError(
+				types.ErrOperationFailed,
+				"failed to scan event",
+				err,
+			)
+		}
+		if payload.Valid {
+			var model transport.CustomerDataModel
+			if err := json.Unmarshal([]byte(payload.String), &model); err != nil {
+				return nil, types.NewErrorWithContext(
+					types.ErrInternal,
+					"malformed event payload",
+					err,
+					map[string]any{"seq": event.Seq},
+				)
+			}
+			event.Customer = transport.CustomerDataModelToDomain(&model)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, types.NewError(
+			types.ErrOperationFailed,
+			"failed to read events",
+			err,
+		)
+	}
+	return events, nil
+}
@@ -0,0 +1,136 @@
+package outbound
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+func newTestEventStore(t *testing.T) (*repository, *eventStore) {
+	t.Helper()
+
+	r, _ := newTestRepository(t)
+	events, err := newEventStore(r.sqliteRepo.DB())
+	require.NoError(t, err)
+	return r, events
+}
+
+func Test_EventStore_AppendRead(t *testing.T) {
+	_, events := newTestEventStore(t)
+	ctx := context.Background()
+
+	appended := []*domain.Event{
+		{Type: domain.EventCustomerCreated, CustomerID: 7, Customer: &domain.Customer{ID: 7, Name: "Ana", Age: 30}},
+		{Type: domain.EventCustomerUpdated, CustomerID: 7, Customer: &domain.Customer{ID: 7, Name: "Ana", Age: 31}},
+		{Type: domain.EventCustomerDeleted, CustomerID: 7},
+	}
+	for _, event := range appended {
+		require.NoError(t, events.Append(ctx, event))
+	}
+
+	tests := []struct {
+		name     string
+		fromSeq  int64
+		expected []string
+	}{
+		{name: "should read every event in append order", fromSeq: 0, expected: []string{domain.EventCustomerCreated, domain.EventCustomerUpdated, domain.EventCustomerDeleted}},
+		{name: "should read from the given sequence inclusive", fromSeq: appended[1].Seq, expected: []string{domain.EventCustomerUpdated, domain.EventCustomerDeleted}},
+		{name: "should return empty past the last sequence", fromSeq: appended[2].Seq + 1, expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			read, err := events.Read(ctx, tt.fromSeq)
+			require.NoError(t, err)
+
+			got := make([]string, len(read))
+			for i, event := range read {
+				got[i] = event.Type
+				if i > 0 {
+					assert.Greater(t, event.Seq, read[i-1].Seq)
+				}
+			}
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+
+	require.NoError(t, events.Append(ctx, &domain.Event{Type: domain.EventCustomerCreated, CustomerID: 8}))
+
+	byCustomer, err := events.ReadByCustomer(ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, byCustomer, 3)
+	assert.Equal(t, appended[0].Seq, byCustomer[0].Seq)
+
+	byCustomers, err := events.ReadByCustomers(ctx, []int64{8, 7, 99})
+	require.NoError(t, err)
+	require.Len(t, byCustomers, 4)
+	assert.Equal(t, []int64{7, 7, 7, 8}, []int64{byCustomers[0].CustomerID, byCustomers[1].CustomerID, byCustomers[2].CustomerID, byCustomers[3].CustomerID})
+
+	none, err := events.ReadByCustomers(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+
+	read, err := events.Read(ctx, 0)
+	require.NoError(t, err)
+	require.NotNil(t, read[1].Customer)
+	assert.Equal(t, 31, read[1].Customer.Age)
+	assert.Nil(t, read[2].Customer)
+	assert.False(t, read[0].OccurredAt.IsZero())
+}
+
+func Test_EventStore_WithinTx(t *testing.T) {
+	r, events := newTestEventStore(t)
+	ctx := context.Background()
+
+	customer := func(email string) *domain.Customer {
+		return &domain.Customer{
+			Name:      "Luis",
+			LastName:  "Gomez",
+			Email:     email,
+			Age:       40,
+			BirthDate: time.Date(1984, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+	}
+
+	t.Run("should commit the state change and the event together", func(t *testing.T) {
+		c := customer("luis@example.com")
+		err := r.WithinTx(ctx, func(ctx context.Context) error {
+			if err := r.Create(ctx, c); err != nil {
+				return err
+			}
+			return events.Append(ctx, &domain.Event{Type: domain.EventCustomerCreated, CustomerID: c.ID, Customer: c})
+		})
+		require.NoError(t, err)
+
+		_, err = r.GetByEmail(ctx, "luis@example.com")
+		require.NoError(t, err)
+
+		read, err := events.Read(ctx, 0)
+		require.NoError(t, err)
+		require.Len(t, read, 1)
+		assert.Equal(t, c.ID, read[0].CustomerID)
+	})
+
+	t.Run("should roll back the state change and the event on error", func(t *testing.T) {
+		c := customer("rollback@example.com")
+		err := r.WithinTx(ctx, func(ctx context.Context) error {
+			require.NoError(t, r.Create(ctx, c))
+			require.NoError(t, events.Append(ctx, &domain.Event{Type: domain.EventCustomerCreated, CustomerID: c.ID, Customer: c}))
+			return errors.New("boom")
+		})
+		require.Error(t, err)
+
+		_, err = r.GetByEmail(ctx, "rollback@example.com")
+		assert.Error(t, err)
+
+		read, err := events.Read(ctx, 0)
+		require.NoError(t, err)
+		assert.Len(t, read, 1)
+	})
+}
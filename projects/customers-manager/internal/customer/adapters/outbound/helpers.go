@@ -5,9 +5,12 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	types "github.com/devpablocristo/tech-house/pkg/types"
 	transport "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/outbound/transport"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
 )
 
 const (
@@ -20,10 +23,23 @@ const (
             email       TEXT NOT NULL UNIQUE,
             phone       TEXT NOT NULL,
             age         INTEGER NOT NULL,
-            birth_date  DATETIME NOT NULL
+            birth_date  DATETIME NOT NULL,
+            created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            anonymized_at DATETIME,
+            updated_at  DATETIME,
+            deleted_at  DATETIME,
+            search_text TEXT
         );
     `
 
+	// Migraciones de columnas agregadas después de la creación inicial de la tabla.
+	// SQLite no admite un default no constante en ALTER TABLE, por eso las filas previas quedan en epoch.
+	addCreatedAtColumn    = `ALTER TABLE customers ADD COLUMN created_at DATETIME NOT NULL DEFAULT '1970-01-01 00:00:00'`
+	addAnonymizedAtColumn = `ALTER TABLE customers ADD COLUMN anonymized_at DATETIME`
+	addUpdatedAtColumn    = `ALTER TABLE customers ADD COLUMN updated_at DATETIME`
+	addDeletedAtColumn    = `ALTER TABLE customers ADD COLUMN deleted_at DATETIME`
+	addSearchTextColumn   = `ALTER TABLE customers ADD COLUMN search_text TEXT`
+
 	// Base select query
 	selectAllCustomersQuery = `
         SELECT  id, 
@@ -32,13 +48,36 @@ const (
                 email, 
                 phone, 
                 age, 
-                birth_date
+                birth_date,
+                created_at,
+                anonymized_at,
+                deleted_at
         FROM    customers
     `
 
-	// Select queries
-	selectCustomerByIDQuery    = selectAllCustomersQuery + ` WHERE id = ?`
-	selectCustomerByEmailQuery = selectAllCustomersQuery + ` WHERE email = ?`
+	// Select queries. Los customers anonimizados solo se leen en los agregados (selectActiveCustomersQuery) y
+	// los eliminados lógicamente solo con las variantes "including deleted"
+	selectActiveCustomersQuery                 = selectAllCustomersQuery + ` WHERE deleted_at IS NULL`
+	selectCustomerByIDQuery                    = selectAllCustomersQuery + ` WHERE id = ? AND anonymized_at IS NULL AND deleted_at IS NULL`
+	selectCustomerByIDIncludingDeletedQuery    = selectAllCustomersQuery + ` WHERE id = ? AND anonymized_at IS NULL`
+	selectCustomerByEmailQuery                 = selectAllCustomersQuery + ` WHERE email = ? AND anonymized_at IS NULL AND deleted_at IS NULL`
+	selectCustomerByEmailIncludingDeletedQuery = selectAllCustomersQuery + ` WHERE email = ? AND anonymized_at IS NULL`
+	countCustomersQuery                        = `SELECT COUNT(*) FROM customers WHERE anonymized_at IS NULL AND deleted_at IS NULL`
+
+	// search_text guarda nombre, apellido y email normalizados (domain.Customer.SearchText) para filtrar la
+	// búsqueda en la base. Las filas sin search_text (grabadas antes de la columna) siempre son candidatas.
+	selectSearchCandidatesQuery = selectAllCustomersQuery + `
+        WHERE   anonymized_at IS NULL AND deleted_at IS NULL
+                AND (search_text IS NULL OR search_text LIKE ? ESCAPE '\')`
+	selectMissingSearchTextQuery = `SELECT id, name, last_name, email FROM customers WHERE search_text IS NULL AND anonymized_at IS NULL`
+	updateSearchTextQuery        = `UPDATE customers SET search_text = ? WHERE id = ?`
+
+	// La última actividad es la última modificación o, si nunca se modificó, el alta. Incluye a los eliminados
+	// lógicamente, así la retención también los purga.
+	selectInactiveCustomersQuery = selectAllCustomersQuery + `
+        WHERE   anonymized_at IS NULL AND COALESCE(updated_at, created_at) < ?
+        ORDER BY COALESCE(updated_at, created_at), id
+        LIMIT ?`
 
 	// Insert query
 	insertCustomerQuery = `
@@ -48,8 +87,10 @@ const (
             email,
             phone,
             age,
-            birth_date
-        ) VALUES (?, ?, ?, ?, ?, ?)
+            birth_date,
+            created_at,
+            search_text
+        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
     `
 
 	// Update query
@@ -60,14 +101,67 @@ const (
                 email = ?, 
                 phone = ?, 
                 age = ?, 
-                birth_date = ?
-        WHERE   id = ?
+                birth_date = ?,
+                updated_at = ?,
+                search_text = ?
+        WHERE   id = ? AND deleted_at IS NULL AND anonymized_at IS NULL
+    `
+
+	// Anonymize query
+	anonymizeCustomerQuery = `
+        UPDATE  customers
+        SET     name = ?,
+                last_name = ?,
+                email = ?,
+                phone = ?,
+                birth_date = ?,
+                anonymized_at = ?,
+                search_text = NULL
+        WHERE   id = ? AND anonymized_at IS NULL
     `
 
-	// Delete query
+	// Soft delete y restore: solo cambian deleted_at de un customer no anonimizado
+	softDeleteCustomerQuery = `UPDATE customers SET deleted_at = ? WHERE id = ? AND anonymized_at IS NULL AND deleted_at IS NULL`
+	restoreCustomerQuery    = `UPDATE customers SET deleted_at = NULL WHERE id = ? AND anonymized_at IS NULL AND deleted_at IS NOT NULL`
+
+	// Delete query. Borra también a los eliminados lógicamente
 	deleteCustomerQuery = `DELETE FROM customers WHERE id = ?`
 )
 
+// sortColumns mapea los campos de domain.SortableFields a columnas; el ORDER BY solo se arma desde acá
+var sortColumns = map[string]string{
+	domain.SortByID:        "id",
+	domain.SortByName:      "name",
+	domain.SortByLastName:  "last_name",
+	domain.SortByAge:       "age",
+	domain.SortByCreatedAt: "created_at",
+}
+
+// customersPageQuery arma la query de una página de customers no anonimizados ni eliminados en el orden indicado,
+// desempatando por id ascendente para que la paginación sea estable
+func customersPageQuery(sort domain.CustomerSort) string {
+	column, ok := sortColumns[sort.Field]
+	if !ok {
+		column = sortColumns[domain.SortByID]
+	}
+	direction := "ASC"
+	if sort.Desc {
+		direction = "DESC"
+	}
+
+	orderBy := column + " " + direction
+	if column != "id" {
+		orderBy += ", id ASC"
+	}
+	return selectAllCustomersQuery + ` WHERE anonymized_at IS NULL AND deleted_at IS NULL ORDER BY ` + orderBy + ` LIMIT ? OFFSET ?`
+}
+
+// likeContains arma el patrón LIKE de un texto contenido en cualquier posición, escapando sus comodines
+func likeContains(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+	return "%" + escaped + "%"
+}
+
 func validateRows(result sql.Result) error {
 	rows, err := result.RowsAffected()
 	if err != nil {
@@ -92,7 +186,8 @@ func scanCustomer(row *sql.Row) (*transport.CustomerDataModel, error) {
 	var model transport.CustomerDataModel
 	err := row.Scan(
 		&model.ID, &model.Name, &model.LastName, &model.Email,
-		&model.Phone, &model.Age, &model.BirthDate,
+		&model.Phone, &model.Age, &model.BirthDate, &model.CreatedAt,
+		&model.AnonymizedAt, &model.DeletedAt,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -111,8 +206,10 @@ func scanCustomer(row *sql.Row) (*transport.CustomerDataModel, error) {
 	return &model, nil
 }
 
+// validateEmailConflict también considera a los customers eliminados lógicamente, que conservan su email
+// (UNIQUE) mientras se los pueda restaurar
 func (r *repository) validateEmailConflict(ctx context.Context, customerID int64, email string) error {
-	existing, err := r.GetByEmail(ctx, email)
+	existing, err := r.getCustomer(ctx, selectCustomerByEmailIncludingDeletedQuery, email)
 	if err != nil {
 		if !types.IsNotFound(err) {
 			return err
@@ -128,3 +225,41 @@ func (r *repository) validateEmailConflict(ctx context.Context, customerID int64
 	}
 	return nil
 }
+
+// defaultQueryTimeout acota cada llamada a la base; es bastante menor que el timeout de la invocación
+// (API Gateway corta a los 29s) para que una query descontrolada no consuma todo el presupuesto
+const defaultQueryTimeout = 5 * time.Second
+
+// queryContext deriva el contexto de una llamada a la base con el query timeout configurado
+func (r *repository) queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, r.queryTimeout)
+}
+
+// isQueryTimeout indica si la llamada se cortó por un deadline (el query timeout o el del request)
+func isQueryTimeout(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+func newQueryTimeoutError(err error) error {
+	return types.NewError(
+		types.ErrTimeout,
+		"query timed out",
+		err,
+	)
+}
+
+// queryError envuelve un error de la base como ErrOperationFailed, salvo los errores de dominio
+// (timeout, not found) que se propagan tal cual para que el mapeo HTTP sea el correcto
+func queryError(err error, message string) error {
+	if _, ok := types.GetErrorType(err); ok {
+		return err
+	}
+	return types.NewError(
+		types.ErrOperationFailed,
+		message,
+		err,
+	)
+}
@@ -0,0 +1,135 @@
+package outbound
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+	ports "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/ports"
+)
+
+const idempotencySchema = `
+CREATE TABLE IF NOT EXISTS customer_idempotency_keys (
+	idempotency_key TEXT PRIMARY KEY,
+	customer_id INTEGER NOT NULL,
+	created_at DATETIME NOT NULL
+);`
+
+const (
+	selectIdempotencyKeyQuery = `
+		SELECT idempotency_key, customer_id, created_at
+		FROM customer_idempotency_keys
+		WHERE idempotency_key = ?`
+
+	// insertIdempotencyKeyQuery graba la key y solo reemplaza un registro vencido (created_at no posterior al
+	// último parámetro); con un registro vigente no modifica ninguna fila
+	insertIdempotencyKeyQuery = `
+		INSERT INTO customer_idempotency_keys (idempotency_key, customer_id, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (idempotency_key) DO UPDATE SET
+			customer_id = excluded.customer_id,
+			created_at = excluded.created_at
+		WHERE customer_idempotency_keys.created_at <= ?`
+)
+
+// idempotencyStore implementa ports.IdempotencyStore sobre la misma base SQLite que el repositorio,
+// para que la key se grabe en la misma transacción que el alta
+type idempotencyStore struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewIdempotencyStore crea el IdempotencyStore sobre la base del repositorio recibido (debe venir de NewRepository)
+func NewIdempotencyStore(repo ports.Repository) (ports.IdempotencyStore, error) {
+	r, ok := repo.(*repository)
+	if !ok {
+		return nil, types.NewError(
+			types.ErrInvalidInput,
+			"idempotency store requires a sqlite repository",
+			nil,
+		)
+	}
+	return newIdempotencyStore(r.sqliteRepo.DB())
+}
+
+func newIdempotencyStore(db *sql.DB) (*idempotencyStore, error) {
+	if _, err := db.Exec(idempotencySchema); err != nil {
+		return nil, types.NewError(
+			types.ErrOperationFailed,
+			"failed to create idempotency schema",
+			err,
+		)
+	}
+	return &idempotencyStore{db: db, now: time.Now}, nil
+}
+
+// Get retorna el registro de la key, o ErrNotFound si nunca se grabó
+func (s *idempotencyStore) Get(ctx context.Context, key string) (*domain.IdempotencyRecord, error) {
+	var record domain.IdempotencyRecord
+	err := s.db.QueryRowContext(ctx, selectIdempotencyKeyQuery, key).
+		Scan(&record.Key, &record.CustomerID, &record.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, types.NewError(
+				types.ErrNotFound,
+				"idempotency key not found",
+				err,
+			)
+		}
+		return nil, types.NewError(
+			types.ErrOperationFailed,
+			"failed to read idempotency key",
+			err,
+		)
+	}
+	return &record, nil
+}
+
+// Save graba la key en la transacción del contexto, si la hay. Una key existente solo se reemplaza si su
+// registro es de expiredAt o anterior; si sigue vigente retorna ErrConflict y la transacción debe deshacerse.
+func (s *idempotencyStore) Save(ctx context.Context, record *domain.IdempotencyRecord, expiredAt time.Time) error {
+	if record == nil {
+		return types.NewError(
+			types.ErrInvalidInput,
+			"idempotency record cannot be nil",
+			nil,
+		)
+	}
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = s.now().UTC()
+	}
+
+	exec := s.db.ExecContext
+	if tx := txFromContext(ctx); tx != nil {
+		exec = tx.ExecContext
+	}
+
+	result, err := exec(ctx, insertIdempotencyKeyQuery, record.Key, record.CustomerID, record.CreatedAt.UTC(), expiredAt.UTC())
+	if err != nil {
+		return types.NewError(
+			types.ErrOperationFailed,
+			"failed to save idempotency key",
+			err,
+		)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return types.NewError(
+			types.ErrOperationFailed,
+			"failed to save idempotency key",
+			err,
+		)
+	}
+	if affected == 0 {
+		return types.NewErrorWithContext(
+			types.ErrConflict,
+			"idempotency key already used",
+			nil,
+			map[string]any{"key": record.Key},
+		)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package outbound
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	core "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+func Test_IdempotencyStore(t *testing.T) {
+	r, _ := newTestRepository(t)
+	store, err := newIdempotencyStore(r.sqliteRepo.DB())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("should return not found for an unknown key", func(t *testing.T) {
+		_, err := store.Get(ctx, "unknown")
+		assert.True(t, types.IsNotFound(err))
+	})
+
+	t.Run("should save a key and replace it only once expired", func(t *testing.T) {
+		first := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+		require.NoError(t, store.Save(ctx, &domain.IdempotencyRecord{Key: "k1", CustomerID: 7, CreatedAt: first}, first.Add(-time.Hour)))
+
+		record, err := store.Get(ctx, "k1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), record.CustomerID)
+		assert.True(t, first.Equal(record.CreatedAt))
+
+		second := first.Add(30 * time.Minute)
+		err = store.Save(ctx, &domain.IdempotencyRecord{Key: "k1", CustomerID: 8, CreatedAt: second}, second.Add(-time.Hour))
+		assert.True(t, types.IsConflict(err))
+
+		record, err = store.Get(ctx, "k1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), record.CustomerID)
+
+		third := first.Add(48 * time.Hour)
+		require.NoError(t, store.Save(ctx, &domain.IdempotencyRecord{Key: "k1", CustomerID: 9, CreatedAt: third}, third.Add(-time.Hour)))
+
+		record, err = store.Get(ctx, "k1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(9), record.CustomerID)
+		assert.True(t, third.Equal(record.CreatedAt))
+	})
+
+	t.Run("should roll back the key with the transaction", func(t *testing.T) {
+		err := r.WithinTx(ctx, func(ctx context.Context) error {
+			require.NoError(t, store.Save(ctx, &domain.IdempotencyRecord{Key: "k2", CustomerID: 8}, time.Now().Add(-time.Hour)))
+			return errors.New("boom")
+		})
+		require.Error(t, err)
+
+		_, err = store.Get(ctx, "k2")
+		assert.True(t, types.IsNotFound(err))
+	})
+}
+
+// racingIdempotencyStore hace que la primera lectura de la key de cada request espere a la de los demás,
+// así todos la encuentran vacía y compiten por grabarla, como dos reintentos simultáneos de API Gateway
+type racingIdempotencyStore struct {
+	*idempotencyStore
+	firstReads sync.WaitGroup
+	mu         sync.Mutex
+	pending    int
+}
+
+func (s *racingIdempotencyStore) Get(ctx context.Context, key string) (*domain.IdempotencyRecord, error) {
+	record, err := s.idempotencyStore.Get(ctx, key)
+	s.mu.Lock()
+	first := s.pending > 0
+	if first {
+		s.pending--
+	}
+	s.mu.Unlock()
+	if first {
+		s.firstReads.Done()
+		s.firstReads.Wait()
+	}
+	return record, err
+}
+
+func Test_IdempotencyStore_ConcurrentCreates(t *testing.T) {
+	r, _ := newTestRepository(t)
+	inner, err := newIdempotencyStore(r.sqliteRepo.DB())
+	require.NoError(t, err)
+
+	const requests = 10
+	store := &racingIdempotencyStore{idempotencyStore: inner, pending: requests}
+	store.firstReads.Add(requests)
+	ucs := core.NewUseCases(r, core.WithIdempotencyStore(store, time.Hour))
+
+	var (
+		wg       sync.WaitGroup
+		ids      [requests]int64
+		replayed [requests]bool
+		errs     [requests]error
+	)
+	for i := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			customer := &domain.Customer{
+				Name:      "Homero",
+				LastName:  "Simpson",
+				Email:     "homero@springfield.com",
+				Phone:     "1234567",
+				Age:       39,
+				BirthDate: time.Date(1985, 5, 12, 0, 0, 0, 0, time.UTC),
+			}
+			_, replayed[i], errs[i] = ucs.CreateCustomerIdempotent(context.Background(), "retry-1", customer)
+			ids[i] = customer.ID
+		}()
+	}
+	wg.Wait()
+
+	created := 0
+	for i := range requests {
+		require.NoError(t, errs[i])
+		assert.Equal(t, ids[0], ids[i])
+		if !replayed[i] {
+			created++
+		}
+	}
+	assert.Equal(t, 1, created)
+
+	customers, err := r.GetAll(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, customers, 2) // el customer de newTestRepository y el del alta idempotente
+
+	record, err := store.Get(context.Background(), "retry-1")
+	require.NoError(t, err)
+	assert.Equal(t, ids[0], record.CustomerID)
+}
@@ -0,0 +1,51 @@
+package outbound
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+)
+
+// slowSelectStub simula una query que no termina hasta que se cancela su contexto
+type slowSelectStub struct {
+	sqliteStub
+}
+
+func (slowSelectStub) SelectContext(ctx context.Context, _ any, _ string, _ ...any) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func Test_repository_QueryTimeout(t *testing.T) {
+	t.Run("should cancel a slow query at the query timeout", func(t *testing.T) {
+		r := newRepository(slowSelectStub{}, WithQueryTimeout(20*time.Millisecond))
+
+		// El request tiene mucho más presupuesto que la query
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		start := time.Now()
+		_, err := r.GetAll(ctx)
+		elapsed := time.Since(start)
+
+		require.Error(t, err)
+		errType, ok := types.GetErrorType(err)
+		require.True(t, ok)
+		assert.Equal(t, types.ErrTimeout, errType)
+		assert.Less(t, elapsed, time.Second)
+		assert.NoError(t, ctx.Err())
+	})
+
+	t.Run("should default to a timeout shorter than the request", func(t *testing.T) {
+		r := newRepository(sqliteStub{})
+		assert.Equal(t, defaultQueryTimeout, r.queryTimeout)
+
+		r = newRepository(sqliteStub{}, WithQueryTimeout(0))
+		assert.Equal(t, defaultQueryTimeout, r.queryTimeout)
+	})
+}
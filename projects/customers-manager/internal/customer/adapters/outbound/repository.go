@@ -3,8 +3,9 @@ package outbound
 import (
 	"context"
 	"database/sql"
-	"errors"
+	"time"
 
+	pkglogger "github.com/devpablocristo/tech-house/pkg/config/logger"
 	sqrepo "github.com/devpablocristo/tech-house/pkg/databases/sql/sqlite"
 	sqdefs "github.com/devpablocristo/tech-house/pkg/databases/sql/sqlite/defs"
 
@@ -15,10 +16,43 @@ import (
 )
 
 type repository struct {
-	sqliteRepo sqdefs.Repository
+	sqliteRepo         sqdefs.Repository
+	slowQueryThreshold time.Duration
+	now                func() time.Time
+	warnf              func(format string, v ...any)
+	statementCache     bool
+	stmts              *stmtCache
+	queryTimeout       time.Duration
 }
 
-func NewRepository() (ports.Repository, error) {
+// RepositoryOption configura opciones del repositorio
+type RepositoryOption func(*repository)
+
+// WithSlowQueryThreshold loguea en Warn las operaciones que tardan más que el umbral; 0 lo deshabilita
+func WithSlowQueryThreshold(threshold time.Duration) RepositoryOption {
+	return func(r *repository) {
+		r.slowQueryThreshold = threshold
+	}
+}
+
+// WithQueryTimeout acota cada llamada a la base; una query que lo excede se cancela con ErrTimeout.
+// Con 0 se usa defaultQueryTimeout y con un valor negativo se deshabilita.
+func WithQueryTimeout(timeout time.Duration) RepositoryOption {
+	return func(r *repository) {
+		if timeout != 0 {
+			r.queryTimeout = timeout
+		}
+	}
+}
+
+// WithStatementCache reutiliza prepared statements por query en lugar de preparar en cada llamada
+func WithStatementCache(enabled bool) RepositoryOption {
+	return func(r *repository) {
+		r.statementCache = enabled
+	}
+}
+
+func NewRepository(opts ...RepositoryOption) (ports.Repository, error) {
 	r, err := sqrepo.Bootstrap()
 	if err != nil {
 		return nil, types.NewError(
@@ -36,9 +70,43 @@ func NewRepository() (ports.Repository, error) {
 		)
 	}
 
-	return &repository{
-		sqliteRepo: r,
-	}, nil
+	return newRepository(r, opts...), nil
+}
+
+func newRepository(sqliteRepo sqdefs.Repository, opts ...RepositoryOption) *repository {
+	r := &repository{
+		sqliteRepo:   sqliteRepo,
+		now:          time.Now,
+		warnf:        pkglogger.Warn,
+		queryTimeout: defaultQueryTimeout,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.statementCache {
+		r.stmts = newStmtCache(sqliteRepo.DB())
+	}
+	return r
+}
+
+// Ping verifica que la base responde, sin leer datos
+func (r *repository) Ping(ctx context.Context) error {
+	if err := r.sqliteRepo.DB().PingContext(ctx); err != nil {
+		return types.NewError(
+			types.ErrConnection,
+			"failed to ping database",
+			err,
+		)
+	}
+	return nil
+}
+
+// Close libera los prepared statements cacheados; se llama al apagar el servicio
+func (r *repository) Close() error {
+	if r.stmts == nil {
+		return nil
+	}
+	return r.stmts.Close()
 }
 
 func initSchema(sqliteRepo *sql.DB) error {
@@ -50,18 +118,113 @@ func initSchema(sqliteRepo *sql.DB) error {
 			err,
 		)
 	}
+
+	if err := addColumnIfMissing(sqliteRepo, "created_at", addCreatedAtColumn); err != nil {
+		return types.NewError(
+			types.ErrOperationFailed,
+			"failed to migrate schema",
+			err,
+		)
+	}
+	if err := addColumnIfMissing(sqliteRepo, "anonymized_at", addAnonymizedAtColumn); err != nil {
+		return types.NewError(
+			types.ErrOperationFailed,
+			"failed to migrate schema",
+			err,
+		)
+	}
+	if err := addColumnIfMissing(sqliteRepo, "updated_at", addUpdatedAtColumn); err != nil {
+		return types.NewError(
+			types.ErrOperationFailed,
+			"failed to migrate schema",
+			err,
+		)
+	}
+	if err := addColumnIfMissing(sqliteRepo, "deleted_at", addDeletedAtColumn); err != nil {
+		return types.NewError(
+			types.ErrOperationFailed,
+			"failed to migrate schema",
+			err,
+		)
+	}
+	if err := addColumnIfMissing(sqliteRepo, "search_text", addSearchTextColumn); err != nil {
+		return types.NewError(
+			types.ErrOperationFailed,
+			"failed to migrate schema",
+			err,
+		)
+	}
+	if err := backfillSearchText(sqliteRepo); err != nil {
+		return types.NewError(
+			types.ErrOperationFailed,
+			"failed to backfill search text",
+			err,
+		)
+	}
 	return nil
 }
 
+// backfillSearchText completa search_text de los customers grabados antes de la columna. Se calcula en Go
+// porque SQLite no sabe quitar acentos.
+func backfillSearchText(db *sql.DB) error {
+	rows, err := db.Query(selectMissingSearchTextQuery)
+	if err != nil {
+		return err
+	}
+	var pending []domain.Customer
+	for rows.Next() {
+		var c domain.Customer
+		if err := rows.Scan(&c.ID, &c.Name, &c.LastName, &c.Email); err != nil {
+			rows.Close()
+			return err
+		}
+		pending = append(pending, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, c := range pending {
+		if _, err := db.Exec(updateSearchTextQuery, c.SearchText(), c.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumnIfMissing aplica la migración solo si la tabla customers todavía no tiene la columna
+func addColumnIfMissing(db *sql.DB, column, migration string) error {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('customers') WHERE name = ?`, column).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err = db.Exec(migration)
+	return err
+}
+
 func (r *repository) GetAll(ctx context.Context) ([]domain.Customer, error) {
+	defer r.trackQuery(ctx, "GetAll")()
+
+	return r.getAll(ctx, selectActiveCustomersQuery)
+}
+
+// GetAllIncludingDeleted retorna todos los customers, también los eliminados lógicamente
+func (r *repository) GetAllIncludingDeleted(ctx context.Context) ([]domain.Customer, error) {
+	defer r.trackQuery(ctx, "GetAllIncludingDeleted")()
+
+	return r.getAll(ctx, selectAllCustomersQuery)
+}
+
+func (r *repository) getAll(ctx context.Context, query string) ([]domain.Customer, error) {
 	var models []transport.CustomerDataModel
-	err := r.sqliteRepo.SelectContext(ctx, &models, selectAllCustomersQuery)
+	err := r.selectContext(ctx, &models, query)
 	if err != nil {
-		return nil, types.NewError(
-			types.ErrOperationFailed,
-			"failed to fetch customers",
-			err,
-		)
+		return nil, queryError(err, "failed to fetch customers")
 	}
 
 	customers := make([]domain.Customer, len(models))
@@ -71,8 +234,88 @@ func (r *repository) GetAll(ctx context.Context) ([]domain.Customer, error) {
 	return customers, nil
 }
 
+func (r *repository) GetPage(ctx context.Context, limit, offset int, sort domain.CustomerSort) ([]domain.Customer, error) {
+	defer r.trackQuery(ctx, "GetPage")()
+
+	var models []transport.CustomerDataModel
+	err := r.selectContext(ctx, &models, customersPageQuery(sort), limit, offset)
+	if err != nil {
+		return nil, queryError(err, "failed to fetch customers page")
+	}
+
+	customers := make([]domain.Customer, len(models))
+	for i, model := range models {
+		customers[i] = *transport.CustomerDataModelToDomain(&model)
+	}
+	return customers, nil
+}
+
+// SearchCandidates retorna los customers activos cuyo search_text contiene needle, más los que todavía no tienen
+// search_text; el caso de uso confirma cada candidato
+func (r *repository) SearchCandidates(ctx context.Context, needle string) ([]domain.Customer, error) {
+	defer r.trackQuery(ctx, "SearchCandidates")()
+
+	var models []transport.CustomerDataModel
+	err := r.selectContext(ctx, &models, selectSearchCandidatesQuery, likeContains(needle))
+	if err != nil {
+		return nil, queryError(err, "failed to search customers")
+	}
+
+	customers := make([]domain.Customer, len(models))
+	for i, model := range models {
+		customers[i] = *transport.CustomerDataModelToDomain(&model)
+	}
+	return customers, nil
+}
+
+// GetInactive retorna hasta limit customers no anonimizados sin actividad desde before, los más antiguos primero
+func (r *repository) GetInactive(ctx context.Context, before time.Time, limit int) ([]domain.Customer, error) {
+	defer r.trackQuery(ctx, "GetInactive")()
+
+	var models []transport.CustomerDataModel
+	err := r.selectContext(ctx, &models, selectInactiveCustomersQuery, before.UTC(), limit)
+	if err != nil {
+		return nil, queryError(err, "failed to fetch inactive customers")
+	}
+
+	customers := make([]domain.Customer, len(models))
+	for i, model := range models {
+		customers[i] = *transport.CustomerDataModelToDomain(&model)
+	}
+	return customers, nil
+}
+
+func (r *repository) Count(ctx context.Context) (int, error) {
+	defer r.trackQuery(ctx, "Count")()
+
+	ctx, cancel := r.queryContext(ctx)
+	defer cancel()
+
+	var total int
+	if err := r.queryRow(ctx, countCustomersQuery).Scan(&total); err != nil {
+		if isQueryTimeout(ctx) {
+			return 0, newQueryTimeoutError(err)
+		}
+		return 0, queryError(err, "failed to count customers")
+	}
+	return total, nil
+}
+
 func (r *repository) GetByID(ctx context.Context, id int64) (*domain.Customer, error) {
-	model, err := scanCustomer(r.sqliteRepo.QueryRowContext(ctx, selectCustomerByIDQuery, id))
+	defer r.trackQuery(ctx, "GetByID")()
+
+	model, err := r.getCustomer(ctx, selectCustomerByIDQuery, id)
+	if err != nil {
+		return nil, err
+	}
+	return transport.CustomerDataModelToDomain(model), nil
+}
+
+// GetByIDIncludingDeleted busca un customer no anonimizado aunque esté eliminado lógicamente
+func (r *repository) GetByIDIncludingDeleted(ctx context.Context, id int64) (*domain.Customer, error) {
+	defer r.trackQuery(ctx, "GetByIDIncludingDeleted")()
+
+	model, err := r.getCustomer(ctx, selectCustomerByIDIncludingDeletedQuery, id)
 	if err != nil {
 		return nil, err
 	}
@@ -80,7 +323,9 @@ func (r *repository) GetByID(ctx context.Context, id int64) (*domain.Customer, e
 }
 
 func (r *repository) GetByEmail(ctx context.Context, email string) (*domain.Customer, error) {
-	model, err := scanCustomer(r.sqliteRepo.QueryRowContext(ctx, selectCustomerByEmailQuery, email))
+	defer r.trackQuery(ctx, "GetByEmail")()
+
+	model, err := r.getCustomer(ctx, selectCustomerByEmailQuery, email)
 	if err != nil {
 		return nil, err
 	}
@@ -88,42 +333,37 @@ func (r *repository) GetByEmail(ctx context.Context, email string) (*domain.Cust
 }
 
 func (r *repository) Create(ctx context.Context, customer *domain.Customer) error {
+	defer r.trackQuery(ctx, "Create")()
+
 	if err := r.validateEmailConflict(ctx, 0, customer.Email); err != nil {
 		return err
 	}
 
+	if customer.CreatedAt.IsZero() {
+		customer.CreatedAt = r.now().UTC()
+	}
+
 	model := transport.DomainToCustomerDataModel(customer)
-	_, err := r.sqliteRepo.DB().ExecContext(ctx, insertCustomerQuery,
+	result, err := r.exec(ctx, insertCustomerQuery,
 		model.Name, model.LastName, model.Email,
-		model.Phone, model.Age, model.BirthDate,
+		model.Phone, model.Age, model.BirthDate, model.CreatedAt, customer.SearchText(),
 	)
 	if err != nil {
-		return types.NewError(
-			types.ErrOperationFailed,
-			"failed to create customer",
-			err,
-		)
+		return queryError(err, "failed to create customer")
+	}
+
+	if id, err := result.LastInsertId(); err == nil {
+		customer.ID = id
 	}
 	return nil
 }
 
 func (r *repository) Update(ctx context.Context, customer *domain.Customer) error {
+	defer r.trackQuery(ctx, "Update")()
+
 	// Verificar que existe el customer primero
-	var customers []transport.CustomerDataModel
-	err := r.sqliteRepo.SelectContext(ctx, &customers, selectCustomerByIDQuery, customer.ID)
-	if err != nil {
-		return types.NewError(
-			types.ErrOperationFailed,
-			"failed to fetch customer",
-			err,
-		)
-	}
-	if len(customers) == 0 {
-		return types.NewError(
-			types.ErrNotFound,
-			"customer not found",
-			errors.New("no details available"),
-		)
+	if _, err := r.getCustomer(ctx, selectCustomerByIDQuery, customer.ID); err != nil {
+		return err
 	}
 
 	// Validar conflicto de email
@@ -133,16 +373,12 @@ func (r *repository) Update(ctx context.Context, customer *domain.Customer) erro
 
 	// Hacer el update - usando el mismo wrapper
 	model := transport.DomainToCustomerDataModel(customer)
-	result, err := r.sqliteRepo.ExecContext(ctx, updateCustomerQuery, // <-- Aquí está el cambio
+	result, err := r.exec(ctx, updateCustomerQuery,
 		model.Name, model.LastName, model.Email,
-		model.Phone, model.Age, model.BirthDate, model.ID,
+		model.Phone, model.Age, model.BirthDate, r.now().UTC(), customer.SearchText(), model.ID,
 	)
 	if err != nil {
-		return types.NewError(
-			types.ErrOperationFailed,
-			"failed to update customer",
-			err,
-		)
+		return queryError(err, "failed to update customer")
 	}
 
 	return validateRows(result)
@@ -180,14 +416,52 @@ func (r *repository) Update(ctx context.Context, customer *domain.Customer) erro
 // 	return validateRows(result)
 // }
 
+// Anonymize guarda los placeholders de un customer todavía no anonimizado; NotFound si no existe o ya lo está
+func (r *repository) Anonymize(ctx context.Context, customer *domain.Customer) error {
+	defer r.trackQuery(ctx, "Anonymize")()
+
+	model := transport.DomainToCustomerDataModel(customer)
+	result, err := r.exec(ctx, anonymizeCustomerQuery,
+		model.Name, model.LastName, model.Email,
+		model.Phone, model.BirthDate, model.AnonymizedAt, model.ID,
+	)
+	if err != nil {
+		return queryError(err, "failed to anonymize customer")
+	}
+
+	return validateRows(result)
+}
+
+// SoftDelete marca el customer como eliminado en deletedAt; NotFound si no existe, está anonimizado o ya se eliminó
+func (r *repository) SoftDelete(ctx context.Context, id int64, deletedAt time.Time) error {
+	defer r.trackQuery(ctx, "SoftDelete")()
+
+	result, err := r.exec(ctx, softDeleteCustomerQuery, deletedAt.UTC(), id)
+	if err != nil {
+		return queryError(err, "failed to soft delete customer")
+	}
+
+	return validateRows(result)
+}
+
+// Restore revierte la eliminación lógica; NotFound si no existe, está anonimizado o no estaba eliminado
+func (r *repository) Restore(ctx context.Context, id int64) error {
+	defer r.trackQuery(ctx, "Restore")()
+
+	result, err := r.exec(ctx, restoreCustomerQuery, id)
+	if err != nil {
+		return queryError(err, "failed to restore customer")
+	}
+
+	return validateRows(result)
+}
+
 func (r *repository) Delete(ctx context.Context, id int64) error {
-	result, err := r.sqliteRepo.DB().ExecContext(ctx, deleteCustomerQuery, id)
+	defer r.trackQuery(ctx, "Delete")()
+
+	result, err := r.exec(ctx, deleteCustomerQuery, id)
 	if err != nil {
-		return types.NewError(
-			types.ErrOperationFailed,
-			"failed to delete customer",
-			err,
-		)
+		return queryError(err, "failed to delete customer")
 	}
 
 	return validateRows(result)
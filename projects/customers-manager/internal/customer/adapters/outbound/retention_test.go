@@ -0,0 +1,66 @@
+package outbound
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+func Test_repository_GetInactive(t *testing.T) {
+	r, anaID := newTestRepository(t)
+	ctx := context.Background()
+
+	cutoff := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	create := func(name string, createdAt time.Time) *domain.Customer {
+		customer := &domain.Customer{
+			Name:      name,
+			LastName:  "Test",
+			Email:     fmt.Sprintf("%s@example.com", name),
+			Phone:     "1234567",
+			Age:       30,
+			BirthDate: time.Date(1994, 1, 1, 0, 0, 0, 0, time.UTC),
+			CreatedAt: createdAt,
+		}
+		require.NoError(t, r.Create(ctx, customer))
+		return customer
+	}
+
+	// Ana se creó con la fecha actual; el resto queda a ambos lados del umbral
+	oldest := create("oldest", cutoff.Add(-72*time.Hour))
+	old := create("old", cutoff.Add(-time.Second))
+	create("boundary", cutoff)
+	create("recent", cutoff.Add(time.Hour))
+
+	updated := create("updated", cutoff.Add(-48*time.Hour))
+	r.now = func() time.Time { return cutoff.Add(time.Minute) }
+	require.NoError(t, r.Update(ctx, updated))
+
+	anonymized := create("anonymized", cutoff.Add(-24*time.Hour))
+	anonymized.Anonymize("0123456789abcdef", cutoff)
+	require.NoError(t, r.Anonymize(ctx, anonymized))
+
+	t.Run("should return customers whose last activity is before the cutoff, oldest first", func(t *testing.T) {
+		inactive, err := r.GetInactive(ctx, cutoff, 10)
+		require.NoError(t, err)
+
+		ids := make([]int64, len(inactive))
+		for i, c := range inactive {
+			ids[i] = c.ID
+		}
+		assert.Equal(t, []int64{oldest.ID, old.ID}, ids)
+		assert.NotContains(t, ids, anaID)
+	})
+
+	t.Run("should honor the batch limit", func(t *testing.T) {
+		inactive, err := r.GetInactive(ctx, cutoff, 1)
+		require.NoError(t, err)
+		require.Len(t, inactive, 1)
+		assert.Equal(t, oldest.ID, inactive[0].ID)
+	})
+}
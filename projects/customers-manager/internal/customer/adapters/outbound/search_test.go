@@ -0,0 +1,100 @@
+package outbound
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+func Test_repository_SearchCandidates(t *testing.T) {
+	r, anaID := newTestRepository(t)
+	ctx := context.Background()
+
+	create := func(name, lastName, email string) int64 {
+		customer := &domain.Customer{
+			Name:      name,
+			LastName:  lastName,
+			Email:     email,
+			Phone:     "1234567",
+			Age:       40,
+			BirthDate: time.Date(1985, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		require.NoError(t, r.Create(ctx, customer))
+		return customer.ID
+	}
+	joseID := create("José", "Pérez", "jose@example.com")
+	underscoreID := create("Zoe", "Ab_c", "zoe@example.com")
+	wildcardID := create("Zoe", "Abxc", "zoe2@example.com")
+
+	search := func(needle string) []int64 {
+		t.Helper()
+		customers, err := r.SearchCandidates(ctx, needle)
+		require.NoError(t, err)
+		return idsOf(customers)
+	}
+
+	t.Run("should filter by the normalized name, last name and email", func(t *testing.T) {
+		assert.Equal(t, []int64{joseID}, search("jose"))
+		assert.Equal(t, []int64{anaID, joseID}, search("perez"))
+		assert.Equal(t, []int64{anaID, joseID, underscoreID, wildcardID}, search("example.com"))
+		assert.Empty(t, search("zzz"))
+	})
+
+	t.Run("should treat LIKE wildcards in the query literally", func(t *testing.T) {
+		assert.Equal(t, []int64{underscoreID}, search("b_c"))
+		assert.Empty(t, search("%"))
+	})
+
+	t.Run("should keep the search text in sync on update", func(t *testing.T) {
+		customer, err := r.GetByID(ctx, joseID)
+		require.NoError(t, err)
+		customer.Name = "Joaquín"
+		require.NoError(t, r.Update(ctx, customer))
+
+		assert.Equal(t, []int64{joseID}, search("joaquin"))
+	})
+
+	t.Run("should leave out soft deleted and anonymized customers", func(t *testing.T) {
+		require.NoError(t, r.SoftDelete(ctx, underscoreID, time.Now()))
+
+		customer, err := r.GetByID(ctx, wildcardID)
+		require.NoError(t, err)
+		customer.Anonymize("0123456789abcdef", time.Now())
+		require.NoError(t, r.Anonymize(ctx, customer))
+
+		var searchText sql.NullString
+		require.NoError(t, r.sqliteRepo.DB().QueryRow(`SELECT search_text FROM customers WHERE id = ?`, wildcardID).Scan(&searchText))
+		assert.False(t, searchText.Valid, "anonymize must drop the normalized personal data")
+
+		assert.Equal(t, []int64{anaID, joseID}, search("example.com"))
+	})
+
+	t.Run("should return rows without search text and backfill them on start", func(t *testing.T) {
+		db := r.sqliteRepo.DB()
+		result, err := db.Exec(`INSERT INTO customers (name, last_name, email, phone, age, birth_date) VALUES (?, ?, ?, ?, ?, ?)`,
+			"Émile", "Legacy", "emile@example.org", "1234567", 50, time.Date(1975, 1, 1, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		legacyID, err := result.LastInsertId()
+		require.NoError(t, err)
+
+		assert.Contains(t, search("zzz"), legacyID)
+
+		require.NoError(t, initSchema(db))
+		assert.NotContains(t, search("zzz"), legacyID)
+		assert.Equal(t, []int64{legacyID}, search("emile"))
+	})
+}
+
+func idsOf(customers []domain.Customer) []int64 {
+	ids := make([]int64, len(customers))
+	for i, c := range customers {
+		ids[i] = c.ID
+	}
+	return ids
+}
@@ -0,0 +1,36 @@
+package outbound
+
+import (
+	"context"
+	"expvar"
+
+	pkglogger "github.com/devpablocristo/tech-house/pkg/config/logger"
+)
+
+// slowQueries cuenta las queries lentas por operación; se publica como "customers_slow_queries" en
+// GET /api/{version}/admin/debug/vars, que requiere el scope admin
+var slowQueries = expvar.NewMap("customers_slow_queries")
+
+// trackQuery mide la duración de una operación del repositorio y, si supera el umbral configurado,
+// la registra en Warn y la cuenta en slowQueries. Solo se loguean el nombre de la operación y la
+// duración, nunca los parámetros, para no filtrar datos personales. Si el contexto trae un logger se usa
+// ese, así la línea sale con los campos del request. Uso: defer r.trackQuery(ctx, "GetByID")()
+func (r *repository) trackQuery(ctx context.Context, operation string) func() {
+	if r.slowQueryThreshold <= 0 {
+		return func() {}
+	}
+
+	start := r.now()
+	return func() {
+		elapsed := r.now().Sub(start)
+		if elapsed < r.slowQueryThreshold {
+			return
+		}
+		slowQueries.Add(operation, 1)
+		warnf := r.warnf
+		if logger := pkglogger.FromContext(ctx); logger.Enabled() {
+			warnf = logger.Warn
+		}
+		warnf("slow query: operation=%s duration=%s threshold=%s", operation, elapsed, r.slowQueryThreshold)
+	}
+}
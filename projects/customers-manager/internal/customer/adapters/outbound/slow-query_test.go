@@ -0,0 +1,102 @@
+package outbound
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkglogger "github.com/devpablocristo/tech-house/pkg/config/logger"
+	sqdefs "github.com/devpablocristo/tech-house/pkg/databases/sql/sqlite/defs"
+)
+
+// sqliteStub implementa sqdefs.Repository sin base de datos; solo SelectContext hace falta en estos tests
+type sqliteStub struct{}
+
+func (sqliteStub) Connect(sqdefs.Config) error                                     { return nil }
+func (sqliteStub) Close()                                                          {}
+func (sqliteStub) DB() *sql.DB                                                     { return nil }
+func (sqliteStub) SelectContext(context.Context, any, string, ...any) error        { return nil }
+func (sqliteStub) QueryRowContext(context.Context, string, ...any) *sql.Row        { return nil }
+func (sqliteStub) ExecContext(context.Context, string, ...any) (sql.Result, error) { return nil, nil }
+
+// stepClock avanza un paso fijo en cada lectura, simulando que cada query tarda step
+func stepClock(step time.Duration) func() time.Time {
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		current = current.Add(step)
+		return current
+	}
+}
+
+func Test_repository_SlowQueryLog(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		duration  time.Duration
+		wantLog   bool
+	}{
+		{name: "should log a query over the threshold", threshold: 100 * time.Millisecond, duration: 250 * time.Millisecond, wantLog: true},
+		{name: "should not log a query under the threshold", threshold: 100 * time.Millisecond, duration: 50 * time.Millisecond},
+		{name: "should not log when disabled", threshold: 0, duration: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs []string
+			r := newRepository(sqliteStub{}, WithSlowQueryThreshold(tt.threshold))
+			r.now = stepClock(tt.duration)
+			r.warnf = func(format string, v ...any) {
+				logs = append(logs, fmt.Sprintf(format, v...))
+			}
+
+			before := slowQueryCount("GetAll")
+			_, err := r.GetAll(context.Background())
+			require.NoError(t, err)
+
+			if !tt.wantLog {
+				assert.Empty(t, logs)
+				assert.Equal(t, before, slowQueryCount("GetAll"))
+				return
+			}
+
+			require.Len(t, logs, 1)
+			assert.Contains(t, logs[0], "operation=GetAll")
+			assert.Contains(t, logs[0], "duration="+tt.duration.String())
+			assert.Equal(t, before+1, slowQueryCount("GetAll"))
+		})
+	}
+}
+
+func Test_repository_SlowQueryLog_ContextFields(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	r := newRepository(sqliteStub{}, WithSlowQueryThreshold(time.Millisecond))
+	r.now = stepClock(time.Second)
+	r.warnf = func(format string, v ...any) {
+		t.Fatalf("the context logger should be used instead of the default one")
+	}
+
+	ctx := pkglogger.WithFields(context.Background(), "request_id", "abc-123")
+	_, err := r.GetAll(ctx)
+	require.NoError(t, err)
+
+	assert.Contains(t, buf.String(), "operation=GetAll")
+	assert.Contains(t, buf.String(), "request_id=abc-123")
+}
+
+func slowQueryCount(operation string) int64 {
+	if v, ok := slowQueries.Get(operation).(interface{ Value() int64 }); ok {
+		return v.Value()
+	}
+	return 0
+}
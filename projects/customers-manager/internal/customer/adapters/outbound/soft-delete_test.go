@@ -0,0 +1,77 @@
+package outbound
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+func Test_repository_SoftDelete(t *testing.T) {
+	r, id := newTestRepository(t)
+	ctx := context.Background()
+	deletedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, r.SoftDelete(ctx, id, deletedAt))
+
+	t.Run("should hide the customer from detail, email lookup, listing and count", func(t *testing.T) {
+		_, err := r.GetByID(ctx, id)
+		assert.True(t, types.IsNotFound(err))
+
+		_, err = r.GetByEmail(ctx, "ana@example.com")
+		assert.True(t, types.IsNotFound(err))
+
+		customers, err := r.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, customers)
+
+		total, err := r.Count(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, total)
+	})
+
+	t.Run("should keep the customer when including the deleted ones", func(t *testing.T) {
+		customer, err := r.GetByIDIncludingDeleted(ctx, id)
+		require.NoError(t, err)
+		require.NotNil(t, customer.DeletedAt)
+		assert.True(t, deletedAt.Equal(*customer.DeletedAt))
+
+		customers, err := r.GetAllIncludingDeleted(ctx)
+		require.NoError(t, err)
+		assert.Len(t, customers, 1)
+	})
+
+	t.Run("should keep the email reserved", func(t *testing.T) {
+		err := r.Create(ctx, &domain.Customer{
+			Name:      "Otra",
+			LastName:  "Persona",
+			Email:     "ana@example.com",
+			Phone:     "1234567890",
+			Age:       30,
+			BirthDate: time.Date(1995, 1, 1, 0, 0, 0, 0, time.UTC),
+		})
+		assert.True(t, types.IsConflict(err))
+	})
+
+	t.Run("should not update nor soft delete twice", func(t *testing.T) {
+		customer, err := r.GetByIDIncludingDeleted(ctx, id)
+		require.NoError(t, err)
+		assert.True(t, types.IsNotFound(r.Update(ctx, customer)))
+		assert.True(t, types.IsNotFound(r.SoftDelete(ctx, id, deletedAt)))
+	})
+
+	t.Run("should restore the customer once", func(t *testing.T) {
+		require.NoError(t, r.Restore(ctx, id))
+
+		customer, err := r.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.Nil(t, customer.DeletedAt)
+
+		assert.True(t, types.IsNotFound(r.Restore(ctx, id)))
+	})
+}
@@ -0,0 +1,83 @@
+package outbound
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+func Test_repository_GetPage_Sort(t *testing.T) {
+	r, anaID := newTestRepository(t)
+	ctx := context.Background()
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ids := map[string]int64{"ana": anaID}
+	for _, c := range []struct {
+		name      string
+		age       int
+		createdAt time.Time
+	}{
+		{"bruno", 45, base.Add(time.Hour)},
+		{"carla", 25, base},
+		{"diego", 45, base.Add(time.Hour)},
+	} {
+		customer := &domain.Customer{
+			Name:      c.name,
+			LastName:  "Test",
+			Email:     fmt.Sprintf("%s@example.com", c.name),
+			Phone:     "1234567",
+			Age:       c.age,
+			BirthDate: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+			CreatedAt: c.createdAt,
+		}
+		require.NoError(t, r.Create(ctx, customer))
+		ids[c.name] = customer.ID
+	}
+
+	// Ana se creó con la fecha actual y tiene 30 años
+	tests := []struct {
+		name string
+		sort domain.CustomerSort
+		want []string
+	}{
+		{"should order by id by default", domain.DefaultCustomerSort, []string{"ana", "bruno", "carla", "diego"}},
+		{"should order by created_at desc breaking ties by id", domain.CustomerSort{Field: domain.SortByCreatedAt, Desc: true}, []string{"ana", "bruno", "diego", "carla"}},
+		{"should order by age breaking ties by id", domain.CustomerSort{Field: domain.SortByAge}, []string{"carla", "ana", "bruno", "diego"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := r.GetPage(ctx, 10, 0, tt.sort)
+			require.NoError(t, err)
+
+			want := make([]int64, len(tt.want))
+			for i, name := range tt.want {
+				want[i] = ids[name]
+			}
+			got := make([]int64, len(page))
+			for i, c := range page {
+				got[i] = c.ID
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+
+	t.Run("should paginate without overlaps on ties", func(t *testing.T) {
+		sort := domain.CustomerSort{Field: domain.SortByAge, Desc: true}
+		first, err := r.GetPage(ctx, 1, 0, sort)
+		require.NoError(t, err)
+		second, err := r.GetPage(ctx, 1, 1, sort)
+		require.NoError(t, err)
+
+		require.Len(t, first, 1)
+		require.Len(t, second, 1)
+		assert.Equal(t, ids["bruno"], first[0].ID)
+		assert.Equal(t, ids["diego"], second[0].ID)
+	})
+}
@@ -0,0 +1,149 @@
+package outbound
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+
+	transport "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/outbound/transport"
+)
+
+// stmtCache guarda los prepared statements por texto de query.
+// Un *sql.Stmt es válido para todo el pool: database/sql lo vuelve a preparar de forma transparente
+// en cada conexión que lo necesite, así que alcanza con uno por query.
+type stmtCache struct {
+	db    *sql.DB
+	mu    sync.Mutex
+	stmts map[string]*sql.Stmt
+}
+
+func newStmtCache(db *sql.DB) *stmtCache {
+	return &stmtCache{
+		db:    db,
+		stmts: make(map[string]*sql.Stmt),
+	}
+}
+
+// get retorna el statement cacheado para la query o lo prepara la primera vez
+func (c *stmtCache) get(ctx context.Context, query string) (*sql.Stmt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if stmt, ok := c.stmts[query]; ok {
+		return stmt, nil
+	}
+
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	c.stmts[query] = stmt
+	return stmt, nil
+}
+
+// evict descarta el statement de una query, para que el próximo uso lo prepare de nuevo
+func (c *stmtCache) evict(query string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if stmt, ok := c.stmts[query]; ok {
+		_ = stmt.Close()
+		delete(c.stmts, query)
+	}
+}
+
+// Close cierra todos los statements cacheados
+func (c *stmtCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	for query, stmt := range c.stmts {
+		if err := stmt.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(c.stmts, query)
+	}
+	return errors.Join(errs...)
+}
+
+// isStaleStatementError indica si el error se debe a una conexión reseteada o a un statement inválido,
+// casos en los que conviene descartar el statement cacheado
+func isStaleStatementError(err error) bool {
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone)
+}
+
+// getCustomer busca un customer con una query de una fila, acotada por el query timeout
+func (r *repository) getCustomer(ctx context.Context, query string, args ...any) (*transport.CustomerDataModel, error) {
+	ctx, cancel := r.queryContext(ctx)
+	defer cancel()
+
+	model, err := scanCustomer(r.queryRow(ctx, query, args...))
+	if err != nil && isQueryTimeout(ctx) {
+		return nil, newQueryTimeoutError(err)
+	}
+	return model, err
+}
+
+// selectContext ejecuta una query de varias filas acotada por el query timeout
+func (r *repository) selectContext(ctx context.Context, dest any, query string, args ...any) error {
+	ctx, cancel := r.queryContext(ctx)
+	defer cancel()
+
+	err := r.sqliteRepo.SelectContext(ctx, dest, query, args...)
+	if err != nil && isQueryTimeout(ctx) {
+		return newQueryTimeoutError(err)
+	}
+	return err
+}
+
+// queryRow ejecuta una query de una fila usando el statement cacheado si el cache está habilitado
+func (r *repository) queryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.QueryRowContext(ctx, query, args...)
+	}
+	if r.stmts == nil {
+		return r.sqliteRepo.QueryRowContext(ctx, query, args...)
+	}
+
+	stmt, err := r.stmts.get(ctx, query)
+	if err != nil {
+		// Si no se pudo preparar, la query directa reporta el error al escanear
+		return r.sqliteRepo.QueryRowContext(ctx, query, args...)
+	}
+	return stmt.QueryRowContext(ctx, args...)
+}
+
+// exec ejecuta una sentencia acotada por el query timeout, usando el statement cacheado si el cache está habilitado
+func (r *repository) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, cancel := r.queryContext(ctx)
+	defer cancel()
+
+	result, err := r.execStatement(ctx, query, args...)
+	if err != nil && isQueryTimeout(ctx) {
+		return nil, newQueryTimeoutError(err)
+	}
+	return result, err
+}
+
+func (r *repository) execStatement(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.ExecContext(ctx, query, args...)
+	}
+	if r.stmts == nil {
+		return r.sqliteRepo.ExecContext(ctx, query, args...)
+	}
+
+	stmt, err := r.stmts.get(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := stmt.ExecContext(ctx, args...)
+	if err != nil && isStaleStatementError(err) {
+		r.stmts.evict(query)
+	}
+	return result, err
+}
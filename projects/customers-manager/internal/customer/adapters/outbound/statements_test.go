@@ -0,0 +1,157 @@
+package outbound
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sqdefs "github.com/devpablocristo/tech-house/pkg/databases/sql/sqlite/defs"
+	transport "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/adapters/outbound/transport"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+// sqliteDB implementa sqdefs.Repository sobre una base SQLite en memoria
+type sqliteDB struct {
+	sqliteStub
+	db *sql.DB
+}
+
+func (s sqliteDB) DB() *sql.DB { return s.db }
+
+func (s sqliteDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return s.db.QueryRowContext(ctx, query, args...)
+}
+
+func (s sqliteDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return s.db.ExecContext(ctx, query, args...)
+}
+
+// SelectContext solo admite listados de customers, que son los únicos que usa el repositorio
+func (s sqliteDB) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	models, ok := dest.(*[]transport.CustomerDataModel)
+	if !ok {
+		return fmt.Errorf("unsupported destination %T", dest)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var model transport.CustomerDataModel
+		err := rows.Scan(
+			&model.ID, &model.Name, &model.LastName, &model.Email,
+			&model.Phone, &model.Age, &model.BirthDate, &model.CreatedAt,
+			&model.AnonymizedAt, &model.DeletedAt,
+		)
+		if err != nil {
+			return err
+		}
+		*models = append(*models, model)
+	}
+	return rows.Err()
+}
+
+var _ sqdefs.Repository = sqliteDB{}
+
+// newTestRepository crea un repositorio sobre una base en memoria con un customer cargado
+func newTestRepository(tb testing.TB, opts ...RepositoryOption) (*repository, int64) {
+	tb.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(tb, err)
+	db.SetMaxOpenConns(1)
+	tb.Cleanup(func() { db.Close() })
+
+	require.NoError(tb, initSchema(db))
+
+	r := newRepository(sqliteDB{db: db}, opts...)
+	tb.Cleanup(func() { r.Close() })
+
+	require.NoError(tb, r.Create(context.Background(), &domain.Customer{
+		Name:      "Ana",
+		LastName:  "Perez",
+		Email:     "ana@example.com",
+		Phone:     "1234567",
+		Age:       30,
+		BirthDate: time.Date(1994, 1, 1, 0, 0, 0, 0, time.UTC),
+	}))
+
+	customer, err := r.GetByEmail(context.Background(), "ana@example.com")
+	require.NoError(tb, err)
+	return r, customer.ID
+}
+
+func Test_repository_StatementCache(t *testing.T) {
+	t.Run("should reuse a single statement per query", func(t *testing.T) {
+		r, id := newTestRepository(t, WithStatementCache(true))
+
+		for i := 0; i < 3; i++ {
+			customer, err := r.GetByID(context.Background(), id)
+			require.NoError(t, err)
+			assert.Equal(t, "Ana", customer.Name)
+		}
+
+		r.stmts.mu.Lock()
+		_, cached := r.stmts.stmts[selectCustomerByIDQuery]
+		r.stmts.mu.Unlock()
+		assert.True(t, cached)
+	})
+
+	t.Run("should close the statements on Close", func(t *testing.T) {
+		r, id := newTestRepository(t, WithStatementCache(true))
+		_, err := r.GetByID(context.Background(), id)
+		require.NoError(t, err)
+
+		require.NoError(t, r.Close())
+		assert.Empty(t, r.stmts.stmts)
+	})
+
+	t.Run("should prepare again after a statement is evicted", func(t *testing.T) {
+		r, id := newTestRepository(t, WithStatementCache(true))
+		_, err := r.GetByID(context.Background(), id)
+		require.NoError(t, err)
+
+		r.stmts.evict(selectCustomerByIDQuery)
+
+		customer, err := r.GetByID(context.Background(), id)
+		require.NoError(t, err)
+		assert.Equal(t, id, customer.ID)
+	})
+
+	t.Run("should not cache when disabled", func(t *testing.T) {
+		r, id := newTestRepository(t)
+		_, err := r.GetByID(context.Background(), id)
+		require.NoError(t, err)
+		assert.Nil(t, r.stmts)
+	})
+}
+
+func BenchmarkRepository_GetByID(b *testing.B) {
+	for _, cached := range []bool{false, true} {
+		name := "without statement cache"
+		if cached {
+			name = "with statement cache"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			r, id := newTestRepository(b, WithStatementCache(cached))
+			ctx := context.Background()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := r.GetByID(ctx, id); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
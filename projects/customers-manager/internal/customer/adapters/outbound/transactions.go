@@ -0,0 +1,56 @@
+package outbound
+
+import (
+	"context"
+	"database/sql"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+)
+
+// txKey es la clave de contexto de la transacción en curso
+type txKey struct{}
+
+// txFromContext retorna la transacción abierta por WithinTx, o nil si no hay ninguna
+func txFromContext(ctx context.Context) *sql.Tx {
+	tx, _ := ctx.Value(txKey{}).(*sql.Tx)
+	return tx
+}
+
+// WithinTx ejecuta fn en una transacción. Las escrituras y lecturas de una fila del repositorio y del
+// EventStore que usen el contexto recibido por fn participan de ella. Si ya hay una transacción en el
+// contexto se reutiliza.
+func (r *repository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if txFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	tx, err := r.sqliteRepo.DB().BeginTx(ctx, nil)
+	if err != nil {
+		return types.NewError(
+			types.ErrOperationFailed,
+			"failed to begin transaction",
+			err,
+		)
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return types.NewError(
+			types.ErrOperationFailed,
+			"failed to commit transaction",
+			err,
+		)
+	}
+	committed = true
+	return nil
+}
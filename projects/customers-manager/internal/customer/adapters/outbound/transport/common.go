@@ -15,30 +15,41 @@ type CustomerDataModel struct {
 	Phone     string    `db:"phone"`
 	Age       int       `db:"age"`
 	BirthDate time.Time `db:"birth_date"`
+	CreatedAt time.Time `db:"created_at"`
+	// AnonymizedAt es NULL mientras el customer conserva sus datos personales
+	AnonymizedAt *time.Time `db:"anonymized_at"`
+	// DeletedAt es NULL mientras el customer no se eliminó lógicamente
+	DeletedAt *time.Time `db:"deleted_at"`
 }
 
 // Mappers
 func CustomerDataModelToDomain(model *CustomerDataModel) *domain.Customer {
 	return &domain.Customer{
-		ID:        model.ID,
-		Name:      model.Name,
-		LastName:  model.LastName,
-		Email:     model.Email,
-		Phone:     model.Phone,
-		Age:       model.Age,
-		BirthDate: model.BirthDate,
+		ID:           model.ID,
+		Name:         model.Name,
+		LastName:     model.LastName,
+		Email:        model.Email,
+		Phone:        model.Phone,
+		Age:          model.Age,
+		BirthDate:    model.BirthDate,
+		CreatedAt:    model.CreatedAt,
+		AnonymizedAt: model.AnonymizedAt,
+		DeletedAt:    model.DeletedAt,
 	}
 }
 
 func DomainToCustomerDataModel(customer *domain.Customer) *CustomerDataModel {
 	return &CustomerDataModel{
-		ID:        customer.ID,
-		Name:      customer.Name,
-		LastName:  customer.LastName,
-		Email:     customer.Email,
-		Phone:     customer.Phone,
-		Age:       customer.Age,
-		BirthDate: customer.BirthDate,
+		ID:           customer.ID,
+		Name:         customer.Name,
+		LastName:     customer.LastName,
+		Email:        customer.Email,
+		Phone:        customer.Phone,
+		Age:          customer.Age,
+		BirthDate:    customer.BirthDate,
+		CreatedAt:    customer.CreatedAt,
+		AnonymizedAt: customer.AnonymizedAt,
+		DeletedAt:    customer.DeletedAt,
 	}
 }
 
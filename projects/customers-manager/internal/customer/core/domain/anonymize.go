@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// Placeholders que reemplazan los datos personales de un customer anonimizado
+const (
+	AnonymizedName        = "anonymized"
+	AnonymizedEmailDomain = "anonymized.invalid"
+)
+
+// Anonymize reemplaza los datos personales del customer por placeholders irreversibles. token debe ser
+// aleatorio y no derivado de los datos originales; solo sirve para que el email siga siendo único.
+// Se conservan la edad y la fecha de alta para los agregados; la fecha de nacimiento se reduce al año.
+func (c *Customer) Anonymize(token string, at time.Time) {
+	c.Name = AnonymizedName
+	c.LastName = AnonymizedName
+	c.Email = AnonymizedName + "-" + token + "@" + AnonymizedEmailDomain
+	c.Phone = ""
+	c.BirthDate = time.Date(c.BirthDate.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	c.AnonymizedAt = &at
+}
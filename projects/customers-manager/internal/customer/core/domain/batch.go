@@ -0,0 +1,17 @@
+package domain
+
+import "fmt"
+
+// BatchItemError es el error de un alta en lote que se descartó completa por el item en la posición Index
+type BatchItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("batch item %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
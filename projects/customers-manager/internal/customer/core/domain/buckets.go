@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rango de edades que deben cubrir los AgeBucket definidos por el cliente; coincide con la validación del alta
+const (
+	MinBucketAge = 1
+	MaxBucketAge = 150
+)
+
+// AgeBucket es un rango de edades, ambos extremos inclusive, para agrupar el conteo de customers
+type AgeBucket struct {
+	Min int
+	Max int
+}
+
+// Label es el nombre del grupo en el conteo, con el mismo formato que los rangos por décadas ("30-39")
+func (b AgeBucket) Label() string {
+	return fmt.Sprintf("%d-%d", b.Min, b.Max)
+}
+
+// Contains indica si la edad está dentro del rango
+func (b AgeBucket) Contains(age int) bool {
+	return age >= b.Min && age <= b.Max
+}
+
+// ParseAgeBuckets convierte "1-17,18-64,65-150" en los AgeBucket en el orden recibido. Solo valida el formato;
+// que los rangos no se solapen y cubran MinBucketAge..MaxBucketAge lo valida el caso de uso.
+func ParseAgeBuckets(value string) ([]AgeBucket, error) {
+	parts := strings.Split(value, ",")
+	buckets := make([]AgeBucket, 0, len(parts))
+	for _, part := range parts {
+		low, high, ok := strings.Cut(strings.TrimSpace(part), "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid age bucket %q, expected \"min-max\"", part)
+		}
+		minAge, err := strconv.Atoi(low)
+		if err != nil {
+			return nil, fmt.Errorf("invalid age bucket %q, expected \"min-max\"", part)
+		}
+		maxAge, err := strconv.Atoi(high)
+		if err != nil {
+			return nil, fmt.Errorf("invalid age bucket %q, expected \"min-max\"", part)
+		}
+		buckets = append(buckets, AgeBucket{Min: minAge, Max: maxAge})
+	}
+	return buckets, nil
+}
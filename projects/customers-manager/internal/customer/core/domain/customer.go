@@ -10,6 +10,44 @@ type Customer struct {
 	Phone     string
 	Age       int
 	BirthDate time.Time
+	CreatedAt time.Time
+	// AnonymizedAt indica cuándo se borraron los datos personales (nil si no se anonimizó)
+	AnonymizedAt *time.Time
+	// DeletedAt indica cuándo se eliminó lógicamente (nil si no se eliminó); se puede restaurar
+	DeletedAt *time.Time
+}
+
+// Dimensiones permitidas para el ranking de customers (GetTopCustomers)
+const (
+	TopByAge       = "age"
+	TopByCreatedAt = "created_at"
+)
+
+// Dimensiones permitidas para agrupar conteos de customers (CountByField)
+const (
+	GroupByAgeBucket   = "age_bucket"
+	GroupByEmailDomain = "email_domain"
+	// GroupOther agrupa los valores que exceden el máximo de grupos del resultado
+	GroupOther = "other"
+)
+
+// CodeCustomerNotFound identifica en la respuesta de API un customer inexistente, para distinguirlo de una ruta inexistente
+const CodeCustomerNotFound = "CUSTOMER_NOT_FOUND"
+
+// CustomerPage es una página del listado de customers; Total es la cantidad de customers sin paginar
+type CustomerPage struct {
+	Customers []Customer
+	Total     int
+	Offset    int
+}
+
+// NextOffset retorna el offset de la página siguiente, o 0 si esta es la última
+func (p *CustomerPage) NextOffset() int {
+	next := p.Offset + len(p.Customers)
+	if len(p.Customers) == 0 || next >= p.Total {
+		return 0
+	}
+	return next
 }
 
 type KPI struct {
@@ -0,0 +1,7 @@
+package domain
+
+// Warning describe un paso opcional que falló sin invalidar la operación (por ejemplo, un enriquecimiento)
+type Warning struct {
+	Step    string
+	Message string
+}
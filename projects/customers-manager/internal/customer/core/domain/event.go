@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// Tipos de eventos de dominio de customers
+const (
+	EventCustomerCreated = "CustomerCreated"
+	EventCustomerUpdated = "CustomerUpdated"
+	EventCustomerDeleted = "CustomerDeleted"
+	// EventCustomerAnonymized registra un borrado de datos personales; su payload ya no tiene PII
+	EventCustomerAnonymized = "CustomerAnonymized"
+	// EventCustomerSoftDeleted registra una eliminación lógica; EventCustomerRestored la revierte
+	EventCustomerSoftDeleted = "CustomerSoftDeleted"
+	EventCustomerRestored    = "CustomerRestored"
+)
+
+// Event es un evento de dominio del log append-only de mutaciones de customers.
+// Seq lo asigna el EventStore al agregarlo y es estrictamente creciente.
+type Event struct {
+	Seq        int64
+	Type       string
+	CustomerID int64
+	// Customer es el estado del customer después de la mutación (nil en CustomerDeleted)
+	Customer   *Customer
+	OccurredAt time.Time
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// Datos relacionados que se pueden expandir en el detalle de un customer (?expand=)
+const (
+	ExpandAuditSummary = "audit_summary"
+)
+
+// ExpandableFields es el allowlist de expansiones del detalle de un customer
+var ExpandableFields = []string{ExpandAuditSummary}
+
+// AuditSummary resume el historial de eventos del customer; sin eventos las fechas quedan en nil
+type AuditSummary struct {
+	EventCount    int
+	LastEventType string
+	FirstEventAt  *time.Time
+	LastEventAt   *time.Time
+}
+
+// ExpandedCustomer es el customer con las expansiones pedidas; las no pedidas quedan en nil
+type ExpandedCustomer struct {
+	Customer     *Customer
+	AuditSummary *AuditSummary
+}
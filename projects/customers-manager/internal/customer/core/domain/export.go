@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// CustomerExport reúne todos los datos de un customer para una solicitud de acceso del titular (GDPR):
+// el registro actual y su historial de eventos, con quién y cuándo pidió la exportación
+type CustomerExport struct {
+	Customer    *Customer
+	Events      []Event
+	ExportedAt  time.Time
+	RequestedBy string
+}
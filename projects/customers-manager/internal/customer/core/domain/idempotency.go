@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// MaxIdempotencyKeyLength es el largo máximo del header Idempotency-Key
+const MaxIdempotencyKeyLength = 255
+
+// IdempotencyRecord asocia una Idempotency-Key ya procesada con el customer que dio de alta
+type IdempotencyRecord struct {
+	Key        string
+	CustomerID int64
+	CreatedAt  time.Time
+}
+
+// Expired indica si el registro ya no vale para repetir el resultado original pasado ttl desde el alta
+func (r *IdempotencyRecord) Expired(ttl time.Duration, now time.Time) bool {
+	return !now.Before(r.CreatedAt.Add(ttl))
+}
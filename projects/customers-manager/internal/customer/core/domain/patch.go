@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// CustomerPatch es una modificación parcial del customer: solo se aplican los campos no nil.
+// Phone apuntando a "" borra el teléfono. Precondition, si no es nil, se evalúa sobre el customer
+// leído en la misma transacción que el guardado; si retorna un error el patch no se aplica.
+type CustomerPatch struct {
+	Name         *string
+	LastName     *string
+	Email        *string
+	Phone        *string
+	Age          *int
+	BirthDate    *time.Time
+	Precondition func(current Customer) error
+}
+
+// IsEmpty indica que el patch no modifica ningún campo; no tiene en cuenta Precondition
+func (p CustomerPatch) IsEmpty() bool {
+	return p.Name == nil && p.LastName == nil && p.Email == nil &&
+		p.Phone == nil && p.Age == nil && p.BirthDate == nil
+}
+
+// Apply copia sobre el customer los campos informados en el patch
+func (p CustomerPatch) Apply(c *Customer) {
+	if p.Name != nil {
+		c.Name = *p.Name
+	}
+	if p.LastName != nil {
+		c.LastName = *p.LastName
+	}
+	if p.Email != nil {
+		c.Email = *p.Email
+	}
+	if p.Phone != nil {
+		c.Phone = *p.Phone
+	}
+	if p.Age != nil {
+		c.Age = *p.Age
+	}
+	if p.BirthDate != nil {
+		c.BirthDate = *p.BirthDate
+	}
+}
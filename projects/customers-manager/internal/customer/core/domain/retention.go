@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// Acciones posibles de la política de retención sobre los customers inactivos
+const (
+	RetentionAnonymize = "anonymize"
+	RetentionDelete    = "delete"
+)
+
+// RetentionPolicy define qué se hace con los customers sin actividad por más de MaxInactivity.
+// BatchSize acota cuántos customers se procesan por ejecución.
+type RetentionPolicy struct {
+	MaxInactivity time.Duration
+	Action        string
+	BatchSize     int
+}
+
+// RetentionReport resume una ejecución de la política de retención
+type RetentionReport struct {
+	Cutoff    time.Time
+	Action    string
+	Processed []int64
+	Failed    []int64
+	// More indica que el lote se completó y puede haber más customers pendientes para la próxima ejecución
+	More bool
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// FoldSearch normaliza un texto para la búsqueda: minúsculas y sin acentos ("José" queda "jose").
+// El transformer tiene estado, por eso se crea uno por llamada.
+func FoldSearch(value string) string {
+	removeMarks := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(removeMarks, value)
+	if err != nil {
+		folded = value
+	}
+	return strings.ToLower(folded)
+}
+
+// SearchText es el texto sobre el que se busca al customer: nombre, apellido y email normalizados con
+// FoldSearch, uno por línea para que una búsqueda no coincida a caballo entre dos campos
+func (c Customer) SearchText() string {
+	return FoldSearch(c.Name) + "\n" + FoldSearch(c.LastName) + "\n" + FoldSearch(c.Email)
+}
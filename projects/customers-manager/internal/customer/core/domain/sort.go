@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Campos por los que se puede ordenar el listado de customers
+const (
+	SortByID        = "id"
+	SortByName      = "name"
+	SortByLastName  = "last_name"
+	SortByAge       = "age"
+	SortByCreatedAt = "created_at"
+)
+
+// SortableFields es el allowlist de campos de ordenamiento del listado
+var SortableFields = []string{SortByID, SortByName, SortByLastName, SortByAge, SortByCreatedAt}
+
+// CustomerSort es el orden del listado de customers. Los empates se resuelven siempre por id ascendente,
+// así el orden es determinístico y la paginación estable.
+type CustomerSort struct {
+	Field string
+	Desc  bool
+}
+
+// DefaultCustomerSort es el orden cuando no se configura otro: por id, es decir, por orden de alta
+var DefaultCustomerSort = CustomerSort{Field: SortByID}
+
+// ParseCustomerSort convierte "campo [asc|desc]" (por ejemplo "created_at desc") en un CustomerSort;
+// vacío retorna DefaultCustomerSort
+func ParseCustomerSort(value string) (CustomerSort, error) {
+	parts := strings.Fields(strings.ToLower(value))
+	if len(parts) == 0 {
+		return DefaultCustomerSort, nil
+	}
+	if len(parts) > 2 {
+		return CustomerSort{}, fmt.Errorf("invalid sort %q, expected \"field [asc|desc]\"", value)
+	}
+
+	sort := CustomerSort{Field: parts[0]}
+	if !IsSortableField(sort.Field) {
+		return CustomerSort{}, fmt.Errorf("invalid sort field %q, expected one of %s", sort.Field, strings.Join(SortableFields, ", "))
+	}
+	if len(parts) == 2 {
+		switch parts[1] {
+		case "asc":
+		case "desc":
+			sort.Desc = true
+		default:
+			return CustomerSort{}, fmt.Errorf("invalid sort direction %q, expected asc or desc", parts[1])
+		}
+	}
+	return sort, nil
+}
+
+// IsSortableField indica si el campo está en el allowlist de ordenamiento
+func IsSortableField(field string) bool {
+	for _, sortable := range SortableFields {
+		if field == sortable {
+			return true
+		}
+	}
+	return false
+}
+
+// String retorna el orden en el mismo formato que acepta ParseCustomerSort
+func (s CustomerSort) String() string {
+	if s.Desc {
+		return s.Field + " desc"
+	}
+	return s.Field + " asc"
+}
+
+// ParseNameCollation convierte un locale BCP 47 (por ejemplo "es" o "de-DE") en el idioma de la collation
+// de los ordenamientos por nombre; vacío retorna language.Und, es decir, orden por bytes
+func ParseNameCollation(value string) (language.Tag, error) {
+	if value == "" {
+		return language.Und, nil
+	}
+	tag, err := language.Parse(value)
+	if err != nil {
+		return language.Und, fmt.Errorf("invalid name collation locale %q: %w", value, err)
+	}
+	return tag, nil
+}
+
+// IsNameField indica si el campo de ordenamiento es un nombre, al que aplica la collation configurada
+func IsNameField(field string) bool {
+	return field == SortByName || field == SortByLastName
+}
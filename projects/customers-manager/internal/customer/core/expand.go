@@ -0,0 +1,150 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	utils "github.com/devpablocristo/tech-house/pkg/utils"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+// expansionResolver completa una expansión para todos los customers recibidos a la vez. Debe leer los datos
+// relacionados con una única consulta por relación, sin importar la cantidad de customers, para evitar N+1.
+type expansionResolver func(ctx context.Context, uc *UseCases, expanded []*domain.ExpandedCustomer) error
+
+// expansionLimits acota el costo de ?expand=: la cantidad de valores por request y los resolvers simultáneos
+type expansionLimits struct {
+	maxValues      int
+	maxConcurrency int
+}
+
+// defaultExpansionLimits son los límites cuando no se configuran otros
+var defaultExpansionLimits = expansionLimits{maxValues: 5, maxConcurrency: 2}
+
+// expansionResolvers asocia cada valor de domain.ExpandableFields con su resolver
+var expansionResolvers = map[string]expansionResolver{
+	domain.ExpandAuditSummary: resolveAuditSummary,
+}
+
+// GetCustomerExpanded retorna el customer junto con las expansiones pedidas. Cada resolver se invoca solo si
+// su expansión fue pedida; un valor fuera de domain.ExpandableFields, o más valores que el máximo configurado,
+// es ErrValidation y no se lee el customer. Los resolvers corren en paralelo hasta el límite de concurrencia.
+func (uc *UseCases) GetCustomerExpanded(ctx context.Context, id int64, expand []string) (*domain.ExpandedCustomer, error) {
+	resolvers, err := uc.expansionResolvers(expand)
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := uc.GetCustomerByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	expanded := &domain.ExpandedCustomer{Customer: customer}
+	if err := uc.resolveExpansions(ctx, resolvers, []*domain.ExpandedCustomer{expanded}); err != nil {
+		return nil, err
+	}
+	return expanded, nil
+}
+
+// ExpandCustomers agrega las expansiones pedidas a una lista ya leída (por ejemplo una página del listado).
+// Cada resolver consulta los datos de toda la lista de una vez, así el costo no crece con el tamaño de la página.
+// Valida expand igual que GetCustomerExpanded.
+func (uc *UseCases) ExpandCustomers(ctx context.Context, customers []domain.Customer, expand []string) ([]domain.ExpandedCustomer, error) {
+	resolvers, err := uc.expansionResolvers(expand)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.ExpandedCustomer, len(customers))
+	expanded := make([]*domain.ExpandedCustomer, len(customers))
+	for i := range customers {
+		result[i].Customer = &customers[i]
+		expanded[i] = &result[i]
+	}
+	if err := uc.resolveExpansions(ctx, resolvers, expanded); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// expansionResolvers valida expand contra el máximo configurado y el allowlist, y retorna un resolver por
+// expansión distinta
+func (uc *UseCases) expansionResolvers(expand []string) ([]expansionResolver, error) {
+	if len(expand) > uc.expansion.maxValues {
+		return nil, types.NewErrorWithContext(
+			types.ErrValidation,
+			fmt.Sprintf("too many expand values: %d, maximum is %d", len(expand), uc.expansion.maxValues),
+			nil,
+			map[string]any{"expand": expand, "max": uc.expansion.maxValues},
+		)
+	}
+
+	resolvers := make([]expansionResolver, 0, len(expand))
+	requested := make(map[string]bool, len(expand))
+	for _, name := range expand {
+		resolver, ok := expansionResolvers[name]
+		if !ok {
+			return nil, types.NewErrorWithContext(
+				types.ErrValidation,
+				fmt.Sprintf("invalid expand: %q", name),
+				nil,
+				map[string]any{"expand": name, "allowed": domain.ExpandableFields},
+			)
+		}
+		if !requested[name] {
+			requested[name] = true
+			resolvers = append(resolvers, resolver)
+		}
+	}
+	return resolvers, nil
+}
+
+// resolveExpansions corre los resolvers en paralelo hasta el límite de concurrencia. Cada resolver completa
+// un campo distinto de ExpandedCustomer, así que pueden escribir los mismos customers a la vez.
+func (uc *UseCases) resolveExpansions(ctx context.Context, resolvers []expansionResolver, expanded []*domain.ExpandedCustomer) error {
+	if len(resolvers) == 0 || len(expanded) == 0 {
+		return nil
+	}
+	_, err := utils.RunConcurrent(ctx, resolvers, uc.expansion.maxConcurrency, func(ctx context.Context, resolve expansionResolver) (struct{}, error) {
+		return struct{}{}, resolve(ctx, uc, expanded)
+	})
+	return err
+}
+
+// resolveAuditSummary resume el historial de cada customer con una sola lectura del EventStore;
+// sin EventStore configurado los resúmenes quedan vacíos
+func resolveAuditSummary(ctx context.Context, uc *UseCases, expanded []*domain.ExpandedCustomer) error {
+	byCustomer := make(map[int64][]domain.Event, len(expanded))
+	if uc.events != nil {
+		ids := make([]int64, len(expanded))
+		for i, e := range expanded {
+			ids[i] = e.Customer.ID
+		}
+		events, err := uc.events.ReadByCustomers(ctx, ids)
+		if err != nil {
+			return types.NewError(
+				types.ErrOperationFailed,
+				"failed to read customer events",
+				err,
+			)
+		}
+		for _, event := range events {
+			byCustomer[event.CustomerID] = append(byCustomer[event.CustomerID], event)
+		}
+	}
+
+	for _, e := range expanded {
+		summary := &domain.AuditSummary{}
+		if events := byCustomer[e.Customer.ID]; len(events) > 0 {
+			first, last := events[0], events[len(events)-1]
+			summary.EventCount = len(events)
+			summary.LastEventType = last.Type
+			summary.FirstEventAt = &first.OccurredAt
+			summary.LastEventAt = &last.OccurredAt
+		}
+		e.AuditSummary = summary
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	core "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+// countingEventStore cuenta las lecturas por customer, para verificar que un resolver solo corre si se lo pide
+// y que consulta una única vez sin importar cuántos customers expande
+type countingEventStore struct {
+	*eventStoreStub
+	reads int
+}
+
+func (s *countingEventStore) ReadByCustomer(ctx context.Context, customerID int64) ([]domain.Event, error) {
+	s.reads++
+	return s.eventStoreStub.ReadByCustomer(ctx, customerID)
+}
+
+func (s *countingEventStore) ReadByCustomers(ctx context.Context, customerIDs []int64) ([]domain.Event, error) {
+	s.reads++
+	return s.eventStoreStub.ReadByCustomers(ctx, customerIDs)
+}
+
+func Test_UseCases_GetCustomerExpanded(t *testing.T) {
+	created := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+	updated := created.Add(48 * time.Hour)
+	customers := []domain.Customer{{ID: 1, Name: "Ana"}, {ID: 2, Name: "Luis"}}
+
+	newEvents := func() *countingEventStore {
+		events := &countingEventStore{eventStoreStub: &eventStoreStub{}}
+		for _, event := range []*domain.Event{
+			{Type: domain.EventCustomerCreated, CustomerID: 1, OccurredAt: created},
+			{Type: domain.EventCustomerCreated, CustomerID: 2, OccurredAt: created},
+			{Type: domain.EventCustomerUpdated, CustomerID: 1, OccurredAt: updated},
+		} {
+			require.NoError(t, events.Append(context.Background(), event))
+		}
+		return events
+	}
+
+	t.Run("should not resolve anything when nothing is requested", func(t *testing.T) {
+		events := newEvents()
+		ucs := core.NewUseCases(&repoStub{customers: customers}, core.WithEventStore(events))
+
+		expanded, err := ucs.GetCustomerExpanded(context.Background(), 1, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Ana", expanded.Customer.Name)
+		assert.Nil(t, expanded.AuditSummary)
+		assert.Zero(t, events.reads)
+	})
+
+	t.Run("should expand the audit summary", func(t *testing.T) {
+		events := newEvents()
+		ucs := core.NewUseCases(&repoStub{customers: customers}, core.WithEventStore(events))
+
+		expanded, err := ucs.GetCustomerExpanded(context.Background(), 1, []string{domain.ExpandAuditSummary, domain.ExpandAuditSummary})
+		require.NoError(t, err)
+		require.NotNil(t, expanded.AuditSummary)
+		assert.Equal(t, 2, expanded.AuditSummary.EventCount)
+		assert.Equal(t, domain.EventCustomerUpdated, expanded.AuditSummary.LastEventType)
+		assert.Equal(t, created, *expanded.AuditSummary.FirstEventAt)
+		assert.Equal(t, updated, *expanded.AuditSummary.LastEventAt)
+		assert.Equal(t, 1, events.reads, "a repeated expansion must be resolved once")
+	})
+
+	t.Run("should return an empty audit summary without an event store", func(t *testing.T) {
+		ucs := core.NewUseCases(&repoStub{customers: customers})
+
+		expanded, err := ucs.GetCustomerExpanded(context.Background(), 2, []string{domain.ExpandAuditSummary})
+		require.NoError(t, err)
+		assert.Equal(t, &domain.AuditSummary{}, expanded.AuditSummary)
+	})
+
+	t.Run("should reject unknown expansions", func(t *testing.T) {
+		events := newEvents()
+		ucs := core.NewUseCases(&repoStub{customers: customers}, core.WithEventStore(events))
+
+		_, err := ucs.GetCustomerExpanded(context.Background(), 1, []string{domain.ExpandAuditSummary, "tags"})
+		errType, ok := types.GetErrorType(err)
+		require.True(t, ok)
+		assert.Equal(t, types.ErrValidation, errType)
+		assert.Zero(t, events.reads)
+	})
+
+	t.Run("should reject more expansions than allowed", func(t *testing.T) {
+		events := newEvents()
+		ucs := core.NewUseCases(&repoStub{customers: customers}, core.WithEventStore(events), core.WithExpansionLimits(2, 1))
+
+		_, err := ucs.GetCustomerExpanded(context.Background(), 1, []string{domain.ExpandAuditSummary, domain.ExpandAuditSummary})
+		require.NoError(t, err)
+
+		_, err = ucs.GetCustomerExpanded(context.Background(), 1, []string{domain.ExpandAuditSummary, domain.ExpandAuditSummary, domain.ExpandAuditSummary})
+		errType, ok := types.GetErrorType(err)
+		require.True(t, ok)
+		assert.Equal(t, types.ErrValidation, errType)
+		assert.Equal(t, 1, events.reads, "the rejected request must not resolve anything")
+	})
+
+	t.Run("should fail when the customer does not exist", func(t *testing.T) {
+		ucs := core.NewUseCases(&repoStub{customers: customers})
+
+		_, err := ucs.GetCustomerExpanded(context.Background(), 99, []string{domain.ExpandAuditSummary})
+		assert.True(t, types.IsNotFound(err))
+	})
+}
+
+func Test_UseCases_ExpandCustomers(t *testing.T) {
+	occurred := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+
+	const n = 50
+	customers := make([]domain.Customer, n)
+	events := &countingEventStore{eventStoreStub: &eventStoreStub{}}
+	for i := range customers {
+		customers[i] = domain.Customer{ID: int64(i + 1), Name: fmt.Sprintf("customer-%d", i+1)}
+		// Cada customer tiene entre uno y tres eventos, para verificar que el reparto no los mezcla
+		for j := 0; j <= i%3; j++ {
+			require.NoError(t, events.Append(context.Background(), &domain.Event{
+				Type:       domain.EventCustomerUpdated,
+				CustomerID: customers[i].ID,
+				OccurredAt: occurred.Add(time.Duration(j) * time.Hour),
+			}))
+		}
+	}
+	ucs := core.NewUseCases(&repoStub{customers: customers}, core.WithEventStore(events))
+
+	t.Run("should resolve an expansion for the whole list with a single query", func(t *testing.T) {
+		events.reads = 0
+
+		expanded, err := ucs.ExpandCustomers(context.Background(), customers, []string{domain.ExpandAuditSummary})
+		require.NoError(t, err)
+		require.Len(t, expanded, n)
+		assert.Equal(t, 1, events.reads, "expanding %d customers must not issue one query per customer", n)
+
+		for i, e := range expanded {
+			assert.Equal(t, customers[i].ID, e.Customer.ID)
+			require.NotNil(t, e.AuditSummary)
+			assert.Equal(t, i%3+1, e.AuditSummary.EventCount)
+			assert.Equal(t, occurred, *e.AuditSummary.FirstEventAt)
+			assert.Equal(t, occurred.Add(time.Duration(i%3)*time.Hour), *e.AuditSummary.LastEventAt)
+		}
+	})
+
+	t.Run("should not query without expansions or customers", func(t *testing.T) {
+		events.reads = 0
+
+		expanded, err := ucs.ExpandCustomers(context.Background(), customers, nil)
+		require.NoError(t, err)
+		assert.Len(t, expanded, n)
+		assert.Nil(t, expanded[0].AuditSummary)
+
+		expanded, err = ucs.ExpandCustomers(context.Background(), []domain.Customer{}, []string{domain.ExpandAuditSummary})
+		require.NoError(t, err)
+		assert.Empty(t, expanded)
+		assert.Zero(t, events.reads)
+	})
+
+	t.Run("should reject unknown expansions", func(t *testing.T) {
+		_, err := ucs.ExpandCustomers(context.Background(), customers, []string{"tags"})
+		errType, ok := types.GetErrorType(err)
+		require.True(t, ok)
+		assert.Equal(t, types.ErrValidation, errType)
+	})
+}
@@ -1,8 +1,21 @@
 package core
 
 import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"math"
+	"slices"
+	"sort"
+	"strings"
 
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+
+	pkglogger "github.com/devpablocristo/tech-house/pkg/config/logger"
+	types "github.com/devpablocristo/tech-house/pkg/types"
 	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
 )
 
@@ -27,3 +40,349 @@ func calculateKPI(customers []domain.Customer) *domain.KPI {
 
 	return kpi
 }
+
+// maxPageLimit es el tamaño máximo de página de GetCustomersPaged
+const maxPageLimit = 500
+
+// sortCustomers ordena una copia de los customers según sort, desempatando por id ascendente. Los campos de
+// nombre se comparan con la collation del idioma indicado (por ejemplo "Ä" junto a "A" en alemán), o por
+// bytes con language.Und.
+func sortCustomers(customers []domain.Customer, sort domain.CustomerSort, collation language.Tag) []domain.Customer {
+	sorted := make([]domain.Customer, len(customers))
+	copy(sorted, customers)
+
+	compareNames := strings.Compare
+	if collation != language.Und && domain.IsNameField(sort.Field) {
+		// Un Collator no es seguro para uso concurrente, por eso se crea uno por ordenamiento
+		compareNames = collate.New(collation).CompareString
+	}
+
+	slices.SortStableFunc(sorted, func(a, b domain.Customer) int {
+		var order int
+		switch sort.Field {
+		case domain.SortByID:
+			order = cmp.Compare(a.ID, b.ID)
+		case domain.SortByName:
+			order = compareNames(a.Name, b.Name)
+		case domain.SortByLastName:
+			order = compareNames(a.LastName, b.LastName)
+		case domain.SortByAge:
+			order = cmp.Compare(a.Age, b.Age)
+		case domain.SortByCreatedAt:
+			order = a.CreatedAt.Compare(b.CreatedAt)
+		}
+		if sort.Desc {
+			order = -order
+		}
+		if order != 0 {
+			return order
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return sorted
+}
+
+// getPage lee una página en el orden indicado. Si ese orden es por nombre con una collation configurada,
+// la base no puede ordenarla (SQLite compara bytes) y se ordena en memoria sobre los customers no anonimizados.
+func (uc *UseCases) getPage(ctx context.Context, sort domain.CustomerSort, limit, offset int) ([]domain.Customer, error) {
+	if uc.collation == language.Und || !domain.IsNameField(sort.Field) {
+		return uc.repo.GetPage(ctx, limit, offset, sort)
+	}
+
+	all, err := uc.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pageOf(sortCustomers(activeCustomers(all), sort, uc.collation), limit, offset), nil
+}
+
+// validateSort exige que el campo de ordenamiento esté en domain.SortableFields
+func validateSort(sort domain.CustomerSort) error {
+	if !domain.IsSortableField(sort.Field) {
+		return types.NewErrorWithContext(
+			types.ErrInvalidInput,
+			fmt.Sprintf("invalid sort field: %q", sort.Field),
+			nil,
+			map[string]any{"sort": sort.Field, "allowed": domain.SortableFields},
+		)
+	}
+	return nil
+}
+
+// validatePage exige limit entre 1 y maxPageLimit y offset no negativo
+func validatePage(limit, offset int) error {
+	if limit < 1 || limit > maxPageLimit {
+		return types.NewErrorWithContext(
+			types.ErrInvalidInput,
+			fmt.Sprintf("limit must be between 1 and %d", maxPageLimit),
+			nil,
+			map[string]any{"limit": limit},
+		)
+	}
+	if offset < 0 {
+		return types.NewErrorWithContext(
+			types.ErrInvalidInput,
+			"offset must not be negative",
+			nil,
+			map[string]any{"offset": offset},
+		)
+	}
+	return nil
+}
+
+// activeCustomers descarta los customers anonimizados, que no aparecen en el listado
+func activeCustomers(customers []domain.Customer) []domain.Customer {
+	active := make([]domain.Customer, 0, len(customers))
+	for _, customer := range customers {
+		if customer.AnonymizedAt == nil {
+			active = append(active, customer)
+		}
+	}
+	return active
+}
+
+// pageOf retorna la página de una lista ya ordenada
+func pageOf(sorted []domain.Customer, limit, offset int) []domain.Customer {
+	if offset >= len(sorted) {
+		return []domain.Customer{}
+	}
+	return sorted[offset:min(offset+limit, len(sorted))]
+}
+
+// maxSearchLength es el largo máximo de la query de SearchCustomers
+const maxSearchLength = 100
+
+// matchesSearch indica si el nombre, el apellido o el email del customer contienen needle (ya normalizada)
+func matchesSearch(c domain.Customer, needle string) bool {
+	for _, field := range []string{c.Name, c.LastName, c.Email} {
+		if strings.Contains(domain.FoldSearch(field), needle) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxTopCustomers es el tope de n en GetTopCustomers
+const maxTopCustomers = 100
+
+// topCustomers ordena una copia de los customers de mayor a menor según la dimensión, desempatando por id
+// ascendente, y retorna los primeros n
+func topCustomers(customers []domain.Customer, by string, n int) []domain.Customer {
+	sorted := make([]domain.Customer, len(customers))
+	copy(sorted, customers)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		switch by {
+		case domain.TopByCreatedAt:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.After(b.CreatedAt)
+			}
+		default:
+			if a.Age != b.Age {
+				return a.Age > b.Age
+			}
+		}
+		return a.ID < b.ID
+	})
+
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// maxAggregateGroups es la cantidad máxima de grupos que retorna CountByField
+const maxAggregateGroups = 50
+
+// groupKeys son las dimensiones permitidas en CountByField y cómo se calcula el grupo de cada customer
+var groupKeys = map[string]func(domain.Customer) string{
+	domain.GroupByAgeBucket:   ageBucket,
+	domain.GroupByEmailDomain: emailDomain,
+}
+
+// ageBucket agrupa la edad por décadas ("30-39")
+func ageBucket(c domain.Customer) string {
+	low := c.Age / 10 * 10
+	return fmt.Sprintf("%d-%d", low, low+9)
+}
+
+// emailDomain retorna el dominio del email en minúsculas
+func emailDomain(c domain.Customer) string {
+	at := strings.LastIndex(c.Email, "@")
+	if at < 0 || at == len(c.Email)-1 {
+		return "unknown"
+	}
+	return strings.ToLower(c.Email[at+1:])
+}
+
+// countByField cuenta los customers por grupo. Si hay más de maxGroups grupos conserva los
+// maxGroups-1 más frecuentes (desempatando por nombre) y suma el resto en GroupOther.
+func countByField(customers []domain.Customer, groupKey func(domain.Customer) string, maxGroups int) map[string]int64 {
+	counts := make(map[string]int64)
+	for _, c := range customers {
+		counts[groupKey(c)]++
+	}
+	if len(counts) <= maxGroups {
+		return counts
+	}
+
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	capped := make(map[string]int64, maxGroups)
+	for i, key := range keys {
+		if i < maxGroups-1 {
+			capped[key] = counts[key]
+			continue
+		}
+		capped[domain.GroupOther] += counts[key]
+	}
+	return capped
+}
+
+// defaultMaxAgeBuckets es la cantidad máxima de AgeBucket por request cuando no se configura otra
+const defaultMaxAgeBuckets = 20
+
+// validateAgeBuckets exige entre 1 y maxBuckets rangos que, ordenados, no se solapen ni dejen huecos y cubran
+// domain.MinBucketAge..domain.MaxBucketAge; cualquier otra definición es ErrValidation
+func validateAgeBuckets(buckets []domain.AgeBucket, maxBuckets int) error {
+	if len(buckets) == 0 || len(buckets) > maxBuckets {
+		return types.NewErrorWithContext(
+			types.ErrValidation,
+			fmt.Sprintf("age buckets must be between 1 and %d", maxBuckets),
+			nil,
+			map[string]any{"buckets": len(buckets), "max": maxBuckets},
+		)
+	}
+
+	sorted := slices.Clone(buckets)
+	slices.SortFunc(sorted, func(a, b domain.AgeBucket) int {
+		return cmp.Compare(a.Min, b.Min)
+	})
+
+	next := domain.MinBucketAge
+	for _, bucket := range sorted {
+		if bucket.Min > bucket.Max {
+			return types.NewErrorWithContext(
+				types.ErrValidation,
+				fmt.Sprintf("invalid age bucket %s: min is greater than max", bucket.Label()),
+				nil,
+				map[string]any{"bucket": bucket.Label()},
+			)
+		}
+		if bucket.Min < next {
+			return types.NewErrorWithContext(
+				types.ErrValidation,
+				fmt.Sprintf("age bucket %s overlaps another bucket", bucket.Label()),
+				nil,
+				map[string]any{"bucket": bucket.Label()},
+			)
+		}
+		if bucket.Min > next {
+			return types.NewErrorWithContext(
+				types.ErrValidation,
+				fmt.Sprintf("age buckets leave ages %d-%d uncovered", next, bucket.Min-1),
+				nil,
+				map[string]any{"bucket": bucket.Label()},
+			)
+		}
+		next = bucket.Max + 1
+	}
+	if next <= domain.MaxBucketAge {
+		return types.NewErrorWithContext(
+			types.ErrValidation,
+			fmt.Sprintf("age buckets leave ages %d-%d uncovered", next, domain.MaxBucketAge),
+			nil,
+			map[string]any{"min": domain.MinBucketAge, "max": domain.MaxBucketAge},
+		)
+	}
+	return nil
+}
+
+// countByAgeBuckets cuenta los customers en cada bucket, incluidos los vacíos. Las edades fuera de
+// domain.MinBucketAge..domain.MaxBucketAge, que el alta no admite, se suman en GroupOther.
+func countByAgeBuckets(customers []domain.Customer, buckets []domain.AgeBucket) map[string]int64 {
+	counts := make(map[string]int64, len(buckets))
+	for _, bucket := range buckets {
+		counts[bucket.Label()] = 0
+	}
+	for _, c := range customers {
+		i := slices.IndexFunc(buckets, func(b domain.AgeBucket) bool { return b.Contains(c.Age) })
+		if i < 0 {
+			counts[domain.GroupOther]++
+			continue
+		}
+		counts[buckets[i].Label()]++
+	}
+	return counts
+}
+
+// enrich aplica los enriquecedores habilitados. Cada uno trabaja sobre una copia que solo se adopta si
+// termina bien, así un paso que falla a mitad de camino no deja el customer modificado a medias.
+func (uc *UseCases) enrich(ctx context.Context, customer *domain.Customer) []domain.Warning {
+	warnings := []domain.Warning{}
+	for _, enricher := range uc.enrichers {
+		if uc.skipped[enricher.Name()] {
+			continue
+		}
+
+		enriched := *customer
+		if err := enricher.Enrich(ctx, &enriched); err != nil {
+			pkglogger.FromContext(ctx).Warn("enrichment step %s failed: %v", enricher.Name(), err)
+			warnings = append(warnings, domain.Warning{
+				Step:    enricher.Name(),
+				Message: err.Error(),
+			})
+			continue
+		}
+		*customer = enriched
+	}
+	return warnings
+}
+
+// anonymizationToken genera el sufijo aleatorio del email anonimizado; no se deriva de los datos del
+// customer para que no se pueda revertir comparando contra emails conocidos
+func anonymizationToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// customerNotFound envuelve el NotFound del repositorio con el código CUSTOMER_NOT_FOUND, así la API
+// distingue un customer inexistente de una ruta inexistente
+func customerNotFound(err error) error {
+	return types.NewError(
+		types.ErrNotFound,
+		"customer not found",
+		err,
+	).WithCode(domain.CodeCustomerNotFound)
+}
+
+// appendEvent agrega el evento al log si hay un EventStore configurado; se llama dentro de la
+// transacción del cambio de estado para que ambos se confirmen o se descarten juntos
+func (uc *UseCases) appendEvent(ctx context.Context, eventType string, customerID int64, customer *domain.Customer) error {
+	if uc.events == nil {
+		return nil
+	}
+
+	event := &domain.Event{
+		Type:       eventType,
+		CustomerID: customerID,
+	}
+	if customer != nil {
+		snapshot := *customer
+		event.Customer = &snapshot
+	}
+	return uc.events.Append(ctx, event)
+}
@@ -2,25 +2,129 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
 )
 
 type UseCases interface {
 	GetCustomers(context.Context) ([]domain.Customer, error)
+	GetCustomersPaged(ctx context.Context, limit, offset int) (*domain.CustomerPage, error)
+	// GetCustomersSorted retorna una página del listado en el orden pedido; los empates se ordenan por id
+	GetCustomersSorted(ctx context.Context, sort domain.CustomerSort, limit, offset int) (*domain.CustomerPage, error)
+	// SearchCustomers retorna una página de los customers cuyo nombre, apellido o email contienen query
+	SearchCustomers(ctx context.Context, query string, limit, offset int) (*domain.CustomerPage, error)
 	GetCustomerByID(context.Context, int64) (*domain.Customer, error)
 	GetCustomerByEmail(context.Context, string) (*domain.Customer, error)
-	CreateCustomer(context.Context, *domain.Customer) error
+	// GetCustomerExpanded retorna el customer con las expansiones pedidas (domain.ExpandableFields)
+	GetCustomerExpanded(ctx context.Context, id int64, expand []string) (*domain.ExpandedCustomer, error)
+	// ExpandCustomers agrega las expansiones pedidas a una lista ya leída, con una consulta por expansión
+	ExpandCustomers(ctx context.Context, customers []domain.Customer, expand []string) ([]domain.ExpandedCustomer, error)
+	CreateCustomer(context.Context, *domain.Customer) ([]domain.Warning, error)
+	// CreateCustomerIdempotent da de alta el customer una sola vez por key; si la key ya se procesó completa
+	// customer con el dado de alta originalmente y retorna replayed en true
+	CreateCustomerIdempotent(ctx context.Context, key string, customer *domain.Customer) (warnings []domain.Warning, replayed bool, err error)
+	// CreateCustomers da de alta el lote completo o ninguno; el error indica la posición del que falló
+	CreateCustomers(ctx context.Context, customers []domain.Customer) ([]domain.Customer, error)
 	UpdateCustomer(context.Context, *domain.Customer) error
+	// PatchCustomer aplica solo los campos informados del patch y retorna el customer resultante
+	PatchCustomer(ctx context.Context, id int64, patch domain.CustomerPatch) (*domain.Customer, error)
 	DeleteCustomer(context.Context, int64) error
+	// SoftDeleteCustomer elimina el customer de forma lógica; RestoreCustomer lo recupera y lo retorna
+	SoftDeleteCustomer(context.Context, int64) error
+	RestoreCustomer(context.Context, int64) (*domain.Customer, error)
+	// GetCustomerIncludingDeleted y GetCustomersIncludingDeleted también ven los customers eliminados lógicamente
+	GetCustomerIncludingDeleted(context.Context, int64) (*domain.Customer, error)
+	GetCustomersIncludingDeleted(ctx context.Context, limit, offset int) (*domain.CustomerPage, error)
 	GetKPI(context.Context) (*domain.KPI, error)
+	// GetKPIForRange calcula los KPIs de los customers dados de alta en el rango; un extremo en cero no acota
+	GetKPIForRange(ctx context.Context, from, to time.Time) (*domain.KPI, error)
+	GetTopCustomers(ctx context.Context, by string, n int) ([]domain.Customer, error)
+	CountByField(ctx context.Context, field string) (map[string]int64, error)
+	// CountByAgeBuckets cuenta los customers en rangos de edades definidos por el cliente
+	CountByAgeBuckets(ctx context.Context, buckets []domain.AgeBucket) (map[string]int64, error)
+	ExportCustomer(ctx context.Context, id int64, requestedBy string) (*domain.CustomerExport, error)
+	AnonymizeCustomer(ctx context.Context, id int64) error
+}
+
+// Transactor ejecuta fn dentro de una transacción; las operaciones del repositorio y del EventStore que
+// reciben el contexto de fn participan de ella. Si fn retorna error se hace rollback de todo.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// EventStore es el log append-only de eventos de customers, base para reconstruir proyecciones
+type EventStore interface {
+	// Append agrega el evento y le asigna el número de secuencia
+	Append(ctx context.Context, event *domain.Event) error
+	// Read retorna los eventos con secuencia mayor o igual a fromSeq, en orden
+	Read(ctx context.Context, fromSeq int64) ([]domain.Event, error)
+	// ReadByCustomer retorna los eventos de un customer, en orden
+	ReadByCustomer(ctx context.Context, customerID int64) ([]domain.Event, error)
+	// ReadByCustomers retorna los eventos de varios customers con una única consulta, en orden
+	ReadByCustomers(ctx context.Context, customerIDs []int64) ([]domain.Event, error)
+	// Redact borra el payload de los eventos de un customer; es la única modificación permitida del log
+	Redact(ctx context.Context, customerID int64) error
+}
+
+// IdempotencyStore guarda las Idempotency-Key procesadas con el customer que dieron de alta
+type IdempotencyStore interface {
+	// Get retorna el registro de la key, o ErrNotFound si no se procesó
+	Get(ctx context.Context, key string) (*domain.IdempotencyRecord, error)
+	// Save graba el registro en la transacción del contexto, si la hay. Una key ya grabada solo se reemplaza si
+	// su registro vence (CreatedAt no posterior a expiredAt); si sigue vigente retorna ErrConflict.
+	Save(ctx context.Context, record *domain.IdempotencyRecord, expiredAt time.Time) error
+}
+
+// Enricher completa datos opcionales del customer antes de darlo de alta (geocodificación, verificación
+// del teléfono, etc.). Si falla, el alta sigue con los datos originales y se informa un warning.
+type Enricher interface {
+	Name() string
+	Enrich(ctx context.Context, customer *domain.Customer) error
 }
 
 type Repository interface {
+	Transactor
+	// GetAll retorna los customers no eliminados lógicamente, incluidos los anonimizados (para los agregados)
 	GetAll(context.Context) ([]domain.Customer, error)
+	// GetAllIncludingDeleted retorna todos los customers, también los eliminados lógicamente
+	GetAllIncludingDeleted(context.Context) ([]domain.Customer, error)
+	// GetPage retorna hasta limit customers en el orden indicado a partir de offset
+	GetPage(ctx context.Context, limit, offset int, sort domain.CustomerSort) ([]domain.Customer, error)
+	Count(context.Context) (int, error)
+	// SearchCandidates retorna los customers no anonimizados ni eliminados que pueden contener needle (ya
+	// normalizada con domain.FoldSearch) en el nombre, el apellido o el email. Puede incluir customers que
+	// no coinciden, pero nunca omite uno que sí: el caso de uso confirma cada candidato.
+	SearchCandidates(ctx context.Context, needle string) ([]domain.Customer, error)
+	// GetInactive retorna hasta limit customers no anonimizados cuya última actividad es anterior a before
+	GetInactive(ctx context.Context, before time.Time, limit int) ([]domain.Customer, error)
 	GetByID(context.Context, int64) (*domain.Customer, error)
+	// GetByIDIncludingDeleted busca un customer no anonimizado aunque esté eliminado lógicamente
+	GetByIDIncludingDeleted(context.Context, int64) (*domain.Customer, error)
 	Create(context.Context, *domain.Customer) error
 	Update(context.Context, *domain.Customer) error
 	Delete(context.Context, int64) error
+	// SoftDelete marca el customer como eliminado lógicamente; Restore lo revierte. Ambos son ErrNotFound si el
+	// customer no existe, está anonimizado o ya está en el estado pedido.
+	SoftDelete(ctx context.Context, id int64, deletedAt time.Time) error
+	Restore(ctx context.Context, id int64) error
+	// Anonymize guarda los placeholders del customer y lo marca como anonimizado
+	Anonymize(context.Context, *domain.Customer) error
 	GetByEmail(context.Context, string) (*domain.Customer, error)
+	Pinger
+	Close() error
+}
+
+// Pinger verifica que una dependencia responde; lo usan los health checks
+type Pinger interface {
+	Ping(context.Context) error
+}
+
+// Projection es un modelo de lectura que se construye aplicando los eventos del EventStore.
+// Apply debe ser idempotente: un evento con secuencia menor o igual a Checkpoint ya fue aplicado y se ignora.
+type Projection interface {
+	Name() string
+	// Checkpoint retorna la secuencia del último evento aplicado (0 si no se aplicó ninguno)
+	Checkpoint(ctx context.Context) (int64, error)
+	Apply(ctx context.Context, event domain.Event) error
 }
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"context"
+	"sync"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+	ports "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/ports"
+)
+
+// Replay aplica a la proyección los eventos posteriores a su checkpoint y retorna cuántos aplicó.
+// Si falla a mitad de camino, volver a llamarlo retoma desde el último evento aplicado.
+func Replay(ctx context.Context, events ports.EventStore, projection ports.Projection) (int, error) {
+	checkpoint, err := projection.Checkpoint(ctx)
+	if err != nil {
+		return 0, types.NewErrorWithContext(
+			types.ErrOperationFailed,
+			"failed to read projection checkpoint",
+			err,
+			map[string]any{"projection": projection.Name()},
+		)
+	}
+
+	pending, err := events.Read(ctx, checkpoint+1)
+	if err != nil {
+		return 0, types.NewErrorWithContext(
+			types.ErrOperationFailed,
+			"failed to read events",
+			err,
+			map[string]any{"projection": projection.Name(), "from_seq": checkpoint + 1},
+		)
+	}
+
+	applied := 0
+	for _, event := range pending {
+		if err := ctx.Err(); err != nil {
+			return applied, err
+		}
+		if err := projection.Apply(ctx, event); err != nil {
+			return applied, types.NewErrorWithContext(
+				types.ErrOperationFailed,
+				"failed to apply event",
+				err,
+				map[string]any{"projection": projection.Name(), "seq": event.Seq},
+			)
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+// CustomerCounter es una proyección en memoria con la cantidad de customers activos.
+// Guarda los ids vistos en lugar de un contador para que reaplicar un evento no altere el resultado.
+type CustomerCounter struct {
+	mu        sync.RWMutex
+	customers map[int64]struct{}
+	lastSeq   int64
+}
+
+// NewCustomerCounter crea la proyección vacía
+func NewCustomerCounter() *CustomerCounter {
+	return &CustomerCounter{customers: make(map[int64]struct{})}
+}
+
+func (p *CustomerCounter) Name() string {
+	return "customer_counter"
+}
+
+func (p *CustomerCounter) Checkpoint(ctx context.Context) (int64, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.lastSeq, nil
+}
+
+func (p *CustomerCounter) Apply(ctx context.Context, event domain.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if event.Seq <= p.lastSeq {
+		return nil
+	}
+
+	switch event.Type {
+	case domain.EventCustomerCreated, domain.EventCustomerUpdated, domain.EventCustomerRestored:
+		p.customers[event.CustomerID] = struct{}{}
+	case domain.EventCustomerDeleted, domain.EventCustomerSoftDeleted:
+		delete(p.customers, event.CustomerID)
+	}
+	p.lastSeq = event.Seq
+	return nil
+}
+
+// Count retorna la cantidad de customers activos según los eventos aplicados
+func (p *CustomerCounter) Count() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.customers)
+}
@@ -0,0 +1,142 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	core "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+// eventStoreStub implementa ports.EventStore sobre una secuencia fija de eventos
+type eventStoreStub struct {
+	events []domain.Event
+}
+
+func (s *eventStoreStub) Append(ctx context.Context, event *domain.Event) error {
+	event.Seq = int64(len(s.events) + 1)
+	s.events = append(s.events, *event)
+	return nil
+}
+
+func (s *eventStoreStub) Read(ctx context.Context, fromSeq int64) ([]domain.Event, error) {
+	result := []domain.Event{}
+	for _, event := range s.events {
+		if event.Seq >= fromSeq {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+func (s *eventStoreStub) ReadByCustomer(ctx context.Context, customerID int64) ([]domain.Event, error) {
+	result := []domain.Event{}
+	for _, event := range s.events {
+		if event.CustomerID == customerID {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+func (s *eventStoreStub) ReadByCustomers(ctx context.Context, customerIDs []int64) ([]domain.Event, error) {
+	result := []domain.Event{}
+	for _, event := range s.events {
+		if slices.Contains(customerIDs, event.CustomerID) {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+func (s *eventStoreStub) Redact(ctx context.Context, customerID int64) error {
+	for i := range s.events {
+		if s.events[i].CustomerID == customerID {
+			s.events[i].Customer = nil
+		}
+	}
+	return nil
+}
+
+// failingProjection falla una única vez al aplicar el evento failAt
+type failingProjection struct {
+	*core.CustomerCounter
+	failAt int64
+}
+
+func (p *failingProjection) Apply(ctx context.Context, event domain.Event) error {
+	if event.Seq == p.failAt {
+		p.failAt = 0
+		return errors.New("projection unavailable")
+	}
+	return p.CustomerCounter.Apply(ctx, event)
+}
+
+func fixedEvents() *eventStoreStub {
+	return &eventStoreStub{events: []domain.Event{
+		{Seq: 1, Type: domain.EventCustomerCreated, CustomerID: 1},
+		{Seq: 2, Type: domain.EventCustomerCreated, CustomerID: 2},
+		{Seq: 3, Type: domain.EventCustomerUpdated, CustomerID: 1},
+		{Seq: 4, Type: domain.EventCustomerCreated, CustomerID: 3},
+		{Seq: 5, Type: domain.EventCustomerDeleted, CustomerID: 2},
+		{Seq: 6, Type: domain.EventCustomerCreated, CustomerID: 4},
+	}}
+}
+
+func Test_Replay(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should replay every event from the start", func(t *testing.T) {
+		counter := core.NewCustomerCounter()
+
+		applied, err := core.Replay(ctx, fixedEvents(), counter)
+		require.NoError(t, err)
+		assert.Equal(t, 6, applied)
+		assert.Equal(t, 3, counter.Count())
+
+		checkpoint, err := counter.Checkpoint(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(6), checkpoint)
+	})
+
+	t.Run("should resume a partial rebuild from the checkpoint", func(t *testing.T) {
+		projection := &failingProjection{CustomerCounter: core.NewCustomerCounter(), failAt: 4}
+
+		applied, err := core.Replay(ctx, fixedEvents(), projection)
+		require.Error(t, err)
+		assert.Equal(t, 3, applied)
+
+		applied, err = core.Replay(ctx, fixedEvents(), projection)
+		require.NoError(t, err)
+		assert.Equal(t, 3, applied)
+		assert.Equal(t, 3, projection.Count())
+	})
+
+	t.Run("should be a no-op when the projection is up to date", func(t *testing.T) {
+		counter := core.NewCustomerCounter()
+		_, err := core.Replay(ctx, fixedEvents(), counter)
+		require.NoError(t, err)
+
+		applied, err := core.Replay(ctx, fixedEvents(), counter)
+		require.NoError(t, err)
+		assert.Zero(t, applied)
+		assert.Equal(t, 3, counter.Count())
+	})
+
+	t.Run("should ignore events already applied", func(t *testing.T) {
+		counter := core.NewCustomerCounter()
+		events := fixedEvents()
+		for _, event := range events.events {
+			require.NoError(t, counter.Apply(ctx, event))
+		}
+		for _, event := range events.events {
+			require.NoError(t, counter.Apply(ctx, event))
+		}
+		assert.Equal(t, 3, counter.Count())
+	})
+}
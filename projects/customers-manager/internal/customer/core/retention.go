@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pkglogger "github.com/devpablocristo/tech-house/pkg/config/logger"
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+	ports "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/ports"
+)
+
+// defaultRetentionBatchSize es el lote por ejecución cuando la política no lo define
+const defaultRetentionBatchSize = 100
+
+// ApplyRetention anonimiza o borra, según la política, un lote de customers sin actividad desde now-MaxInactivity.
+// Cada acción pasa por los casos de uso, así queda registrada en el log de eventos como cualquier otra baja o
+// anonimización. Es idempotente: los customers ya anonimizados o borrados no vuelven a seleccionarse, y un
+// customer que desaparece entre la selección y la acción se ignora. Un customer que falla no corta el lote.
+func ApplyRetention(ctx context.Context, repo ports.Repository, ucs ports.UseCases, policy domain.RetentionPolicy, now time.Time) (*domain.RetentionReport, error) {
+	if policy.MaxInactivity <= 0 {
+		return nil, types.NewErrorWithContext(
+			types.ErrInvalidInput,
+			"retention period must be greater than zero",
+			nil,
+			map[string]any{"max_inactivity": policy.MaxInactivity.String()},
+		)
+	}
+
+	var action func(context.Context, int64) error
+	switch policy.Action {
+	case domain.RetentionAnonymize:
+		action = ucs.AnonymizeCustomer
+	case domain.RetentionDelete:
+		action = ucs.DeleteCustomer
+	default:
+		return nil, types.NewErrorWithContext(
+			types.ErrInvalidInput,
+			fmt.Sprintf("invalid retention action: %s", policy.Action),
+			nil,
+			map[string]any{"action": policy.Action, "allowed": []string{domain.RetentionAnonymize, domain.RetentionDelete}},
+		)
+	}
+
+	batchSize := policy.BatchSize
+	if batchSize < 1 {
+		batchSize = defaultRetentionBatchSize
+	}
+
+	report := &domain.RetentionReport{
+		Cutoff:    now.Add(-policy.MaxInactivity).UTC(),
+		Action:    policy.Action,
+		Processed: []int64{},
+		Failed:    []int64{},
+	}
+
+	customers, err := repo.GetInactive(ctx, report.Cutoff, batchSize)
+	if err != nil {
+		return nil, types.NewErrorWithContext(
+			types.ErrOperationFailed,
+			"failed to get inactive customers",
+			err,
+			map[string]any{"cutoff": report.Cutoff},
+		)
+	}
+	report.More = len(customers) == batchSize
+
+	logger := pkglogger.FromContext(ctx)
+	for _, customer := range customers {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		if err := action(ctx, customer.ID); err != nil {
+			if types.IsNotFound(err) {
+				continue
+			}
+			logger.Warn("retention %s of customer %d failed: %v", policy.Action, customer.ID, err)
+			report.Failed = append(report.Failed, customer.ID)
+			continue
+		}
+		logger.Info("retention %s of customer %d inactive since before %s", policy.Action, customer.ID, report.Cutoff.Format(time.RFC3339))
+		report.Processed = append(report.Processed, customer.ID)
+	}
+	return report, nil
+}
@@ -0,0 +1,131 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	core "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+func Test_ApplyRetention(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	period := 365 * 24 * time.Hour
+	cutoff := now.Add(-period)
+	birth := time.Date(1990, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	// Dos customers justo antes del umbral, uno justo en el umbral y uno reciente
+	newCustomers := func() []domain.Customer {
+		return []domain.Customer{
+			{ID: 1, Name: "Ana", LastName: "Perez", Email: "ana@example.com", Age: 34, BirthDate: birth, CreatedAt: cutoff.Add(-48 * time.Hour)},
+			{ID: 2, Name: "Luis", LastName: "Gomez", Email: "luis@example.com", Age: 50, BirthDate: birth, CreatedAt: cutoff.Add(-time.Second)},
+			{ID: 3, Name: "Eva", LastName: "Diaz", Email: "eva@example.com", Age: 28, BirthDate: birth, CreatedAt: cutoff},
+			{ID: 4, Name: "Juan", LastName: "Lopez", Email: "juan@example.com", Age: 41, BirthDate: birth, CreatedAt: now.Add(-time.Hour)},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		action        string
+		wantProcessed []int64
+		wantRemaining []int64
+	}{
+		{
+			name:          "should anonymize customers inactive beyond the threshold",
+			action:        domain.RetentionAnonymize,
+			wantProcessed: []int64{1, 2},
+			wantRemaining: []int64{1, 2, 3, 4},
+		},
+		{
+			name:          "should delete customers inactive beyond the threshold",
+			action:        domain.RetentionDelete,
+			wantProcessed: []int64{1, 2},
+			wantRemaining: []int64{3, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &repoStub{customers: newCustomers()}
+			events := &eventStoreStub{}
+			ucs := core.NewUseCases(repo, core.WithEventStore(events))
+			policy := domain.RetentionPolicy{MaxInactivity: period, Action: tt.action, BatchSize: 10}
+
+			report, err := core.ApplyRetention(context.Background(), repo, ucs, policy, now)
+			require.NoError(t, err)
+
+			assert.Equal(t, cutoff, report.Cutoff)
+			assert.Equal(t, tt.wantProcessed, report.Processed)
+			assert.Empty(t, report.Failed)
+			assert.False(t, report.More)
+
+			remaining := []int64{}
+			for _, c := range repo.customers {
+				remaining = append(remaining, c.ID)
+			}
+			assert.Equal(t, tt.wantRemaining, remaining)
+
+			// Cada acción queda registrada en el log de eventos
+			recorded, err := events.Read(context.Background(), 0)
+			require.NoError(t, err)
+			assert.Len(t, recorded, len(tt.wantProcessed))
+
+			again, err := core.ApplyRetention(context.Background(), repo, ucs, policy, now)
+			require.NoError(t, err)
+			assert.Empty(t, again.Processed, "a second run must not touch the same customers")
+		})
+	}
+
+	t.Run("should process at most one batch per run", func(t *testing.T) {
+		repo := &repoStub{customers: newCustomers()}
+		ucs := core.NewUseCases(repo)
+		policy := domain.RetentionPolicy{MaxInactivity: period, Action: domain.RetentionAnonymize, BatchSize: 1}
+
+		first, err := core.ApplyRetention(context.Background(), repo, ucs, policy, now)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1}, first.Processed)
+		assert.True(t, first.More)
+
+		second, err := core.ApplyRetention(context.Background(), repo, ucs, policy, now)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{2}, second.Processed)
+	})
+
+	t.Run("should anonymize a soft deleted customer", func(t *testing.T) {
+		deletedAt := cutoff.Add(-time.Hour)
+		customers := newCustomers()
+		customers[0].DeletedAt = &deletedAt
+		repo := &repoStub{customers: customers}
+		ucs := core.NewUseCases(repo)
+		policy := domain.RetentionPolicy{MaxInactivity: period, Action: domain.RetentionAnonymize, BatchSize: 10}
+
+		report, err := core.ApplyRetention(context.Background(), repo, ucs, policy, now)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1, 2}, report.Processed)
+		assert.Empty(t, report.Failed)
+
+		require.NotNil(t, repo.customers[0].AnonymizedAt)
+		assert.NotEqual(t, "ana@example.com", repo.customers[0].Email)
+		assert.NotNil(t, repo.customers[0].DeletedAt, "anonymizing must not restore the customer")
+	})
+
+	t.Run("should reject an invalid policy", func(t *testing.T) {
+		repo := &repoStub{customers: newCustomers()}
+		ucs := core.NewUseCases(repo)
+
+		for _, policy := range []domain.RetentionPolicy{
+			{MaxInactivity: 0, Action: domain.RetentionAnonymize},
+			{MaxInactivity: period, Action: "archive"},
+		} {
+			_, err := core.ApplyRetention(context.Background(), repo, ucs, policy, now)
+			errType, ok := types.GetErrorType(err)
+			require.True(t, ok)
+			assert.Equal(t, types.ErrInvalidInput, errType)
+		}
+	})
+}
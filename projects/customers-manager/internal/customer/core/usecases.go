@@ -2,6 +2,14 @@ package core
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/text/language"
 
 	types "github.com/devpablocristo/tech-house/pkg/types"
 	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
@@ -9,15 +17,133 @@ import (
 )
 
 type UseCases struct {
-	repo ports.Repository
+	repo      ports.Repository
+	events    ports.EventStore
+	enrichers []ports.Enricher
+	skipped   map[string]bool
+	sort      domain.CustomerSort
+	expansion expansionLimits
+	collation language.Tag
+
+	idempotency    ports.IdempotencyStore
+	idempotencyTTL time.Duration
+
+	maxAgeBuckets int
+
+	kpiFlight singleflight.Group
+	kpiWindow time.Duration
+	kpiMu     sync.Mutex
+	kpiLast   *domain.KPI
+	kpiLastAt time.Time
 }
 
-func NewUseCases(r ports.Repository) ports.UseCases {
-	return &UseCases{
-		repo: r,
+// UseCaseOption configura opciones de los casos de uso
+type UseCaseOption func(*UseCases)
+
+// WithEventStore registra cada alta, modificación y baja en el log de eventos,
+// en la misma transacción que el cambio de estado
+func WithEventStore(events ports.EventStore) UseCaseOption {
+	return func(uc *UseCases) {
+		uc.events = events
 	}
 }
 
+// WithEnrichers agrega pasos de enriquecimiento opcionales al alta, que se ejecutan en orden
+func WithEnrichers(enrichers ...ports.Enricher) UseCaseOption {
+	return func(uc *UseCases) {
+		uc.enrichers = append(uc.enrichers, enrichers...)
+	}
+}
+
+// WithSkippedEnrichers deshabilita los pasos de enriquecimiento indicados por nombre
+func WithSkippedEnrichers(names ...string) UseCaseOption {
+	return func(uc *UseCases) {
+		if uc.skipped == nil {
+			uc.skipped = make(map[string]bool, len(names))
+		}
+		for _, name := range names {
+			uc.skipped[name] = true
+		}
+	}
+}
+
+// WithDefaultSort define el orden del listado de customers (ver domain.ParseCustomerSort)
+func WithDefaultSort(sort domain.CustomerSort) UseCaseOption {
+	return func(uc *UseCases) {
+		uc.sort = sort
+	}
+}
+
+// WithNameCollation ordena los campos de nombre con la collation del idioma (ver domain.ParseNameCollation).
+// SQLite ordena por bytes, así que con un idioma configurado las páginas ordenadas por nombre se ordenan en
+// memoria sobre todos los customers en lugar de en la base.
+func WithNameCollation(collation language.Tag) UseCaseOption {
+	return func(uc *UseCases) {
+		uc.collation = collation
+	}
+}
+
+// WithExpansionLimits define cuántas expansiones acepta un request y cuántos resolvers corren a la vez;
+// un valor menor a 1 mantiene el de por defecto
+func WithExpansionLimits(maxValues, maxConcurrency int) UseCaseOption {
+	return func(uc *UseCases) {
+		if maxValues > 0 {
+			uc.expansion.maxValues = maxValues
+		}
+		if maxConcurrency > 0 {
+			uc.expansion.maxConcurrency = maxConcurrency
+		}
+	}
+}
+
+// WithMaxAgeBuckets define cuántos rangos acepta CountByAgeBuckets; un valor menor a 1 mantiene el de por defecto
+func WithMaxAgeBuckets(maxBuckets int) UseCaseOption {
+	return func(uc *UseCases) {
+		if maxBuckets > 0 {
+			uc.maxAgeBuckets = maxBuckets
+		}
+	}
+}
+
+// WithKPICoalescingWindow reutiliza el resultado de GetKPI para los requests que llegan hasta window después
+// de calcularlo. Los requests concurrentes comparten siempre un único cálculo; window en cero solo coalesce
+// los que llegan mientras el cálculo está en curso.
+func WithKPICoalescingWindow(window time.Duration) UseCaseOption {
+	return func(uc *UseCases) {
+		uc.kpiWindow = window
+	}
+}
+
+// defaultIdempotencyTTL es cuánto vale una Idempotency-Key cuando no se configura otro TTL
+const defaultIdempotencyTTL = 24 * time.Hour
+
+// WithIdempotencyStore guarda las Idempotency-Key de CreateCustomerIdempotent durante ttl; un ttl menor a 1
+// usa el de por defecto. Sin store configurado la key se ignora.
+func WithIdempotencyStore(store ports.IdempotencyStore, ttl time.Duration) UseCaseOption {
+	return func(uc *UseCases) {
+		uc.idempotency = store
+		uc.idempotencyTTL = ttl
+		if ttl <= 0 {
+			uc.idempotencyTTL = defaultIdempotencyTTL
+		}
+	}
+}
+
+func NewUseCases(r ports.Repository, opts ...UseCaseOption) ports.UseCases {
+	uc := &UseCases{
+		repo:          r,
+		sort:          domain.DefaultCustomerSort,
+		expansion:     defaultExpansionLimits,
+		maxAgeBuckets: defaultMaxAgeBuckets,
+	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// GetCustomers retorna el listado completo en el orden por defecto; como los listados paginados, sin los
+// customers anonimizados
 func (uc *UseCases) GetCustomers(ctx context.Context) ([]domain.Customer, error) {
 	customers, err := uc.repo.GetAll(ctx)
 	if err != nil {
@@ -27,18 +153,103 @@ func (uc *UseCases) GetCustomers(ctx context.Context) ([]domain.Customer, error)
 			err,
 		)
 	}
-	return customers, nil
+	return sortCustomers(activeCustomers(customers), uc.sort, uc.collation), nil
+}
+
+// GetCustomersPaged retorna una página del listado, en el orden por defecto, junto con el total de customers.
+// limit debe estar entre 1 y maxPageLimit y offset no puede ser negativo.
+func (uc *UseCases) GetCustomersPaged(ctx context.Context, limit, offset int) (*domain.CustomerPage, error) {
+	return uc.GetCustomersSorted(ctx, uc.sort, limit, offset)
+}
+
+// GetCustomersSorted retorna una página del listado en el orden pedido, junto con el total de customers. El campo
+// debe estar en domain.SortableFields (si no, ErrInvalidInput) y los empates se resuelven por id, es decir, por
+// orden de alta. limit y offset se validan como en GetCustomersPaged.
+func (uc *UseCases) GetCustomersSorted(ctx context.Context, sort domain.CustomerSort, limit, offset int) (*domain.CustomerPage, error) {
+	if err := validateSort(sort); err != nil {
+		return nil, err
+	}
+	if err := validatePage(limit, offset); err != nil {
+		return nil, err
+	}
+
+	customers, err := uc.getPage(ctx, sort, limit, offset)
+	if err != nil {
+		return nil, types.NewError(
+			types.ErrOperationFailed,
+			"failed to get customers",
+			err,
+		)
+	}
+
+	total, err := uc.repo.Count(ctx)
+	if err != nil {
+		return nil, types.NewError(
+			types.ErrOperationFailed,
+			"failed to count customers",
+			err,
+		)
+	}
+
+	return &domain.CustomerPage{
+		Customers: customers,
+		Total:     total,
+		Offset:    offset,
+	}, nil
+}
+
+// SearchCustomers retorna una página de los customers cuyo nombre, apellido o email contienen query, sin
+// distinguir mayúsculas ni acentos, en el orden por defecto; Total es la cantidad de coincidencias.
+// Una query vacía equivale a GetCustomersPaged.
+func (uc *UseCases) SearchCustomers(ctx context.Context, query string, limit, offset int) (*domain.CustomerPage, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return uc.GetCustomersPaged(ctx, limit, offset)
+	}
+	if err := validatePage(limit, offset); err != nil {
+		return nil, err
+	}
+	if len(query) > maxSearchLength {
+		return nil, types.NewErrorWithContext(
+			types.ErrValidation,
+			fmt.Sprintf("search query must not exceed %d characters", maxSearchLength),
+			nil,
+			map[string]any{"length": len(query)},
+		)
+	}
+
+	// El repositorio filtra en la base y retorna solo candidatos; acá se confirma cada uno, así la búsqueda no
+	// depende de que el texto normalizado guardado esté al día
+	needle := domain.FoldSearch(query)
+	candidates, err := uc.repo.SearchCandidates(ctx, needle)
+	if err != nil {
+		return nil, types.NewError(
+			types.ErrOperationFailed,
+			"failed to search customers",
+			err,
+		)
+	}
+
+	matches := make([]domain.Customer, 0, len(candidates))
+	for _, customer := range activeCustomers(candidates) {
+		if matchesSearch(customer, needle) {
+			matches = append(matches, customer)
+		}
+	}
+
+	sorted := sortCustomers(matches, uc.sort, uc.collation)
+	return &domain.CustomerPage{
+		Customers: pageOf(sorted, limit, offset),
+		Total:     len(sorted),
+		Offset:    offset,
+	}, nil
 }
 
 func (uc *UseCases) GetCustomerByID(ctx context.Context, ID int64) (*domain.Customer, error) {
 	customer, err := uc.repo.GetByID(ctx, ID)
 	if err != nil {
 		if types.IsNotFound(err) {
-			return nil, types.NewError(
-				types.ErrNotFound,
-				"customer not found",
-				err,
-			)
+			return nil, customerNotFound(err)
 		}
 		return nil, types.NewError(
 			types.ErrOperationFailed,
@@ -49,30 +260,167 @@ func (uc *UseCases) GetCustomerByID(ctx context.Context, ID int64) (*domain.Cust
 	return customer, nil
 }
 
-func (uc *UseCases) CreateCustomer(ctx context.Context, customer *domain.Customer) error {
-	if err := uc.repo.Create(ctx, customer); err != nil {
+// CreateCustomer da de alta el customer. Los pasos de enriquecimiento que fallan no impiden el alta:
+// se retornan como warnings junto con el customer creado con sus datos originales.
+func (uc *UseCases) CreateCustomer(ctx context.Context, customer *domain.Customer) ([]domain.Warning, error) {
+	return uc.createCustomer(ctx, customer, nil)
+}
+
+// CreateCustomerIdempotent da de alta el customer y graba la key en la misma transacción. Si la key ya se
+// procesó y no venció, no se crea otro customer: se completa customer con el original y replayed es true.
+// La key se graba con un insert que falla si otro request ya la grabó, así dos reintentos concurrentes que no
+// la encontraron no dan de alta dos customers: el que pierde deshace su alta y repite el resultado del otro.
+// Una key vacía, o sin IdempotencyStore configurado, es un alta común.
+func (uc *UseCases) CreateCustomerIdempotent(ctx context.Context, key string, customer *domain.Customer) ([]domain.Warning, bool, error) {
+	if key == "" || uc.idempotency == nil {
+		warnings, err := uc.CreateCustomer(ctx, customer)
+		return warnings, false, err
+	}
+	if len(key) > domain.MaxIdempotencyKeyLength {
+		return nil, false, types.NewErrorWithContext(
+			types.ErrValidation,
+			fmt.Sprintf("idempotency key must not exceed %d characters", domain.MaxIdempotencyKeyLength),
+			nil,
+			map[string]any{"length": len(key)},
+		)
+	}
+
+	now := time.Now().UTC()
+	replayed, err := uc.replayIdempotent(ctx, key, customer, now)
+	if err != nil || replayed {
+		return nil, replayed, err
+	}
+
+	warnings, err := uc.createCustomer(ctx, customer, func(ctx context.Context) error {
+		return uc.idempotency.Save(ctx, &domain.IdempotencyRecord{
+			Key:        key,
+			CustomerID: customer.ID,
+			CreatedAt:  now,
+		}, now.Add(-uc.idempotencyTTL))
+	})
+	if types.IsConflict(err) {
+		// Un request concurrente con la misma key ganó la carrera (la key ya está grabada, o su customer ocupa
+		// el email): el alta de este se deshizo con la transacción y se repite el resultado del otro
+		if replayed, replayErr := uc.replayIdempotent(ctx, key, customer, now); replayErr != nil || replayed {
+			return nil, replayed, replayErr
+		}
+	}
+	return warnings, false, err
+}
+
+// replayIdempotent completa customer con el alta original de la key si la key existe y no venció
+func (uc *UseCases) replayIdempotent(ctx context.Context, key string, customer *domain.Customer, now time.Time) (bool, error) {
+	record, err := uc.idempotency.Get(ctx, key)
+	if err != nil {
+		if types.IsNotFound(err) {
+			return false, nil
+		}
+		return false, types.NewError(
+			types.ErrOperationFailed,
+			"failed to read idempotency key",
+			err,
+		)
+	}
+	if record.Expired(uc.idempotencyTTL, now) {
+		return false, nil
+	}
+
+	original, err := uc.GetCustomerByID(ctx, record.CustomerID)
+	if err != nil {
+		return false, err
+	}
+	*customer = *original
+	return true, nil
+}
+
+// createCustomer da de alta el customer y registra el evento; afterCreate, si no es nil, corre en la misma
+// transacción después del alta
+func (uc *UseCases) createCustomer(ctx context.Context, customer *domain.Customer, afterCreate func(ctx context.Context) error) ([]domain.Warning, error) {
+	warnings := uc.enrich(ctx, customer)
+
+	err := uc.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.repo.Create(ctx, customer); err != nil {
+			return err
+		}
+		if err := uc.appendEvent(ctx, domain.EventCustomerCreated, customer.ID, customer); err != nil {
+			return err
+		}
+		if afterCreate != nil {
+			return afterCreate(ctx)
+		}
+		return nil
+	})
+	if err != nil {
 		if types.IsConflict(err) {
-			return err // Propagamos el error de conflicto tal cual
+			return nil, err // Propagamos el error de conflicto tal cual
 		}
-		return types.NewError(
+		return nil, types.NewError(
 			types.ErrOperationFailed,
 			"failed to create customer",
 			err,
 		)
 	}
-	return nil
+	return warnings, nil
 }
 
-func (uc *UseCases) GetCustomerByEmail(ctx context.Context, email string) (*domain.Customer, error) {
-	customer, err := uc.repo.GetByEmail(ctx, email)
+// CreateCustomers da de alta el lote en una única transacción: si un customer falla se hace rollback de todos
+// y se retorna un *domain.BatchItemError con su posición. Retorna una copia del lote con los IDs asignados.
+// Los pasos de enriquecimiento que fallan se registran en el log pero, a diferencia del alta individual,
+// no se retornan como warnings.
+func (uc *UseCases) CreateCustomers(ctx context.Context, customers []domain.Customer) ([]domain.Customer, error) {
+	if len(customers) == 0 {
+		return nil, types.NewError(
+			types.ErrValidation,
+			"batch must not be empty",
+			nil,
+		)
+	}
+
+	created := make([]domain.Customer, len(customers))
+	copy(created, customers)
+	for i := range created {
+		uc.enrich(ctx, &created[i])
+	}
+
+	err := uc.repo.WithinTx(ctx, func(ctx context.Context) error {
+		for i := range created {
+			if err := uc.repo.Create(ctx, &created[i]); err != nil {
+				return &domain.BatchItemError{Index: i, Err: err}
+			}
+			if err := uc.appendEvent(ctx, domain.EventCustomerCreated, created[i].ID, &created[i]); err != nil {
+				return &domain.BatchItemError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		if types.IsNotFound(err) {
+		var itemErr *domain.BatchItemError
+		if !errors.As(err, &itemErr) {
 			return nil, types.NewError(
-				types.ErrNotFound,
-				"customer not found",
+				types.ErrOperationFailed,
+				"failed to create customers",
 				err,
 			)
 		}
+		// Como en el alta individual, los conflictos se propagan tal cual
+		if !types.IsConflict(itemErr.Err) {
+			itemErr.Err = types.NewError(
+				types.ErrOperationFailed,
+				"failed to create customer",
+				itemErr.Err,
+			)
+		}
+		return nil, itemErr
+	}
+	return created, nil
+}
+
+func (uc *UseCases) GetCustomerByEmail(ctx context.Context, email string) (*domain.Customer, error) {
+	customer, err := uc.repo.GetByEmail(ctx, email)
+	if err != nil {
+		if types.IsNotFound(err) {
+			return nil, customerNotFound(err)
+		}
 		return nil, types.NewError(
 			types.ErrOperationFailed,
 			"failed to get customer by email",
@@ -83,10 +431,16 @@ func (uc *UseCases) GetCustomerByEmail(ctx context.Context, email string) (*doma
 }
 
 func (uc *UseCases) UpdateCustomer(ctx context.Context, customer *domain.Customer) error {
-	if err := uc.repo.Update(ctx, customer); err != nil {
-		if types.IsNotFound(err) {
+	err := uc.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.repo.Update(ctx, customer); err != nil {
 			return err
 		}
+		return uc.appendEvent(ctx, domain.EventCustomerUpdated, customer.ID, customer)
+	})
+	if err != nil {
+		if types.IsNotFound(err) {
+			return customerNotFound(err)
+		}
 		if types.IsConflict(err) {
 			return err
 		}
@@ -99,11 +453,65 @@ func (uc *UseCases) UpdateCustomer(ctx context.Context, customer *domain.Custome
 	return nil
 }
 
-func (uc *UseCases) DeleteCustomer(ctx context.Context, ID int64) error {
-	if err := uc.repo.Delete(ctx, ID); err != nil {
+// PatchCustomer modifica solo los campos no nil del patch; la lectura del customer actual y el guardado
+// ocurren en la misma transacción, así un PATCH concurrente no pisa campos que este no informó. La
+// Precondition del patch se evalúa sobre esa misma lectura, lo que la hace atómica con el guardado.
+// Un patch vacío retorna el customer sin modificarlo ni registrar eventos.
+func (uc *UseCases) PatchCustomer(ctx context.Context, id int64, patch domain.CustomerPatch) (*domain.Customer, error) {
+	if patch.IsEmpty() && patch.Precondition == nil {
+		return uc.GetCustomerByID(ctx, id)
+	}
+
+	var customer *domain.Customer
+	err := uc.repo.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := uc.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if patch.Precondition != nil {
+			if err := patch.Precondition(*current); err != nil {
+				return err
+			}
+		}
+		if patch.IsEmpty() {
+			customer = current
+			return nil
+		}
+		patch.Apply(current)
+
+		if err := uc.repo.Update(ctx, current); err != nil {
+			return err
+		}
+		customer = current
+		return uc.appendEvent(ctx, domain.EventCustomerUpdated, id, current)
+	})
+	if err != nil {
 		if types.IsNotFound(err) {
+			return nil, customerNotFound(err)
+		}
+		if types.IsConflict(err) {
+			return nil, err
+		}
+		return nil, types.NewError(
+			types.ErrOperationFailed,
+			"failed to patch customer",
+			err,
+		)
+	}
+	return customer, nil
+}
+
+func (uc *UseCases) DeleteCustomer(ctx context.Context, ID int64) error {
+	err := uc.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.repo.Delete(ctx, ID); err != nil {
 			return err
 		}
+		return uc.appendEvent(ctx, domain.EventCustomerDeleted, ID, nil)
+	})
+	if err != nil {
+		if types.IsNotFound(err) {
+			return customerNotFound(err)
+		}
 		return types.NewError(
 			types.ErrOperationFailed,
 			"failed to delete customer",
@@ -113,7 +521,323 @@ func (uc *UseCases) DeleteCustomer(ctx context.Context, ID int64) error {
 	return nil
 }
 
+// SoftDeleteCustomer elimina el customer de forma lógica: deja de aparecer en el detalle, el listado y los KPIs,
+// pero se conserva (con su email reservado) y se puede recuperar con RestoreCustomer
+func (uc *UseCases) SoftDeleteCustomer(ctx context.Context, ID int64) error {
+	err := uc.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.repo.SoftDelete(ctx, ID, time.Now().UTC()); err != nil {
+			return err
+		}
+		return uc.appendEvent(ctx, domain.EventCustomerSoftDeleted, ID, nil)
+	})
+	if err != nil {
+		if types.IsNotFound(err) {
+			return customerNotFound(err)
+		}
+		return types.NewError(
+			types.ErrOperationFailed,
+			"failed to soft delete customer",
+			err,
+		)
+	}
+	return nil
+}
+
+// RestoreCustomer revierte la eliminación lógica y retorna el customer restaurado. Restaurar un customer que no
+// está eliminado no hace nada y lo retorna tal cual.
+func (uc *UseCases) RestoreCustomer(ctx context.Context, ID int64) (*domain.Customer, error) {
+	customer, err := uc.GetCustomerIncludingDeleted(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	if customer.DeletedAt == nil {
+		return customer, nil
+	}
+
+	err = uc.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.repo.Restore(ctx, ID); err != nil {
+			return err
+		}
+		restored, err := uc.repo.GetByID(ctx, ID)
+		if err != nil {
+			return err
+		}
+		customer = restored
+		return uc.appendEvent(ctx, domain.EventCustomerRestored, ID, restored)
+	})
+	if err != nil {
+		if types.IsNotFound(err) {
+			return nil, customerNotFound(err)
+		}
+		return nil, types.NewError(
+			types.ErrOperationFailed,
+			"failed to restore customer",
+			err,
+		)
+	}
+	return customer, nil
+}
+
+// GetCustomerIncludingDeleted busca el customer aunque esté eliminado lógicamente; DeletedAt indica si lo está
+func (uc *UseCases) GetCustomerIncludingDeleted(ctx context.Context, ID int64) (*domain.Customer, error) {
+	customer, err := uc.repo.GetByIDIncludingDeleted(ctx, ID)
+	if err != nil {
+		if types.IsNotFound(err) {
+			return nil, customerNotFound(err)
+		}
+		return nil, types.NewError(
+			types.ErrOperationFailed,
+			"failed to get customer",
+			err,
+		)
+	}
+	return customer, nil
+}
+
+// GetCustomersIncludingDeleted retorna una página del listado, en el orden por defecto, que incluye a los
+// customers eliminados lógicamente; Total cuenta a ambos. limit y offset se validan como en GetCustomersPaged.
+func (uc *UseCases) GetCustomersIncludingDeleted(ctx context.Context, limit, offset int) (*domain.CustomerPage, error) {
+	if err := validatePage(limit, offset); err != nil {
+		return nil, err
+	}
+
+	all, err := uc.repo.GetAllIncludingDeleted(ctx)
+	if err != nil {
+		return nil, types.NewError(
+			types.ErrOperationFailed,
+			"failed to get customers",
+			err,
+		)
+	}
+
+	sorted := sortCustomers(activeCustomers(all), uc.sort, uc.collation)
+	return &domain.CustomerPage{
+		Customers: pageOf(sorted, limit, offset),
+		Total:     len(sorted),
+		Offset:    offset,
+	}, nil
+}
+
+// GetTopCustomers retorna los n customers con mayor valor en la dimensión indicada (edad o fecha de alta).
+// Los empates se desempatan por id ascendente para que el resultado sea estable entre llamadas; n se limita a maxTopCustomers.
+// Los customers anonimizados no entran en el ranking.
+func (uc *UseCases) GetTopCustomers(ctx context.Context, by string, n int) ([]domain.Customer, error) {
+	if by != domain.TopByAge && by != domain.TopByCreatedAt {
+		return nil, types.NewErrorWithContext(
+			types.ErrValidation,
+			fmt.Sprintf("invalid top dimension: %s", by),
+			nil,
+			map[string]any{"by": by, "allowed": []string{domain.TopByAge, domain.TopByCreatedAt}},
+		)
+	}
+	if n < 1 {
+		return nil, types.NewErrorWithContext(
+			types.ErrValidation,
+			"n must be greater than zero",
+			nil,
+			map[string]any{"n": n},
+		)
+	}
+	if n > maxTopCustomers {
+		n = maxTopCustomers
+	}
+
+	customers, err := uc.repo.GetAll(ctx)
+	if err != nil {
+		return nil, types.NewError(
+			types.ErrOperationFailed,
+			"failed to get top customers",
+			err,
+		)
+	}
+
+	return topCustomers(activeCustomers(customers), by, n), nil
+}
+
+// CountByField retorna la cantidad de customers agrupada por la dimensión indicada (age_bucket, email_domain).
+// Si hay más de maxAggregateGroups grupos, los menos frecuentes se suman en GroupOther.
+func (uc *UseCases) CountByField(ctx context.Context, field string) (map[string]int64, error) {
+	groupKey, ok := groupKeys[field]
+	if !ok {
+		return nil, types.NewErrorWithContext(
+			types.ErrValidation,
+			fmt.Sprintf("invalid group_by field: %s", field),
+			nil,
+			map[string]any{"group_by": field, "allowed": []string{domain.GroupByAgeBucket, domain.GroupByEmailDomain}},
+		)
+	}
+
+	customers, err := uc.repo.GetAll(ctx)
+	if err != nil {
+		return nil, types.NewError(
+			types.ErrOperationFailed,
+			"failed to count customers",
+			err,
+		)
+	}
+	if field == domain.GroupByEmailDomain {
+		// El email de los anonimizados es un placeholder: contarlo expondría cuántos borrados hubo
+		customers = activeCustomers(customers)
+	}
+
+	return countByField(customers, groupKey, maxAggregateGroups), nil
+}
+
+// CountByAgeBuckets retorna la cantidad de customers en cada rango de edades definido por el cliente. Los rangos
+// deben ser como máximo los configurados, no solaparse y cubrir todas las edades admitidas; si no, ErrValidation.
+func (uc *UseCases) CountByAgeBuckets(ctx context.Context, buckets []domain.AgeBucket) (map[string]int64, error) {
+	if err := validateAgeBuckets(buckets, uc.maxAgeBuckets); err != nil {
+		return nil, err
+	}
+
+	customers, err := uc.repo.GetAll(ctx)
+	if err != nil {
+		return nil, types.NewError(
+			types.ErrOperationFailed,
+			"failed to count customers",
+			err,
+		)
+	}
+
+	return countByAgeBuckets(customers, buckets), nil
+}
+
+// ExportCustomer arma el bundle de datos del customer para una solicitud de acceso del titular.
+// Sin EventStore configurado el historial queda vacío.
+func (uc *UseCases) ExportCustomer(ctx context.Context, id int64, requestedBy string) (*domain.CustomerExport, error) {
+	customer, err := uc.GetCustomerByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []domain.Event{}
+	if uc.events != nil {
+		events, err = uc.events.ReadByCustomer(ctx, id)
+		if err != nil {
+			return nil, types.NewError(
+				types.ErrOperationFailed,
+				"failed to read customer events",
+				err,
+			)
+		}
+	}
+
+	return &domain.CustomerExport{
+		Customer:    customer,
+		Events:      events,
+		ExportedAt:  time.Now().UTC(),
+		RequestedBy: requestedBy,
+	}, nil
+}
+
+// AnonymizeCustomer borra los datos personales del customer (derecho de supresión) conservando la edad y
+// la fecha de alta para los KPIs. También se borra el payload de sus eventos previos y se registra el
+// evento CustomerAnonymized; a partir de ahí el customer no aparece en el detalle ni en el listado. Un
+// customer eliminado lógicamente también se anonimiza: la supresión no depende de que siga activo.
+func (uc *UseCases) AnonymizeCustomer(ctx context.Context, id int64) error {
+	customer, err := uc.GetCustomerIncludingDeleted(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	token, err := anonymizationToken()
+	if err != nil {
+		return types.NewError(
+			types.ErrInternal,
+			"failed to generate anonymization token",
+			err,
+		)
+	}
+	customer.Anonymize(token, time.Now().UTC())
+
+	err = uc.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.repo.Anonymize(ctx, customer); err != nil {
+			return err
+		}
+		if uc.events != nil {
+			if err := uc.events.Redact(ctx, id); err != nil {
+				return err
+			}
+		}
+		return uc.appendEvent(ctx, domain.EventCustomerAnonymized, id, customer)
+	})
+	if err != nil {
+		if types.IsNotFound(err) {
+			return customerNotFound(err)
+		}
+		return types.NewError(
+			types.ErrOperationFailed,
+			"failed to anonymize customer",
+			err,
+		)
+	}
+	return nil
+}
+
+// GetKPI calcula los KPIs de todos los customers. Los requests concurrentes comparten un único cálculo (y el
+// mismo *domain.KPI, que no se debe modificar) para no leer todos los customers una vez por request.
 func (uc *UseCases) GetKPI(ctx context.Context) (*domain.KPI, error) {
+	if kpi := uc.recentKPI(); kpi != nil {
+		return kpi, nil
+	}
+
+	// El cálculo no se corta si se cancela el request que lo inició, porque otros pueden estar esperándolo;
+	// lo sigue acotando el query timeout del repositorio
+	result, err, _ := uc.kpiFlight.Do("kpi", func() (any, error) {
+		customers, err := uc.repo.GetAll(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, types.NewError(
+				types.ErrOperationFailed,
+				"failed to calculate KPI",
+				err,
+			)
+		}
+		kpi := calculateKPI(customers)
+		uc.rememberKPI(kpi)
+		return kpi, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*domain.KPI), nil
+}
+
+// recentKPI retorna el último KPI calculado si todavía está dentro de la ventana de coalescing
+func (uc *UseCases) recentKPI() *domain.KPI {
+	if uc.kpiWindow <= 0 {
+		return nil
+	}
+	uc.kpiMu.Lock()
+	defer uc.kpiMu.Unlock()
+	if uc.kpiLast == nil || time.Since(uc.kpiLastAt) >= uc.kpiWindow {
+		return nil
+	}
+	return uc.kpiLast
+}
+
+// rememberKPI guarda el KPI calculado para los requests que llegan dentro de la ventana de coalescing
+func (uc *UseCases) rememberKPI(kpi *domain.KPI) {
+	if uc.kpiWindow <= 0 {
+		return
+	}
+	uc.kpiMu.Lock()
+	defer uc.kpiMu.Unlock()
+	uc.kpiLast = kpi
+	uc.kpiLastAt = time.Now()
+}
+
+// GetKPIForRange calcula los KPIs de los customers dados de alta entre from y to, ambos inclusive.
+// Un extremo en cero deja el rango abierto de ese lado; sin ninguno equivale a GetKPI.
+func (uc *UseCases) GetKPIForRange(ctx context.Context, from, to time.Time) (*domain.KPI, error) {
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return nil, types.NewErrorWithContext(
+			types.ErrInvalidInput,
+			"from must not be after to",
+			nil,
+			map[string]any{"from": from, "to": to},
+		)
+	}
+
 	customers, err := uc.repo.GetAll(ctx)
 	if err != nil {
 		return nil, types.NewError(
@@ -123,5 +847,15 @@ func (uc *UseCases) GetKPI(ctx context.Context) (*domain.KPI, error) {
 		)
 	}
 
-	return calculateKPI(customers), nil
+	inRange := make([]domain.Customer, 0, len(customers))
+	for _, c := range customers {
+		if !from.IsZero() && c.CreatedAt.Before(from) {
+			continue
+		}
+		if !to.IsZero() && c.CreatedAt.After(to) {
+			continue
+		}
+		inRange = append(inRange, c)
+	}
+	return calculateKPI(inRange), nil
 }
@@ -0,0 +1,1312 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/devpablocristo/tech-house/pkg/types"
+	core "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core"
+	domain "github.com/devpablocristo/tech-house/projects/customers-manager/internal/customer/core/domain"
+)
+
+// repoStub implementa ports.Repository en memoria para los tests de los casos de uso
+type repoStub struct {
+	customers []domain.Customer
+	err       error
+	sort      domain.CustomerSort
+	needle    string
+}
+
+func (r *repoStub) GetAll(ctx context.Context) ([]domain.Customer, error) {
+	return r.customers, r.err
+}
+
+// GetPage registra el orden pedido pero no lo aplica; el ORDER BY se prueba en el repositorio
+func (r *repoStub) GetPage(ctx context.Context, limit, offset int, sort domain.CustomerSort) ([]domain.Customer, error) {
+	r.sort = sort
+	if r.err != nil {
+		return nil, r.err
+	}
+	if offset >= len(r.customers) {
+		return []domain.Customer{}, nil
+	}
+	return r.customers[offset:min(offset+limit, len(r.customers))], nil
+}
+
+// SearchCandidates registra la búsqueda y retorna todos los customers; el filtro en la base se prueba en el
+// repositorio y el caso de uso confirma cada candidato
+func (r *repoStub) SearchCandidates(ctx context.Context, needle string) ([]domain.Customer, error) {
+	r.needle = needle
+	return r.customers, r.err
+}
+
+func (r *repoStub) Count(ctx context.Context) (int, error) {
+	return len(r.customers), r.err
+}
+
+func (r *repoStub) GetAllIncludingDeleted(ctx context.Context) ([]domain.Customer, error) {
+	return r.customers, r.err
+}
+
+func (r *repoStub) GetByID(ctx context.Context, id int64) (*domain.Customer, error) {
+	for _, c := range r.customers {
+		if c.ID == id && c.AnonymizedAt == nil && c.DeletedAt == nil {
+			return &c, nil
+		}
+	}
+	return nil, types.NewError(types.ErrNotFound, "customer not found", nil)
+}
+
+func (r *repoStub) GetByIDIncludingDeleted(ctx context.Context, id int64) (*domain.Customer, error) {
+	for _, c := range r.customers {
+		if c.ID == id && c.AnonymizedAt == nil {
+			return &c, nil
+		}
+	}
+	return nil, types.NewError(types.ErrNotFound, "customer not found", nil)
+}
+
+func (r *repoStub) SoftDelete(ctx context.Context, id int64, deletedAt time.Time) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i := range r.customers {
+		if r.customers[i].ID == id && r.customers[i].DeletedAt == nil {
+			r.customers[i].DeletedAt = &deletedAt
+			return nil
+		}
+	}
+	return types.NewError(types.ErrNotFound, "customer not found", nil)
+}
+
+func (r *repoStub) Restore(ctx context.Context, id int64) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i := range r.customers {
+		if r.customers[i].ID == id && r.customers[i].DeletedAt != nil {
+			r.customers[i].DeletedAt = nil
+			return nil
+		}
+	}
+	return types.NewError(types.ErrNotFound, "customer not found", nil)
+}
+
+func (r *repoStub) GetByEmail(ctx context.Context, email string) (*domain.Customer, error) {
+	for _, c := range r.customers {
+		if c.Email == email {
+			return &c, nil
+		}
+	}
+	return nil, types.NewError(types.ErrNotFound, "customer not found", nil)
+}
+
+func (r *repoStub) Create(ctx context.Context, customer *domain.Customer) error { return r.err }
+func (r *repoStub) Ping(ctx context.Context) error                              { return r.err }
+func (r *repoStub) Close() error                                                { return nil }
+
+func (r *repoStub) Update(ctx context.Context, customer *domain.Customer) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i := range r.customers {
+		if r.customers[i].ID == customer.ID {
+			r.customers[i] = *customer
+			return nil
+		}
+	}
+	return types.NewError(types.ErrNotFound, "customer not found", nil)
+}
+
+func (r *repoStub) Anonymize(ctx context.Context, customer *domain.Customer) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i := range r.customers {
+		if r.customers[i].ID == customer.ID {
+			r.customers[i] = *customer
+			return nil
+		}
+	}
+	return types.NewError(types.ErrNotFound, "customer not found", nil)
+}
+
+func (r *repoStub) Delete(ctx context.Context, id int64) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i := range r.customers {
+		if r.customers[i].ID == id {
+			r.customers = append(r.customers[:i], r.customers[i+1:]...)
+			return nil
+		}
+	}
+	return types.NewError(types.ErrNotFound, "customer not found", nil)
+}
+
+// GetInactive usa la fecha de alta como última actividad
+func (r *repoStub) GetInactive(ctx context.Context, before time.Time, limit int) ([]domain.Customer, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	inactive := []domain.Customer{}
+	for _, c := range r.customers {
+		if c.AnonymizedAt == nil && c.CreatedAt.Before(before) && len(inactive) < limit {
+			inactive = append(inactive, c)
+		}
+	}
+	return inactive, nil
+}
+
+func (r *repoStub) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func ids(customers []domain.Customer) []int64 {
+	result := make([]int64, len(customers))
+	for i, c := range customers {
+		result[i] = c.ID
+	}
+	return result
+}
+
+func Test_UseCases_GetCustomersPaged(t *testing.T) {
+	customers := make([]domain.Customer, 5)
+	for i := range customers {
+		customers[i] = domain.Customer{ID: int64(i + 1)}
+	}
+
+	tests := []struct {
+		name           string
+		limit          int
+		offset         int
+		wantIDs        []int64
+		wantNextOffset int
+	}{
+		{name: "should return the first page", limit: 2, offset: 0, wantIDs: []int64{1, 2}, wantNextOffset: 2},
+		{name: "should return a middle page", limit: 2, offset: 2, wantIDs: []int64{3, 4}, wantNextOffset: 4},
+		{name: "should omit next offset on the last page", limit: 2, offset: 4, wantIDs: []int64{5}},
+		{name: "should return an empty page past the end", limit: 2, offset: 10, wantIDs: []int64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ucs := core.NewUseCases(&repoStub{customers: customers})
+
+			page, err := ucs.GetCustomersPaged(context.Background(), tt.limit, tt.offset)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantIDs, ids(page.Customers))
+			assert.Equal(t, 5, page.Total)
+			assert.Equal(t, tt.wantNextOffset, page.NextOffset())
+		})
+	}
+
+	t.Run("should reject invalid parameters", func(t *testing.T) {
+		ucs := core.NewUseCases(&repoStub{customers: customers})
+
+		for _, params := range [][2]int{{0, 0}, {501, 0}, {10, -1}} {
+			_, err := ucs.GetCustomersPaged(context.Background(), params[0], params[1])
+			errType, ok := types.GetErrorType(err)
+			require.True(t, ok)
+			assert.Equal(t, types.ErrInvalidInput, errType)
+		}
+	})
+}
+
+func Test_UseCases_GetCustomersSorted(t *testing.T) {
+	// Los ids son el orden de alta; los empates se resuelven por id en ambas direcciones
+	customers := []domain.Customer{
+		{ID: 3, Name: "Carla"},
+		{ID: 1, Name: "Álvaro"},
+		{ID: 4, Name: "Ana"},
+		{ID: 2, Name: "Carla"},
+	}
+
+	t.Run("should pass the requested sort to the repository", func(t *testing.T) {
+		repo := &repoStub{customers: customers}
+		ucs := core.NewUseCases(repo)
+		sort := domain.CustomerSort{Field: domain.SortByAge, Desc: true}
+
+		page, err := ucs.GetCustomersSorted(context.Background(), sort, 10, 0)
+		require.NoError(t, err)
+
+		assert.Equal(t, sort, repo.sort)
+		assert.Equal(t, 4, page.Total)
+	})
+
+	t.Run("should keep insertion order on ties when sorting in memory", func(t *testing.T) {
+		collation, err := domain.ParseNameCollation("es")
+		require.NoError(t, err)
+		ucs := core.NewUseCases(&repoStub{customers: customers}, core.WithNameCollation(collation))
+
+		page, err := ucs.GetCustomersSorted(context.Background(), domain.CustomerSort{Field: domain.SortByName}, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1, 4, 2, 3}, ids(page.Customers))
+
+		page, err = ucs.GetCustomersSorted(context.Background(), domain.CustomerSort{Field: domain.SortByName, Desc: true}, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{2, 3, 4, 1}, ids(page.Customers))
+	})
+
+	t.Run("should reject a field outside the allowlist", func(t *testing.T) {
+		ucs := core.NewUseCases(&repoStub{customers: customers})
+
+		_, err := ucs.GetCustomersSorted(context.Background(), domain.CustomerSort{Field: "email"}, 10, 0)
+		errType, ok := types.GetErrorType(err)
+		require.True(t, ok)
+		assert.Equal(t, types.ErrInvalidInput, errType)
+	})
+}
+
+func Test_UseCases_SearchCustomers(t *testing.T) {
+	customers := []domain.Customer{
+		{ID: 1, Name: "José", LastName: "Pérez", Email: "jose@example.com"},
+		{ID: 2, Name: "Ana", LastName: "Gómez", Email: "ana@example.com"},
+		{ID: 3, Name: "Josefina", LastName: "Ruiz", Email: "jruiz@example.com"},
+		{ID: 4, Name: "Luis", LastName: "Martín", Email: "LUIS@Example.com"},
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		wantIDs   []int64
+		wantTotal int
+	}{
+		{name: "should ignore case and accents", query: "JOSE", wantIDs: []int64{1, 3}, wantTotal: 2},
+		{name: "should match an accented query against plain text", query: "jósé", wantIDs: []int64{1, 3}, wantTotal: 2},
+		{name: "should match the last name", query: "gomez", wantIDs: []int64{2}, wantTotal: 1},
+		{name: "should match the email", query: "luis@example", wantIDs: []int64{4}, wantTotal: 1},
+		{name: "should return an empty page without matches", query: "zzz", wantIDs: []int64{}, wantTotal: 0},
+		{name: "should fall back to the list for a blank query", query: "  ", wantIDs: []int64{1, 2, 3, 4}, wantTotal: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ucs := core.NewUseCases(&repoStub{customers: customers})
+
+			page, err := ucs.SearchCustomers(context.Background(), tt.query, 10, 0)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantIDs, ids(page.Customers))
+			assert.Equal(t, tt.wantTotal, page.Total)
+		})
+	}
+
+	t.Run("should search the repository with the normalized query", func(t *testing.T) {
+		repo := &repoStub{customers: customers}
+		ucs := core.NewUseCases(repo)
+
+		_, err := ucs.SearchCustomers(context.Background(), "  Jósé ", 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, "jose", repo.needle)
+	})
+
+	t.Run("should paginate the matches", func(t *testing.T) {
+		ucs := core.NewUseCases(&repoStub{customers: customers})
+
+		page, err := ucs.SearchCustomers(context.Background(), "example", 2, 2)
+		require.NoError(t, err)
+
+		assert.Equal(t, []int64{3, 4}, ids(page.Customers))
+		assert.Equal(t, 4, page.Total)
+	})
+
+	t.Run("should reject a query that is too long", func(t *testing.T) {
+		ucs := core.NewUseCases(&repoStub{customers: customers})
+
+		_, err := ucs.SearchCustomers(context.Background(), strings.Repeat("a", 101), 10, 0)
+		errType, ok := types.GetErrorType(err)
+		require.True(t, ok)
+		assert.Equal(t, types.ErrValidation, errType)
+	})
+}
+
+func Test_UseCases_GetTopCustomers(t *testing.T) {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	// Desordenados a propósito y con empates en ambas dimensiones
+	customers := []domain.Customer{
+		{ID: 4, Age: 40, CreatedAt: jan},
+		{ID: 2, Age: 60, CreatedAt: feb},
+		{ID: 5, Age: 60, CreatedAt: jan},
+		{ID: 1, Age: 40, CreatedAt: feb},
+		{ID: 3, Age: 60, CreatedAt: jan},
+	}
+
+	tests := []struct {
+		name     string
+		by       string
+		n        int
+		expected []int64
+	}{
+		{name: "should order by age breaking ties by id", by: domain.TopByAge, n: 5, expected: []int64{2, 3, 5, 1, 4}},
+		{name: "should cut ties at n by id", by: domain.TopByAge, n: 2, expected: []int64{2, 3}},
+		{name: "should order by created_at breaking ties by id", by: domain.TopByCreatedAt, n: 3, expected: []int64{1, 2, 3}},
+		{name: "should return everything when n exceeds the customers", by: domain.TopByAge, n: 50, expected: []int64{2, 3, 5, 1, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &repoStub{customers: customers}
+			ucs := core.NewUseCases(repo)
+
+			first, err := ucs.GetTopCustomers(context.Background(), tt.by, tt.n)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ids(first))
+
+			// El orden es estable entre llamadas y no modifica lo que retorna el repositorio
+			second, err := ucs.GetTopCustomers(context.Background(), tt.by, tt.n)
+			require.NoError(t, err)
+			assert.Equal(t, ids(first), ids(second))
+			assert.Equal(t, int64(4), repo.customers[0].ID)
+		})
+	}
+
+	t.Run("should cap n", func(t *testing.T) {
+		many := make([]domain.Customer, 150)
+		for i := range many {
+			many[i] = domain.Customer{ID: int64(i + 1), Age: 30}
+		}
+		ucs := core.NewUseCases(&repoStub{customers: many})
+
+		top, err := ucs.GetTopCustomers(context.Background(), domain.TopByAge, 1000)
+		require.NoError(t, err)
+		assert.Len(t, top, 100)
+	})
+
+	t.Run("should skip anonymized customers", func(t *testing.T) {
+		anonymizedAt := feb
+		ucs := core.NewUseCases(&repoStub{customers: append([]domain.Customer{
+			{ID: 6, Age: 90, CreatedAt: feb, AnonymizedAt: &anonymizedAt},
+		}, customers...)})
+
+		top, err := ucs.GetTopCustomers(context.Background(), domain.TopByAge, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{2, 3}, ids(top))
+	})
+
+	t.Run("should reject invalid parameters", func(t *testing.T) {
+		ucs := core.NewUseCases(&repoStub{customers: customers})
+
+		_, err := ucs.GetTopCustomers(context.Background(), "email", 10)
+		assert.True(t, types.IsValidationError(err))
+
+		_, err = ucs.GetTopCustomers(context.Background(), domain.TopByAge, 0)
+		assert.True(t, types.IsValidationError(err))
+	})
+}
+
+func Test_UseCases_CountByField(t *testing.T) {
+	customers := []domain.Customer{
+		{ID: 1, Age: 25, Email: "ana@example.com"},
+		{ID: 2, Age: 29, Email: "luis@Example.com"},
+		{ID: 3, Age: 30, Email: "eva@test.org"},
+		{ID: 4, Age: 41, Email: "juan@example.com"},
+	}
+
+	tests := []struct {
+		name     string
+		field    string
+		expected map[string]int64
+	}{
+		{
+			name:     "should group by age bucket",
+			field:    domain.GroupByAgeBucket,
+			expected: map[string]int64{"20-29": 2, "30-39": 1, "40-49": 1},
+		},
+		{
+			name:     "should group by email domain ignoring case",
+			field:    domain.GroupByEmailDomain,
+			expected: map[string]int64{"example.com": 3, "test.org": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ucs := core.NewUseCases(&repoStub{customers: customers})
+
+			counts, err := ucs.CountByField(context.Background(), tt.field)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, counts)
+		})
+	}
+
+	t.Run("should leave anonymized customers out of the email domains only", func(t *testing.T) {
+		anonymizedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		withAnonymized := append([]domain.Customer{
+			{ID: 5, Age: 33, Email: "anonymized-abc@" + domain.AnonymizedEmailDomain, AnonymizedAt: &anonymizedAt},
+		}, customers...)
+		ucs := core.NewUseCases(&repoStub{customers: withAnonymized})
+
+		domains, err := ucs.CountByField(context.Background(), domain.GroupByEmailDomain)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"example.com": 3, "test.org": 1}, domains)
+
+		ages, err := ucs.CountByField(context.Background(), domain.GroupByAgeBucket)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"20-29": 2, "30-39": 2, "40-49": 1}, ages)
+	})
+
+	t.Run("should fold the least frequent groups into other", func(t *testing.T) {
+		many := make([]domain.Customer, 0, 60)
+		for i := 0; i < 60; i++ {
+			many = append(many, domain.Customer{ID: int64(i + 1), Email: fmt.Sprintf("c%d@domain%02d.com", i, i)})
+		}
+		many = append(many, domain.Customer{ID: 100, Email: "x@domain59.com"})
+		ucs := core.NewUseCases(&repoStub{customers: many})
+
+		counts, err := ucs.CountByField(context.Background(), domain.GroupByEmailDomain)
+		require.NoError(t, err)
+		assert.Len(t, counts, 50)
+		assert.Equal(t, int64(2), counts["domain59.com"])
+		assert.Equal(t, int64(11), counts[domain.GroupOther])
+	})
+
+	t.Run("should reject an unsupported field", func(t *testing.T) {
+		ucs := core.NewUseCases(&repoStub{customers: customers})
+
+		_, err := ucs.CountByField(context.Background(), "status")
+		assert.True(t, types.IsValidationError(err))
+	})
+}
+
+func Test_UseCases_CountByAgeBuckets(t *testing.T) {
+	customers := []domain.Customer{{ID: 1, Age: 16}, {ID: 2, Age: 30}, {ID: 3, Age: 64}, {ID: 4, Age: 70}}
+
+	t.Run("should count customers in each bucket, including empty ones", func(t *testing.T) {
+		ucs := core.NewUseCases(&repoStub{customers: customers})
+
+		counts, err := ucs.CountByAgeBuckets(context.Background(), []domain.AgeBucket{
+			{Min: 65, Max: 150}, {Min: 1, Max: 17}, {Min: 18, Max: 29}, {Min: 30, Max: 64},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"1-17": 1, "18-29": 0, "30-64": 2, "65-150": 1}, counts)
+	})
+
+	tooMany := make([]domain.AgeBucket, 0, 150)
+	for age := domain.MinBucketAge; age <= domain.MaxBucketAge; age++ {
+		tooMany = append(tooMany, domain.AgeBucket{Min: age, Max: age})
+	}
+
+	tests := []struct {
+		name    string
+		buckets []domain.AgeBucket
+	}{
+		{name: "should reject no buckets", buckets: nil},
+		{name: "should reject more buckets than the maximum", buckets: tooMany},
+		{name: "should reject overlapping buckets", buckets: []domain.AgeBucket{{Min: 1, Max: 30}, {Min: 30, Max: 150}}},
+		{name: "should reject a gap between buckets", buckets: []domain.AgeBucket{{Min: 1, Max: 29}, {Min: 31, Max: 150}}},
+		{name: "should reject buckets that do not reach the maximum age", buckets: []domain.AgeBucket{{Min: 1, Max: 99}}},
+		{name: "should reject buckets that do not start at the minimum age", buckets: []domain.AgeBucket{{Min: 18, Max: 150}}},
+		{name: "should reject a bucket with min greater than max", buckets: []domain.AgeBucket{{Min: 1, Max: 40}, {Min: 60, Max: 41}, {Min: 61, Max: 150}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ucs := core.NewUseCases(&repoStub{customers: customers})
+
+			_, err := ucs.CountByAgeBuckets(context.Background(), tt.buckets)
+			assert.True(t, types.IsValidationError(err))
+		})
+	}
+
+	t.Run("should honor a configured maximum", func(t *testing.T) {
+		buckets := []domain.AgeBucket{{Min: 1, Max: 17}, {Min: 18, Max: 64}, {Min: 65, Max: 150}}
+
+		_, err := core.NewUseCases(&repoStub{customers: customers}, core.WithMaxAgeBuckets(2)).CountByAgeBuckets(context.Background(), buckets)
+		assert.True(t, types.IsValidationError(err))
+
+		_, err = core.NewUseCases(&repoStub{customers: customers}, core.WithMaxAgeBuckets(200)).CountByAgeBuckets(context.Background(), tooMany)
+		assert.NoError(t, err)
+	})
+}
+
+// enricherStub cambia el teléfono del customer o falla después de modificarlo
+type enricherStub struct {
+	name  string
+	phone string
+	err   error
+}
+
+func (e enricherStub) Name() string { return e.name }
+
+func (e enricherStub) Enrich(ctx context.Context, customer *domain.Customer) error {
+	customer.Phone = e.phone
+	return e.err
+}
+
+func Test_UseCases_CreateCustomer_Enrichment(t *testing.T) {
+	verified := enricherStub{name: "phone_verification", phone: "+5491112345678"}
+	failing := enricherStub{name: "geocoding", phone: "garbage", err: errors.New("provider unavailable")}
+
+	tests := []struct {
+		name         string
+		opts         []core.UseCaseOption
+		wantPhone    string
+		wantWarnings []domain.Warning
+	}{
+		{
+			name:         "should apply successful enrichment",
+			opts:         []core.UseCaseOption{core.WithEnrichers(verified)},
+			wantPhone:    "+5491112345678",
+			wantWarnings: []domain.Warning{},
+		},
+		{
+			name:         "should create with core fields and warn when enrichment fails",
+			opts:         []core.UseCaseOption{core.WithEnrichers(verified, failing)},
+			wantPhone:    "+5491112345678",
+			wantWarnings: []domain.Warning{{Step: "geocoding", Message: "provider unavailable"}},
+		},
+		{
+			name:         "should skip disabled enrichers",
+			opts:         []core.UseCaseOption{core.WithEnrichers(verified, failing), core.WithSkippedEnrichers("phone_verification", "geocoding")},
+			wantPhone:    "1234567",
+			wantWarnings: []domain.Warning{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ucs := core.NewUseCases(&repoStub{}, tt.opts...)
+			customer := &domain.Customer{Name: "Ana", Phone: "1234567"}
+
+			warnings, err := ucs.CreateCustomer(context.Background(), customer)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantWarnings, warnings)
+			assert.Equal(t, tt.wantPhone, customer.Phone)
+		})
+	}
+
+	t.Run("should fail when the core record cannot be created", func(t *testing.T) {
+		ucs := core.NewUseCases(&repoStub{err: errors.New("db down")}, core.WithEnrichers(failing))
+
+		warnings, err := ucs.CreateCustomer(context.Background(), &domain.Customer{Name: "Ana"})
+		require.Error(t, err)
+		assert.Nil(t, warnings)
+	})
+}
+
+func Test_UseCases_ExportCustomer(t *testing.T) {
+	customers := []domain.Customer{{ID: 1, Name: "Ana"}, {ID: 2, Name: "Luis"}}
+	events := &eventStoreStub{}
+	for _, event := range []*domain.Event{
+		{Type: domain.EventCustomerCreated, CustomerID: 1, Customer: &customers[0]},
+		{Type: domain.EventCustomerCreated, CustomerID: 2, Customer: &customers[1]},
+		{Type: domain.EventCustomerUpdated, CustomerID: 1, Customer: &customers[0]},
+	} {
+		require.NoError(t, events.Append(context.Background(), event))
+	}
+
+	t.Run("should bundle the customer and only its events", func(t *testing.T) {
+		ucs := core.NewUseCases(&repoStub{customers: customers}, core.WithEventStore(events))
+
+		export, err := ucs.ExportCustomer(context.Background(), 1, "privacy-officer")
+		require.NoError(t, err)
+
+		assert.Equal(t, "Ana", export.Customer.Name)
+		assert.Equal(t, "privacy-officer", export.RequestedBy)
+		assert.False(t, export.ExportedAt.IsZero())
+		require.Len(t, export.Events, 2)
+		assert.Equal(t, domain.EventCustomerCreated, export.Events[0].Type)
+		assert.Equal(t, domain.EventCustomerUpdated, export.Events[1].Type)
+	})
+
+	t.Run("should return an empty history without an event store", func(t *testing.T) {
+		ucs := core.NewUseCases(&repoStub{customers: customers})
+
+		export, err := ucs.ExportCustomer(context.Background(), 2, "privacy-officer")
+		require.NoError(t, err)
+		assert.Empty(t, export.Events)
+		assert.NotNil(t, export.Events)
+	})
+
+	t.Run("should fail when the customer does not exist", func(t *testing.T) {
+		ucs := core.NewUseCases(&repoStub{customers: customers}, core.WithEventStore(events))
+
+		_, err := ucs.ExportCustomer(context.Background(), 99, "privacy-officer")
+		assert.True(t, types.IsNotFound(err))
+	})
+}
+
+func Test_UseCases_AnonymizeCustomer(t *testing.T) {
+	birth := time.Date(1990, 6, 15, 0, 0, 0, 0, time.UTC)
+	created := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	customers := []domain.Customer{
+		{ID: 1, Name: "Ana", LastName: "Perez", Email: "ana@example.com", Phone: "1234567", Age: 34, BirthDate: birth, CreatedAt: created},
+		{ID: 2, Name: "Luis", LastName: "Gomez", Email: "luis@example.com", Phone: "7654321", Age: 50, BirthDate: birth, CreatedAt: created},
+	}
+	repo := &repoStub{customers: customers}
+	events := &eventStoreStub{}
+	require.NoError(t, events.Append(context.Background(), &domain.Event{Type: domain.EventCustomerCreated, CustomerID: 1, Customer: &customers[0]}))
+	ucs := core.NewUseCases(repo, core.WithEventStore(events))
+
+	kpiBefore, err := ucs.GetKPI(context.Background())
+	require.NoError(t, err)
+
+	require.NoError(t, ucs.AnonymizeCustomer(context.Background(), 1))
+
+	anonymized := repo.customers[0]
+	assert.Equal(t, domain.AnonymizedName, anonymized.Name)
+	assert.Equal(t, domain.AnonymizedName, anonymized.LastName)
+	assert.NotContains(t, anonymized.Email, "ana")
+	assert.True(t, strings.HasSuffix(anonymized.Email, "@"+domain.AnonymizedEmailDomain))
+	assert.Empty(t, anonymized.Phone)
+	assert.Equal(t, time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), anonymized.BirthDate)
+	assert.Equal(t, 34, anonymized.Age)
+	assert.Equal(t, created, anonymized.CreatedAt)
+	require.NotNil(t, anonymized.AnonymizedAt)
+
+	t.Run("should keep the record in aggregates", func(t *testing.T) {
+		kpiAfter, err := ucs.GetKPI(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, kpiBefore, kpiAfter)
+
+		counts, err := ucs.CountByField(context.Background(), domain.GroupByAgeBucket)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"30-39": 1, "50-59": 1}, counts)
+	})
+
+	t.Run("should hide the record from the detail", func(t *testing.T) {
+		_, err := ucs.GetCustomerByID(context.Background(), 1)
+		assert.True(t, types.IsNotFound(err))
+
+		err = ucs.AnonymizeCustomer(context.Background(), 1)
+		assert.True(t, types.IsNotFound(err))
+	})
+
+	t.Run("should redact the event history and record the anonymization", func(t *testing.T) {
+		history, err := events.ReadByCustomer(context.Background(), 1)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.Nil(t, history[0].Customer)
+		assert.Equal(t, domain.EventCustomerAnonymized, history[1].Type)
+		require.NotNil(t, history[1].Customer)
+		assert.Equal(t, domain.AnonymizedName, history[1].Customer.Name)
+	})
+}
+
+func Test_UseCases_SoftDeleteCustomer(t *testing.T) {
+	birth := time.Date(1990, 6, 15, 0, 0, 0, 0, time.UTC)
+	repo := &repoStub{customers: []domain.Customer{
+		{ID: 1, Name: "Ana", LastName: "Perez", Email: "ana@example.com", Phone: "1234567", Age: 34, BirthDate: birth},
+		{ID: 2, Name: "Luis", LastName: "Gomez", Email: "luis@example.com", Phone: "7654321", Age: 50, BirthDate: birth},
+	}}
+	events := &eventStoreStub{}
+	ucs := core.NewUseCases(repo, core.WithEventStore(events))
+
+	require.NoError(t, ucs.SoftDeleteCustomer(context.Background(), 1))
+
+	t.Run("should hide the customer from the detail and keep it when including the deleted ones", func(t *testing.T) {
+		_, err := ucs.GetCustomerByID(context.Background(), 1)
+		assert.True(t, types.IsNotFound(err))
+
+		customer, err := ucs.GetCustomerIncludingDeleted(context.Background(), 1)
+		require.NoError(t, err)
+		assert.NotNil(t, customer.DeletedAt)
+
+		page, err := ucs.GetCustomersIncludingDeleted(context.Background(), 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 2, page.Total)
+		assert.Equal(t, []int64{1, 2}, ids(page.Customers))
+	})
+
+	t.Run("should not soft delete twice", func(t *testing.T) {
+		err := ucs.SoftDeleteCustomer(context.Background(), 1)
+		assert.True(t, types.IsNotFound(err))
+	})
+
+	t.Run("should restore the customer once and record both events", func(t *testing.T) {
+		customer, err := ucs.RestoreCustomer(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Nil(t, customer.DeletedAt)
+
+		again, err := ucs.RestoreCustomer(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, customer, again)
+
+		history, err := events.ReadByCustomer(context.Background(), 1)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.Equal(t, domain.EventCustomerSoftDeleted, history[0].Type)
+		assert.Equal(t, domain.EventCustomerRestored, history[1].Type)
+	})
+
+	t.Run("should answer not found for an unknown customer", func(t *testing.T) {
+		assert.True(t, types.IsNotFound(ucs.SoftDeleteCustomer(context.Background(), 99)))
+
+		_, err := ucs.RestoreCustomer(context.Background(), 99)
+		assert.True(t, types.IsNotFound(err))
+	})
+
+	t.Run("should validate the page", func(t *testing.T) {
+		_, err := ucs.GetCustomersIncludingDeleted(context.Background(), 0, 0)
+		errType, ok := types.GetErrorType(err)
+		require.True(t, ok)
+		assert.Equal(t, types.ErrInvalidInput, errType)
+	})
+}
+
+func Test_UseCases_PatchCustomer(t *testing.T) {
+	birth := time.Date(1990, 6, 15, 0, 0, 0, 0, time.UTC)
+	newRepo := func() *repoStub {
+		return &repoStub{customers: []domain.Customer{
+			{ID: 1, Name: "Ana", LastName: "Perez", Email: "ana@example.com", Phone: "1234567", Age: 34, BirthDate: birth},
+		}}
+	}
+
+	t.Run("should change only the age and leave the name untouched", func(t *testing.T) {
+		repo := newRepo()
+		events := &eventStoreStub{}
+		ucs := core.NewUseCases(repo, core.WithEventStore(events))
+
+		age := 35
+		customer, err := ucs.PatchCustomer(context.Background(), 1, domain.CustomerPatch{Age: &age})
+		require.NoError(t, err)
+
+		assert.Equal(t, 35, customer.Age)
+		assert.Equal(t, "Ana", customer.Name)
+		assert.Equal(t, "Perez", customer.LastName)
+		assert.Equal(t, "ana@example.com", customer.Email)
+		assert.Equal(t, "1234567", customer.Phone)
+		assert.Equal(t, birth, customer.BirthDate)
+		assert.Equal(t, *customer, repo.customers[0])
+
+		recorded, err := events.Read(context.Background(), 0)
+		require.NoError(t, err)
+		require.Len(t, recorded, 1)
+		assert.Equal(t, domain.EventCustomerUpdated, recorded[0].Type)
+	})
+
+	t.Run("should clear the phone with an empty value", func(t *testing.T) {
+		repo := newRepo()
+		ucs := core.NewUseCases(repo)
+
+		empty := ""
+		customer, err := ucs.PatchCustomer(context.Background(), 1, domain.CustomerPatch{Phone: &empty})
+		require.NoError(t, err)
+		assert.Empty(t, customer.Phone)
+		assert.Equal(t, 34, customer.Age)
+	})
+
+	t.Run("should not write an empty patch", func(t *testing.T) {
+		repo := newRepo()
+		events := &eventStoreStub{}
+		ucs := core.NewUseCases(repo, core.WithEventStore(events))
+
+		customer, err := ucs.PatchCustomer(context.Background(), 1, domain.CustomerPatch{})
+		require.NoError(t, err)
+		assert.Equal(t, "Ana", customer.Name)
+
+		recorded, err := events.Read(context.Background(), 0)
+		require.NoError(t, err)
+		assert.Empty(t, recorded)
+	})
+
+	t.Run("should not apply the patch when the precondition fails", func(t *testing.T) {
+		repo := newRepo()
+		events := &eventStoreStub{}
+		ucs := core.NewUseCases(repo, core.WithEventStore(events))
+
+		name := "Luis"
+		var checked domain.Customer
+		_, err := ucs.PatchCustomer(context.Background(), 1, domain.CustomerPatch{
+			Name: &name,
+			Precondition: func(current domain.Customer) error {
+				checked = current
+				return types.NewError(types.ErrConflict, "email changed", nil)
+			},
+		})
+		errType, ok := types.GetErrorType(err)
+		require.True(t, ok)
+		assert.Equal(t, types.ErrConflict, errType)
+		assert.Equal(t, "ana@example.com", checked.Email)
+		assert.Equal(t, "Ana", repo.customers[0].Name)
+
+		recorded, err := events.Read(context.Background(), 0)
+		require.NoError(t, err)
+		assert.Empty(t, recorded)
+	})
+
+	t.Run("should check the precondition of a patch without fields", func(t *testing.T) {
+		ucs := core.NewUseCases(newRepo())
+
+		var called bool
+		customer, err := ucs.PatchCustomer(context.Background(), 1, domain.CustomerPatch{
+			Precondition: func(current domain.Customer) error {
+				called = true
+				return nil
+			},
+		})
+		require.NoError(t, err)
+		assert.True(t, called)
+		assert.Equal(t, "Ana", customer.Name)
+	})
+
+	t.Run("should return not found for a missing customer", func(t *testing.T) {
+		ucs := core.NewUseCases(newRepo())
+
+		name := "Luis"
+		_, err := ucs.PatchCustomer(context.Background(), 99, domain.CustomerPatch{Name: &name})
+		assert.True(t, types.IsNotFound(err))
+	})
+}
+
+func Test_UseCases_DefaultSort(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	customers := []domain.Customer{
+		{ID: 1, Name: "Carla", Age: 40, CreatedAt: base.Add(2 * time.Hour)},
+		{ID: 2, Name: "Ana", Age: 30, CreatedAt: base},
+		{ID: 3, Name: "Bruno", Age: 30, CreatedAt: base.Add(2 * time.Hour)},
+	}
+
+	tests := []struct {
+		name    string
+		sort    string
+		wantIDs []int64
+	}{
+		{name: "should keep creation order without a configured sort", sort: "", wantIDs: []int64{1, 2, 3}},
+		{name: "should apply created_at desc breaking ties by id", sort: "created_at desc", wantIDs: []int64{1, 3, 2}},
+		{name: "should apply age asc breaking ties by id", sort: "age", wantIDs: []int64{2, 3, 1}},
+		{name: "should apply name asc", sort: "NAME asc", wantIDs: []int64{2, 3, 1}},
+		{name: "should apply id desc", sort: "id desc", wantIDs: []int64{3, 2, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sort, err := domain.ParseCustomerSort(tt.sort)
+			require.NoError(t, err)
+			repo := &repoStub{customers: customers}
+			ucs := core.NewUseCases(repo, core.WithDefaultSort(sort))
+
+			list, err := ucs.GetCustomers(context.Background())
+			require.NoError(t, err)
+			ids := make([]int64, len(list))
+			for i, c := range list {
+				ids[i] = c.ID
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+			assert.Equal(t, int64(1), customers[0].ID, "the repository slice must not be reordered")
+
+			_, err = ucs.GetCustomersPaged(context.Background(), 10, 0)
+			require.NoError(t, err)
+			assert.Equal(t, sort, repo.sort, "the page must be requested in the default order")
+		})
+	}
+
+	t.Run("should reject an unknown sort", func(t *testing.T) {
+		for _, value := range []string{"email", "age sideways", "age desc extra"} {
+			_, err := domain.ParseCustomerSort(value)
+			assert.Error(t, err, value)
+		}
+	})
+}
+
+func Test_UseCases_CustomerNotFoundCode(t *testing.T) {
+	ucs := core.NewUseCases(&repoStub{customers: []domain.Customer{}})
+	ctx := context.Background()
+	name := "Luis"
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{name: "get by id", call: func() error { _, err := ucs.GetCustomerByID(ctx, 99); return err }},
+		{name: "get by email", call: func() error { _, err := ucs.GetCustomerByEmail(ctx, "nobody@example.com"); return err }},
+		{name: "update", call: func() error { return ucs.UpdateCustomer(ctx, &domain.Customer{ID: 99}) }},
+		{name: "patch", call: func() error {
+			_, err := ucs.PatchCustomer(ctx, 99, domain.CustomerPatch{Name: &name})
+			return err
+		}},
+		{name: "delete", call: func() error { return ucs.DeleteCustomer(ctx, 99) }},
+		{name: "anonymize", call: func() error { return ucs.AnonymizeCustomer(ctx, 99) }},
+	}
+
+	for _, tt := range tests {
+		t.Run("should return CUSTOMER_NOT_FOUND on "+tt.name, func(t *testing.T) {
+			err := tt.call()
+			require.True(t, types.IsNotFound(err))
+
+			apiErr, status := types.NewAPIError(err)
+			assert.Equal(t, http.StatusNotFound, status)
+			assert.Equal(t, types.APIErrorType(domain.CodeCustomerNotFound), apiErr.Type)
+		})
+	}
+}
+
+func Test_UseCases_NameCollation(t *testing.T) {
+	anonymizedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	customers := []domain.Customer{
+		{ID: 1, Name: "Zoe"},
+		{ID: 2, Name: "Älva"},
+		{ID: 3, Name: "Ana"},
+		{ID: 4, Name: "Émile"},
+		{ID: 5, Name: "Bruno"},
+		{ID: 6, Name: "Ábel", AnonymizedAt: &anonymizedAt},
+	}
+	byName := domain.CustomerSort{Field: domain.SortByName}
+
+	names := func(list []domain.Customer) []string {
+		result := make([]string, len(list))
+		for i, c := range list {
+			result[i] = c.Name
+		}
+		return result
+	}
+
+	t.Run("should sort names by byte order without a collation", func(t *testing.T) {
+		ucs := core.NewUseCases(&repoStub{customers: customers[:5]}, core.WithDefaultSort(byName))
+
+		list, err := ucs.GetCustomers(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Ana", "Bruno", "Zoe", "Älva", "Émile"}, names(list))
+	})
+
+	t.Run("should sort names with the locale collation", func(t *testing.T) {
+		collation, err := domain.ParseNameCollation("de")
+		require.NoError(t, err)
+		ucs := core.NewUseCases(&repoStub{customers: customers[:5]}, core.WithDefaultSort(byName), core.WithNameCollation(collation))
+
+		list, err := ucs.GetCustomers(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Älva", "Ana", "Bruno", "Émile", "Zoe"}, names(list))
+	})
+
+	t.Run("should leave anonymized customers out of the full listing", func(t *testing.T) {
+		ucs := core.NewUseCases(&repoStub{customers: customers}, core.WithDefaultSort(byName))
+
+		list, err := ucs.GetCustomers(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Ana", "Bruno", "Zoe", "Älva", "Émile"}, names(list))
+	})
+
+	t.Run("should sort collated pages in memory without anonymized customers", func(t *testing.T) {
+		collation, err := domain.ParseNameCollation("de")
+		require.NoError(t, err)
+		repo := &repoStub{customers: customers}
+		ucs := core.NewUseCases(repo, core.WithDefaultSort(byName), core.WithNameCollation(collation))
+
+		page, err := ucs.GetCustomersPaged(context.Background(), 2, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Ana", "Bruno"}, names(page.Customers))
+		assert.Equal(t, domain.CustomerSort{}, repo.sort, "the page must not be requested to the repository")
+
+		page, err = ucs.GetCustomersPaged(context.Background(), 2, 10)
+		require.NoError(t, err)
+		assert.Empty(t, page.Customers)
+	})
+
+	t.Run("should reject an invalid locale", func(t *testing.T) {
+		_, err := domain.ParseNameCollation("not a locale")
+		assert.Error(t, err)
+	})
+}
+
+// slowKPIRepo cuenta las lecturas de GetAll y las bloquea hasta que se cierra release
+type slowKPIRepo struct {
+	*repoStub
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *slowKPIRepo) GetAll(ctx context.Context) ([]domain.Customer, error) {
+	if r.calls.Add(1) == 1 {
+		close(r.started)
+	}
+	<-r.release
+	return r.customers, nil
+}
+
+func Test_UseCases_GetKPI_Coalescing(t *testing.T) {
+	newRepo := func() *slowKPIRepo {
+		return &slowKPIRepo{
+			repoStub: &repoStub{customers: []domain.Customer{{ID: 1, Age: 30}, {ID: 2, Age: 50}}},
+			started:  make(chan struct{}),
+			release:  make(chan struct{}),
+		}
+	}
+
+	t.Run("should share one computation among concurrent requests", func(t *testing.T) {
+		repo := newRepo()
+		ucs := core.NewUseCases(repo, core.WithKPICoalescingWindow(time.Minute))
+
+		const requests = 20
+		results := make([]*domain.KPI, requests)
+		var wg sync.WaitGroup
+		for i := range requests {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				kpi, err := ucs.GetKPI(context.Background())
+				assert.NoError(t, err)
+				results[i] = kpi
+			}()
+		}
+
+		<-repo.started
+		close(repo.release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), repo.calls.Load())
+		for _, kpi := range results {
+			assert.Same(t, results[0], kpi)
+		}
+		assert.Equal(t, float64(40), results[0].AverageAge)
+	})
+
+	t.Run("should recompute sequential requests without a window", func(t *testing.T) {
+		repo := newRepo()
+		close(repo.release)
+		ucs := core.NewUseCases(repo)
+
+		for range 2 {
+			_, err := ucs.GetKPI(context.Background())
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(2), repo.calls.Load())
+	})
+}
+
+func Test_UseCases_GetKPIForRange(t *testing.T) {
+	jan := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+	may := time.Date(2025, 5, 15, 0, 0, 0, 0, time.UTC)
+	customers := []domain.Customer{
+		{ID: 1, Age: 20, CreatedAt: jan},
+		{ID: 2, Age: 30, CreatedAt: mar},
+		{ID: 3, Age: 50, CreatedAt: may},
+	}
+
+	tests := []struct {
+		name    string
+		from    time.Time
+		to      time.Time
+		wantAvg float64
+	}{
+		{name: "should use every customer without a range", wantAvg: 100.0 / 3},
+		{name: "should include both ends of the range", from: mar, to: may, wantAvg: 40},
+		{name: "should leave the range open without to", from: mar, wantAvg: 40},
+		{name: "should leave the range open without from", to: mar, wantAvg: 25},
+		{name: "should return zero KPIs for an empty range", from: may.Add(time.Hour), wantAvg: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ucs := core.NewUseCases(&repoStub{customers: customers})
+
+			kpi, err := ucs.GetKPIForRange(context.Background(), tt.from, tt.to)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.wantAvg, kpi.AverageAge, 1e-9)
+		})
+	}
+
+	t.Run("should reject an inverted range", func(t *testing.T) {
+		ucs := core.NewUseCases(&repoStub{customers: customers})
+
+		_, err := ucs.GetKPIForRange(context.Background(), may, jan)
+		errType, ok := types.GetErrorType(err)
+		require.True(t, ok)
+		assert.Equal(t, types.ErrInvalidInput, errType)
+	})
+}
+
+// conflictRepo asigna IDs en Create y responde conflicto para un email ya dado de alta
+type conflictRepo struct {
+	*repoStub
+	emails map[string]bool
+}
+
+func (r *conflictRepo) Create(ctx context.Context, customer *domain.Customer) error {
+	if r.emails[customer.Email] {
+		return types.NewError(types.ErrConflict, "email already exists", nil)
+	}
+	r.emails[customer.Email] = true
+	customer.ID = int64(len(r.emails))
+	return nil
+}
+
+func Test_UseCases_CreateCustomers(t *testing.T) {
+	batch := []domain.Customer{{Email: "ana@example.com"}, {Email: "luis@example.com"}, {Email: "eva@example.com"}}
+
+	t.Run("should create every customer and record its event", func(t *testing.T) {
+		events := &eventStoreStub{}
+		ucs := core.NewUseCases(&conflictRepo{repoStub: &repoStub{}, emails: map[string]bool{}}, core.WithEventStore(events))
+
+		created, err := ucs.CreateCustomers(context.Background(), batch)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1, 2, 3}, ids(created))
+		assert.Zero(t, batch[0].ID, "the input batch must not be modified")
+		assert.Len(t, events.events, 3)
+	})
+
+	t.Run("should report the position of the item that failed", func(t *testing.T) {
+		ucs := core.NewUseCases(&conflictRepo{repoStub: &repoStub{}, emails: map[string]bool{"luis@example.com": true}})
+
+		_, err := ucs.CreateCustomers(context.Background(), batch)
+		var itemErr *domain.BatchItemError
+		require.ErrorAs(t, err, &itemErr)
+		assert.Equal(t, 1, itemErr.Index)
+		assert.True(t, types.IsConflict(err))
+	})
+
+	t.Run("should wrap other failures as operation failed", func(t *testing.T) {
+		ucs := core.NewUseCases(&repoStub{err: errors.New("disk full")})
+
+		_, err := ucs.CreateCustomers(context.Background(), batch)
+		var itemErr *domain.BatchItemError
+		require.ErrorAs(t, err, &itemErr)
+		assert.Equal(t, 0, itemErr.Index)
+		errType, ok := types.GetErrorType(err)
+		require.True(t, ok)
+		assert.Equal(t, types.ErrOperationFailed, errType)
+	})
+
+	t.Run("should reject an empty batch", func(t *testing.T) {
+		ucs := core.NewUseCases(&repoStub{})
+
+		_, err := ucs.CreateCustomers(context.Background(), nil)
+		errType, ok := types.GetErrorType(err)
+		require.True(t, ok)
+		assert.Equal(t, types.ErrValidation, errType)
+	})
+}
+
+// storingRepo guarda en memoria los customers que se dan de alta
+type storingRepo struct {
+	*repoStub
+}
+
+func (r *storingRepo) Create(ctx context.Context, customer *domain.Customer) error {
+	customer.ID = int64(len(r.customers) + 1)
+	r.customers = append(r.customers, *customer)
+	return nil
+}
+
+// idempotencyStoreStub implementa ports.IdempotencyStore en memoria
+type idempotencyStoreStub struct {
+	records map[string]domain.IdempotencyRecord
+}
+
+func (s *idempotencyStoreStub) Get(ctx context.Context, key string) (*domain.IdempotencyRecord, error) {
+	record, ok := s.records[key]
+	if !ok {
+		return nil, types.NewError(types.ErrNotFound, "idempotency key not found", nil)
+	}
+	return &record, nil
+}
+
+func (s *idempotencyStoreStub) Save(ctx context.Context, record *domain.IdempotencyRecord, expiredAt time.Time) error {
+	if current, ok := s.records[record.Key]; ok && current.CreatedAt.After(expiredAt) {
+		return types.NewError(types.ErrConflict, "idempotency key already used", nil)
+	}
+	s.records[record.Key] = *record
+	return nil
+}
+
+// racingIdempotencyStore simula un request concurrente que graba la key entre el Get y el Save de otro
+type racingIdempotencyStore struct {
+	*idempotencyStoreStub
+	winner domain.IdempotencyRecord
+	raced  bool
+}
+
+func (s *racingIdempotencyStore) Get(ctx context.Context, key string) (*domain.IdempotencyRecord, error) {
+	if !s.raced {
+		s.raced = true
+		record, err := s.idempotencyStoreStub.Get(ctx, key)
+		s.records[s.winner.Key] = s.winner
+		return record, err
+	}
+	return s.idempotencyStoreStub.Get(ctx, key)
+}
+
+func Test_UseCases_CreateCustomerIdempotent(t *testing.T) {
+	newCustomer := func() *domain.Customer {
+		return &domain.Customer{Name: "Ana", Email: "ana@example.com"}
+	}
+
+	t.Run("should create a single customer for a repeated key", func(t *testing.T) {
+		repo := &storingRepo{repoStub: &repoStub{}}
+		ucs := core.NewUseCases(repo, core.WithIdempotencyStore(&idempotencyStoreStub{records: map[string]domain.IdempotencyRecord{}}, time.Hour))
+
+		first := newCustomer()
+		_, replayed, err := ucs.CreateCustomerIdempotent(context.Background(), "retry-1", first)
+		require.NoError(t, err)
+		assert.False(t, replayed)
+
+		second := newCustomer()
+		_, replayed, err = ucs.CreateCustomerIdempotent(context.Background(), "retry-1", second)
+		require.NoError(t, err)
+		assert.True(t, replayed)
+		assert.Equal(t, first.ID, second.ID)
+		assert.Len(t, repo.customers, 1)
+
+		_, replayed, err = ucs.CreateCustomerIdempotent(context.Background(), "retry-2", newCustomer())
+		require.NoError(t, err)
+		assert.False(t, replayed)
+		assert.Len(t, repo.customers, 2)
+	})
+
+	t.Run("should create again once the key expired", func(t *testing.T) {
+		repo := &storingRepo{repoStub: &repoStub{}}
+		ucs := core.NewUseCases(repo, core.WithIdempotencyStore(&idempotencyStoreStub{records: map[string]domain.IdempotencyRecord{}}, time.Nanosecond))
+
+		for range 2 {
+			_, replayed, err := ucs.CreateCustomerIdempotent(context.Background(), "retry-1", newCustomer())
+			require.NoError(t, err)
+			assert.False(t, replayed)
+		}
+		assert.Len(t, repo.customers, 2)
+	})
+
+	t.Run("should replay the concurrent request that saved the key first", func(t *testing.T) {
+		repo := &storingRepo{repoStub: &repoStub{}}
+		winner := newCustomer()
+		require.NoError(t, repo.Create(context.Background(), winner))
+		store := &racingIdempotencyStore{
+			idempotencyStoreStub: &idempotencyStoreStub{records: map[string]domain.IdempotencyRecord{}},
+			winner:               domain.IdempotencyRecord{Key: "retry-1", CustomerID: winner.ID, CreatedAt: time.Now().UTC()},
+		}
+		ucs := core.NewUseCases(repo, core.WithIdempotencyStore(store, time.Hour))
+
+		customer := newCustomer()
+		_, replayed, err := ucs.CreateCustomerIdempotent(context.Background(), "retry-1", customer)
+		require.NoError(t, err)
+		assert.True(t, replayed)
+		assert.Equal(t, winner.ID, customer.ID)
+		assert.Equal(t, winner.ID, store.records["retry-1"].CustomerID)
+	})
+
+	t.Run("should ignore the key without an idempotency store", func(t *testing.T) {
+		repo := &storingRepo{repoStub: &repoStub{}}
+		ucs := core.NewUseCases(repo)
+
+		for range 2 {
+			_, replayed, err := ucs.CreateCustomerIdempotent(context.Background(), "retry-1", newCustomer())
+			require.NoError(t, err)
+			assert.False(t, replayed)
+		}
+		assert.Len(t, repo.customers, 2)
+	})
+
+	t.Run("should reject a key that is too long", func(t *testing.T) {
+		ucs := core.NewUseCases(&storingRepo{repoStub: &repoStub{}}, core.WithIdempotencyStore(&idempotencyStoreStub{records: map[string]domain.IdempotencyRecord{}}, time.Hour))
+
+		_, _, err := ucs.CreateCustomerIdempotent(context.Background(), strings.Repeat("k", domain.MaxIdempotencyKeyLength+1), newCustomer())
+		errType, ok := types.GetErrorType(err)
+		require.True(t, ok)
+		assert.Equal(t, types.ErrValidation, errType)
+	})
+}